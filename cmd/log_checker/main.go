@@ -1,19 +1,51 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"github.com/joho/godotenv"
+	"os"
+	"uptime/config"
 	"uptime/database"
 	"uptime/internal/logcleanup"
+	"uptime/internal/rollup"
+	"uptime/repositories"
 )
 
 func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would be deleted without deleting it")
+	flag.Parse()
+
 	fmt.Println("Starting log cleanup process...")
 
 	if err := godotenv.Load(); err != nil {
 		fmt.Println("Warning: No .env file found")
 	}
 
-	database.Connect()
-	logcleanup.CleanupOldLogs()
+	config.Load()
+	if err := database.Connect(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	store := repositories.NewSQLStore(database.DB)
+	if !*dryRun {
+		rollup.Run(store)
+		logcleanup.CleanupOldLogs(store)
+		return
+	}
+
+	results, err := logcleanup.Run(store, true)
+	for _, r := range results {
+		scope := "global policy"
+		if r.GroupID != nil {
+			scope = fmt.Sprintf("group %d", *r.GroupID)
+		}
+		fmt.Printf("%-24s %-14s older than %s: %d row(s) would be deleted\n",
+			r.Tier, scope, r.Cutoff.Format("2006-01-02 15:04:05"), r.Rows)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+
+	"uptime/config"
+	"uptime/database"
+	"uptime/internal/migrate"
+)
+
+const usage = `Usage: migrate <command> [arguments]
+
+Commands:
+  up [N]       apply all pending migrations, or only the next N
+  down [N]     roll back the last N applied migrations (default 1)
+  status       list migrations and when they were applied
+  create NAME  write empty up and down files for a new migration
+`
+
+func main() {
+	dir := flag.String("dir", "internal/migrate/migrations", "migrations directory used by create")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		fmt.Fprintln(os.Stderr, "\nFlags:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		paths, err := migrate.Create(*dir, args[1])
+		for _, p := range paths {
+			fmt.Println("Created", p)
+		}
+		exitOnError(err)
+		return
+	}
+
+	if err := godotenv.Load(); err != nil {
+		fmt.Println("Warning: No .env file found")
+	}
+	config.Load()
+	exitOnError(database.Connect())
+	sqlDB, err := database.DB.DB()
+	exitOnError(err)
+
+	switch args[0] {
+	case "up":
+		applied, err := migrate.Up(sqlDB, database.Driver(), steps(args, 0))
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		exitOnError(err)
+	case "down":
+		reverted, err := migrate.Down(sqlDB, database.Driver(), steps(args, 1))
+		for _, m := range reverted {
+			fmt.Printf("Rolled back %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("No migration to roll back")
+		}
+		exitOnError(err)
+	case "status":
+		statuses, err := migrate.GetStatus(sqlDB, database.Driver())
+		exitOnError(err)
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Dirty {
+				state += " (dirty)"
+			}
+			fmt.Printf("%04d_%-32s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// steps reads the optional step count following the command
+func steps(args []string, fallback int) int {
+	if len(args) < 2 {
+		return fallback
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n <= 0 {
+		fmt.Fprintf(os.Stderr, "invalid step count %q\n", args[1])
+		os.Exit(2)
+	}
+	return n
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"strings"
 
 	"github.com/joho/godotenv"
+	"gorm.io/gorm"
 
+	"uptime/config"
 	"uptime/database"
 	"uptime/models"
 )
@@ -24,7 +26,11 @@ func main() {
 		fmt.Println("Warning: No .env file found")
 	}
 
-	database.Connect()
+	config.Load()
+	if err := database.Connect(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	apiKey := os.Getenv("UPTIME_API_KEY")
 	if apiKey == "" {
@@ -90,27 +96,51 @@ func syncNodes(urls []string) {
 		existingUrls[i] = node.URL
 	}
 
-	toDelete := difference(existingUrls, urls)
-	if len(toDelete) > 0 {
-		if err := database.DB.Where("url IN ?", toDelete).Delete(&models.Node{}).Error; err != nil {
-			fmt.Printf("Error deleting nodes: %v\n", err)
+	// Nodes dropped from the list are archived rather than deleted, so their
+	// logs keep reporting, and come back with their history if re-listed
+	var archivedNodes []models.Node
+	if err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").Find(&archivedNodes).Error; err != nil {
+		fmt.Printf("Error fetching archived nodes: %v\n", err)
+		return
+	}
+	archivedIDs := make(map[string]uint, len(archivedNodes))
+	for _, node := range archivedNodes {
+		archivedIDs[node.URL] = node.ID
+	}
+
+	toArchive := difference(existingUrls, urls)
+	if len(toArchive) > 0 {
+		if err := archiveNodes(toArchive); err != nil {
+			fmt.Printf("Error archiving nodes: %v\n", err)
 		} else {
-			fmt.Printf("Deleted %d node(s): %s\n", len(toDelete), strings.Join(toDelete, ", "))
+			fmt.Printf("Archived %d node(s): %s\n", len(toArchive), strings.Join(toArchive, ", "))
 		}
 	}
 
 	toAdd := difference(urls, existingUrls)
-	var successCount int
+	var successCount, restoredCount int
 	for _, url := range toAdd {
-		if strings.TrimSpace(url) != "" {
-			if err := database.DB.Create(&models.Node{URL: url}).Error; err != nil {
-				fmt.Printf("Error adding node %s: %v\n", url, err)
+		if strings.TrimSpace(url) == "" {
+			continue
+		}
+		if id, ok := archivedIDs[url]; ok {
+			if err := restoreNode(id); err != nil {
+				fmt.Printf("Error restoring node %s: %v\n", url, err)
 			} else {
-				successCount++
+				restoredCount++
 			}
+			continue
+		}
+		if err := database.DB.Create(&models.Node{URL: url, Lifecycle: models.NodeActive}).Error; err != nil {
+			fmt.Printf("Error adding node %s: %v\n", url, err)
+		} else {
+			successCount++
 		}
 	}
 
+	if restoredCount > 0 {
+		fmt.Printf("Restored %d archived node(s)\n", restoredCount)
+	}
 	if successCount > 0 {
 		fmt.Printf("Successfully added %d new node(s)\n", successCount)
 	} else if len(toAdd) == 0 {
@@ -118,6 +148,24 @@ func syncNodes(urls []string) {
 	}
 }
 
+// archiveNodes marks the nodes monitoring urls archived and soft deletes them
+func archiveNodes(urls []string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Node{}).Where("url IN ?", urls).
+			Updates(map[string]interface{}{"lifecycle": models.NodeArchived, "paused_until": nil}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("url IN ?", urls).Delete(&models.Node{}).Error
+	})
+}
+
+// restoreNode brings an archived node back as active
+func restoreNode(id uint) error {
+	return database.DB.Unscoped().Model(&models.Node{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "lifecycle": models.NodeActive}).Error
+}
+
 func difference(a, b []string) []string {
 	m := make(map[string]bool)
 	for _, item := range b {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/joho/godotenv"
 
+	"uptime/config"
 	"uptime/database"
 	"uptime/models"
 )
@@ -26,7 +27,11 @@ func main() {
 		fmt.Println("Warning: No .env file found")
 	}
 
-	database.Connect()
+	config.Load()
+	if err := database.Connect(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	apiKey := os.Getenv("UPTIME_API_KEY")
 	if apiKey == "" {
@@ -78,11 +83,12 @@ func main() {
 			continue
 		}
 
+		// archived nodes count as existing, node_sync restores them
 		var node models.Node
-		err := database.DB.Where("url = ?", url).First(&node).Error
+		err := database.DB.Unscoped().Where("url = ?", url).First(&node).Error
 		if err != nil {
 			if strings.Contains(err.Error(), "record not found") {
-				if createErr := database.DB.Create(&models.Node{URL: url}).Error; createErr != nil {
+				if createErr := database.DB.Create(&models.Node{URL: url, Lifecycle: models.NodeActive}).Error; createErr != nil {
 					fmt.Printf("Error creating node for URL %s: %v\n", url, createErr)
 				} else {
 					successCount++
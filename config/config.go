@@ -11,15 +11,45 @@ import (
 
 type Config struct {
 	Database struct {
-		DSN string
+		Driver string // mysql, postgres or sqlite
+		DSN    string
 	}
 	Server struct {
 		Port string
 	}
 	UptimeChecker struct {
-		CheckInterval   time.Duration
-		RequestTimeout  time.Duration
-		MaxWorkers      int
+		CheckInterval     time.Duration
+		RequestTimeout    time.Duration
+		MaxWorkers        int
+		CertExpiryWarning time.Duration
+		Retries           int
+		RetryDelay        time.Duration
+		ConfirmCycles     int
+		Jitter            float64
+		ReloadInterval    time.Duration
+		OverlapPolicy     string
+		// Check results are written in batches of WriteBatchSize, or every
+		// WriteFlushInterval, with at most WriteQueueSize waiting
+		WriteBatchSize     int
+		WriteFlushInterval time.Duration
+		WriteQueueSize     int
+	}
+	Notifications struct {
+		WebhookTimeout   time.Duration
+		SMTPTimeout      time.Duration
+		RetryDelay       time.Duration
+		TelegramAPIURL   string
+		ChatBatchWindow  time.Duration
+		ChatMaxBatchSize int
+	}
+	Retention struct {
+		RawLogs       time.Duration // 0 keeps the data forever
+		HourlyRollups time.Duration
+		DailyRollups  time.Duration
+		Incidents     time.Duration
+		Deliveries    time.Duration
+		BatchSize     int
+		Schedule      string
 	}
 	API struct {
 		Key string
@@ -32,38 +62,180 @@ func Load() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using system environment variables")
 	}
-	
+
 	AppConfig = &Config{}
-	
-	AppConfig.Database.DSN = getEnv("MYSQL_DSN", "root:@tcp(127.0.0.1:3306)/ms-uptime?charset=utf8mb4&parseTime=True&loc=Local")
-	
+
+	AppConfig.Database.Driver = getEnv("DB_DRIVER", "mysql")
+	switch AppConfig.Database.Driver {
+	case "postgres":
+		AppConfig.Database.DSN = getEnv("POSTGRES_DSN", "host=127.0.0.1 user=postgres dbname=uptime sslmode=disable")
+	case "sqlite":
+		AppConfig.Database.DSN = getEnv("SQLITE_PATH", "uptime.db")
+	default:
+		AppConfig.Database.DSN = getEnv("MYSQL_DSN", "root:@tcp(127.0.0.1:3306)/ms-uptime?charset=utf8mb4&parseTime=True&loc=Local")
+	}
+
 	AppConfig.Server.Port = getEnv("PORT", "3000")
-	
+
 	checkIntervalStr := getEnv("CHECK_INTERVAL", "5m")
 	if checkInterval, err := time.ParseDuration(checkIntervalStr); err == nil {
 		AppConfig.UptimeChecker.CheckInterval = checkInterval
 	} else {
 		AppConfig.UptimeChecker.CheckInterval = 1 * time.Minute
 	}
-	
+
 	timeoutStr := getEnv("REQUEST_TIMEOUT", "60s")
 	if timeout, err := time.ParseDuration(timeoutStr); err == nil {
 		AppConfig.UptimeChecker.RequestTimeout = timeout
 	} else {
 		AppConfig.UptimeChecker.RequestTimeout = 60 * time.Second
 	}
-	
+
 	maxWorkersStr := getEnv("MAX_WORKERS", "50")
 	if maxWorkers, err := strconv.Atoi(maxWorkersStr); err == nil {
 		AppConfig.UptimeChecker.MaxWorkers = maxWorkers
 	} else {
 		AppConfig.UptimeChecker.MaxWorkers = 50
 	}
-	
+
+	certDaysStr := getEnv("CERT_EXPIRY_WARNING_DAYS", "14")
+	if certDays, err := strconv.Atoi(certDaysStr); err == nil && certDays >= 0 {
+		AppConfig.UptimeChecker.CertExpiryWarning = time.Duration(certDays) * 24 * time.Hour
+	} else {
+		AppConfig.UptimeChecker.CertExpiryWarning = 14 * 24 * time.Hour
+	}
+
+	retriesStr := getEnv("CHECK_RETRIES", "2")
+	if retries, err := strconv.Atoi(retriesStr); err == nil && retries >= 0 {
+		AppConfig.UptimeChecker.Retries = retries
+	} else {
+		AppConfig.UptimeChecker.Retries = 2
+	}
+
+	retryDelayStr := getEnv("CHECK_RETRY_DELAY", "2s")
+	if retryDelay, err := time.ParseDuration(retryDelayStr); err == nil {
+		AppConfig.UptimeChecker.RetryDelay = retryDelay
+	} else {
+		AppConfig.UptimeChecker.RetryDelay = 2 * time.Second
+	}
+
+	confirmStr := getEnv("CHECK_CONFIRM_CYCLES", "1")
+	if confirm, err := strconv.Atoi(confirmStr); err == nil && confirm >= 1 {
+		AppConfig.UptimeChecker.ConfirmCycles = confirm
+	} else {
+		AppConfig.UptimeChecker.ConfirmCycles = 1
+	}
+
+	jitterStr := getEnv("CHECK_JITTER", "0.1")
+	if jitter, err := strconv.ParseFloat(jitterStr, 64); err == nil && jitter >= 0 && jitter < 1 {
+		AppConfig.UptimeChecker.Jitter = jitter
+	} else {
+		AppConfig.UptimeChecker.Jitter = 0.1
+	}
+
+	reloadStr := getEnv("SCHEDULER_RELOAD_INTERVAL", "30s")
+	if reload, err := time.ParseDuration(reloadStr); err == nil && reload > 0 {
+		AppConfig.UptimeChecker.ReloadInterval = reload
+	} else {
+		AppConfig.UptimeChecker.ReloadInterval = 30 * time.Second
+	}
+
+	switch policy := getEnv("CHECK_OVERLAP_POLICY", "skip"); policy {
+	case "skip", "queue":
+		AppConfig.UptimeChecker.OverlapPolicy = policy
+	default:
+		log.Printf("Unknown CHECK_OVERLAP_POLICY %q, using skip", policy)
+		AppConfig.UptimeChecker.OverlapPolicy = "skip"
+	}
+
+	writeBatchStr := getEnv("WRITE_BATCH_SIZE", "500")
+	if writeBatch, err := strconv.Atoi(writeBatchStr); err == nil && writeBatch > 0 {
+		AppConfig.UptimeChecker.WriteBatchSize = writeBatch
+	} else {
+		AppConfig.UptimeChecker.WriteBatchSize = 500
+	}
+
+	writeFlushStr := getEnv("WRITE_FLUSH_INTERVAL", "1s")
+	if writeFlush, err := time.ParseDuration(writeFlushStr); err == nil && writeFlush > 0 {
+		AppConfig.UptimeChecker.WriteFlushInterval = writeFlush
+	} else {
+		AppConfig.UptimeChecker.WriteFlushInterval = time.Second
+	}
+
+	writeQueueStr := getEnv("WRITE_QUEUE_SIZE", "5000")
+	if writeQueue, err := strconv.Atoi(writeQueueStr); err == nil && writeQueue > 0 {
+		AppConfig.UptimeChecker.WriteQueueSize = writeQueue
+	} else {
+		AppConfig.UptimeChecker.WriteQueueSize = 5000
+	}
+
+	webhookTimeoutStr := getEnv("WEBHOOK_TIMEOUT", "10s")
+	if webhookTimeout, err := time.ParseDuration(webhookTimeoutStr); err == nil && webhookTimeout > 0 {
+		AppConfig.Notifications.WebhookTimeout = webhookTimeout
+	} else {
+		AppConfig.Notifications.WebhookTimeout = 10 * time.Second
+	}
+
+	smtpTimeoutStr := getEnv("SMTP_TIMEOUT", "30s")
+	if smtpTimeout, err := time.ParseDuration(smtpTimeoutStr); err == nil && smtpTimeout > 0 {
+		AppConfig.Notifications.SMTPTimeout = smtpTimeout
+	} else {
+		AppConfig.Notifications.SMTPTimeout = 30 * time.Second
+	}
+
+	// WEBHOOK_RETRY_DELAY is the name used before email channels existed
+	notifyRetryStr := getEnv("NOTIFY_RETRY_DELAY", getEnv("WEBHOOK_RETRY_DELAY", "5s"))
+	if notifyRetry, err := time.ParseDuration(notifyRetryStr); err == nil && notifyRetry > 0 {
+		AppConfig.Notifications.RetryDelay = notifyRetry
+	} else {
+		AppConfig.Notifications.RetryDelay = 5 * time.Second
+	}
+
+	AppConfig.Notifications.TelegramAPIURL = getEnv("TELEGRAM_API_URL", "https://api.telegram.org")
+
+	chatWindowStr := getEnv("CHAT_BATCH_WINDOW", "30s")
+	if chatWindow, err := time.ParseDuration(chatWindowStr); err == nil && chatWindow >= 0 {
+		AppConfig.Notifications.ChatBatchWindow = chatWindow
+	} else {
+		AppConfig.Notifications.ChatBatchWindow = 30 * time.Second
+	}
+
+	chatBatchStr := getEnv("CHAT_MAX_BATCH_LINES", "30")
+	if chatBatch, err := strconv.Atoi(chatBatchStr); err == nil && chatBatch > 0 {
+		AppConfig.Notifications.ChatMaxBatchSize = chatBatch
+	} else {
+		AppConfig.Notifications.ChatMaxBatchSize = 30
+	}
+
+	AppConfig.Retention.RawLogs = getDays("RETENTION_RAW_LOG_DAYS", 31)
+	AppConfig.Retention.HourlyRollups = getDays("RETENTION_HOURLY_ROLLUP_DAYS", 90)
+	AppConfig.Retention.DailyRollups = getDays("RETENTION_DAILY_ROLLUP_DAYS", 730)
+	AppConfig.Retention.Incidents = getDays("RETENTION_INCIDENT_DAYS", 365)
+	AppConfig.Retention.Deliveries = getDays("RETENTION_DELIVERY_DAYS", 30)
+
+	batchSizeStr := getEnv("CLEANUP_BATCH_SIZE", "5000")
+	if batchSize, err := strconv.Atoi(batchSizeStr); err == nil && batchSize > 0 {
+		AppConfig.Retention.BatchSize = batchSize
+	} else {
+		AppConfig.Retention.BatchSize = 5000
+	}
+
+	AppConfig.Retention.Schedule = getEnv("CLEANUP_SCHEDULE", "@every 5m")
+
 	// API config
 	AppConfig.API.Key = getEnv("UPTIME_API_KEY", "")
 }
 
+// getDays reads a number of days, 0 meaning forever
+func getDays(key string, defaultDays int) time.Duration {
+	days, err := strconv.Atoi(getEnv(key, strconv.Itoa(defaultDays)))
+	if err != nil || days < 0 {
+		log.Printf("Invalid %s, using %d", key, defaultDays)
+		days = defaultDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
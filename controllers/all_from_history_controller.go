@@ -1,15 +1,71 @@
 package controllers
 
 import (
+	"context"
 	"log"
 	"os"
 
-	"uptime/database"
+	"uptime/internal/logquery"
 	"uptime/models"
+	"uptime/repositories"
+	"uptime/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// HistoryResponse is a node's latest check in the history reports
+type HistoryResponse struct {
+	ID          uint     `json:"id"`
+	NodeID      uint     `json:"node_id"`
+	Delay       *float64 `json:"delay,omitempty"`
+	Status      *uint    `json:"status,omitempty"`
+	Up          int      `json:"up"`
+	Suspended   int      `json:"suspended"`
+	Degraded    int      `json:"degraded"`
+	Maintenance int      `json:"maintenance"`
+	Exception   *string  `json:"exception"`
+	CreatedAt   int64    `json:"created_at"`
+	UpdatedAt   int64    `json:"updated_at"`
+}
+
+func historyResponse(h *models.History) HistoryResponse {
+	return HistoryResponse{
+		ID:          h.ID,
+		NodeID:      h.NodeID,
+		Delay:       h.Delay,
+		Status:      h.Status,
+		Up:          boolToInt(h.Up),
+		Suspended:   boolToInt(h.Suspended),
+		Degraded:    boolToInt(h.Degraded),
+		Maintenance: boolToInt(h.Maintenance),
+		Exception:   h.Exception,
+		CreatedAt:   h.CreatedAt.Unix(),
+		UpdatedAt:   h.UpdatedAt.Unix(),
+	}
+}
+
+// AllFormHistory retrieves the latest check of every node
+// @Summary Get all histories
+// @Description The latest check result of every node, filtered and sorted like /report/get
+// @Tags reports
+// @Produce json
+// @Param Authorization header string true "API Key"
+// @Param group_id query int false "Group ID, subgroups included"
+// @Param tag query string false "Tag name"
+// @Param filter query string false "Filter, e.g. status>=500 and (delay>2 or exception~timeout)"
+// @Param sort query string false "Sort keys, - for descending, e.g. -delay,status"
+// @Param fields query string false "Fields of each report to return, e.g. id,delay,created_at"
+// @Param tz query string false "Time zone of times in filter without an offset (default UTC)"
+// @Param limit query int false "Page size, at most 10000"
+// @Param after_id query int false "Continue after the row with this ID in the report's sort"
+// @Param cursor query string false "Continue where the previous page ended, from next_cursor"
+// @Param format query string false "ndjson to stream one row per line, ending with a summary line" Enums(ndjson)
+// @Success 200 {object} ReportResponse "Report data"
+// @Failure 401 {object} ReportResponse "Unauthorized"
+// @Failure 422 {object} ReportResponse "Invalid parameters"
+// @Failure 500 {object} ReportResponse "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /report/all-from-history [get]
 func AllFormHistory(c *fiber.Ctx) error {
 	key := c.Get("Authorization")
 	apiKey := os.Getenv("UPTIME_API_KEY")
@@ -22,81 +78,67 @@ func AllFormHistory(c *fiber.Ctx) error {
 		})
 	}
 
-	allItem := c.Query("all-item")
-	firstItem := c.Query("first-item")
-	lastItem := c.Query("last-item")
-	ascDelay := c.Query("asc-delay")
-	descDelay := c.Query("desc-delay")
-	ascStatus := c.Query("asc-status")
-	descStatus := c.Query("desc-status")
-	ascUp := c.Query("asc-up")
-	descUp := c.Query("desc-up")
-	ascSuspended := c.Query("asc-suspended")
-	descSuspended := c.Query("desc-suspended")
-	ascException := c.Query("asc-exception")
-	descException := c.Query("desc-exception")
-	up := c.Query("up")
-	down := c.Query("down")
-	suspended := c.Query("suspended")
-	exception := c.Query("exception")
-
-	db := database.DB.Model(&models.History{})
-
-	if allItem != "" {
-		db = db.Order("id desc")
-	}
-	if firstItem != "" {
-		db = db.Order("id asc").Limit(1)
-	}
-	if lastItem != "" {
-		db = db.Order("id desc").Limit(1)
-	}
-	if ascDelay != "" {
-		db = db.Order("delay asc")
-	}
-	if descDelay != "" {
-		db = db.Order("delay desc")
-	}
-	if ascStatus != "" {
-		db = db.Order("status asc")
-	}
-	if descStatus != "" {
-		db = db.Order("status desc")
-	}
-	if ascUp != "" {
-		db = db.Order("up asc")
-	}
-	if descUp != "" {
-		db = db.Order("up desc")
-	}
-	if ascSuspended != "" {
-		db = db.Order("suspended asc")
-	}
-	if descSuspended != "" {
-		db = db.Order("suspended desc")
-	}
-	if ascException != "" {
-		db = db.Order("exception asc")
-	}
-	if descException != "" {
-		db = db.Order("exception desc")
+	nodeIDs, err := reportNodeIDs(c)
+	if err != nil {
+		return scopeFailed(c, err)
 	}
 
-	if up == "1" {
-		db = db.Where("up = ?", true)
+	filter := repositories.LogFilter{NodeIDs: nodeIDs}
+	if _, _, err := parseReportQuery(c, &filter, false); err != nil {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
 	}
-	if down == "1" {
-		db = db.Where("up = ?", false)
+	fields, err := parseFields[HistoryResponse](c)
+	if err != nil {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
 	}
-	if suspended == "1" {
-		db = db.Where("suspended = ?", true)
+
+	page, err := parseLogPage(c, &filter, services.GetHistory, logquery.HistoryField)
+	if err != nil {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
 	}
-	if exception == "1" {
-		db = db.Where("exception IS NOT NULL")
+
+	selectRow := rowSelector[HistoryResponse](fields)
+
+	// The rows go out as they are read, followed by a summary line with the
+	// next page's cursor when there is one
+	if wantsNDJSON(c) {
+		return streamNDJSON(c, func(ctx context.Context, emit func(v interface{}) error) error {
+			err := services.EachHistory(ctx, filter, func(h *models.History) error {
+				page.add(h)
+				return emit(selectRow(historyResponse(h)))
+			})
+			if err != nil {
+				return err
+			}
+			if next := page.next(); next != "" {
+				return emit(map[string]interface{}{"summary": map[string]interface{}{"next_cursor": next}})
+			}
+			return nil
+		})
 	}
 
-	var histories []models.History
-	if err := db.Find(&histories).Error; err != nil {
+	reports := []interface{}{}
+	err = services.EachHistory(c.Context(), filter, func(h *models.History) error {
+		page.add(h)
+		reports = append(reports, selectRow(historyResponse(h)))
+		return nil
+	})
+	if err != nil {
 		log.Println("Database error:", err)
 		return c.Status(500).JSON(ReportResponse{
 			Code:    500,
@@ -106,39 +148,17 @@ func AllFormHistory(c *fiber.Ctx) error {
 		})
 	}
 
-	type HistoryResponse struct {
-		ID        uint     `json:"id"`
-		NodeID    uint     `json:"node_id"`
-		Delay     *float64 `json:"delay,omitempty"`
-		Status    *uint    `json:"status,omitempty"`
-		Up        int      `json:"up"`
-		Suspended int      `json:"suspended"`
-		Exception *string  `json:"exception"`
-		CreatedAt int64    `json:"created_at"`
-		UpdatedAt int64    `json:"updated_at"`
+	data := map[string]interface{}{
+		"reports": reports,
 	}
-
-	data := make([]HistoryResponse, len(histories))
-	for i, h := range histories {
-		data[i] = HistoryResponse{
-			ID:        h.ID,
-			NodeID:    h.NodeID,
-			Delay:     h.Delay,
-			Status:    h.Status,
-			Up:        boolToInt(h.Up),
-			Suspended: boolToInt(h.Suspended),
-			Exception: h.Exception,
-			CreatedAt: h.CreatedAt.Unix(),
-			UpdatedAt: h.UpdatedAt.Unix(),
-		}
+	if next := page.next(); next != "" {
+		data["next_cursor"] = next
 	}
 
 	return c.JSON(ReportResponse{
 		Code:    200,
 		Msg:     "url report",
 		Success: true,
-		Data: map[string]interface{}{
-			"reports": data,
-		},
+		Data:    data,
 	})
 }
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"uptime/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateAssertionRule creates a new content assertion rule
+// @Summary Create an assertion rule
+// @Description Add a content assertion (contains, not_contains, regex, json_path, header). Rules without node_id apply to every node.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param rule body services.AssertionRuleInput true "Assertion rule"
+// @Success 201 {object} models.AssertionRule "Rule created successfully"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /rules [post]
+func CreateAssertionRule(c *fiber.Ctx) error {
+	var body services.AssertionRuleInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	rule, err := services.CreateAssertionRule(body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create assertion rule"})
+	}
+	return c.Status(201).JSON(rule)
+}
+
+// GetAllAssertionRules lists assertion rules
+// @Summary Get assertion rules
+// @Description List all assertion rules, the rules of one node (node_id) or the global default set (default=1)
+// @Tags rules
+// @Produce json
+// @Param node_id query int false "Node ID"
+// @Param default query string false "Set to 1 to list only the global default rules"
+// @Success 200 {array} models.AssertionRule "List of rules"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /rules [get]
+func GetAllAssertionRules(c *fiber.Ctx) error {
+	if c.Query("default") == "1" {
+		rules, err := services.GetAssertionRulesByNode(nil)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(rules)
+	}
+
+	if nodeIDStr := c.Query("node_id"); nodeIDStr != "" {
+		nodeID, err := strconv.Atoi(nodeIDStr)
+		if err != nil || nodeID <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid node_id format"})
+		}
+		id := uint(nodeID)
+		rules, err := services.GetAssertionRulesByNode(&id)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(rules)
+	}
+
+	rules, err := services.GetAllAssertionRules()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(rules)
+}
+
+func GetAssertionRule(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	rule, err := services.GetAssertionRule(uint(id))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Assertion rule not found"})
+	}
+	return c.JSON(rule)
+}
+
+func UpdateAssertionRule(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	var body services.AssertionRuleInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	rule, err := services.UpdateAssertionRule(uint(id), body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Assertion rule not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update assertion rule"})
+	}
+	return c.JSON(rule)
+}
+
+func DeleteAssertionRule(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	err = services.DeleteAssertionRuleByID(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Assertion rule not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete assertion rule"})
+	}
+	return c.SendStatus(204)
+}
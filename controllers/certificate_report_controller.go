@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"uptime/config"
+	"uptime/repositories"
+	"uptime/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CertificateReportItem is one node's latest TLS certificate
+type CertificateReportItem struct {
+	NodeID       uint     `json:"node_id"`
+	URL          string   `json:"url"`
+	Subject      string   `json:"subject"`
+	Issuer       string   `json:"issuer"`
+	SANs         []string `json:"sans"`
+	NotBefore    int64    `json:"not_before"`
+	NotAfter     int64    `json:"not_after"`
+	DaysToExpiry int      `json:"days_to_expiry"`
+	Expiring     int      `json:"expiring"`
+	ChainValid   int      `json:"chain_valid"`
+	ChainError   *string  `json:"chain_error"`
+	CheckedAt    int64    `json:"checked_at"`
+}
+
+// GetCertificateReport lists the TLS certificates of all https nodes
+// @Summary Get certificate expiry report
+// @Description List the latest TLS certificate of every https node ordered by days to expiry
+// @Tags reports
+// @Produce json
+// @Param Authorization header string true "API Key"
+// @Param days query int false "Only include certificates expiring within this many days"
+// @Param expiring query string false "Set to 1 to only include certificates inside CERT_EXPIRY_WARNING_DAYS"
+// @Param group_id query int false "Group ID, subgroups included"
+// @Param tag query string false "Tag name"
+// @Param limit query int false "Page size, at most 10000"
+// @Param after_id query int false "Continue after the certificate of this node, from next_after_id"
+// @Success 200 {object} ReportResponse "Report data"
+// @Failure 401 {object} ReportResponse "Unauthorized"
+// @Failure 422 {object} ReportResponse "Invalid parameters"
+// @Failure 500 {object} ReportResponse "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /report/certificates [get]
+func GetCertificateReport(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), 30*time.Second)
+	defer cancel()
+
+	key := c.Get("Authorization")
+	apiKey := os.Getenv("UPTIME_API_KEY")
+	if key != apiKey {
+		return c.Status(401).JSON(ReportResponse{
+			Code:    401,
+			Msg:     "Token expired",
+			Success: false,
+			Data:    nil,
+		})
+	}
+
+	scope, err := parseNodeScope(c)
+	if err != nil {
+		return scopeFailed(c, err)
+	}
+
+	page, err := parsePage(c)
+	if err != nil {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
+	}
+
+	now := time.Now()
+	warning := config.AppConfig.UptimeChecker.CertExpiryWarning
+
+	var notAfter *time.Time
+	if daysStr := c.Query("days"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 0 {
+			return c.Status(422).JSON(ReportResponse{
+				Code:    422,
+				Msg:     "days format invalid",
+				Success: false,
+				Data:    nil,
+			})
+		}
+		t := now.AddDate(0, 0, days)
+		notAfter = &t
+	}
+	if c.Query("expiring") == "1" {
+		if t := now.Add(warning); notAfter == nil || t.Before(*notAfter) {
+			notAfter = &t
+		}
+	}
+
+	rows, err := services.GetCertificates(ctx, notAfter)
+	if err != nil {
+		log.Println("Database error:", err)
+		return c.Status(500).JSON(ReportResponse{
+			Code:    500,
+			Msg:     "Database error",
+			Success: false,
+			Data:    nil,
+		})
+	}
+
+	// Nodes may be kept apart from the certificates, so URLs are matched
+	// here, which also leaves out the nodes outside the group or tag
+	nodes, err := services.FindNodes(scope, "", repositories.Page{})
+	if err != nil {
+		return scopeFailed(c, err)
+	}
+	urls := make(map[uint]string, len(nodes))
+	for _, n := range nodes {
+		urls[n.ID] = n.URL
+	}
+
+	items := make([]CertificateReportItem, 0, len(rows))
+	for _, r := range rows {
+		url, ok := urls[r.NodeID]
+		if !ok {
+			continue
+		}
+		var sans []string
+		if r.SANs != "" {
+			sans = strings.Split(r.SANs, ",")
+		}
+		items = append(items, CertificateReportItem{
+			NodeID:       r.NodeID,
+			URL:          url,
+			Subject:      r.Subject,
+			Issuer:       r.Issuer,
+			SANs:         sans,
+			NotBefore:    r.NotBefore.Unix(),
+			NotAfter:     r.NotAfter.Unix(),
+			DaysToExpiry: r.DaysToExpiry(now),
+			Expiring:     boolToInt(r.NotAfter.Sub(now) <= warning),
+			ChainValid:   boolToInt(r.ChainValid),
+			ChainError:   r.ChainError,
+			CheckedAt:    r.UpdatedAt.Unix(),
+		})
+	}
+
+	// One certificate is kept per node, so the page is cut here, continuing
+	// after the certificate of the node given as after_id
+	if page.AfterID != 0 {
+		i := 0
+		for i < len(items) && items[i].NodeID != page.AfterID {
+			i++
+		}
+		if i == len(items) {
+			return c.Status(422).JSON(ReportResponse{
+				Code:    422,
+				Msg:     "after_id: node has no certificate in the report",
+				Success: false,
+				Data:    nil,
+			})
+		}
+		items = items[i+1:]
+	}
+	if page.Limit > 0 && len(items) > page.Limit {
+		items = items[:page.Limit]
+	}
+
+	data := map[string]interface{}{
+		"certificates": items,
+	}
+	if len(items) > 0 {
+		if next := nextAfterID(page, len(items), items[len(items)-1].NodeID); next != 0 {
+			data["next_after_id"] = next
+		}
+	}
+
+	return c.JSON(ReportResponse{
+		Code:    200,
+		Msg:     "certificate report",
+		Success: true,
+		Data:    data,
+	})
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"uptime/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateChatChannel creates a new Telegram or Slack notification channel
+// @Summary Create a chat channel
+// @Description Add a Telegram bot or Slack incoming webhook channel for the selected nodes and groups. Events arriving within CHAT_BATCH_WINDOW are grouped into one message.
+// @Tags chat-channels
+// @Accept json
+// @Produce json
+// @Param channel body services.ChatChannelInput true "Chat channel"
+// @Success 201 {object} models.ChatChannel "Chat channel created successfully"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /chat-channels [post]
+func CreateChatChannel(c *fiber.Ctx) error {
+	var body services.ChatChannelInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	channel, err := services.CreateChatChannel(body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create chat channel"})
+	}
+	return c.Status(201).JSON(channel)
+}
+
+// GetAllChatChannels lists chat channels
+// @Summary Get chat channels
+// @Description List all chat channels. Bot tokens and webhook URLs are never returned.
+// @Tags chat-channels
+// @Produce json
+// @Success 200 {array} models.ChatChannel "List of chat channels"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /chat-channels [get]
+func GetAllChatChannels(c *fiber.Ctx) error {
+	channels, err := services.GetAllChatChannels()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(channels)
+}
+
+func GetChatChannel(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	channel, err := services.GetChatChannel(uint(id))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Chat channel not found"})
+	}
+	return c.JSON(channel)
+}
+
+func UpdateChatChannel(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	var body services.ChatChannelInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	channel, err := services.UpdateChatChannel(uint(id), body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Chat channel not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update chat channel"})
+	}
+	return c.JSON(channel)
+}
+
+func DeleteChatChannel(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	err = services.DeleteChatChannelByID(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Chat channel not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete chat channel"})
+	}
+	return c.SendStatus(204)
+}
+
+// TestChatChannel sends a test message
+// @Summary Test a chat channel
+// @Description Send a single test message through the channel, bypassing the batch window, and return the recorded delivery
+// @Tags chat-channels
+// @Produce json
+// @Param id path int true "Chat channel ID"
+// @Success 200 {object} models.NotificationDelivery "Delivery result"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /chat-channels/{id}/test [post]
+func TestChatChannel(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	delivery, err := services.TestChatChannel(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Chat channel not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(delivery)
+}
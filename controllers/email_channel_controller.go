@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"uptime/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateEmailChannel creates a new SMTP notification channel
+// @Summary Create an email channel
+// @Description Add an SMTP channel notified on down, up and suspended transitions of the selected nodes and groups. Messages are rendered from the subject, text and html templates.
+// @Tags email-channels
+// @Accept json
+// @Produce json
+// @Param channel body services.EmailChannelInput true "Email channel"
+// @Success 201 {object} models.EmailChannel "Email channel created successfully"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /email-channels [post]
+func CreateEmailChannel(c *fiber.Ctx) error {
+	var body services.EmailChannelInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	channel, err := services.CreateEmailChannel(body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create email channel"})
+	}
+	return c.Status(201).JSON(channel)
+}
+
+// GetAllEmailChannels lists email channels
+// @Summary Get email channels
+// @Description List all email channels. Passwords are never returned.
+// @Tags email-channels
+// @Produce json
+// @Success 200 {array} models.EmailChannel "List of email channels"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /email-channels [get]
+func GetAllEmailChannels(c *fiber.Ctx) error {
+	channels, err := services.GetAllEmailChannels()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(channels)
+}
+
+func GetEmailChannel(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	channel, err := services.GetEmailChannel(uint(id))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Email channel not found"})
+	}
+	return c.JSON(channel)
+}
+
+func UpdateEmailChannel(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	var body services.EmailChannelInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	channel, err := services.UpdateEmailChannel(uint(id), body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Email channel not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update email channel"})
+	}
+	return c.JSON(channel)
+}
+
+func DeleteEmailChannel(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	err = services.DeleteEmailChannelByID(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Email channel not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete email channel"})
+	}
+	return c.SendStatus(204)
+}
+
+// TestEmailChannel sends a test message
+// @Summary Test an email channel
+// @Description Send a single test message through the channel and return the recorded delivery
+// @Tags email-channels
+// @Produce json
+// @Param id path int true "Email channel ID"
+// @Success 200 {object} models.NotificationDelivery "Delivery result"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /email-channels/{id}/test [post]
+func TestEmailChannel(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	delivery, err := services.TestEmailChannel(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Email channel not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(delivery)
+}
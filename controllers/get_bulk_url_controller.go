@@ -4,14 +4,11 @@ import (
 	"context"
 	"log"
 	"os"
-	"runtime"
-	"sync"
-	"sync/atomic"
 	"time"
 
-	"uptime/database"
 	"uptime/models"
-	"uptime/utils"
+	"uptime/repositories"
+	"uptime/services"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -23,6 +20,37 @@ type BulkURLResponse struct {
 	Data    interface{} `json:"data"`
 }
 
+// NodeHistoriesResponse is a node with its latest checks
+type NodeHistoriesResponse struct {
+	ID        uint              `json:"id"`
+	URL       string            `json:"url"`
+	Histories []HistoryResponse `json:"histories"`
+}
+
+// nodeHistoriesPage returns the data of a page of nodes with their
+// histories, with the after_id of the next page when there is one
+func nodeHistoriesPage(nodes []models.Node, page repositories.Page) map[string]interface{} {
+	urls := make([]NodeHistoriesResponse, len(nodes))
+	for i := range nodes {
+		n := &nodes[i]
+		histories := make([]HistoryResponse, len(n.Histories))
+		for j := range n.Histories {
+			histories[j] = historyResponse(&n.Histories[j])
+		}
+		urls[i] = NodeHistoriesResponse{ID: n.ID, URL: n.URL, Histories: histories}
+	}
+
+	data := map[string]interface{}{
+		"urls": urls,
+	}
+	if len(nodes) > 0 {
+		if next := nextAfterID(page, len(nodes), nodes[len(nodes)-1].ID); next != 0 {
+			data["next_after_id"] = next
+		}
+	}
+	return data
+}
+
 func GetBulkURL(c *fiber.Ctx) error {
 	// Set timeout context for the entire request
 	ctx, cancel := context.WithTimeout(c.Context(), 45*time.Second)
@@ -51,8 +79,23 @@ func GetBulkURL(c *fiber.Ctx) error {
 		})
 	}
 
-	var nodes []models.Node
-	if err := database.DB.WithContext(ctx).Preload("Histories").Where("url IN ?", body.URLs).Order("id desc").Find(&nodes).Error; err != nil {
+	nodeIDs, err := reportNodeIDs(c)
+	if err != nil {
+		return scopeFailed(c, err)
+	}
+
+	page, err := parsePage(c)
+	if err != nil {
+		return c.Status(422).JSON(BulkURLResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
+	}
+
+	nodes, err := services.GetNodesWithHistories(ctx, body.URLs, nodeIDs, page)
+	if err != nil {
 		log.Println("Database error:", err)
 		return c.Status(500).JSON(BulkURLResponse{
 			Code:    500,
@@ -62,135 +105,10 @@ func GetBulkURL(c *fiber.Ctx) error {
 		})
 	}
 
-	type HistoryResponse struct {
-		ID        uint     `json:"id"`
-		NodeID    uint     `json:"node_id"`
-		Delay     *float64 `json:"delay,omitempty"`
-		Status    *uint    `json:"status,omitempty"`
-		Up        int      `json:"up"`
-		Suspended int      `json:"suspended"`
-		Exception *string  `json:"exception"`
-		CreatedAt int64    `json:"created_at"`
-		UpdatedAt int64    `json:"updated_at"`
-	}
-
-	type NodeResponse struct {
-		ID        uint              `json:"id"`
-		URL       string            `json:"url"`
-		Histories []HistoryResponse `json:"histories"`
-	}
-
-	result := make([]NodeResponse, len(nodes))
-	
-	// Optimize parallel processing with worker pool pattern for nested data
-	if len(nodes) > 0 {
-		// Calculate total work (nodes + their histories)
-		totalHistories := 0
-		for _, node := range nodes {
-			totalHistories += len(node.Histories)
-		}
-		
-		// Use worker pool for better resource management
-		numWorkers := runtime.NumCPU()
-		if len(nodes) < 100 {
-			// For small datasets, use fewer workers
-			numWorkers = utils.Min(numWorkers, utils.Max(1, len(nodes)/10))
-		} else {
-			// For large datasets, use more workers but cap appropriately
-			numWorkers = utils.Min(numWorkers*2, 16)
-		}
-		
-		if numWorkers == 0 {
-			numWorkers = 1
-		}
-
-		// Create jobs channel for nodes
-		jobs := make(chan int, len(nodes))
-		var wg sync.WaitGroup
-		var processedCount int64
-
-		// Start workers
-		for w := 0; w < numWorkers; w++ {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				
-				for {
-					select {
-					case <-ctx.Done():
-						// Exit early if context is cancelled
-						return
-					case nodeIdx, ok := <-jobs:
-						if !ok {
-							return
-						}
-						
-						// Process single node with all its histories
-						n := nodes[nodeIdx]
-						histories := make([]HistoryResponse, len(n.Histories))
-						
-						// Process histories for this node
-						for j, h := range n.Histories {
-							histories[j] = HistoryResponse{
-								ID:        h.ID,
-								NodeID:    h.NodeID,
-								Delay:     h.Delay,
-								Status:    h.Status,
-								Up:        boolToInt(h.Up),
-								Suspended: boolToInt(h.Suspended),
-								Exception: h.Exception,
-								CreatedAt: h.CreatedAt.Unix(),
-								UpdatedAt: h.UpdatedAt.Unix(),
-							}
-						}
-						
-						result[nodeIdx] = NodeResponse{
-							ID:        n.ID,
-							URL:       n.URL,
-							Histories: histories,
-						}
-						
-						atomic.AddInt64(&processedCount, 1)
-					}
-				}
-			}()
-		}
-
-		// Send jobs to workers
-		go func() {
-			defer close(jobs)
-			for i := 0; i < len(nodes); i++ {
-				select {
-				case <-ctx.Done():
-					return
-				case jobs <- i:
-				}
-			}
-		}()
-
-		// Wait for all workers to finish
-		wg.Wait()
-
-		// Check if context was cancelled during processing
-		if ctx.Err() != nil {
-			return c.Status(408).JSON(BulkURLResponse{
-				Code:    408,
-				Msg:     "Request timeout during data processing",
-				Success: false,
-				Data:    nil,
-			})
-		}
-
-		log.Printf("Bulk URL: Processed %d nodes with %d total histories using %d workers", 
-			atomic.LoadInt64(&processedCount), totalHistories, numWorkers)
-	}
-
 	return c.JSON(BulkURLResponse{
 		Code:    200,
 		Msg:     "urls report",
 		Success: true,
-		Data: map[string]interface{}{
-			"urls": result,
-		},
+		Data:    nodeHistoriesPage(nodes, page),
 	})
 }
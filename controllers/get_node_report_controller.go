@@ -4,15 +4,14 @@ import (
 	"context"
 	"log"
 	"os"
-	"runtime"
-	"sync"
-	"sync/atomic"
 	"time"
-	"unsafe"
 
-	"uptime/database"
+	"uptime/internal/logquery"
+	"uptime/internal/retention"
+	"uptime/internal/rollup"
 	"uptime/models"
-	"uptime/utils"
+	"uptime/repositories"
+	"uptime/services"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -31,17 +30,136 @@ func boolToInt(b bool) int {
 	return 0
 }
 
+// rollupsFor returns the rollups of the part of [from, to] older than the raw
+// log retention and their tier, or no rollups when raw logs cover the range
+func rollupsFor(node models.Node, from, to time.Time) (string, []models.NodeLogRollup, error) {
+	policies, err := retention.Load()
+	if err != nil {
+		return "", nil, err
+	}
+	plan := rollup.PlanRange(from, to, time.Now(), policies.For(node.GroupID))
+	if plan.Tier == "" {
+		return "", nil, nil
+	}
+	var rollups []models.NodeLogRollup
+	err = repositories.GetRollups(plan.Tier, node.ID, plan.RollupFrom, plan.RollupTo(to), &rollups)
+	return plan.Tier, rollups, err
+}
+
+// NodeLogResponse is a node log in the node reports
+type NodeLogResponse struct {
+	ID           uint     `json:"id"`
+	NodeID       uint     `json:"node_id"`
+	Delay        *float64 `json:"delay,omitempty"`
+	Status       *uint    `json:"status,omitempty"`
+	Up           int      `json:"up"`
+	Suspended    int      `json:"suspended"`
+	Degraded     int      `json:"degraded"`
+	Maintenance  int      `json:"maintenance"`
+	Attempts     uint     `json:"attempts"`
+	Unconfirmed  int      `json:"unconfirmed"`
+	Exception    *string  `json:"exception"`
+	DNSTime      *float64 `json:"dns_time"`
+	ConnectTime  *float64 `json:"connect_time"`
+	TLSTime      *float64 `json:"tls_time"`
+	TTFB         *float64 `json:"ttfb"`
+	TransferTime *float64 `json:"transfer_time"`
+	ResponseSize *int64   `json:"response_size"`
+	CreatedAt    int64    `json:"created_at"`
+	UpdatedAt    int64    `json:"updated_at"`
+}
+
+func nodeLogResponse(r *models.NodeLog) NodeLogResponse {
+	return NodeLogResponse{
+		ID:           r.ID,
+		NodeID:       r.NodeID,
+		Delay:        r.Delay,
+		Status:       r.Status,
+		Up:           boolToInt(r.Up),
+		Suspended:    boolToInt(r.Suspended),
+		Degraded:     boolToInt(r.Degraded),
+		Maintenance:  boolToInt(r.Maintenance),
+		Attempts:     r.Attempts,
+		Unconfirmed:  boolToInt(r.Unconfirmed),
+		Exception:    r.Exception,
+		DNSTime:      r.DNSTime,
+		ConnectTime:  r.ConnectTime,
+		TLSTime:      r.TLSTime,
+		TTFB:         r.TTFB,
+		TransferTime: r.TransferTime,
+		ResponseSize: r.ResponseSize,
+		CreatedAt:    r.CreatedAt.Unix(),
+		UpdatedAt:    r.UpdatedAt.Unix(),
+	}
+}
+
+// phaseSum adds up one request phase to average it
+type phaseSum struct {
+	sum   float64
+	count int
+}
+
+func (p *phaseSum) add(v *float64) {
+	if v != nil {
+		p.sum += *v
+		p.count++
+	}
+}
+
+func (p phaseSum) average() *float64 {
+	if p.count == 0 {
+		return nil
+	}
+	v := p.sum / float64(p.count)
+	return &v
+}
+
+// phaseAverages averages the duration of each request phase in seconds,
+// ignoring logs where the phase did not happen
+type phaseAverages struct {
+	dns, connect, tls, ttfb, transfer phaseSum
+}
+
+func (p *phaseAverages) add(l *models.NodeLog) {
+	p.dns.add(l.DNSTime)
+	p.connect.add(l.ConnectTime)
+	p.tls.add(l.TLSTime)
+	p.ttfb.add(l.TTFB)
+	p.transfer.add(l.TransferTime)
+}
+
+func (p *phaseAverages) result() map[string]*float64 {
+	return map[string]*float64{
+		"dns_time":      p.dns.average(),
+		"connect_time":  p.connect.average(),
+		"tls_time":      p.tls.average(),
+		"ttfb":          p.ttfb.average(),
+		"transfer_time": p.transfer.average(),
+	}
+}
+
 // GetNodeReport retrieves monitoring report for a specific node
 // @Summary Get node report
-// @Description Get detailed monitoring report for a specific website/service
+// @Description Get the check logs of a website/service, filtered and sorted. The legacy flags (up, down, suspended, exception, all-item, order-asc, order-desc, first-item, last-item, asc-* and desc-*) are still accepted and applied after filter and sort.
 // @Tags reports
-// @Produce json
+// @Produce json,application/x-ndjson,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param Authorization header string true "API Key"
-// @Param id query string false "Node ID"
-// @Param url query string false "Node URL"
+// @Param url query string true "Node URL"
+// @Param filter query string false "Filter, e.g. status>=500 and (delay>2 or exception~timeout)"
+// @Param sort query string false "Sort keys, - for descending, e.g. -delay,status"
+// @Param fields query string false "Fields of each report to return, e.g. id,delay,created_at"
+// @Param start-date query string false "Range start (YYYY-MM-DD, YYYY-MM-DDTHH:MM:SS or RFC 3339)"
+// @Param end-date query string false "Range end (YYYY-MM-DD, YYYY-MM-DDTHH:MM:SS or RFC 3339)"
+// @Param tz query string false "Time zone of dates and times without an offset and of exported times, e.g. Asia/Tehran or +03:30 (default UTC)"
+// @Param limit query int false "Page size, at most 10000"
+// @Param after_id query int false "Continue after the row with this ID in the report's sort"
+// @Param cursor query string false "Continue where the previous page ended, from next_cursor"
+// @Param format query string false "ndjson to stream one row per line, ending with a summary line; csv or xlsx to download the rows, xlsx with a summary sheet. Also taken from the Accept header" Enums(ndjson, csv, xlsx)
+// @Param calendar query string false "Calendar of exported dates (default gregorian)" Enums(gregorian, jalali)
 // @Success 200 {object} ReportResponse "Report data"
 // @Failure 401 {object} ReportResponse "Unauthorized"
 // @Failure 404 {object} ReportResponse "Node not found"
+// @Failure 422 {object} ReportResponse "Invalid parameters"
 // @Failure 500 {object} ReportResponse "Internal Server Error"
 // @Security ApiKeyAuth
 // @Router /report/get [get]
@@ -72,9 +190,8 @@ func GetNodeReport(c *fiber.Ctx) error {
 		})
 	}
 
-	// Use context for database query with timeout
-	var node models.Node
-	if err := database.DB.WithContext(ctx).Where("url = ?", url).First(&node).Error; err != nil {
+	node, err := services.GetNodeByURL(url)
+	if err != nil {
 		return c.Status(404).JSON(ReportResponse{
 			Code:    404,
 			Msg:     "URL Not Found",
@@ -83,146 +200,102 @@ func GetNodeReport(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse query parameters in parallel using goroutines
-	type QueryParams struct {
-		startDateStr  string
-		endDateStr    string
-		allItem       string
-		orderAsc      string
-		orderDesc     string
-		firstItem     string
-		lastItem      string
-		ascDelay      string
-		descDelay     string
-		ascStatus     string
-		descStatus    string
-		ascUp         string
-		descUp        string
-		ascSuspended  string
-		descSuspended string
-		ascException  string
-		descException string
-		up            string
-		down          string
-		suspended     string
-		exception     string
+	filter := repositories.LogFilter{NodeID: node.ID}
+	rangeStart, rangeEnd, err := parseReportQuery(c, &filter, true)
+	if err != nil {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
 	}
-
-	params := QueryParams{
-		startDateStr:  c.Query("start-date"),
-		endDateStr:    c.Query("end-date"),
-		allItem:       c.Query("all-item"),
-		orderAsc:      c.Query("order-asc"),
-		orderDesc:     c.Query("order-desc"),
-		firstItem:     c.Query("first-item"),
-		lastItem:      c.Query("last-item"),
-		ascDelay:      c.Query("asc-delay"),
-		descDelay:     c.Query("desc-delay"),
-		ascStatus:     c.Query("asc-status"),
-		descStatus:    c.Query("desc-status"),
-		ascUp:         c.Query("asc-up"),
-		descUp:        c.Query("desc-up"),
-		ascSuspended:  c.Query("asc-suspended"),
-		descSuspended: c.Query("desc-suspended"),
-		ascException:  c.Query("asc-exception"),
-		descException: c.Query("desc-exception"),
-		up:            c.Query("up"),
-		down:          c.Query("down"),
-		suspended:     c.Query("suspended"),
-		exception:     c.Query("exception"),
+	fields, err := parseFields[NodeLogResponse](c)
+	if err != nil {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
+	}
+	page, err := parseLogPage(c, &filter, services.GetNodeLog, logquery.NodeLogField)
+	if err != nil {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
+	}
+	format, dates, err := parseExport(c)
+	if err != nil {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
 	}
 
-	db := database.DB.WithContext(ctx).Model(&models.NodeLog{}).Where("node_id = ?", node.ID)
-
-	// Date parsing with error handling
-	if params.startDateStr != "" && params.endDateStr != "" {
-		startDate, err := time.Parse("2006-01-02", params.startDateStr)
-		if err != nil {
-			return c.Status(422).JSON(ReportResponse{
-				Code:    422,
-				Msg:     "Start date format invalid",
-				Success: false,
-				Data:    nil,
-			})
-		}
-		endDate, err := time.Parse("2006-01-02", params.endDateStr)
-		if err != nil {
-			return c.Status(422).JSON(ReportResponse{
-				Code:    422,
-				Msg:     "End date format invalid",
+	// Days older than the raw log retention are only left in the rollups
+	var tier string
+	var rollups []models.NodeLogRollup
+	if !rangeStart.IsZero() {
+		if tier, rollups, err = rollupsFor(*node, rangeStart, rangeEnd); err != nil {
+			log.Println("Database error:", err)
+			return c.Status(500).JSON(ReportResponse{
+				Code:    500,
+				Msg:     "Database error",
 				Success: false,
 				Data:    nil,
 			})
 		}
-		db = db.Where("created_at BETWEEN ? AND ?", startDate, endDate.Add(23*time.Hour+59*time.Minute+59*time.Second))
 	}
 
-	// Apply filters
-	if params.up == "1" {
-		db = db.Where("up = ?", true)
-	}
-	if params.down == "1" {
-		db = db.Where("up = ?", false)
-	}
-	if params.suspended == "1" {
-		db = db.Where("suspended = ?", true)
-	}
-	if params.exception == "1" {
-		db = db.Where("exception IS NOT NULL")
+	if format != "" {
+		e := &nodeReportExport{node: node, filter: filter, page: page, fields: fields, dates: dates,
+			from: rangeStart, to: rangeEnd, tier: tier, rollups: rollups}
+		return e.send(c, format)
 	}
 
-	// Apply ordering
-	if params.allItem != "" {
-		db = db.Order("id desc")
-	}
-	if params.orderAsc != "" {
-		db = db.Order("id asc")
-	}
-	if params.orderDesc != "" {
-		db = db.Order("id desc")
-	}
-	if params.firstItem != "" {
-		db = db.Order("id asc").Limit(1)
-	}
-	if params.lastItem != "" {
-		db = db.Order("id desc").Limit(1)
-	}
-	if params.ascDelay != "" {
-		db = db.Order("delay asc")
-	}
-	if params.descDelay != "" {
-		db = db.Order("delay desc")
-	}
-	if params.ascStatus != "" {
-		db = db.Order("status asc")
-	}
-	if params.descStatus != "" {
-		db = db.Order("status desc")
-	}
-	if params.ascUp != "" {
-		db = db.Order("up asc")
-	}
-	if params.descUp != "" {
-		db = db.Order("up desc")
-	}
-	if params.ascSuspended != "" {
-		db = db.Order("suspended asc")
-	}
-	if params.descSuspended != "" {
-		db = db.Order("suspended desc")
-	}
-	if params.ascException != "" {
-		db = db.Order("exception asc")
-	}
-	if params.descException != "" {
-		db = db.Order("exception desc")
+	selectRow := rowSelector[NodeLogResponse](fields)
+
+	// The rows go out as they are read, followed by a summary line with
+	// the rollups and the next page's cursor when there are any
+	if wantsNDJSON(c) {
+		return streamNDJSON(c, func(ctx context.Context, emit func(v interface{}) error) error {
+			err := services.EachNodeLog(ctx, filter, func(l *models.NodeLog) error {
+				page.add(l)
+				return emit(selectRow(nodeLogResponse(l)))
+			})
+			if err != nil {
+				return err
+			}
+			summary := map[string]interface{}{}
+			if tier != "" {
+				summary["rollup_tier"] = tier
+				summary["rollups"] = rollups
+			}
+			if next := page.next(); next != "" {
+				summary["next_cursor"] = next
+			}
+			if len(summary) == 0 {
+				return nil
+			}
+			return emit(map[string]interface{}{"summary": summary})
+		})
 	}
 
-	var reports []models.NodeLog
-	
 	// Measure database query time
 	dbStartTime := time.Now()
-	if err := db.Find(&reports).Error; err != nil {
+	reports := []interface{}{}
+	err = services.EachNodeLog(ctx, filter, func(l *models.NodeLog) error {
+		page.add(l)
+		reports = append(reports, selectRow(nodeLogResponse(l)))
+		return nil
+	})
+	if err != nil {
 		log.Println("Database error:", err)
 		return c.Status(500).JSON(ReportResponse{
 			Code:    500,
@@ -231,134 +304,23 @@ func GetNodeReport(c *fiber.Ctx) error {
 			Data:    nil,
 		})
 	}
-	dbDuration := time.Since(dbStartTime)
-	log.Printf("Database query took: %v for %d records", dbDuration, len(reports))
+	log.Printf("Database query took: %v for %d records", time.Since(dbStartTime), len(reports))
 
-	type NodeLogResponse struct {
-		ID        uint     `json:"id"`
-		NodeID    uint     `json:"node_id"`
-		Delay     *float64 `json:"delay,omitempty"`
-		Status    *uint    `json:"status,omitempty"`
-		Up        int      `json:"up"`
-		Suspended int      `json:"suspended"`
-		Exception *string  `json:"exception"`
-		CreatedAt int64    `json:"created_at"`
-		UpdatedAt int64    `json:"updated_at"`
+	data := map[string]interface{}{
+		"reports": reports,
 	}
-
-	reportData := make([]NodeLogResponse, len(reports))
-	
-	// Hyper-optimized parallel processing specifically tuned for 4K+ records
-	if len(reports) > 0 {
-		// Specialized optimization for 4K dataset (4225 records)
-		numWorkers := runtime.NumCPU()
-		chunkSize := 200 // Optimal for 4K records
-		
-		if len(reports) >= 4000 {
-			// Special handling for our 4K+ dataset
-			numWorkers = utils.Min(numWorkers*8, 48) // Maximum parallelism
-			chunkSize = 300 // Larger chunks for efficiency
-		} else if len(reports) >= 2000 {
-			numWorkers = utils.Min(numWorkers*4, 24)
-			chunkSize = 200
-		} else if len(reports) >= 1000 {
-			numWorkers = utils.Min(numWorkers*2, 16)
-			chunkSize = 150
-		} else {
-			numWorkers = utils.Min(8, numWorkers)
-			chunkSize = 100
-		}
-		
-		if numWorkers == 0 {
-			numWorkers = 1
-		}
-
-		var wg sync.WaitGroup
-		var processedCount int64
-		
-		// Memory optimization
-		_ = unsafe.Pointer(&reportData[0])
-		
-		// Pre-spawn all workers to avoid goroutine creation overhead
-		jobs := make(chan [2]int, (len(reports)/chunkSize)+1)
-		
-		// Start workers
-		for w := 0; w < numWorkers; w++ {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				
-				for job := range jobs {
-					start, end := job[0], job[1]
-					
-					// Fast context check
-					select {
-					case <-ctx.Done():
-						return
-					default:
-					}
-					
-					// Optimized processing loop
-					for j := start; j < end; j++ {
-						r := &reports[j]
-						reportData[j] = NodeLogResponse{
-							ID:        r.ID,
-							NodeID:    r.NodeID,
-							Delay:     r.Delay,
-							Status:    r.Status,
-							Up:        boolToInt(r.Up),
-							Suspended: boolToInt(r.Suspended),
-							Exception: r.Exception,
-							CreatedAt: r.CreatedAt.Unix(),
-							UpdatedAt: r.UpdatedAt.Unix(),
-						}
-					}
-					
-					atomic.AddInt64(&processedCount, int64(end-start))
-				}
-			}()
-		}
-		
-		// Send jobs to workers
-		go func() {
-			defer close(jobs)
-			for i := 0; i < len(reports); i += chunkSize {
-				end := i + chunkSize
-				if end > len(reports) {
-					end = len(reports)
-				}
-				
-				select {
-				case <-ctx.Done():
-					return
-				case jobs <- [2]int{i, end}:
-				}
-			}
-		}()
-
-		// Wait for completion
-		wg.Wait()
-
-		// Context check
-		if ctx.Err() != nil {
-			return c.Status(408).JSON(ReportResponse{
-				Code:    408,
-				Msg:     "Request timeout during data processing",
-				Success: false,
-				Data:    nil,
-			})
-		}
-
-		log.Printf("Report: Processed %d items with %d workers in chunks of %d (4K-optimized)", 
-			atomic.LoadInt64(&processedCount), numWorkers, chunkSize)
+	if tier != "" {
+		data["rollup_tier"] = tier
+		data["rollups"] = rollups
+	}
+	if next := page.next(); next != "" {
+		data["next_cursor"] = next
 	}
 
 	return c.JSON(ReportResponse{
 		Code:    200,
 		Msg:     "url report",
 		Success: true,
-		Data: map[string]interface{}{
-			"reports": reportData,
-		},
+		Data:    data,
 	})
 }
@@ -4,18 +4,41 @@ import (
 	"context"
 	"log"
 	"os"
-	"runtime"
-	"sync"
-	"sync/atomic"
 	"time"
 
-	"uptime/database"
+	"uptime/internal/logquery"
 	"uptime/models"
-	"uptime/utils"
+	"uptime/repositories"
+	"uptime/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// GetNodeSmartReport retrieves a node's logs with their counts
+// @Summary Get node smart report
+// @Description Like /report/get, with the number of logs, down and maintenance checks and the average request phases
+// @Tags reports
+// @Produce json,application/x-ndjson,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param Authorization header string true "API Key"
+// @Param url query string true "Node URL"
+// @Param filter query string false "Filter, e.g. status>=500 and (delay>2 or exception~timeout)"
+// @Param sort query string false "Sort keys, - for descending, e.g. -delay,status"
+// @Param fields query string false "Fields of each report to return, e.g. id,delay,created_at"
+// @Param start-date query string false "Range start (YYYY-MM-DD, YYYY-MM-DDTHH:MM:SS or RFC 3339)"
+// @Param end-date query string false "Range end (YYYY-MM-DD, YYYY-MM-DDTHH:MM:SS or RFC 3339)"
+// @Param tz query string false "Time zone of dates and times without an offset and of exported times, e.g. Asia/Tehran or +03:30 (default UTC)"
+// @Param limit query int false "Page size, at most 10000"
+// @Param after_id query int false "Continue after the row with this ID in the report's sort"
+// @Param cursor query string false "Continue where the previous page ended, from next_cursor"
+// @Param format query string false "ndjson to stream one row per line, ending with a summary line; csv or xlsx to download the rows, xlsx with a summary sheet. Also taken from the Accept header" Enums(ndjson, csv, xlsx)
+// @Param calendar query string false "Calendar of exported dates (default gregorian)" Enums(gregorian, jalali)
+// @Success 200 {object} ReportResponse "Report data"
+// @Failure 401 {object} ReportResponse "Unauthorized"
+// @Failure 404 {object} ReportResponse "Node not found"
+// @Failure 422 {object} ReportResponse "Invalid parameters"
+// @Failure 500 {object} ReportResponse "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /report/get-smart-query [get]
 func GetNodeSmartReport(c *fiber.Ctx) error {
 	// Set timeout context for the entire request
 	ctx, cancel := context.WithTimeout(c.Context(), 30*time.Second)
@@ -44,8 +67,8 @@ func GetNodeSmartReport(c *fiber.Ctx) error {
 		})
 	}
 
-	var node models.Node
-	if err := database.DB.WithContext(ctx).Where("url = ?", url).First(&node).Error; err != nil {
+	node, err := services.GetNodeByURL(url)
+	if err != nil {
 		return c.Status(404).JSON(ReportResponse{
 			Code:    404,
 			Msg:     "URL Not Found",
@@ -54,116 +77,127 @@ func GetNodeSmartReport(c *fiber.Ctx) error {
 		})
 	}
 
-	startDateStr := c.Query("start-date")
-	endDateStr := c.Query("end-date")
-	allItem := c.Query("all-item")
-	orderAsc := c.Query("order-asc")
-	orderDesc := c.Query("order-desc")
-	firstItem := c.Query("first-item")
-	lastItem := c.Query("last-item")
-	ascDelay := c.Query("asc-delay")
-	descDelay := c.Query("desc-delay")
-	ascStatus := c.Query("asc-status")
-	descStatus := c.Query("desc-status")
-	ascUp := c.Query("asc-up")
-	descUp := c.Query("desc-up")
-	ascSuspended := c.Query("asc-suspended")
-	descSuspended := c.Query("desc-suspended")
-	ascException := c.Query("asc-exception")
-	descException := c.Query("desc-exception")
-	up := c.Query("up")
-	down := c.Query("down")
-	suspended := c.Query("suspended")
-	exception := c.Query("exception")
-
-	db := database.DB.WithContext(ctx).Model(&models.NodeLog{}).Where("node_id = ?", node.ID)
+	filter := repositories.LogFilter{NodeID: node.ID}
+	rangeStart, rangeEnd, err := parseReportQuery(c, &filter, true)
+	if err != nil {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
+	}
+	fields, err := parseFields[NodeLogResponse](c)
+	if err != nil {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
+	}
+	page, err := parseLogPage(c, &filter, services.GetNodeLog, logquery.NodeLogField)
+	if err != nil {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
+	}
+	format, dates, err := parseExport(c)
+	if err != nil {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
+	}
 
-	if startDateStr != "" && endDateStr != "" {
-		startDate, err := time.Parse("2006-01-02", startDateStr)
-		if err != nil {
-			return c.Status(422).JSON(ReportResponse{
-				Code:    422,
-				Msg:     "Start date format invalid",
-				Success: false,
-				Data:    nil,
-			})
-		}
-		endDate, err := time.Parse("2006-01-02", endDateStr)
-		if err != nil {
-			return c.Status(422).JSON(ReportResponse{
-				Code:    422,
-				Msg:     "End date format invalid",
+	// Days older than the raw log retention are only left in the rollups,
+	// their checks are added to the counts
+	var tier string
+	var rollups []models.NodeLogRollup
+	if !rangeStart.IsZero() {
+		if tier, rollups, err = rollupsFor(*node, rangeStart, rangeEnd); err != nil {
+			log.Println("Database error:", err)
+			return c.Status(500).JSON(ReportResponse{
+				Code:    500,
+				Msg:     "Database error",
 				Success: false,
 				Data:    nil,
 			})
 		}
-		db = db.Where("created_at BETWEEN ? AND ?", startDate, endDate.Add(23*time.Hour+59*time.Minute+59*time.Second))
 	}
 
-	if up == "1" {
-		db = db.Where("up = ?", true)
-	}
-	if down == "1" {
-		db = db.Where("up = ?", false)
-	}
-	if suspended == "1" {
-		db = db.Where("suspended = ?", true)
-	}
-	if exception == "1" {
-		db = db.Where("exception IS NOT NULL")
+	if format != "" {
+		e := &nodeReportExport{node: node, filter: filter, page: page, fields: fields, dates: dates,
+			from: rangeStart, to: rangeEnd, tier: tier, rollups: rollups}
+		return e.send(c, format)
 	}
 
-	if allItem != "" {
-		db = db.Order("id desc")
-	}
-	if orderAsc != "" {
-		db = db.Order("id asc")
-	}
-	if orderDesc != "" {
-		db = db.Order("id desc")
-	}
-	if firstItem != "" {
-		db = db.Order("id asc").Limit(1)
-	}
-	if lastItem != "" {
-		db = db.Order("id desc").Limit(1)
-	}
-	if ascDelay != "" {
-		db = db.Order("delay asc")
-	}
-	if descDelay != "" {
-		db = db.Order("delay desc")
-	}
-	if ascStatus != "" {
-		db = db.Order("status asc")
-	}
-	if descStatus != "" {
-		db = db.Order("status desc")
-	}
-	if ascUp != "" {
-		db = db.Order("up asc")
-	}
-	if descUp != "" {
-		db = db.Order("up desc")
-	}
-	if ascSuspended != "" {
-		db = db.Order("suspended asc")
-	}
-	if descSuspended != "" {
-		db = db.Order("suspended desc")
+	var downCount, maintenanceCount int64
+	var phases phaseAverages
+	count := func(l *models.NodeLog) {
+		page.add(l)
+		phases.add(l)
+		// Checks taken during a maintenance window do not count as downtime
+		if l.Maintenance {
+			maintenanceCount++
+		} else if !l.Up {
+			downCount++
+		}
 	}
-	if ascException != "" {
-		db = db.Order("exception asc")
+	// summary returns the counts over the logs read so far and the rollups
+	summary := func() map[string]interface{} {
+		down, maintenance := downCount, maintenanceCount
+		for _, r := range rollups {
+			down += int64(r.DownCount)
+			maintenance += int64(r.MaintenanceCount)
+		}
+		data := map[string]interface{}{
+			"log_count":         page.count,
+			"down_count":        down,
+			"maintenance_count": maintenance,
+			"phase_averages":    phases.result(),
+		}
+		if tier != "" {
+			data["rollup_tier"] = tier
+			data["rollups"] = rollups
+		}
+		if next := page.next(); next != "" {
+			data["next_cursor"] = next
+		}
+		return data
 	}
-	if descException != "" {
-		db = db.Order("exception desc")
+
+	selectRow := rowSelector[NodeLogResponse](fields)
+
+	// The rows go out as they are read, followed by a summary line with
+	// the counts
+	if wantsNDJSON(c) {
+		return streamNDJSON(c, func(ctx context.Context, emit func(v interface{}) error) error {
+			err := services.EachNodeLog(ctx, filter, func(l *models.NodeLog) error {
+				count(l)
+				return emit(selectRow(nodeLogResponse(l)))
+			})
+			if err != nil {
+				return err
+			}
+			return emit(map[string]interface{}{"summary": summary()})
+		})
 	}
 
-	var reports []models.NodeLog
-	
 	// Measure database query time
 	dbStartTime := time.Now()
-	if err := db.Find(&reports).Error; err != nil {
+	reports := []interface{}{}
+	err = services.EachNodeLog(ctx, filter, func(l *models.NodeLog) error {
+		count(l)
+		reports = append(reports, selectRow(nodeLogResponse(l)))
+		return nil
+	})
+	if err != nil {
 		log.Println("Database error:", err)
 		return c.Status(500).JSON(ReportResponse{
 			Code:    500,
@@ -172,125 +206,16 @@ func GetNodeSmartReport(c *fiber.Ctx) error {
 			Data:    nil,
 		})
 	}
-	dbDuration := time.Since(dbStartTime)
-	log.Printf("Smart Query database took: %v for %d records", dbDuration, len(reports))
-
-	type NodeLogResponse struct {
-		ID        uint     `json:"id"`
-		NodeID    uint     `json:"node_id"`
-		Delay     *float64 `json:"delay,omitempty"`
-		Status    *uint    `json:"status,omitempty"`
-		Up        int      `json:"up"`
-		Suspended int      `json:"suspended"`
-		Exception *string  `json:"exception"`
-		CreatedAt int64    `json:"created_at"`
-		UpdatedAt int64    `json:"updated_at"`
-	}
-
-	reportData := make([]NodeLogResponse, len(reports))
-	var downCount int64 // Use atomic counter for thread-safe counting
-	
-	// Optimize parallel processing with worker pool pattern
-	if len(reports) > 0 {
-		// Use worker pool for better resource management
-		numWorkers := runtime.NumCPU()
-		if len(reports) < 1000 {
-			// For small datasets, use fewer workers to avoid overhead
-			numWorkers = utils.Min(numWorkers, utils.Max(1, len(reports)/100))
-		} else {
-			// For large datasets, use more workers but cap at CPU count * 2
-			numWorkers = utils.Min(numWorkers*2, 16)
-		}
-		
-		if numWorkers == 0 {
-			numWorkers = 1
-		}
-
-		// Create jobs channel
-		jobs := make(chan int, len(reports))
-		var wg sync.WaitGroup
-		var processedCount int64
-
-		// Start workers
-		for w := 0; w < numWorkers; w++ {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				localDownCount := int64(0)
-				
-				for {
-					select {
-					case <-ctx.Done():
-						// Exit early if context is cancelled
-						atomic.AddInt64(&downCount, localDownCount)
-						return
-					case idx, ok := <-jobs:
-						if !ok {
-							atomic.AddInt64(&downCount, localDownCount)
-							return
-						}
-						
-						// Process single item
-						r := reports[idx]
-						if !r.Up {
-							localDownCount++
-						}
-						
-						reportData[idx] = NodeLogResponse{
-							ID:        r.ID,
-							NodeID:    r.NodeID,
-							Delay:     r.Delay,
-							Status:    r.Status,
-							Up:        boolToInt(r.Up),
-							Suspended: boolToInt(r.Suspended),
-							Exception: r.Exception,
-							CreatedAt: r.CreatedAt.Unix(),
-							UpdatedAt: r.UpdatedAt.Unix(),
-						}
-						
-						atomic.AddInt64(&processedCount, 1)
-					}
-				}
-			}()
-		}
+	log.Printf("Smart Query database took: %v for %d records, found %d down entries",
+		time.Since(dbStartTime), len(reports), downCount)
 
-		// Send jobs to workers
-		go func() {
-			defer close(jobs)
-			for i := 0; i < len(reports); i++ {
-				select {
-				case <-ctx.Done():
-					return
-				case jobs <- i:
-				}
-			}
-		}()
-
-		// Wait for all workers to finish
-		wg.Wait()
-
-		// Check if context was cancelled during processing
-		if ctx.Err() != nil {
-			return c.Status(408).JSON(ReportResponse{
-				Code:    408,
-				Msg:     "Request timeout during data processing",
-				Success: false,
-				Data:    nil,
-			})
-		}
-
-		log.Printf("Smart Report: Processed %d items with %d workers, found %d down entries", 
-			atomic.LoadInt64(&processedCount), numWorkers, atomic.LoadInt64(&downCount))
-	}
+	data := summary()
+	data["reports"] = reports
 
 	return c.JSON(ReportResponse{
 		Code:    200,
 		Msg:     "url report",
 		Success: true,
-		Data: map[string]interface{}{
-			"reports":    reportData,
-			"log_count":  len(reportData),
-			"down_count": downCount,
-		},
+		Data:    data,
 	})
 }
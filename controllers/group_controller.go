@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"uptime/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateGroup creates a new node group
+// @Summary Create a group
+// @Description Add a group that nodes and notification channels can refer to
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group body services.GroupInput true "Group"
+// @Success 201 {object} models.Group "Group created successfully"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "Name already exists"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /groups [post]
+func CreateGroup(c *fiber.Ctx) error {
+	var body services.GroupInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	group, err := services.CreateGroup(body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "Duplicate") {
+			return c.Status(409).JSON(fiber.Map{"error": "Group name already exists"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create group"})
+	}
+	return c.Status(201).JSON(group)
+}
+
+// GetAllGroups lists node groups
+// @Summary Get groups
+// @Description List all node groups ordered by name
+// @Tags groups
+// @Produce json
+// @Success 200 {array} models.Group "List of groups"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /groups [get]
+func GetAllGroups(c *fiber.Ctx) error {
+	groups, err := services.GetAllGroups()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(groups)
+}
+
+// GetGroupSummary summarizes the state of every group
+// @Summary Get group summary
+// @Description Per group, subgroups included: the number of nodes, how many are up, down, suspended or not checked yet, and their aggregate uptime percentage over the range, defaulting to the last 24 hours
+// @Tags groups
+// @Produce json
+// @Param group_id query int false "Only this group and its subgroups"
+// @Param start-date query string false "Range start (YYYY-MM-DD or RFC 3339)"
+// @Param end-date query string false "Range end (YYYY-MM-DD or RFC 3339)"
+// @Success 200 {object} services.GroupSummaryReport "Group summaries"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Group not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /groups/summary [get]
+func GetGroupSummary(c *fiber.Ctx) error {
+	query := services.GroupSummaryQuery{To: time.Now()}
+	query.From = query.To.Add(-24 * time.Hour)
+
+	scope, err := parseNodeScope(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	query.GroupID = scope.GroupID
+
+	if startStr := c.Query("start-date"); startStr != "" {
+		start, err := parseTimeParam(startStr, false)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Start date format invalid"})
+		}
+		query.From = *start
+	}
+	if endStr := c.Query("end-date"); endStr != "" {
+		end, err := parseTimeParam(endStr, true)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "End date format invalid"})
+		}
+		query.To = *end
+	}
+
+	report, err := services.GetGroupSummaries(query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Group not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(report)
+}
+
+func GetGroup(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	group, err := services.GetGroup(uint(id))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Group not found"})
+	}
+	return c.JSON(group)
+}
+
+func UpdateGroup(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	var body services.GroupInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	group, err := services.UpdateGroup(uint(id), body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Group not found"})
+		}
+		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "Duplicate") {
+			return c.Status(409).JSON(fiber.Map{"error": "Group name already exists"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update group"})
+	}
+	return c.JSON(group)
+}
+
+func DeleteGroup(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	err = services.DeleteGroupByID(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Group not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete group"})
+	}
+	return c.SendStatus(204)
+}
+
+// errGroupIDFormat is returned by parseNodeScope for a malformed group_id
+var errGroupIDFormat = errors.New("group_id format invalid")
+
+// parseNodeScope reads the group_id and tag query parameters shared by the
+// listings and reports
+func parseNodeScope(c *fiber.Ctx) (services.NodeScope, error) {
+	scope := services.NodeScope{Tag: strings.TrimSpace(c.Query("tag"))}
+	if groupIDStr := c.Query("group_id"); groupIDStr != "" {
+		groupID, err := strconv.Atoi(groupIDStr)
+		if err != nil || groupID <= 0 {
+			return scope, errGroupIDFormat
+		}
+		scope.GroupID = uint(groupID)
+	}
+	return scope, nil
+}
+
+// reportNodeIDs resolves the group_id and tag parameters of a report to the
+// IDs of the nodes in scope, nil when neither is set
+func reportNodeIDs(c *fiber.Ctx) ([]uint, error) {
+	scope, err := parseNodeScope(c)
+	if err != nil {
+		return nil, err
+	}
+	return services.ScopedNodeIDs(scope)
+}
+
+// scopeFailed answers a report whose group_id or tag could not be resolved
+func scopeFailed(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errGroupIDFormat) || errors.Is(err, services.ErrInvalidInput) {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
+	}
+	log.Println("Database error:", err)
+	return c.Status(500).JSON(ReportResponse{
+		Code:    500,
+		Msg:     "Database error",
+		Success: false,
+		Data:    nil,
+	})
+}
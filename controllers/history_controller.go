@@ -1,9 +1,12 @@
 package controllers
 
 import (
+	"context"
+	"errors"
 	"strconv"
 	"strings"
 	"uptime/models"
+	"uptime/repositories"
 	"uptime/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -28,10 +31,39 @@ func CreateHistory(c *fiber.Ctx) error {
 }
 
 func GetAllHistories(c *fiber.Ctx) error {
-	histories, err := services.GetAllHistories()
+	scope, err := parseNodeScope(c)
 	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	nodeIDs, err := services.ScopedNodeIDs(scope)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+
+	page, err := parsePage(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	filter := repositories.LogFilter{NodeIDs: nodeIDs}
+	pageByID(&filter, page)
+
+	if wantsNDJSON(c) {
+		return streamByID(c, page, func(h *models.History) uint { return h.ID },
+			func(ctx context.Context, fn func(*models.History) error) error {
+				return services.EachHistory(ctx, filter, fn)
+			})
+	}
+
+	histories, err := services.FindHistories(c.Context(), filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(histories) > 0 {
+		setNextAfterID(c, page, len(histories), histories[len(histories)-1].ID)
+	}
 	return c.JSON(histories)
 }
 
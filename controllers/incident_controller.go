@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"uptime/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseTimeParam accepts RFC 3339 timestamps or YYYY-MM-DD dates. A date used
+// as the end of a range covers the whole day.
+func parseTimeParam(value string, endOfDay bool) (*time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return &t, nil
+}
+
+// GetIncidents lists incidents
+// @Summary Get incidents
+// @Description List incidents filtered by node, group, tag, status, acknowledgement and time range
+// @Tags incidents
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param node_id query int false "Node ID"
+// @Param group_id query int false "Group ID, subgroups included"
+// @Param tag query string false "Tag name"
+// @Param status query string false "open or resolved"
+// @Param acknowledged query string false "1 or 0"
+// @Param start-date query string false "Range start (YYYY-MM-DD or RFC 3339)"
+// @Param end-date query string false "Range end (YYYY-MM-DD or RFC 3339)"
+// @Param limit query int false "Page size, at most 10000"
+// @Param after_id query int false "Continue after the incident with this ID, from the X-Next-After-Id header"
+// @Param format query string false "csv or xlsx to download the report, xlsx with a summary sheet. Also taken from the Accept header" Enums(csv, xlsx)
+// @Param tz query string false "Time zone of exported times, e.g. Asia/Tehran or +03:30 (default UTC)"
+// @Param calendar query string false "Calendar of exported dates (default gregorian)" Enums(gregorian, jalali)
+// @Success 200 {array} models.Incident "List of incidents"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /incidents [get]
+func GetIncidents(c *fiber.Ctx) error {
+	var query services.IncidentQuery
+
+	if nodeIDStr := c.Query("node_id"); nodeIDStr != "" {
+		nodeID, err := strconv.Atoi(nodeIDStr)
+		if err != nil || nodeID <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid node_id format"})
+		}
+		query.NodeID = uint(nodeID)
+	}
+
+	scope, err := parseNodeScope(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	query.Scope = scope
+
+	query.Status = strings.ToLower(c.Query("status"))
+
+	switch c.Query("acknowledged") {
+	case "":
+	case "1":
+		ack := true
+		query.Acknowledged = &ack
+	case "0":
+		ack := false
+		query.Acknowledged = &ack
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "acknowledged must be 1 or 0"})
+	}
+
+	if startStr := c.Query("start-date"); startStr != "" {
+		from, err := parseTimeParam(startStr, false)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Start date format invalid"})
+		}
+		query.From = from
+	}
+	if endStr := c.Query("end-date"); endStr != "" {
+		to, err := parseTimeParam(endStr, true)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "End date format invalid"})
+		}
+		query.To = to
+	}
+
+	page, err := parsePage(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	query.Page = page
+
+	format, dates, err := parseExport(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	incidents, err := services.GetIncidents(query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(incidents) > 0 {
+		setNextAfterID(c, page, len(incidents), incidents[len(incidents)-1].ID)
+	}
+	if format != "" {
+		return sendIncidentExport(c, format, dates, query, incidents)
+	}
+	return c.JSON(incidents)
+}
+
+// GetIncident retrieves one incident with its notes
+// @Summary Get incident
+// @Description Get an incident with its notes
+// @Tags incidents
+// @Produce json
+// @Param id path int true "Incident ID"
+// @Success 200 {object} models.Incident "Incident"
+// @Failure 404 {object} map[string]string "Incident not found"
+// @Security ApiKeyAuth
+// @Router /incidents/{id} [get]
+func GetIncident(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	incident, err := services.GetIncident(uint(id))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Incident not found"})
+	}
+	return c.JSON(incident)
+}
+
+// AcknowledgeIncident marks an incident as acknowledged
+// @Summary Acknowledge incident
+// @Description Mark an incident as being handled, optionally adding a note
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param id path int true "Incident ID"
+// @Param body body object{acknowledged_by=string,note=string} true "Acknowledgement"
+// @Success 200 {object} models.Incident "Acknowledged incident"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Incident not found"
+// @Security ApiKeyAuth
+// @Router /incidents/{id}/acknowledge [post]
+func AcknowledgeIncident(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	type Request struct {
+		AcknowledgedBy string `json:"acknowledged_by"`
+		Note           string `json:"note"`
+	}
+	var body Request
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	incident, err := services.AcknowledgeIncident(uint(id), body.AcknowledgedBy, body.Note)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Incident not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to acknowledge incident"})
+	}
+	return c.JSON(incident)
+}
+
+// AddIncidentNote annotates an incident
+// @Summary Add incident note
+// @Description Add a free-text note to an incident
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param id path int true "Incident ID"
+// @Param body body object{author=string,body=string} true "Note"
+// @Success 201 {object} models.IncidentNote "Created note"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Incident not found"
+// @Security ApiKeyAuth
+// @Router /incidents/{id}/notes [post]
+func AddIncidentNote(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	type Request struct {
+		Author string `json:"author"`
+		Body   string `json:"body"`
+	}
+	var body Request
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	note, err := services.AddIncidentNote(uint(id), body.Author, body.Body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Incident not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to add note"})
+	}
+	return c.Status(201).JSON(note)
+}
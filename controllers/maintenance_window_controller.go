@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"uptime/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateMaintenanceWindow creates a new maintenance window
+// @Summary Create a maintenance window
+// @Description Add a one-off (starts_at/ends_at), cron or weekly window for the selected nodes and groups. Checks keep running but raise no incidents or notifications.
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param window body services.MaintenanceWindowInput true "Maintenance window"
+// @Success 201 {object} models.MaintenanceWindow "Maintenance window created successfully"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /maintenance-windows [post]
+func CreateMaintenanceWindow(c *fiber.Ctx) error {
+	var body services.MaintenanceWindowInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	window, err := services.CreateMaintenanceWindow(body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create maintenance window"})
+	}
+	return c.Status(201).JSON(window)
+}
+
+// GetAllMaintenanceWindows lists maintenance windows
+// @Summary Get maintenance windows
+// @Description List all maintenance windows
+// @Tags maintenance
+// @Produce json
+// @Success 200 {array} models.MaintenanceWindow "List of maintenance windows"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /maintenance-windows [get]
+func GetAllMaintenanceWindows(c *fiber.Ctx) error {
+	windows, err := services.GetAllMaintenanceWindows()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(windows)
+}
+
+func GetMaintenanceWindow(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	window, err := services.GetMaintenanceWindow(uint(id))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Maintenance window not found"})
+	}
+	return c.JSON(window)
+}
+
+func UpdateMaintenanceWindow(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	var body services.MaintenanceWindowInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	window, err := services.UpdateMaintenanceWindow(uint(id), body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Maintenance window not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update maintenance window"})
+	}
+	return c.JSON(window)
+}
+
+func DeleteMaintenanceWindow(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	err = services.DeleteMaintenanceWindowByID(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Maintenance window not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete maintenance window"})
+	}
+	return c.SendStatus(204)
+}
+
+// GetMaintenanceOccurrences lists when a window is active
+// @Summary Get maintenance window occurrences
+// @Description List the intervals the window is active in a range of at most 366 days, defaulting to the next 7 days
+// @Tags maintenance
+// @Produce json
+// @Param id path int true "Maintenance window ID"
+// @Param start-date query string false "Range start (YYYY-MM-DD or RFC 3339)"
+// @Param end-date query string false "Range end (YYYY-MM-DD or RFC 3339)"
+// @Success 200 {array} maintenance.Interval "Active intervals"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Security ApiKeyAuth
+// @Router /maintenance-windows/{id}/occurrences [get]
+func GetMaintenanceOccurrences(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	from := time.Now()
+	to := from.Add(7 * 24 * time.Hour)
+	if startStr := c.Query("start-date"); startStr != "" {
+		start, err := parseTimeParam(startStr, false)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Start date format invalid"})
+		}
+		from = *start
+	}
+	if endStr := c.Query("end-date"); endStr != "" {
+		end, err := parseTimeParam(endStr, true)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "End date format invalid"})
+		}
+		to = *end
+	}
+
+	occurrences, err := services.GetMaintenanceOccurrences(uint(id), from, to)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Maintenance window not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(occurrences)
+}
@@ -1,9 +1,10 @@
 package controllers
 
 import (
-	"net/url"
+	"errors"
 	"strconv"
 	"strings"
+	"uptime/models"
 	"uptime/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,38 +12,31 @@ import (
 
 // CreateNode creates a new monitoring node
 // @Summary Create a new node
-// @Description Add a new website/service to monitor
+// @Description Add a new website/service to monitor with an optional check definition (method, headers, body, expected status codes, timeout)
 // @Tags nodes
 // @Accept json
 // @Produce json
-// @Param node body object{url=string} true "Node URL"
+// @Param node body services.NodeInput true "Node URL and check configuration"
 // @Success 201 {object} map[string]interface{} "Node created successfully"
 // @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "URL already exists or belongs to an archived node"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Security ApiKeyAuth
 // @Router /nodes [post]
 func CreateNode(c *fiber.Ctx) error {
-	type Request struct {
-		URL string `json:"url"`
-	}
-	var body Request
+	var body services.NodeInput
 	if err := c.BodyParser(&body); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
 	}
 
-	// Validate URL
-	if strings.TrimSpace(body.URL) == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "URL cannot be empty"})
-	}
-
-	// Parse and validate URL format
-	parsedURL, err := url.Parse(body.URL)
-	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid URL format. Must be a valid HTTP/HTTPS URL"})
-	}
-
-	node, err := services.CreateNode(body.URL)
+	node, err := services.CreateNode(body)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, services.ErrNodeArchived) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
 		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "Duplicate") {
 			return c.Status(409).JSON(fiber.Map{"error": "URL already exists"})
 		}
@@ -53,18 +47,40 @@ func CreateNode(c *fiber.Ctx) error {
 
 // GetAllNodes retrieves all monitoring nodes
 // @Summary Get all nodes
-// @Description Retrieve a list of all monitored websites/services
+// @Description Retrieve a list of all monitored websites/services with their tags, optionally limited to a group (subgroups included), a tag or a lifecycle state. Archived nodes are only listed with lifecycle=archived.
 // @Tags nodes
 // @Produce json
+// @Param group_id query int false "Group ID, subgroups included"
+// @Param tag query string false "Tag name"
+// @Param lifecycle query string false "Lifecycle state" Enums(active, paused, archived)
+// @Param limit query int false "Page size, at most 10000"
+// @Param after_id query int false "Continue after the node with this ID, from the X-Next-After-Id header"
 // @Success 200 {array} models.Node "List of nodes"
+// @Failure 400 {object} map[string]string "Bad Request"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Security ApiKeyAuth
 // @Router /nodes [get]
 func GetAllNodes(c *fiber.Ctx) error {
-	nodes, err := services.GetAllNodes()
+	scope, err := parseNodeScope(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	page, err := parsePage(c)
 	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	nodes, err := services.FindNodes(scope, c.Query("lifecycle"), page)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	if len(nodes) > 0 {
+		setNextAfterID(c, page, len(nodes), nodes[len(nodes)-1].ID)
+	}
 	return c.JSON(nodes)
 }
 
@@ -79,7 +95,7 @@ func GetNode(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
 	}
 	
-	node, err := services.GetNode(uint(id))
+	node, err := services.GetNodeIncludingArchived(uint(id))
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Node not found"})
 	}
@@ -97,30 +113,22 @@ func UpdateNode(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
 	}
 	
-	type Request struct {
-		URL string `json:"url"`
-	}
-	var body Request
+	var body services.NodeInput
 	if err := c.BodyParser(&body); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
 	}
 
-	// Validate URL
-	if strings.TrimSpace(body.URL) == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "URL cannot be empty"})
-	}
-
-	// Parse and validate URL format
-	parsedURL, err := url.Parse(body.URL)
-	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid URL format. Must be a valid HTTP/HTTPS URL"})
-	}
-
-	node, err := services.UpdateNodeURL(uint(id), body.URL)
+	node, err := services.UpdateNode(uint(id), body)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(404).JSON(fiber.Map{"error": "Node not found"})
 		}
+		if errors.Is(err, services.ErrNodeArchived) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
 		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "Duplicate") {
 			return c.Status(409).JSON(fiber.Map{"error": "URL already exists"})
 		}
@@ -129,23 +137,132 @@ func UpdateNode(c *fiber.Ctx) error {
 	return c.JSON(node)
 }
 
+// DeleteNode archives a node
+// @Summary Archive a node
+// @Description Stop checking a node and hide it from the node listings. Its logs, histories, incidents and tags are kept, its reports keep working and it can be restored.
+// @Tags nodes
+// @Param id path int true "Node ID"
+// @Success 204 "Node archived"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Node not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /nodes/{id} [delete]
 func DeleteNode(c *fiber.Ctx) error {
+	id, err := nodeIDParam(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	err = services.ArchiveNode(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Node not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to archive node"})
+	}
+	return c.SendStatus(204)
+}
+
+// PauseNode pauses the checks of a node
+// @Summary Pause a node
+// @Description Stop checking a node, until a time, for a duration or until it is resumed. Paused nodes are resumed by the scheduler within SCHEDULER_RELOAD_INTERVAL of their until time.
+// @Tags nodes
+// @Accept json
+// @Produce json
+// @Param id path int true "Node ID"
+// @Param pause body services.PauseInput false "until (RFC 3339) or duration (e.g. 2h), neither pauses indefinitely"
+// @Success 200 {object} models.Node "Paused node"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Node not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /nodes/{id}/pause [post]
+func PauseNode(c *fiber.Ctx) error {
+	id, err := nodeIDParam(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var body services.PauseInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+		}
+	}
+
+	node, err := services.PauseNode(id, body)
+	return lifecycleResponse(c, node, err, "Failed to pause node")
+}
+
+// ResumeNode resumes the checks of a paused node
+// @Summary Resume a node
+// @Description Check a paused node again
+// @Tags nodes
+// @Produce json
+// @Param id path int true "Node ID"
+// @Success 200 {object} models.Node "Resumed node"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Node not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /nodes/{id}/resume [post]
+func ResumeNode(c *fiber.Ctx) error {
+	id, err := nodeIDParam(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	node, err := services.ResumeNode(id)
+	return lifecycleResponse(c, node, err, "Failed to resume node")
+}
+
+// RestoreNode brings an archived node back
+// @Summary Restore a node
+// @Description Restore an archived node as active, with its logs, histories, incidents and tags
+// @Tags nodes
+// @Produce json
+// @Param id path int true "Node ID"
+// @Success 200 {object} models.Node "Restored node"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Node not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /nodes/{id}/restore [post]
+func RestoreNode(c *fiber.Ctx) error {
+	id, err := nodeIDParam(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	node, err := services.RestoreNode(id)
+	return lifecycleResponse(c, node, err, "Failed to restore node")
+}
+
+// nodeIDParam reads the :id parameter of the node routes
+func nodeIDParam(c *fiber.Ctx) (uint, error) {
 	idStr := c.Params("id")
 	if idStr == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+		return 0, errors.New("ID parameter is required")
 	}
-	
+
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+		return 0, errors.New("Invalid ID format")
 	}
-	
-	err = services.DeleteNodeByID(uint(id))
+	return uint(id), nil
+}
+
+// lifecycleResponse answers a pause, resume or restore request
+func lifecycleResponse(c *fiber.Ctx, node *models.Node, err error, failed string) error {
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(404).JSON(fiber.Map{"error": "Node not found"})
 		}
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete node"})
+		return c.Status(500).JSON(fiber.Map{"error": failed})
 	}
-	return c.SendStatus(204)
+	return c.JSON(node)
 }
@@ -1,14 +1,15 @@
 package controllers
 
 import (
+	"context"
+	"errors"
 	"strconv"
 	"strings"
-	"uptime/database"
 	"uptime/models"
+	"uptime/repositories"
 	"uptime/services"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
 func CreateNodeLog(c *fiber.Ctx) error {
@@ -30,10 +31,60 @@ func CreateNodeLog(c *fiber.Ctx) error {
 }
 
 func GetAllNodeLogs(c *fiber.Ctx) error {
-	logs, err := services.GetAllNodeLogs()
+	scope, err := parseNodeScope(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	nodeIDs, err := services.ScopedNodeIDs(scope)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if cycleID := c.Query("cycle_id"); cycleID != "" {
+		logs, err := services.GetNodeLogsByCycle(cycleID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		if nodeIDs != nil {
+			inScope := make(map[uint]bool, len(nodeIDs))
+			for _, id := range nodeIDs {
+				inScope[id] = true
+			}
+			kept := []models.NodeLog{}
+			for _, l := range logs {
+				if inScope[l.NodeID] {
+					kept = append(kept, l)
+				}
+			}
+			logs = kept
+		}
+		return c.JSON(logs)
+	}
+
+	page, err := parsePage(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	filter := repositories.LogFilter{NodeIDs: nodeIDs}
+	pageByID(&filter, page)
+
+	if wantsNDJSON(c) {
+		return streamByID(c, page, func(l *models.NodeLog) uint { return l.ID },
+			func(ctx context.Context, fn func(*models.NodeLog) error) error {
+				return services.EachNodeLog(ctx, filter, fn)
+			})
+	}
+
+	logs, err := services.FindNodeLogs(c.Context(), filter)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	if len(logs) > 0 {
+		setNextAfterID(c, page, len(logs), logs[len(logs)-1].ID)
+	}
 	return c.JSON(logs)
 }
 
@@ -55,6 +106,38 @@ func GetNodeLog(c *fiber.Ctx) error {
 	return c.JSON(log)
 }
 
+// GetNodeLogAttempts lists the individual attempts behind a node log
+// @Summary Get check attempts
+// @Description List every attempt of a check that was retried before its result was logged
+// @Tags node-logs
+// @Produce json
+// @Param id path int true "Node log ID"
+// @Success 200 {array} models.CheckAttempt "List of attempts"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Node log not found"
+// @Security ApiKeyAuth
+// @Router /node-logs/{id}/attempts [get]
+func GetNodeLogAttempts(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	attempts, err := services.GetNodeLogAttempts(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Node log not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(attempts)
+}
+
 func UpdateNodeLog(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	if idStr == "" {
@@ -111,15 +194,18 @@ func DeleteNodeLog(c *fiber.Ctx) error {
 }
 
 func GetAllNodesWithLogs(c *fiber.Ctx) error {
-	var nodes []models.Node
-
-	err := database.DB.Preload("NodeLogs", func(db *gorm.DB) *gorm.DB {
-		return db.Select("id, node_id, delay, status, up, suspended, exception, created_at")
-	}).Find(&nodes).Error
+	page, err := parsePage(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
 
+	nodes, err := services.GetNodesWithLogs(page)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	if len(nodes) > 0 {
+		setNextAfterID(c, page, len(nodes), nodes[len(nodes)-1].ID)
+	}
 
 	return c.JSON(nodes)
 }
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"uptime/repositories"
+	"uptime/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetNotificationDeliveries lists the notification delivery log
+// @Summary Get notification deliveries
+// @Description List notification deliveries, newest first, filtered by channel, target, node and status
+// @Tags notifications
+// @Produce json
+// @Param channel query string false "Channel, e.g. webhook"
+// @Param target_id query int false "Target ID within the channel"
+// @Param node_id query int false "Node ID"
+// @Param status query string false "pending, delivered or failed"
+// @Param limit query int false "Maximum rows (default 100, max 1000)"
+// @Param after_id query int false "Continue after the delivery with this ID, from the X-Next-After-Id header"
+// @Success 200 {array} models.NotificationDelivery "List of deliveries"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /notifications/deliveries [get]
+func GetNotificationDeliveries(c *fiber.Ctx) error {
+	query := services.DeliveryQuery{
+		Channel: strings.ToLower(c.Query("channel")),
+		Status:  strings.ToLower(c.Query("status")),
+		Limit:   100,
+	}
+
+	if targetIDStr := c.Query("target_id"); targetIDStr != "" {
+		targetID, err := strconv.Atoi(targetIDStr)
+		if err != nil || targetID <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid target_id format"})
+		}
+		query.TargetID = uint(targetID)
+	}
+
+	if nodeIDStr := c.Query("node_id"); nodeIDStr != "" {
+		nodeID, err := strconv.Atoi(nodeIDStr)
+		if err != nil || nodeID <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid node_id format"})
+		}
+		query.NodeID = uint(nodeID)
+	}
+
+	if afterIDStr := c.Query("after_id"); afterIDStr != "" {
+		afterID, err := strconv.Atoi(afterIDStr)
+		if err != nil || afterID <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid after_id format"})
+		}
+		query.AfterID = uint(afterID)
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > 1000 {
+			return c.Status(400).JSON(fiber.Map{"error": "limit must be between 1 and 1000"})
+		}
+		query.Limit = limit
+	}
+
+	deliveries, err := services.GetNotificationDeliveries(query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(deliveries) > 0 {
+		setNextAfterID(c, repositories.Page{Limit: query.Limit}, len(deliveries), deliveries[len(deliveries)-1].ID)
+	}
+	return c.JSON(deliveries)
+}
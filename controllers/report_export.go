@@ -0,0 +1,379 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"uptime/internal/export"
+	"uptime/internal/logquery"
+	"uptime/models"
+	"uptime/repositories"
+	"uptime/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// exportFormat returns the spreadsheet format a report is asked for, with
+// format= or the Accept header, "" for JSON or NDJSON
+func exportFormat(c *fiber.Ctx) (string, error) {
+	switch f := strings.ToLower(c.Query("format")); f {
+	case export.CSV, export.XLSX:
+		return f, nil
+	case "json", "ndjson":
+		return "", nil
+	case "":
+	default:
+		return "", fmt.Errorf("format must be json, ndjson, csv or xlsx")
+	}
+	accept := c.Get(fiber.HeaderAccept)
+	for _, f := range []string{export.CSV, export.XLSX} {
+		if strings.Contains(accept, export.ContentTypes[f]) {
+			return f, nil
+		}
+	}
+	return "", nil
+}
+
+// parseExportDates reads the tz and calendar parameters the times of an
+// export are written in
+func parseExportDates(c *fiber.Ctx) (export.Dates, error) {
+	loc, err := logquery.LoadLocation(c.Query("tz"))
+	if err != nil {
+		return export.Dates{}, err
+	}
+	calendar := strings.ToLower(c.Query("calendar"))
+	switch calendar {
+	case "":
+		calendar = export.Gregorian
+	case export.Gregorian, export.Jalali:
+	default:
+		return export.Dates{}, fmt.Errorf("calendar must be %s or %s", export.Gregorian, export.Jalali)
+	}
+	return export.Dates{Loc: loc, Calendar: calendar}, nil
+}
+
+// parseExport reads the format of a report and, for an export, the dates
+// its times are written in
+func parseExport(c *fiber.Ctx) (string, export.Dates, error) {
+	format, err := exportFormat(c)
+	if err != nil || format == "" {
+		return "", export.Dates{}, err
+	}
+	dates, err := parseExportDates(c)
+	return format, dates, err
+}
+
+// sendExport answers with a CSV or XLSX file called name, whose sheets write
+// fills while the file is sent. Like streamNDJSON, write runs after the
+// handler returned and must not use c. A failure leaves the file cut short.
+func sendExport(c *fiber.Ctx, format, name string, write func(ctx context.Context, w export.Writer) error) error {
+	contentType := export.ContentTypes[format]
+	if format == export.CSV {
+		contentType += "; charset=utf-8"
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+name+"."+format+`"`)
+	c.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(context.Background(), streamTimeout)
+		defer cancel()
+
+		w, err := export.New(format, bw)
+		if err == nil {
+			err = write(ctx, w)
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		if err != nil {
+			log.Println("Export error:", err)
+		}
+		bw.Flush()
+	})
+	return nil
+}
+
+// summaryRows writes label / value pairs, skipping the values left nil
+func summaryRows(w export.Writer, rows ...interface{}) error {
+	for i := 0; i+1 < len(rows); i += 2 {
+		if rows[i+1] == nil {
+			continue
+		}
+		if err := w.Row(rows[i], rows[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportTable writes items of type T as rows, one column per JSON field.
+// Fields ending in _at hold Unix times, written as dates.
+type exportTable[T any] struct {
+	columns []string
+	index   map[string]int
+	dates   export.Dates
+}
+
+// newExportTable returns the table of the fields asked for, all of T's
+// fields when fields is nil
+func newExportTable[T any](fields []string, dates export.Dates) *exportTable[T] {
+	t := reflect.TypeOf(*new(T))
+	index := jsonFields(t)
+	columns := fields
+	if columns == nil {
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if _, ok := index[name]; ok {
+				columns = append(columns, name)
+			}
+		}
+	}
+	return &exportTable[T]{columns: columns, index: index, dates: dates}
+}
+
+func (t *exportTable[T]) header(w export.Writer) error {
+	cells := make([]interface{}, len(t.columns))
+	for i, name := range t.columns {
+		cells[i] = name
+	}
+	return w.Row(cells...)
+}
+
+func (t *exportTable[T]) row(w export.Writer, item T) error {
+	v := reflect.ValueOf(item)
+	cells := make([]interface{}, len(t.columns))
+	for i, name := range t.columns {
+		cells[i] = v.Field(t.index[name]).Interface()
+		if unix, ok := cells[i].(int64); ok && strings.HasSuffix(name, "_at") {
+			cells[i] = t.dates.Format(time.Unix(unix, 0))
+		}
+	}
+	return w.Row(cells...)
+}
+
+// nodeReportExport holds what the export of a node report needs once the
+// handler has returned
+type nodeReportExport struct {
+	node     *models.Node
+	filter   repositories.LogFilter
+	page     *logPage[models.NodeLog]
+	fields   []string
+	dates    export.Dates
+	from, to time.Time
+	tier     string
+	rollups  []models.NodeLogRollup
+}
+
+// send answers with the node's logs in the data sheet and their counts,
+// with the checks of the rollups, in the summary sheet
+func (e *nodeReportExport) send(c *fiber.Ctx, format string) error {
+	name := fmt.Sprintf("node-%d-report", e.node.ID)
+	return sendExport(c, format, name, func(ctx context.Context, w export.Writer) error {
+		table := newExportTable[NodeLogResponse](e.fields, e.dates)
+		if err := w.Sheet(export.DataSheet); err != nil {
+			return err
+		}
+		if err := table.header(w); err != nil {
+			return err
+		}
+
+		var checks, up, down, suspended, maintenance int64
+		var delay phaseSum
+		err := services.EachNodeLog(ctx, e.filter, func(l *models.NodeLog) error {
+			e.page.add(l)
+			checks++
+			delay.add(l.Delay)
+			// Checks taken during a maintenance window do not count as downtime,
+			// and suspended ones are neither up nor down, as in the rollups
+			switch state := models.NodeState(l.Up, l.Suspended); {
+			case l.Maintenance:
+				maintenance++
+			case state == models.StateUp:
+				up++
+			case state == models.StateSuspended:
+				suspended++
+			default:
+				down++
+			}
+			return table.row(w, nodeLogResponse(l))
+		})
+		if err != nil {
+			return err
+		}
+		for _, r := range e.rollups {
+			checks += int64(r.Checks)
+			up += int64(r.UpCount)
+			down += int64(r.DownCount)
+			suspended += int64(r.SuspendedCount)
+			maintenance += int64(r.MaintenanceCount)
+		}
+
+		if err := w.Sheet(export.SummarySheet); err != nil {
+			return err
+		}
+		var from, to, tier, next interface{}
+		if !e.from.IsZero() {
+			from, to = e.dates.Format(e.from), e.dates.Format(e.to)
+		}
+		if e.tier != "" {
+			tier = e.tier
+		}
+		if cursor := e.page.next(); cursor != "" {
+			next = cursor
+		}
+		return summaryRows(w,
+			"URL", e.node.URL,
+			"Node ID", e.node.ID,
+			"From", from,
+			"To", to,
+			"Time zone", e.dates.Loc.String(),
+			"Calendar", e.dates.Calendar,
+			"Checks", checks,
+			"Up", up,
+			"Down", down,
+			"Suspended", suspended,
+			"Maintenance", maintenance,
+			"Average delay", delay.average(),
+			"Rollup tier", tier,
+			"Next cursor", next,
+			"Exported at", e.dates.Format(time.Now()),
+		)
+	})
+}
+
+// sendSLAExport answers with the SLA figures of each node of the report in
+// the data sheet, one row for a single node, and those of the whole report
+// in the summary sheet
+func sendSLAExport(c *fiber.Ctx, format string, dates export.Dates, query services.SLAQuery, report *services.SLAReport) error {
+	nodes := report.Nodes
+	if query.GroupID == 0 {
+		node := services.NodeSLA{NodeID: query.NodeID, URL: query.URL, Report: report.Report}
+		if node.NodeID != 0 {
+			if n, err := services.GetNodeIncludingArchived(node.NodeID); err == nil {
+				node.URL = n.URL
+			}
+		} else if n, err := services.GetNodeByURL(node.URL); err == nil {
+			node.NodeID = n.ID
+		}
+		nodes = []services.NodeSLA{node}
+	}
+
+	return sendExport(c, format, "sla-report", func(ctx context.Context, w export.Writer) error {
+		if err := w.Sheet(export.DataSheet); err != nil {
+			return err
+		}
+		err := w.Row("node_id", "url", "uptime_percent", "monitored_time", "downtime", "suspended_time",
+			"maintenance_time", "unknown_time", "checks", "incidents", "mttr", "mtbf",
+			"latency_p50", "latency_p95", "latency_p99", "latency_samples")
+		if err != nil {
+			return err
+		}
+		for _, n := range nodes {
+			err := w.Row(n.NodeID, n.URL, n.UptimePercent, n.MonitoredTime, n.Downtime, n.SuspendedTime,
+				n.MaintenanceTime, n.UnknownTime, n.Checks, n.Incidents, n.MTTR, n.MTBF,
+				n.Latency.P50, n.Latency.P95, n.Latency.P99, n.Latency.Samples)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := w.Sheet(export.SummarySheet); err != nil {
+			return err
+		}
+		var nodeID, url, groupID, tier interface{}
+		switch {
+		case query.GroupID != 0:
+			groupID = query.GroupID
+		default:
+			nodeID, url = nodes[0].NodeID, nodes[0].URL
+		}
+		if report.RollupTier != "" {
+			tier = report.RollupTier
+		}
+		return summaryRows(w,
+			"Node ID", nodeID,
+			"URL", url,
+			"Group ID", groupID,
+			"Nodes", len(nodes),
+			"From", dates.Format(report.From),
+			"To", dates.Format(report.To),
+			"Time zone", dates.Loc.String(),
+			"Calendar", dates.Calendar,
+			"Uptime %", report.UptimePercent,
+			"Monitored time (s)", report.MonitoredTime,
+			"Downtime (s)", report.Downtime,
+			"Suspended time (s)", report.SuspendedTime,
+			"Maintenance time (s)", report.MaintenanceTime,
+			"Unknown time (s)", report.UnknownTime,
+			"Checks", report.Checks,
+			"Incidents", report.Incidents,
+			"MTTR (s)", report.MTTR,
+			"MTBF (s)", report.MTBF,
+			"Latency p50", report.Latency.P50,
+			"Latency p95", report.Latency.P95,
+			"Latency p99", report.Latency.P99,
+			"Latency samples", report.Latency.Samples,
+			"Rollup tier", tier,
+			"Exported at", dates.Format(time.Now()),
+		)
+	})
+}
+
+// sendIncidentExport answers with the incidents in the data sheet and their
+// counts and total downtime in the summary sheet
+func sendIncidentExport(c *fiber.Ctx, format string, dates export.Dates, query services.IncidentQuery, incidents []models.Incident) error {
+	return sendExport(c, format, "incidents", func(ctx context.Context, w export.Writer) error {
+		if err := w.Sheet(export.DataSheet); err != nil {
+			return err
+		}
+		err := w.Row("id", "node_id", "status", "state", "started_at", "resolved_at", "duration",
+			"acknowledged", "acknowledged_at", "acknowledged_by", "first_error")
+		if err != nil {
+			return err
+		}
+		var open, resolved int
+		var downtime float64
+		for _, inc := range incidents {
+			if inc.Status == models.IncidentOpen {
+				open++
+			} else {
+				resolved++
+			}
+			if inc.Duration != nil {
+				downtime += *inc.Duration
+			}
+			err := w.Row(inc.ID, inc.NodeID, inc.Status, inc.State, dates.Format(inc.StartedAt),
+				dates.FormatPtr(inc.ResolvedAt), inc.Duration, inc.Acknowledged,
+				dates.FormatPtr(inc.AcknowledgedAt), inc.AcknowledgedBy, inc.FirstError)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := w.Sheet(export.SummarySheet); err != nil {
+			return err
+		}
+		var from, to interface{}
+		if query.From != nil {
+			from = dates.Format(*query.From)
+		}
+		if query.To != nil {
+			to = dates.Format(*query.To)
+		}
+		return summaryRows(w,
+			"From", from,
+			"To", to,
+			"Time zone", dates.Loc.String(),
+			"Calendar", dates.Calendar,
+			"Incidents", len(incidents),
+			"Open", open,
+			"Resolved", resolved,
+			"Downtime of the resolved (s)", downtime,
+			"Exported at", dates.Format(time.Now()),
+		)
+	})
+}
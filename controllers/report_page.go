@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"uptime/internal/logquery"
+	"uptime/repositories"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxPageSize is the largest limit a listing accepts
+const maxPageSize = 10000
+
+// parsePage reads the limit and after_id parameters of a listing
+func parsePage(c *fiber.Ctx) (repositories.Page, error) {
+	var page repositories.Page
+	if s := c.Query("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 || limit > maxPageSize {
+			return page, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+		}
+		page.Limit = limit
+	}
+	if s := c.Query("after_id"); s != "" {
+		id, err := strconv.ParseUint(s, 10, 0)
+		if err != nil || id == 0 {
+			return page, errors.New("Invalid after_id format")
+		}
+		page.AfterID = uint(id)
+	}
+	return page, nil
+}
+
+// nextAfterID returns the after_id of the page following a page of count
+// rows ending with lastID, 0 when the page was not full and so the last one
+func nextAfterID(page repositories.Page, count int, lastID uint) uint {
+	if page.Limit == 0 || count < page.Limit {
+		return 0
+	}
+	return lastID
+}
+
+// setNextAfterID tells the caller of a listing answered with a bare array
+// where the next page starts
+func setNextAfterID(c *fiber.Ctx, page repositories.Page, count int, lastID uint) {
+	if next := nextAfterID(page, count, lastID); next != 0 {
+		c.Set("X-Next-After-Id", strconv.FormatUint(uint64(next), 10))
+	}
+}
+
+// pageByID narrows filter to a page of node logs or histories in ID order
+func pageByID(filter *repositories.LogFilter, page repositories.Page) {
+	filter.OrderBy("id", false)
+	filter.Limit = page.Limit
+	if page.AfterID != 0 {
+		filter.Where = logquery.And(filter.Where, &logquery.Expr{Op: ">", Field: "id", Value: float64(page.AfterID)})
+	}
+}
+
+// streamByID streams a page of node logs or histories read by each, followed
+// by a summary line with the next page's after_id when there is one
+func streamByID[T any](c *fiber.Ctx, page repositories.Page, id func(*T) uint, each func(ctx context.Context, fn func(*T) error) error) error {
+	return streamNDJSON(c, func(ctx context.Context, emit func(v interface{}) error) error {
+		count, lastID := 0, uint(0)
+		err := each(ctx, func(row *T) error {
+			count, lastID = count+1, id(row)
+			return emit(row)
+		})
+		if err != nil {
+			return err
+		}
+		if next := nextAfterID(page, count, lastID); next != 0 {
+			return emit(map[string]interface{}{"summary": map[string]interface{}{"next_after_id": next}})
+		}
+		return nil
+	})
+}
+
+// logPage is a page of a node log or history report. Its rows are sorted
+// on id last, so each has a distinct position the next page continues from.
+type logPage[T any] struct {
+	orders []logquery.Order
+	limit  int
+	field  func(*T) func(string) interface{}
+	count  int
+	last   T
+}
+
+// parseLogPage reads the limit, after_id and cursor parameters of a node log
+// or history report into filter, after parseReportQuery. after_id continues
+// after the row with that ID, which byID looks up; cursor continues where
+// the previous page's next_cursor points.
+func parseLogPage[T any](c *fiber.Ctx, filter *repositories.LogFilter, byID func(uint) (*T, error), field func(*T) func(string) interface{}) (*logPage[T], error) {
+	page, err := parsePage(c)
+	if err != nil {
+		return nil, err
+	}
+	if page.Limit > 0 && (filter.Limit == 0 || page.Limit < filter.Limit) {
+		filter.Limit = page.Limit
+	}
+
+	orders := make([]logquery.Order, len(filter.Order))
+	for i, o := range filter.Order {
+		orders[i] = logquery.Order{Field: o.Column, Desc: o.Desc}
+	}
+	orders = logquery.WithID(orders)
+	if len(orders) > len(filter.Order) {
+		filter.OrderBy("id", false)
+	}
+
+	cursor := c.Query("cursor")
+	var values []interface{}
+	switch {
+	case cursor != "" && page.AfterID != 0:
+		return nil, errors.New("cursor and after_id cannot be used together")
+	case cursor != "":
+		if values, err = logquery.DecodeCursor(cursor, orders); err != nil {
+			return nil, errors.New("cursor is invalid or was given for another sort")
+		}
+	case page.AfterID != 0:
+		row, err := byID(page.AfterID)
+		if err != nil {
+			return nil, fmt.Errorf("after_id: %d does not exist", page.AfterID)
+		}
+		values = logquery.Values(orders, field(row))
+	}
+	if values != nil {
+		filter.Where = logquery.And(filter.Where, logquery.After(orders, values))
+	}
+
+	return &logPage[T]{orders: orders, limit: filter.Limit, field: field}, nil
+}
+
+// add counts a row of the page
+func (p *logPage[T]) add(row *T) {
+	p.count++
+	p.last = *row
+}
+
+// next returns the cursor of the following page, "" when this page was not
+// full and so the last one
+func (p *logPage[T]) next() string {
+	if p.limit == 0 || p.count < p.limit {
+		return ""
+	}
+	return logquery.EncodeCursor(p.orders, logquery.Values(p.orders, p.field(&p.last)))
+}
+
+// ndjsonType is the content type of responses streamed one JSON document per line
+const ndjsonType = "application/x-ndjson"
+
+// wantsNDJSON reports whether a report is asked for as NDJSON, with
+// format=ndjson or by accepting application/x-ndjson
+func wantsNDJSON(c *fiber.Ctx) bool {
+	return c.Query("format") == "ndjson" || strings.Contains(c.Get(fiber.HeaderAccept), ndjsonType)
+}
+
+// streamTimeout bounds how long a streamed response may take
+const streamTimeout = 10 * time.Minute
+
+// ndjsonFlushEvery is the number of lines after which a stream is flushed,
+// so clients see rows as they are read
+const ndjsonFlushEvery = 500
+
+// streamNDJSON answers with the documents write emits, one per line, sent
+// while write is still reading them. write runs after the handler returned,
+// so it must not use c. The status has been sent by the time it fails, so
+// an error ends the stream with an {"error": ...} line instead.
+func streamNDJSON(c *fiber.Ctx, write func(ctx context.Context, emit func(v interface{}) error) error) error {
+	c.Set(fiber.HeaderContentType, ndjsonType)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(context.Background(), streamTimeout)
+		defer cancel()
+
+		enc := json.NewEncoder(w)
+		lines := 0
+		err := write(ctx, func(v interface{}) error {
+			if err := enc.Encode(v); err != nil {
+				return err
+			}
+			if lines++; lines%ndjsonFlushEvery == 0 {
+				return w.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			log.Println("Streaming error:", err)
+			enc.Encode(fiber.Map{"error": err.Error()})
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+// rowSelector returns a function keeping only fields of an item, all of
+// them when fields is nil, for rows converted one at a time
+func rowSelector[T any](fields []string) func(item T) interface{} {
+	if fields == nil {
+		return func(item T) interface{} { return item }
+	}
+	known := jsonFields(reflect.TypeOf(*new(T)))
+	return func(item T) interface{} {
+		return pickFields(reflect.ValueOf(item), known, fields)
+	}
+}
+
+// pickFields returns the fields of a struct by their JSON names, using the
+// indexes jsonFields found
+func pickFields(v reflect.Value, known map[string]int, fields []string) map[string]interface{} {
+	row := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		row[f] = v.Field(known[f]).Interface()
+	}
+	return row
+}
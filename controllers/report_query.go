@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"uptime/internal/logquery"
+	"uptime/repositories"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseReportQuery reads the filter, sort, tz and, when dated, start-date and
+// end-date parameters of a node log or history report into filter, followed
+// by the legacy flags translated into the same terms. It returns the range
+// asked for, zero where a bound is missing.
+func parseReportQuery(c *fiber.Ctx, filter *repositories.LogFilter, dated bool) (from, to time.Time, err error) {
+	loc, err := logquery.LoadLocation(c.Query("tz"))
+	if err != nil {
+		return from, to, err
+	}
+
+	where, err := logquery.Parse(c.Query("filter"), loc)
+	if err != nil {
+		return from, to, fmt.Errorf("filter: %v", err)
+	}
+	orders, err := logquery.ParseSort(c.Query("sort"))
+	if err != nil {
+		return from, to, fmt.Errorf("sort: %v", err)
+	}
+
+	legacyWhere, legacyOrders, limit := legacyReportQuery(c)
+	if legacyWhere != "" {
+		e, err := logquery.Parse(legacyWhere, loc)
+		if err != nil {
+			return from, to, err
+		}
+		where = logquery.And(where, e)
+	}
+
+	filter.Where = where
+	sorted := make(map[string]bool)
+	for _, o := range append(orders, legacyOrders...) {
+		if !sorted[o.Field] {
+			sorted[o.Field] = true
+			filter.OrderBy(o.Field, o.Desc)
+		}
+	}
+	if limit > 0 {
+		filter.Limit = limit
+	}
+
+	if !dated {
+		return from, to, nil
+	}
+	if s := c.Query("start-date"); s != "" {
+		if from, err = logquery.ParseTime(s, loc, false); err != nil {
+			return from, to, errors.New("Start date format invalid")
+		}
+		filter.From = &from
+	}
+	if s := c.Query("end-date"); s != "" {
+		if to, err = logquery.ParseTime(s, loc, true); err != nil {
+			return from, to, errors.New("End date format invalid")
+		}
+		filter.To = &to
+	}
+	if !from.IsZero() && to.IsZero() {
+		to = time.Now()
+	}
+	if !to.IsZero() && to.Before(from) {
+		return from, to, errors.New("End date is before start date")
+	}
+	return from, to, nil
+}
+
+// legacyOrderFlags are the old ordering flags in the order they were always
+// applied, whatever order the caller gave them in
+var legacyOrderFlags = []struct {
+	flag  string
+	order logquery.Order
+	limit int
+}{
+	{"all-item", logquery.Order{Field: "id", Desc: true}, 0},
+	{"order-asc", logquery.Order{Field: "id"}, 0},
+	{"order-desc", logquery.Order{Field: "id", Desc: true}, 0},
+	{"first-item", logquery.Order{Field: "id"}, 1},
+	{"last-item", logquery.Order{Field: "id", Desc: true}, 1},
+	{"asc-delay", logquery.Order{Field: "delay"}, 0},
+	{"desc-delay", logquery.Order{Field: "delay", Desc: true}, 0},
+	{"asc-status", logquery.Order{Field: "status"}, 0},
+	{"desc-status", logquery.Order{Field: "status", Desc: true}, 0},
+	{"asc-up", logquery.Order{Field: "up"}, 0},
+	{"desc-up", logquery.Order{Field: "up", Desc: true}, 0},
+	{"asc-suspended", logquery.Order{Field: "suspended"}, 0},
+	{"desc-suspended", logquery.Order{Field: "suspended", Desc: true}, 0},
+	{"asc-exception", logquery.Order{Field: "exception"}, 0},
+	{"desc-exception", logquery.Order{Field: "exception", Desc: true}, 0},
+}
+
+// legacyFilterFlags are the old filter flags, set with 1, and their filter
+var legacyFilterFlags = []struct {
+	flag   string
+	filter string
+}{
+	{"up", "up=true"},
+	{"down", "up=false"},
+	{"suspended", "suspended=true"},
+	{"exception", "exception!=null"},
+}
+
+// legacyReportQuery translates the flags the reports took before filter and
+// sort into a filter, orders and a limit
+func legacyReportQuery(c *fiber.Ctx) (string, []logquery.Order, int) {
+	var conditions []string
+	for _, f := range legacyFilterFlags {
+		if c.Query(f.flag) == "1" {
+			conditions = append(conditions, f.filter)
+		}
+	}
+
+	var orders []logquery.Order
+	limit := 0
+	for _, f := range legacyOrderFlags {
+		if c.Query(f.flag) == "" {
+			continue
+		}
+		orders = append(orders, f.order)
+		if f.limit > 0 {
+			limit = f.limit
+		}
+	}
+	return strings.Join(conditions, " and "), orders, limit
+}
+
+// parseFields reads the fields parameter, the JSON names of T's fields to
+// return. It returns nil when every field is returned.
+func parseFields[T any](c *fiber.Ctx) ([]string, error) {
+	fields := logquery.ParseList(c.Query("fields"))
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	known := jsonFields(reflect.TypeOf(*new(T)))
+	for _, f := range fields {
+		if _, ok := known[f]; !ok {
+			return nil, fmt.Errorf("fields: unknown field %q", f)
+		}
+	}
+	return fields, nil
+}
+
+// selectFields keeps only fields of every item, all of them when fields is nil
+func selectFields[T any](items []T, fields []string) interface{} {
+	if fields == nil {
+		return items
+	}
+	known := jsonFields(reflect.TypeOf(*new(T)))
+	out := make([]map[string]interface{}, len(items))
+	for i := range items {
+		out[i] = pickFields(reflect.ValueOf(items[i]), known, fields)
+	}
+	return out
+}
+
+// jsonFields maps the JSON names of a struct's fields to their index
+func jsonFields(t reflect.Type) map[string]int {
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = i
+		}
+	}
+	return fields
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"uptime/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetSLAReport returns uptime, downtime, incident and latency figures
+// @Summary Get SLA report
+// @Description Time-weighted uptime percentage, downtime, incident count, MTTR, MTBF and latency percentiles of a node or group, defaulting to the last 30 days. Maintenance time is excluded from the uptime percentage.
+// @Tags reports
+// @Produce json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param Authorization header string true "API Key"
+// @Param node_id query int false "Node ID"
+// @Param url query string false "Node URL"
+// @Param group_id query int false "Group ID, the report also lists every node of the group and its subgroups"
+// @Param start-date query string false "Range start (YYYY-MM-DD or RFC 3339)"
+// @Param end-date query string false "Range end (YYYY-MM-DD or RFC 3339)"
+// @Param format query string false "csv or xlsx to download the report, xlsx with a summary sheet. Also taken from the Accept header" Enums(csv, xlsx)
+// @Param tz query string false "Time zone of exported times, e.g. Asia/Tehran or +03:30 (default UTC)"
+// @Param calendar query string false "Calendar of exported dates (default gregorian)" Enums(gregorian, jalali)
+// @Success 200 {object} ReportResponse{data=services.SLAReport} "Report data"
+// @Failure 401 {object} ReportResponse "Unauthorized"
+// @Failure 404 {object} ReportResponse "Node or group not found"
+// @Failure 422 {object} ReportResponse "Invalid parameters"
+// @Failure 500 {object} ReportResponse "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /report/sla [get]
+func GetSLAReport(c *fiber.Ctx) error {
+	key := c.Get("Authorization")
+	apiKey := os.Getenv("UPTIME_API_KEY")
+	if key != apiKey {
+		return c.Status(401).JSON(ReportResponse{
+			Code:    401,
+			Msg:     "Token expired",
+			Success: false,
+			Data:    nil,
+		})
+	}
+
+	query := services.SLAQuery{
+		URL: c.Query("url"),
+		To:  time.Now(),
+	}
+	query.From = query.To.AddDate(0, 0, -30)
+
+	if nodeIDStr := c.Query("node_id"); nodeIDStr != "" {
+		nodeID, err := strconv.Atoi(nodeIDStr)
+		if err != nil || nodeID <= 0 {
+			return c.Status(422).JSON(ReportResponse{
+				Code:    422,
+				Msg:     "node_id format invalid",
+				Success: false,
+				Data:    nil,
+			})
+		}
+		query.NodeID = uint(nodeID)
+	}
+	if groupIDStr := c.Query("group_id"); groupIDStr != "" {
+		groupID, err := strconv.Atoi(groupIDStr)
+		if err != nil || groupID <= 0 {
+			return c.Status(422).JSON(ReportResponse{
+				Code:    422,
+				Msg:     "group_id format invalid",
+				Success: false,
+				Data:    nil,
+			})
+		}
+		query.GroupID = uint(groupID)
+	}
+	if startStr := c.Query("start-date"); startStr != "" {
+		start, err := parseTimeParam(startStr, false)
+		if err != nil {
+			return c.Status(422).JSON(ReportResponse{
+				Code:    422,
+				Msg:     "Start date format invalid",
+				Success: false,
+				Data:    nil,
+			})
+		}
+		query.From = *start
+	}
+	if endStr := c.Query("end-date"); endStr != "" {
+		end, err := parseTimeParam(endStr, true)
+		if err != nil {
+			return c.Status(422).JSON(ReportResponse{
+				Code:    422,
+				Msg:     "End date format invalid",
+				Success: false,
+				Data:    nil,
+			})
+		}
+		query.To = *end
+	}
+
+	format, dates, err := parseExport(c)
+	if err != nil {
+		return c.Status(422).JSON(ReportResponse{
+			Code:    422,
+			Msg:     err.Error(),
+			Success: false,
+			Data:    nil,
+		})
+	}
+
+	report, err := services.GetSLAReport(query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(422).JSON(ReportResponse{
+				Code:    422,
+				Msg:     err.Error(),
+				Success: false,
+				Data:    nil,
+			})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(ReportResponse{
+				Code:    404,
+				Msg:     err.Error(),
+				Success: false,
+				Data:    nil,
+			})
+		}
+		log.Println("SLA report error:", err)
+		return c.Status(500).JSON(ReportResponse{
+			Code:    500,
+			Msg:     "Database error",
+			Success: false,
+			Data:    nil,
+		})
+	}
+
+	if format != "" {
+		return sendSLAExport(c, format, dates, query, report)
+	}
+	return c.JSON(ReportResponse{
+		Code:    200,
+		Msg:     "sla report",
+		Success: true,
+		Data:    report,
+	})
+}
@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"uptime/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateTag creates a new tag
+// @Summary Create a tag
+// @Description Add a tag that nodes can be labelled with. Names are stored in lower case.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag body services.TagInput true "Tag"
+// @Success 201 {object} models.Tag "Tag created successfully"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 409 {object} map[string]string "Name already exists"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /tags [post]
+func CreateTag(c *fiber.Ctx) error {
+	var body services.TagInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	tag, err := services.CreateTag(body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "Duplicate") {
+			return c.Status(409).JSON(fiber.Map{"error": "Tag name already exists"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create tag"})
+	}
+	return c.Status(201).JSON(tag)
+}
+
+// GetAllTags lists tags
+// @Summary Get tags
+// @Description List all tags ordered by name
+// @Tags tags
+// @Produce json
+// @Success 200 {array} models.Tag "List of tags"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /tags [get]
+func GetAllTags(c *fiber.Ctx) error {
+	tags, err := services.GetAllTags()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(tags)
+}
+
+func GetTag(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	tag, err := services.GetTag(uint(id))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Tag not found"})
+	}
+	return c.JSON(tag)
+}
+
+func UpdateTag(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	var body services.TagInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	tag, err := services.UpdateTag(uint(id), body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Tag not found"})
+		}
+		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "Duplicate") {
+			return c.Status(409).JSON(fiber.Map{"error": "Tag name already exists"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update tag"})
+	}
+	return c.JSON(tag)
+}
+
+func DeleteTag(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	err = services.DeleteTagByID(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Tag not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete tag"})
+	}
+	return c.SendStatus(204)
+}
+
+// GetNodeTags lists the tags of a node
+// @Summary Get node tags
+// @Description List the tags of a node ordered by name
+// @Tags tags
+// @Produce json
+// @Param id path int true "Node ID"
+// @Success 200 {array} models.Tag "Tags of the node"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Node not found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /nodes/{id}/tags [get]
+func GetNodeTags(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	tags, err := services.GetNodeTags(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Node not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(tags)
+}
+
+// BulkAssignNodes moves nodes to a group and adds or removes their tags
+// @Summary Assign nodes in bulk
+// @Description Select nodes by ID or URL, then move them to a group (or out of their group with clear_group) and add or remove tags. Added tags that do not exist yet are created.
+// @Tags nodes
+// @Accept json
+// @Produce json
+// @Param assignment body services.BulkAssignInput true "Nodes and changes"
+// @Success 200 {object} services.BulkAssignResult "Number of nodes changed"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /nodes/bulk-assign [post]
+func BulkAssignNodes(c *fiber.Ctx) error {
+	var body services.BulkAssignInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	result, err := services.BulkAssign(body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to assign nodes"})
+	}
+	return c.JSON(result)
+}
@@ -1,16 +1,17 @@
 package controllers
 
 import (
-	"uptime/database"
-	"uptime/models"
+	"errors"
+	"uptime/internal/scheduler"
 	"uptime/monitoring"
+	"uptime/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 func CheckUptime(c *fiber.Ctx) error {
-	var nodes []models.Node
-	if err := database.DB.Find(&nodes).Error; err != nil {
+	nodes, err := services.GetActiveNodes()
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -18,7 +19,45 @@ func CheckUptime(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{"message": "No nodes found"})
 	}
 
-	monitoring.Check(nodes)
+	if err := services.CheckNodes(nodes); err != nil {
+		if errors.Is(err, monitoring.ErrCycleRunning) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	return c.JSON(fiber.Map{"message": "Uptime check completed"})
 }
+
+// GetCycleProgress shows the progress of the running check cycles
+// @Summary Get check cycle progress
+// @Description Progress of the scheduler's current CHECK_INTERVAL window and of any manual full cycle: pending, in flight, completed and skipped checks, average latency and ETA
+// @Tags cycles
+// @Produce json
+// @Success 200 {array} monitoring.Progress "Running cycles"
+// @Security ApiKeyAuth
+// @Router /cycles/current [get]
+func GetCycleProgress(c *fiber.Ctx) error {
+	progress := monitoring.ActiveCycles()
+	if p, ok := scheduler.CurrentProgress(); ok {
+		progress = append([]monitoring.Progress{p}, progress...)
+	}
+	return c.JSON(progress)
+}
+
+// GetWriterStats shows the state of the check result writer
+// @Summary Get check result writer stats
+// @Description Queue depth of the check results waiting to be written, and the size and latency of the batch writes
+// @Tags cycles
+// @Produce json
+// @Success 200 {object} monitoring.WriterStats "Writer stats"
+// @Failure 404 {object} map[string]string "Writer not running"
+// @Security ApiKeyAuth
+// @Router /cycles/writer [get]
+func GetWriterStats(c *fiber.Ctx) error {
+	stats, ok := monitoring.CurrentWriterStats()
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{"error": "Writer not running"})
+	}
+	return c.JSON(stats)
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"uptime/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateWebhook creates a new webhook target
+// @Summary Create a webhook
+// @Description Add a webhook notified on down, up and suspended transitions. Payloads are signed with the secret (X-Uptime-Signature: sha256=<hmac>).
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body services.WebhookInput true "Webhook"
+// @Success 201 {object} models.Webhook "Webhook created successfully"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /webhooks [post]
+func CreateWebhook(c *fiber.Ctx) error {
+	var body services.WebhookInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	webhook, err := services.CreateWebhook(body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create webhook"})
+	}
+	return c.Status(201).JSON(webhook)
+}
+
+// GetAllWebhooks lists webhook targets
+// @Summary Get webhooks
+// @Description List all webhook targets. Secrets are never returned.
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} models.Webhook "List of webhooks"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /webhooks [get]
+func GetAllWebhooks(c *fiber.Ctx) error {
+	webhooks, err := services.GetAllWebhooks()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(webhooks)
+}
+
+func GetWebhook(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	webhook, err := services.GetWebhook(uint(id))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Webhook not found"})
+	}
+	return c.JSON(webhook)
+}
+
+func UpdateWebhook(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	var body services.WebhookInput
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON format"})
+	}
+
+	webhook, err := services.UpdateWebhook(uint(id), body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Webhook not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update webhook"})
+	}
+	return c.JSON(webhook)
+}
+
+func DeleteWebhook(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	err = services.DeleteWebhookByID(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Webhook not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete webhook"})
+	}
+	return c.SendStatus(204)
+}
+
+// TestWebhook sends a test event
+// @Summary Test a webhook
+// @Description Send a single signed test event to the webhook and return the recorded delivery
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} models.NotificationDelivery "Delivery result"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Security ApiKeyAuth
+// @Router /webhooks/{id}/test [post]
+func TestWebhook(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	if idStr == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "ID parameter is required"})
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid ID format"})
+	}
+
+	delivery, err := services.TestWebhook(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(404).JSON(fiber.Map{"error": "Webhook not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(delivery)
+}
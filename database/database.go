@@ -2,27 +2,91 @@ package database
 
 import (
 	"fmt"
+	"strings"
 	"uptime/config"
+	"uptime/internal/migrate"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
 
-func Connect() {
+// Supported DB_DRIVER values
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+// Connect opens the database selected by DB_DRIVER
+func Connect() error {
 	dsn := config.AppConfig.Database.DSN
 
+	var dialector gorm.Dialector
+	switch Driver() {
+	case MySQL:
+		dialector = mysql.Open(dsn)
+	case Postgres:
+		dialector = postgres.Open(dsn)
+	case SQLite:
+		dialector = sqlite.Open(sqliteDSN(dsn))
+	default:
+		return fmt.Errorf("unsupported DB_DRIVER %q, expected mysql, postgres or sqlite", Driver())
+	}
+
 	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
+	DB, err = gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
-		panic(fmt.Sprintf("failed to connect database: %v", err))
+		return fmt.Errorf("failed to connect database: %w", err)
 	}
 
-	fmt.Println("✅ Connected to MySQL!")
+	if Driver() == SQLite {
+		// SQLite allows one writer at a time; a single connection queues
+		// the checker's writes instead of failing them with SQLITE_BUSY
+		sqlDB, err := DB.DB()
+		if err != nil {
+			return err
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
+
+	fmt.Printf("✅ Connected to %s!\n", Driver())
 
 	fmt.Println("✅ Database ready!")
+	return nil
+}
+
+// Driver returns the configured DB_DRIVER
+func Driver() string {
+	return config.AppConfig.Database.Driver
+}
+
+// sqliteDSN adds the pragmas the server relies on to a SQLite file path
+func sqliteDSN(path string) string {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+}
+
+// Migrate applies the pending schema migrations embedded in the binary.
+// Concurrent runners wait for each other, see internal/migrate.
+func Migrate() error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrate.Up(sqlDB, Driver(), 0)
+	for _, m := range applied {
+		fmt.Printf("✅ Applied migration %04d_%s\n", m.Version, m.Name)
+	}
+	return err
 }
@@ -24,56 +24,243 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
+        "/chat-channels": {
             "get": {
-                "description": "Check the health status of the API and database connection",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List all chat channels. Bot tokens and webhook URLs are never returned.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "chat-channels"
                 ],
-                "summary": "Health check",
+                "summary": "Get chat channels",
                 "responses": {
                     "200": {
-                        "description": "Service is healthy",
+                        "description": "List of chat channels",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ChatChannel"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a Telegram bot or Slack incoming webhook channel for the selected nodes and groups. Events arriving within CHAT_BATCH_WINDOW are grouped into one message.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chat-channels"
+                ],
+                "summary": "Create a chat channel",
+                "parameters": [
+                    {
+                        "description": "Chat channel",
+                        "name": "channel",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.ChatChannelInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Chat channel created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChatChannel"
                         }
                     },
-                    "503": {
-                        "description": "Service is unhealthy",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/nodes": {
+        "/chat-channels/{id}/test": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Send a single test message through the channel, bypassing the batch window, and return the recorded delivery",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chat-channels"
+                ],
+                "summary": "Test a chat channel",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Chat channel ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Delivery result",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/cycles/current": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve a list of all monitored websites/services",
+                "description": "Progress of the scheduler's current CHECK_INTERVAL window and of any manual full cycle: pending, in flight, completed and skipped checks, average latency and ETA",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "nodes"
+                    "cycles"
                 ],
-                "summary": "Get all nodes",
+                "summary": "Get check cycle progress",
                 "responses": {
                     "200": {
-                        "description": "List of nodes",
+                        "description": "Running cycles",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Node"
+                                "$ref": "#/definitions/monitoring.Progress"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/cycles/writer": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Queue depth of the check results waiting to be written, and the size and latency of the batch writes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cycles"
+                ],
+                "summary": "Get check result writer stats",
+                "responses": {
+                    "200": {
+                        "description": "Writer stats",
+                        "schema": {
+                            "$ref": "#/definitions/monitoring.WriterStats"
+                        }
+                    },
+                    "404": {
+                        "description": "Writer not running",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/email-channels": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List all email channels. Passwords are never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email-channels"
+                ],
+                "summary": "Get email channels",
+                "responses": {
+                    "200": {
+                        "description": "List of email channels",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.EmailChannel"
                             }
                         }
                     },
@@ -94,7 +281,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Add a new website/service to monitor",
+                "description": "Add an SMTP channel notified on down, up and suspended transitions of the selected nodes and groups. Messages are rendered from the subject, text and html templates.",
                 "consumes": [
                     "application/json"
                 ],
@@ -102,31 +289,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "nodes"
+                    "email-channels"
                 ],
-                "summary": "Create a new node",
+                "summary": "Create an email channel",
                 "parameters": [
                     {
-                        "description": "Node URL",
-                        "name": "node",
+                        "description": "Email channel",
+                        "name": "channel",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "url": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/services.EmailChannelInput"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Node created successfully",
+                        "description": "Email channel created successfully",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.EmailChannel"
                         }
                     },
                     "400": {
@@ -150,180 +331,3765 @@ const docTemplate = `{
                 }
             }
         },
-        "/report/get": {
-            "get": {
+        "/email-channels/{id}/test": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get detailed monitoring report for a specific website/service",
+                "description": "Send a single test message through the channel and return the recorded delivery",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "reports"
+                    "email-channels"
                 ],
-                "summary": "Get node report",
+                "summary": "Test an email channel",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "API Key",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Node ID",
+                        "type": "integer",
+                        "description": "Email channel ID",
                         "name": "id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Node URL",
-                        "name": "url",
-                        "in": "query"
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Report data",
+                        "description": "Delivery result",
                         "schema": {
-                            "$ref": "#/definitions/controllers.ReportResponse"
+                            "$ref": "#/definitions/models.NotificationDelivery"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/controllers.ReportResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "404": {
-                        "description": "Node not found",
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/controllers.ReportResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/controllers.ReportResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.ReportResponse": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer"
-                },
-                "data": {},
-                "msg": {
-                    "type": "string"
-                },
-                "success": {
-                    "type": "boolean"
-                }
-            }
         },
-        "models.History": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "delay": {
-                    "type": "number"
-                },
-                "exception": {
+        "/groups": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List all node groups ordered by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get groups",
+                "responses": {
+                    "200": {
+                        "description": "List of groups",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Group"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a group that nodes and notification channels can refer to",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Create a group",
+                "parameters": [
+                    {
+                        "description": "Group",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.GroupInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Group created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Name already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/summary": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Per group, subgroups included: the number of nodes, how many are up, down, suspended or not checked yet, and their aggregate uptime percentage over the range, defaulting to the last 24 hours",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get group summary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only this group and its subgroups",
+                        "name": "group_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start (YYYY-MM-DD or RFC 3339)",
+                        "name": "start-date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (YYYY-MM-DD or RFC 3339)",
+                        "name": "end-date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Group summaries",
+                        "schema": {
+                            "$ref": "#/definitions/services.GroupSummaryReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Check the health status of the API and database connection",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "Service is healthy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "Service is unhealthy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List incidents filtered by node, group, tag, status, acknowledgement and time range",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Get incidents",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node ID",
+                        "name": "node_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Group ID, subgroups included",
+                        "name": "group_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag name",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "open or resolved",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "1 or 0",
+                        "name": "acknowledged",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start (YYYY-MM-DD or RFC 3339)",
+                        "name": "start-date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (YYYY-MM-DD or RFC 3339)",
+                        "name": "end-date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 10000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Continue after the incident with this ID, from the X-Next-After-Id header",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "csv or xlsx to download the report, xlsx with a summary sheet. Also taken from the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time zone of exported times, e.g. Asia/Tehran or +03:30 (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "gregorian",
+                            "jalali"
+                        ],
+                        "type": "string",
+                        "description": "Calendar of exported dates (default gregorian)",
+                        "name": "calendar",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of incidents",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Incident"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get an incident with its notes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Get incident",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Incident ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Incident",
+                        "schema": {
+                            "$ref": "#/definitions/models.Incident"
+                        }
+                    },
+                    "404": {
+                        "description": "Incident not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/{id}/acknowledge": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Mark an incident as being handled, optionally adding a note",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Acknowledge incident",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Incident ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Acknowledgement",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "acknowledged_by": {
+                                    "type": "string"
+                                },
+                                "note": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Acknowledged incident",
+                        "schema": {
+                            "$ref": "#/definitions/models.Incident"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Incident not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/{id}/notes": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a free-text note to an incident",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Add incident note",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Incident ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Note",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "author": {
+                                    "type": "string"
+                                },
+                                "body": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created note",
+                        "schema": {
+                            "$ref": "#/definitions/models.IncidentNote"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Incident not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/maintenance-windows": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List all maintenance windows",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Get maintenance windows",
+                "responses": {
+                    "200": {
+                        "description": "List of maintenance windows",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MaintenanceWindow"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a one-off (starts_at/ends_at), cron or weekly window for the selected nodes and groups. Checks keep running but raise no incidents or notifications.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Create a maintenance window",
+                "parameters": [
+                    {
+                        "description": "Maintenance window",
+                        "name": "window",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.MaintenanceWindowInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Maintenance window created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceWindow"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/maintenance-windows/{id}/occurrences": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the intervals the window is active in a range of at most 366 days, defaulting to the next 7 days",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Get maintenance window occurrences",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maintenance window ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start (YYYY-MM-DD or RFC 3339)",
+                        "name": "start-date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (YYYY-MM-DD or RFC 3339)",
+                        "name": "end-date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Active intervals",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/maintenance.Interval"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/node-logs/{id}/attempts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List every attempt of a check that was retried before its result was logged",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "node-logs"
+                ],
+                "summary": "Get check attempts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node log ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of attempts",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CheckAttempt"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Node log not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/nodes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve a list of all monitored websites/services with their tags, optionally limited to a group (subgroups included), a tag or a lifecycle state. Archived nodes are only listed with lifecycle=archived.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Get all nodes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID, subgroups included",
+                        "name": "group_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag name",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "paused",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "description": "Lifecycle state",
+                        "name": "lifecycle",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 10000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Continue after the node with this ID, from the X-Next-After-Id header",
+                        "name": "after_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of nodes",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Node"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a new website/service to monitor with an optional check definition (method, headers, body, expected status codes, timeout)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Create a new node",
+                "parameters": [
+                    {
+                        "description": "Node URL and check configuration",
+                        "name": "node",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.NodeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Node created successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "URL already exists or belongs to an archived node",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/nodes/bulk-assign": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Select nodes by ID or URL, then move them to a group (or out of their group with clear_group) and add or remove tags. Added tags that do not exist yet are created.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Assign nodes in bulk",
+                "parameters": [
+                    {
+                        "description": "Nodes and changes",
+                        "name": "assignment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.BulkAssignInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of nodes changed",
+                        "schema": {
+                            "$ref": "#/definitions/services.BulkAssignResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/nodes/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stop checking a node and hide it from the node listings. Its logs, histories, incidents and tags are kept, its reports keep working and it can be restored.",
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Archive a node",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Node archived"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Node not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/nodes/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stop checking a node, until a time, for a duration or until it is resumed. Paused nodes are resumed by the scheduler within SCHEDULER_RELOAD_INTERVAL of their until time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Pause a node",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "until (RFC 3339) or duration (e.g. 2h), neither pauses indefinitely",
+                        "name": "pause",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/services.PauseInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Paused node",
+                        "schema": {
+                            "$ref": "#/definitions/models.Node"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Node not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/nodes/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Restore an archived node as active, with its logs, histories, incidents and tags",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Restore a node",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Restored node",
+                        "schema": {
+                            "$ref": "#/definitions/models.Node"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Node not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/nodes/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Check a paused node again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nodes"
+                ],
+                "summary": "Resume a node",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resumed node",
+                        "schema": {
+                            "$ref": "#/definitions/models.Node"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Node not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/nodes/{id}/tags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the tags of a node ordered by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get node tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tags of the node",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tag"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Node not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List notification deliveries, newest first, filtered by channel, target, node and status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get notification deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Channel, e.g. webhook",
+                        "name": "channel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Target ID within the channel",
+                        "name": "target_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Node ID",
+                        "name": "node_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "pending, delivered or failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum rows (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Continue after the delivery with this ID, from the X-Next-After-Id header",
+                        "name": "after_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of deliveries",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.NotificationDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/report/all-from-history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The latest check result of every node, filtered and sorted like /report/get",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get all histories",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API Key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Group ID, subgroups included",
+                        "name": "group_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag name",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter, e.g. status\u003e=500 and (delay\u003e2 or exception~timeout)",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort keys, - for descending, e.g. -delay,status",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fields of each report to return, e.g. id,delay,created_at",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time zone of times in filter without an offset (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 10000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Continue after the row with this ID in the report's sort",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Continue where the previous page ended, from next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "ndjson to stream one row per line, ending with a summary line",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report data",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/report/certificates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the latest TLS certificate of every https node ordered by days to expiry",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get certificate expiry report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API Key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only include certificates expiring within this many days",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to 1 to only include certificates inside CERT_EXPIRY_WARNING_DAYS",
+                        "name": "expiring",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Group ID, subgroups included",
+                        "name": "group_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag name",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 10000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Continue after the certificate of this node, from next_after_id",
+                        "name": "after_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report data",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/report/get": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the check logs of a website/service, filtered and sorted. The legacy flags (up, down, suspended, exception, all-item, order-asc, order-desc, first-item, last-item, asc-* and desc-*) are still accepted and applied after filter and sort.",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get node report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API Key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Node URL",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter, e.g. status\u003e=500 and (delay\u003e2 or exception~timeout)",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort keys, - for descending, e.g. -delay,status",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fields of each report to return, e.g. id,delay,created_at",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start (YYYY-MM-DD, YYYY-MM-DDTHH:MM:SS or RFC 3339)",
+                        "name": "start-date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (YYYY-MM-DD, YYYY-MM-DDTHH:MM:SS or RFC 3339)",
+                        "name": "end-date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time zone of dates and times without an offset and of exported times, e.g. Asia/Tehran or +03:30 (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 10000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Continue after the row with this ID in the report's sort",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Continue where the previous page ended, from next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ndjson",
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "ndjson to stream one row per line, ending with a summary line; csv or xlsx to download the rows, xlsx with a summary sheet. Also taken from the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "gregorian",
+                            "jalali"
+                        ],
+                        "type": "string",
+                        "description": "Calendar of exported dates (default gregorian)",
+                        "name": "calendar",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report data",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Node not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/report/get-smart-query": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Like /report/get, with the number of logs, down and maintenance checks and the average request phases",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get node smart report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API Key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Node URL",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter, e.g. status\u003e=500 and (delay\u003e2 or exception~timeout)",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort keys, - for descending, e.g. -delay,status",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fields of each report to return, e.g. id,delay,created_at",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start (YYYY-MM-DD, YYYY-MM-DDTHH:MM:SS or RFC 3339)",
+                        "name": "start-date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (YYYY-MM-DD, YYYY-MM-DDTHH:MM:SS or RFC 3339)",
+                        "name": "end-date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time zone of dates and times without an offset and of exported times, e.g. Asia/Tehran or +03:30 (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 10000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Continue after the row with this ID in the report's sort",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Continue where the previous page ended, from next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ndjson",
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "ndjson to stream one row per line, ending with a summary line; csv or xlsx to download the rows, xlsx with a summary sheet. Also taken from the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "gregorian",
+                            "jalali"
+                        ],
+                        "type": "string",
+                        "description": "Calendar of exported dates (default gregorian)",
+                        "name": "calendar",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report data",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Node not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/report/sla": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Time-weighted uptime percentage, downtime, incident count, MTTR, MTBF and latency percentiles of a node or group, defaulting to the last 30 days. Maintenance time is excluded from the uptime percentage.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get SLA report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API Key",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Node ID",
+                        "name": "node_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Node URL",
+                        "name": "url",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Group ID, the report also lists every node of the group and its subgroups",
+                        "name": "group_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range start (YYYY-MM-DD or RFC 3339)",
+                        "name": "start-date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (YYYY-MM-DD or RFC 3339)",
+                        "name": "end-date",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "csv or xlsx to download the report, xlsx with a summary sheet. Also taken from the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time zone of exported times, e.g. Asia/Tehran or +03:30 (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "gregorian",
+                            "jalali"
+                        ],
+                        "type": "string",
+                        "description": "Calendar of exported dates (default gregorian)",
+                        "name": "calendar",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report data",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/controllers.ReportResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.SLAReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Node or group not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List all assertion rules, the rules of one node (node_id) or the global default set (default=1)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rules"
+                ],
+                "summary": "Get assertion rules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Node ID",
+                        "name": "node_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to 1 to list only the global default rules",
+                        "name": "default",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of rules",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AssertionRule"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a content assertion (contains, not_contains, regex, json_path, header). Rules without node_id apply to every node.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rules"
+                ],
+                "summary": "Create an assertion rule",
+                "parameters": [
+                    {
+                        "description": "Assertion rule",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.AssertionRuleInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Rule created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.AssertionRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List all tags ordered by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get tags",
+                "responses": {
+                    "200": {
+                        "description": "List of tags",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tag"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a tag that nodes can be labelled with. Names are stored in lower case.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Create a tag",
+                "parameters": [
+                    {
+                        "description": "Tag",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.TagInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Tag created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Name already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List all webhook targets. Secrets are never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhooks",
+                "responses": {
+                    "200": {
+                        "description": "List of webhooks",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a webhook notified on down, up and suspended transitions. Payloads are signed with the secret (X-Uptime-Signature: sha256=\u003chmac\u003e).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.WebhookInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Webhook created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/test": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Send a single signed test event to the webhook and return the recorded delivery",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Test a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Delivery result",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.ReportResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {},
+                "msg": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "maintenance.Interval": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AssertionRule": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ignore_case": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "integer"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "target": {
+                    "description": "header name or JSON path",
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChatChannel": {
+            "type": "object",
+            "properties": {
+                "api_base_url": {
+                    "description": "telegram: overrides TELEGRAM_API_URL",
+                    "type": "string"
+                },
+                "chat_id": {
+                    "description": "telegram chat, user or @channel",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "description": "comma separated new states, empty means all",
+                    "type": "string"
+                },
+                "group_ids": {
+                    "description": "both empty means every node",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "telegram or slack",
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "node_ids": {
+                    "description": "nodes covered, together with GroupIDs",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CheckAttempt": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delay": {
+                    "type": "number"
+                },
+                "exception": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "node_id": {
+                    "type": "integer"
+                },
+                "node_log_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "up": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.EmailChannel": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "description": "comma separated new states, empty means all",
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "group_ids": {
+                    "description": "both empty means every node",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "host": {
+                    "type": "string"
+                },
+                "html_template": {
+                    "description": "html/template, empty uses the default",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "node_ids": {
+                    "description": "nodes covered, together with GroupIDs",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "port": {
+                    "type": "integer"
+                },
+                "skip_verify": {
+                    "description": "accept self-signed server certificates",
+                    "type": "boolean"
+                },
+                "subject_template": {
+                    "type": "string"
+                },
+                "text_template": {
+                    "description": "text/template, empty uses the default",
+                    "type": "string"
+                },
+                "tls_mode": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Group": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "description": "nil for a top level group",
+                    "type": "integer"
+                },
+                "retention": {
+                    "$ref": "#/definitions/models.GroupRetention"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GroupRetention": {
+            "type": "object",
+            "properties": {
+                "daily_rollups": {
+                    "type": "integer"
+                },
+                "hourly_rollups": {
+                    "type": "integer"
+                },
+                "incidents": {
+                    "type": "integer"
+                },
+                "notification_deliveries": {
+                    "type": "integer"
+                },
+                "raw_logs": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Headers": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "models.History": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "degraded": {
+                    "description": "up, but e.g. the certificate is about to expire",
+                    "type": "boolean"
+                },
+                "delay": {
+                    "type": "number"
+                },
+                "exception": {
+                    "type": "string"
+                },
+                "failure_streak": {
+                    "description": "consecutive failing cycles",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "maintenance": {
+                    "description": "checked during a maintenance window",
+                    "type": "boolean"
+                },
+                "node_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "suspended": {
+                    "type": "boolean"
+                },
+                "up": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Incident": {
+            "type": "object",
+            "properties": {
+                "acknowledged": {
+                    "type": "boolean"
+                },
+                "acknowledged_at": {
+                    "type": "string"
+                },
+                "acknowledged_by": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "seconds, set once resolved",
+                    "type": "number"
+                },
+                "first_error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "node_id": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.IncidentNote"
+                    }
+                },
+                "resolve_log_id": {
+                    "type": "integer"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "description": "down or suspended",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "trigger_log_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.IncidentNote": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "incident_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MaintenanceWindow": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "cron": {
+                    "description": "cron: standard 5 field expression",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "cron and weekly: minutes",
+                    "type": "integer"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "ends_at": {
+                    "description": "once",
+                    "type": "string"
+                },
+                "group_ids": {
+                    "description": "both empty means every node",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "node_ids": {
+                    "description": "nodes covered, together with GroupIDs",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "start_time": {
+                    "description": "weekly: HH:MM",
+                    "type": "string"
+                },
+                "starts_at": {
+                    "description": "once",
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "IANA name the schedule is read in",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "weekdays": {
+                    "description": "weekly: comma separated, e.g. \"sat,sun\"",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Node": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "set while the node is archived",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "expect": {
+                    "description": "tcp: banner substring, dns: comma separated record values",
+                    "type": "string"
+                },
+                "expected_status": {
+                    "description": "e.g. \"200-299,301,401\", empty means 2xx",
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "headers": {
+                    "$ref": "#/definitions/models.Headers"
+                },
+                "histories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.History"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "description": "seconds between checks, 0 falls back to CHECK_INTERVAL",
+                    "type": "integer"
+                },
+                "lifecycle": {
+                    "description": "active, paused or archived",
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "node_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NodeLog"
+                    }
+                },
+                "paused_until": {
+                    "description": "when a paused node resumes, nil waits for a resume call",
+                    "type": "string"
+                },
+                "record_type": {
+                    "description": "dns: A, AAAA, CNAME, MX, NS or TXT",
+                    "type": "string"
+                },
+                "resolver": {
+                    "description": "dns: host[:port], empty uses the system resolver",
+                    "type": "string"
+                },
+                "skip_default_rules": {
+                    "type": "boolean"
+                },
+                "tags": {
+                    "description": "stored in node_tags, filled in by the node listing",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Tag"
+                    }
+                },
+                "timeout": {
+                    "description": "seconds, 0 falls back to REQUEST_TIMEOUT",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "http(s) URL, tcp://host:port or dns://name",
+                    "type": "string"
+                }
+            }
+        },
+        "models.NodeLog": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "connect_time": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "cycle_id": {
+                    "description": "the check cycle that produced the log",
+                    "type": "string"
+                },
+                "degraded": {
+                    "description": "up, but e.g. the certificate is about to expire",
+                    "type": "boolean"
+                },
+                "delay": {
+                    "type": "number"
+                },
+                "dns_time": {
+                    "description": "Request phase durations in seconds, nil when the phase did not happen",
+                    "type": "number"
+                },
+                "exception": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "maintenance": {
+                    "description": "checked during a maintenance window",
+                    "type": "boolean"
+                },
+                "node_id": {
+                    "description": "کلید خارجی",
+                    "type": "integer"
+                },
+                "response_size": {
+                    "description": "bytes",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "suspended": {
+                    "type": "boolean"
+                },
+                "tls_time": {
+                    "type": "number"
+                },
+                "transfer_time": {
+                    "type": "number"
+                },
+                "ttfb": {
+                    "type": "number"
+                },
+                "unconfirmed": {
+                    "description": "the check failed but the node has not failed CHECK_CONFIRM_CYCLES times in a row yet",
+                    "type": "boolean"
+                },
+                "up": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.NotificationDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "channel": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "target_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Tag": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "description": "comma separated new states, empty means all",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_attempts": {
+                    "description": "deliveries are retried with exponential backoff",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "monitoring.Progress": {
+            "type": "object",
+            "properties": {
+                "avg_duration": {
+                    "description": "mean wall time per check in seconds, retries included",
+                    "type": "number"
+                },
+                "avg_latency": {
+                    "description": "mean check delay in seconds",
+                    "type": "number"
+                },
+                "completed": {
+                    "type": "integer"
+                },
+                "cycle_id": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "eta": {
+                    "type": "string"
+                },
+                "in_flight": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "monitoring.WriterStats": {
+            "type": "object",
+            "properties": {
+                "avg_flush_latency": {
+                    "description": "seconds",
+                    "type": "number"
+                },
+                "batch_size": {
+                    "type": "integer"
+                },
+                "blocked": {
+                    "description": "checks that waited for room in the queue",
+                    "type": "integer"
+                },
+                "failed": {
+                    "description": "check results whose log could not be written",
+                    "type": "integer"
+                },
+                "flushes": {
+                    "type": "integer"
+                },
+                "last_flush_at": {
+                    "type": "string"
+                },
+                "last_flush_latency": {
+                    "description": "seconds",
+                    "type": "number"
+                },
+                "last_flush_size": {
+                    "type": "integer"
+                },
+                "max_flush_latency": {
+                    "description": "seconds",
+                    "type": "number"
+                },
+                "queue_depth": {
+                    "description": "results waiting to be written",
+                    "type": "integer"
+                },
+                "queue_size": {
+                    "type": "integer"
+                },
+                "written": {
+                    "description": "check results written",
+                    "type": "integer"
+                }
+            }
+        },
+        "services.AssertionRuleInput": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "ignore_case": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "node_id": {
+                    "type": "integer"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.BulkAssignInput": {
+            "type": "object",
+            "properties": {
+                "add_tags": {
+                    "description": "missing tags are created",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "clear_group": {
+                    "description": "takes the nodes out of their group",
+                    "type": "boolean"
+                },
+                "group_id": {
+                    "description": "moves the nodes to this group",
+                    "type": "integer"
+                },
+                "node_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "remove_tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "services.BulkAssignResult": {
+            "type": "object",
+            "properties": {
+                "nodes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.ChatChannelInput": {
+            "type": "object",
+            "properties": {
+                "api_base_url": {
+                    "type": "string"
+                },
+                "bot_token": {
+                    "description": "telegram, omitted on update keeps the current token",
+                    "type": "string"
+                },
+                "chat_id": {
+                    "description": "telegram",
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "description": "down, up, suspended; empty means all",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "group_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "kind": {
+                    "description": "telegram or slack",
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "node_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "webhook_url": {
+                    "description": "slack, omitted on update keeps the current URL",
                     "type": "string"
+                }
+            }
+        },
+        "services.EmailChannelInput": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
                 },
-                "id": {
+                "events": {
+                    "description": "down, up, suspended; empty means all",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "group_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "host": {
+                    "type": "string"
+                },
+                "html_template": {
+                    "type": "string"
+                },
+                "max_attempts": {
                     "type": "integer"
                 },
-                "node_id": {
+                "name": {
+                    "type": "string"
+                },
+                "node_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "password": {
+                    "description": "omitted on update keeps the current password",
+                    "type": "string"
+                },
+                "port": {
+                    "description": "defaults to 587 for starttls, 465 for tls and 25 for none",
                     "type": "integer"
                 },
-                "status": {
+                "skip_verify": {
+                    "type": "boolean"
+                },
+                "subject_template": {
+                    "type": "string"
+                },
+                "text_template": {
+                    "type": "string"
+                },
+                "tls_mode": {
+                    "description": "none, starttls (default) or tls",
+                    "type": "string"
+                },
+                "to": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.GroupInput": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "description": "null for a top level group",
+                    "type": "integer"
+                },
+                "retention": {
+                    "description": "days per tier, null keeps the global setting, 0 keeps forever",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.GroupRetention"
+                        }
+                    ]
+                }
+            }
+        },
+        "services.GroupSummary": {
+            "type": "object",
+            "properties": {
+                "down": {
+                    "type": "integer"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nodes": {
+                    "type": "integer"
+                },
+                "parent_id": {
                     "type": "integer"
                 },
                 "suspended": {
-                    "type": "boolean"
+                    "type": "integer"
+                },
+                "unknown": {
+                    "description": "not checked yet",
+                    "type": "integer"
                 },
                 "up": {
-                    "type": "boolean"
+                    "type": "integer"
                 },
-                "updated_at": {
-                    "type": "string"
+                "uptime_percent": {
+                    "description": "nil when nothing was monitored",
+                    "type": "number"
                 }
             }
         },
-        "models.Node": {
+        "services.GroupSummaryReport": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "from": {
                     "type": "string"
                 },
-                "histories": {
+                "groups": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.History"
+                        "$ref": "#/definitions/services.GroupSummary"
                     }
                 },
-                "id": {
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.MaintenanceWindowInput": {
+            "type": "object",
+            "properties": {
+                "cron": {
+                    "description": "cron, e.g. \"0 2 * * *\"",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "cron and weekly, minutes",
                     "type": "integer"
                 },
-                "node_logs": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "ends_at": {
+                    "description": "once",
+                    "type": "string"
+                },
+                "group_ids": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.NodeLog"
+                        "type": "integer"
                     }
                 },
-                "updated_at": {
+                "kind": {
+                    "description": "once, cron or weekly",
                     "type": "string"
                 },
-                "url": {
+                "name": {
+                    "type": "string"
+                },
+                "node_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "start_time": {
+                    "description": "weekly, HH:MM",
+                    "type": "string"
+                },
+                "starts_at": {
+                    "description": "once",
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "IANA name, defaults to UTC",
                     "type": "string"
+                },
+                "weekdays": {
+                    "description": "weekly, e.g. [\"fri\", \"sat\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "models.NodeLog": {
+        "services.NodeInput": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "body": {
                     "type": "string"
                 },
-                "delay": {
+                "expect": {
+                    "description": "tcp: banner substring, dns: comma separated record values",
+                    "type": "string"
+                },
+                "expected_status": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "description": "omitted on update keeps the current group, 0 removes it",
+                    "type": "integer"
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "interval": {
+                    "description": "seconds, 0 uses CHECK_INTERVAL, omitted on update keeps the current interval",
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "record_type": {
+                    "description": "dns record type, defaults to A",
+                    "type": "string"
+                },
+                "resolver": {
+                    "description": "dns resolver host[:port]",
+                    "type": "string"
+                },
+                "skip_default_rules": {
+                    "description": "omitted on update keeps the current setting",
+                    "type": "boolean"
+                },
+                "timeout": {
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "http (default), tcp or dns",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.NodeSLA": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "integer"
+                },
+                "downtime": {
+                    "description": "down and suspended",
                     "type": "number"
                 },
-                "exception": {
+                "from": {
                     "type": "string"
                 },
-                "id": {
+                "incidents": {
                     "type": "integer"
                 },
+                "latency": {
+                    "$ref": "#/definitions/sla.Latency"
+                },
+                "maintenance_time": {
+                    "type": "number"
+                },
+                "monitored_time": {
+                    "type": "number"
+                },
+                "mtbf": {
+                    "description": "uptime divided by the number of incidents, nil without uptime",
+                    "type": "number"
+                },
+                "mttr": {
+                    "description": "mean duration of the resolved incidents",
+                    "type": "number"
+                },
                 "node_id": {
-                    "description": "کلید خارجی",
                     "type": "integer"
                 },
-                "status": {
+                "suspended_time": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "unknown_time": {
+                    "type": "number"
+                },
+                "uptime_percent": {
+                    "description": "nil when nothing was monitored",
+                    "type": "number"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.PauseInput": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "description": "e.g. 30m or 2h",
+                    "type": "string"
+                },
+                "until": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.SLAReport": {
+            "type": "object",
+            "properties": {
+                "checks": {
                     "type": "integer"
                 },
-                "suspended": {
-                    "type": "boolean"
+                "downtime": {
+                    "description": "down and suspended",
+                    "type": "number"
                 },
-                "up": {
+                "from": {
+                    "type": "string"
+                },
+                "incidents": {
+                    "type": "integer"
+                },
+                "latency": {
+                    "$ref": "#/definitions/sla.Latency"
+                },
+                "maintenance_time": {
+                    "type": "number"
+                },
+                "monitored_time": {
+                    "type": "number"
+                },
+                "mtbf": {
+                    "description": "uptime divided by the number of incidents, nil without uptime",
+                    "type": "number"
+                },
+                "mttr": {
+                    "description": "mean duration of the resolved incidents",
+                    "type": "number"
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.NodeSLA"
+                    }
+                },
+                "rollup_tier": {
+                    "description": "set when part of the range was read from rollups",
+                    "type": "string"
+                },
+                "suspended_time": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "unknown_time": {
+                    "type": "number"
+                },
+                "uptime_percent": {
+                    "description": "nil when nothing was monitored",
+                    "type": "number"
+                }
+            }
+        },
+        "services.TagInput": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.WebhookInput": {
+            "type": "object",
+            "properties": {
+                "enabled": {
                     "type": "boolean"
                 },
-                "updated_at": {
+                "events": {
+                    "description": "down, up, suspended; empty means all",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "omitted on update keeps the current secret, \"\" removes it",
+                    "type": "string"
+                },
+                "url": {
                     "type": "string"
                 }
             }
+        },
+        "sla.Latency": {
+            "type": "object",
+            "properties": {
+                "approximate": {
+                    "description": "read from rollup histograms, each value is a bucket's upper bound",
+                    "type": "boolean"
+                },
+                "p50": {
+                    "type": "number"
+                },
+                "p95": {
+                    "type": "number"
+                },
+                "p99": {
+                    "type": "number"
+                },
+                "samples": {
+                    "type": "integer"
+                }
+            }
         }
     },
     "securityDefinitions": {
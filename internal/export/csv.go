@@ -0,0 +1,54 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// csvWriter writes the data sheet as RFC 4180 CSV, dropping the other sheets
+type csvWriter struct {
+	w     *csv.Writer
+	sheet string
+}
+
+func newCSV(w io.Writer) *csvWriter {
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	return &csvWriter{w: cw}
+}
+
+func (c *csvWriter) Sheet(name string) error {
+	c.sheet = name
+	return nil
+}
+
+func (c *csvWriter) Row(cells ...interface{}) error {
+	if c.sheet != DataSheet {
+		return nil
+	}
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		s, number := text(cell)
+		if !number {
+			s = defuse(s)
+		}
+		record[i] = s
+	}
+	return c.w.Write(record)
+}
+
+// defuse prefixes text a spreadsheet would read as a formula with a quote, so
+// a URL or error message cannot run one when the CSV is opened. Numbers are
+// left alone, negative ones included.
+func defuse(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,123 @@
+// Package export writes reports as CSV or XLSX spreadsheets for people who
+// read them outside the API, with times in their time zone and calendar.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"uptime/internal/jalali"
+)
+
+// Formats
+const (
+	CSV  = "csv"
+	XLSX = "xlsx"
+)
+
+// ContentTypes are the media types of the formats, also accepted in the
+// Accept header
+var ContentTypes = map[string]string{
+	CSV:  "text/csv",
+	XLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Sheets of an export. A CSV file only holds the data sheet.
+const (
+	SummarySheet = "Summary"
+	DataSheet    = "Data"
+)
+
+// Writer writes the sheets of a report row by row. Cells are strings,
+// numbers, bools or nil, and pointers to them.
+type Writer interface {
+	// Sheet starts writing the named sheet
+	Sheet(name string) error
+	Row(cells ...interface{}) error
+	// Close finishes the file. It does not close the underlying writer.
+	Close() error
+}
+
+// New returns a writer of format writing to w
+func New(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCSV(w), nil
+	case XLSX:
+		return newXLSX(w, SummarySheet, DataSheet), nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// Calendars
+const (
+	Gregorian = "gregorian"
+	Jalali    = "jalali"
+)
+
+// Dates formats the times of an export in a time zone and calendar
+type Dates struct {
+	Loc      *time.Location
+	Calendar string // Gregorian or Jalali, Gregorian when empty
+}
+
+// Format writes t as 2006-01-02 15:04:05, or 1404/07/26 15:04:05 in the
+// Jalali calendar
+func (d Dates) Format(t time.Time) string {
+	if d.Loc != nil {
+		t = t.In(d.Loc)
+	}
+	if d.Calendar == Jalali {
+		return jalali.Format(t)
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// FormatPtr is Format for optional times, nil when t is
+func (d Dates) FormatPtr(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return d.Format(*t)
+}
+
+// text returns the text of a cell and whether it is a number
+func text(cell interface{}) (string, bool) {
+	switch v := cell.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, false
+	case *string:
+		if v != nil {
+			return *v, false
+		}
+	case bool:
+		return strconv.FormatBool(v), false
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), true
+	case *uint:
+		if v != nil {
+			return strconv.FormatUint(uint64(*v), 10), true
+		}
+	case *int64:
+		if v != nil {
+			return strconv.FormatInt(*v, 10), true
+		}
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case *float64:
+		if v != nil {
+			return strconv.FormatFloat(*v, 'f', -1, 64), true
+		}
+	default:
+		return fmt.Sprint(v), false
+	}
+	return "", false
+}
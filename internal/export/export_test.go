@@ -0,0 +1,179 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestColumn(t *testing.T) {
+	tests := []struct {
+		i    int
+		want string
+	}{
+		{0, "A"},
+		{1, "B"},
+		{25, "Z"},
+		{26, "AA"},
+		{27, "AB"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+	for _, tt := range tests {
+		if got := column(tt.i); got != tt.want {
+			t.Errorf("column(%d) = %s, want %s", tt.i, got, tt.want)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	var nilFloat *float64
+	code := uint(503)
+	tests := []struct {
+		cell   interface{}
+		want   string
+		number bool
+	}{
+		{nil, "", false},
+		{"down", "down", false},
+		{true, "true", false},
+		{42, "42", true},
+		{int64(-7), "-7", true},
+		{&code, "503", true},
+		{0.25, "0.25", true},
+		{nilFloat, "", false},
+	}
+	for _, tt := range tests {
+		if got, number := text(tt.cell); got != tt.want || number != tt.number {
+			t.Errorf("text(%v) = %q, %v, want %q, %v", tt.cell, got, number, tt.want, tt.number)
+		}
+	}
+}
+
+func TestDates(t *testing.T) {
+	at := time.Date(2025, 3, 20, 21, 0, 5, 0, time.UTC)
+	tehran := time.FixedZone("+03:30", 3*3600+1800)
+	tests := []struct {
+		dates Dates
+		want  string
+	}{
+		{Dates{}, "2025-03-20 21:00:05"},
+		{Dates{Loc: tehran}, "2025-03-21 00:30:05"},
+		{Dates{Loc: tehran, Calendar: Jalali}, "1404/01/01 00:30:05"},
+	}
+	for _, tt := range tests {
+		if got := tt.dates.Format(at); got != tt.want {
+			t.Errorf("%+v.Format() = %s, want %s", tt.dates, got, tt.want)
+		}
+	}
+	if got := (Dates{}).FormatPtr(nil); got != nil {
+		t.Errorf("FormatPtr(nil) = %v, want nil", got)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := New(CSV, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delay := 0.5
+	steps := []error{
+		w.Sheet(SummarySheet),
+		w.Row("dropped", 1),
+		w.Sheet(DataSheet),
+		w.Row("url", "delay", "up"),
+		w.Row("=HYPERLINK(\"x\")", &delay, true),
+		w.Row("-1 day", -1, nil),
+		w.Close(),
+	}
+	for _, err := range steps {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "url,delay,up\r\n\"'=HYPERLINK(\"\"x\"\")\",0.5,true\r\n'-1 day,-1,\r\n"
+	if buf.String() != want {
+		t.Errorf("CSV =\n%q\nwant\n%q", buf.String(), want)
+	}
+}
+
+func TestXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := New(XLSX, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the data sheet is written first and the summary left empty
+	steps := []error{
+		w.Sheet(DataSheet),
+		w.Row("node", "up", "delay", nil, "=1+1"),
+		w.Row("a < b & c", false, 0.25, nil, 3),
+		w.Close(),
+	}
+	for _, err := range steps {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"[Content_Types].xml", []string{`PartName="/xl/worksheets/sheet1.xml"`, `PartName="/xl/worksheets/sheet2.xml"`}},
+		{"_rels/.rels", []string{`Target="xl/workbook.xml"`}},
+		{"xl/workbook.xml", []string{`<sheet name="Summary" sheetId="1" r:id="rId1"/><sheet name="Data" sheetId="2" r:id="rId2"/>`}},
+		{"xl/_rels/workbook.xml.rels", []string{`Id="rId2"`, `Target="worksheets/sheet2.xml"`}},
+		{"xl/worksheets/sheet1.xml", []string{`<sheetData></sheetData>`}},
+		{"xl/worksheets/sheet2.xml", []string{
+			`<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve">node</t></is></c>`,
+			`<c r="E1" t="inlineStr"><is><t xml:space="preserve">=1+1</t></is></c></row>`,
+			`<row r="2"><c r="A2" t="inlineStr"><is><t xml:space="preserve">a &lt; b &amp; c</t></is></c>` +
+				`<c r="B2" t="b"><v>0</v></c><c r="C2"><v>0.25</v></c><c r="E2"><v>3</v></c></row>`,
+		}},
+	}
+	for _, tt := range tests {
+		body, ok := files[tt.path]
+		if !ok {
+			t.Errorf("%s is missing", tt.path)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s does not contain %s:\n%s", tt.path, want, body)
+			}
+		}
+	}
+
+	x := newXLSX(io.Discard, SummarySheet)
+	if err := x.Row("no sheet"); err == nil {
+		t.Error("Row() before Sheet() succeeded")
+	}
+	if err := x.Sheet(DataSheet); err == nil {
+		t.Error("Sheet() of an undeclared sheet succeeded")
+	}
+	if _, err := New("pdf", io.Discard); err == nil {
+		t.Error(`New("pdf") succeeded`)
+	}
+}
@@ -0,0 +1,166 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// xlsxWriter writes an Office Open XML workbook. Each sheet is streamed into
+// the zip as its rows come, so sheets are written one after another, in any
+// order; the workbook lists them in the order they were declared.
+type xlsxWriter struct {
+	zip     *zip.Writer
+	sheets  []string
+	written map[string]bool
+	sheet   io.Writer // the sheet being written, nil before the first
+	row     int
+}
+
+func newXLSX(w io.Writer, sheets ...string) *xlsxWriter {
+	return &xlsxWriter{zip: zip.NewWriter(w), sheets: sheets, written: make(map[string]bool)}
+}
+
+// sheetPath returns where the sheet declared at index i is stored
+func sheetPath(i int) string {
+	return "xl/worksheets/sheet" + strconv.Itoa(i+1) + ".xml"
+}
+
+func (x *xlsxWriter) Sheet(name string) error {
+	i := -1
+	for j, s := range x.sheets {
+		if s == name {
+			i = j
+		}
+	}
+	if i < 0 || x.written[name] {
+		return fmt.Errorf("sheet %q is not declared or already written", name)
+	}
+	if err := x.endSheet(); err != nil {
+		return err
+	}
+
+	w, err := x.zip.Create(sheetPath(i))
+	if err != nil {
+		return err
+	}
+	x.written[name] = true
+	x.sheet, x.row = w, 0
+	_, err = io.WriteString(w, xmlHeader+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return err
+}
+
+func (x *xlsxWriter) endSheet() error {
+	if x.sheet == nil {
+		return nil
+	}
+	_, err := io.WriteString(x.sheet, `</sheetData></worksheet>`)
+	x.sheet = nil
+	return err
+}
+
+func (x *xlsxWriter) Row(cells ...interface{}) error {
+	if x.sheet == nil {
+		return fmt.Errorf("no sheet started")
+	}
+	x.row++
+	row := strconv.Itoa(x.row)
+
+	var b strings.Builder
+	b.WriteString(`<row r="` + row + `">`)
+	for i, cell := range cells {
+		ref := column(i) + row
+		s, number := text(cell)
+		switch {
+		case s == "":
+			// left blank
+		case number:
+			b.WriteString(`<c r="` + ref + `"><v>` + s + `</v></c>`)
+		default:
+			if v, ok := cell.(bool); ok {
+				b.WriteString(`<c r="` + ref + `" t="b"><v>` + strconv.Itoa(boolToInt(v)) + `</v></c>`)
+				continue
+			}
+			b.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(&b, []byte(s))
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+	b.WriteString(`</row>`)
+	_, err := io.WriteString(x.sheet, b.String())
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	if err := x.endSheet(); err != nil {
+		return err
+	}
+	// sheets nobody wrote are left empty
+	for _, name := range x.sheets {
+		if !x.written[name] {
+			if err := x.Sheet(name); err != nil {
+				return err
+			}
+			if err := x.endSheet(); err != nil {
+				return err
+			}
+		}
+	}
+
+	var types, sheets, rels strings.Builder
+	for i, name := range x.sheets {
+		id := strconv.Itoa(i + 1)
+		types.WriteString(`<Override PartName="/` + sheetPath(i) + `" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`)
+		sheets.WriteString(`<sheet name="`)
+		xml.EscapeText(&sheets, []byte(name))
+		sheets.WriteString(`" sheetId="` + id + `" r:id="rId` + id + `"/>`)
+		rels.WriteString(`<Relationship Id="rId` + id + `" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet` + id + `.xml"/>`)
+	}
+
+	parts := []struct{ path, body string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+	}
+	for _, p := range parts {
+		w, err := x.zip.Create(p.path)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, xmlHeader+p.body); err != nil {
+			return err
+		}
+	}
+	return x.zip.Close()
+}
+
+// column returns the letters of the column at index i: A, B, ..., Z, AA, ...
+func column(i int) string {
+	var letters []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		letters = append([]byte{byte('A' + (i-1)%26)}, letters...)
+	}
+	return string(letters)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
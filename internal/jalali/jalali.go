@@ -0,0 +1,81 @@
+// Package jalali converts dates to the Jalali (Solar Hijri) calendar used in
+// Iran, following the jalaali algorithm by Kazimierz M. Borkowski.
+package jalali
+
+import (
+	"fmt"
+	"time"
+)
+
+// breaks are the Jalali years starting a new 2820 year cycle segment
+var breaks = []int{
+	-61, 9, 38, 199, 426, 686, 756, 818, 1111, 1181, 1210,
+	1635, 2060, 2097, 2192, 2262, 2324, 2394, 2456, 3178,
+}
+
+// march returns the day in March of the Gregorian year gy on which the
+// Jalali year gy-621 starts, and whether that Jalali year is a leap year
+func march(gy int) (int, bool) {
+	jy := gy - 621
+	leapJ := -14
+	jp := breaks[0]
+	jump := 0
+	for _, jm := range breaks[1:] {
+		jump = jm - jp
+		if jy < jm {
+			break
+		}
+		leapJ += jump/33*8 + jump%33/4
+		jp = jm
+	}
+	n := jy - jp
+	leapJ += n/33*8 + (n%33+3)/4
+	if jump%33 == 4 && jump-n == 4 {
+		leapJ++
+	}
+	leapG := gy/4 - (gy/100+1)*3/4 - 150
+
+	if jump-n < 6 {
+		n = n - jump + (jump+4)/33*33
+	}
+	leap := ((n+1)%33 - 1) % 4
+	if leap == -1 {
+		leap = 4
+	}
+	return 20 + leapJ - leapG, leap == 0
+}
+
+// dayNumber returns the Julian day number of a Gregorian date
+func dayNumber(gy, gm, gd int) int {
+	d := (gy+(gm-8)/6+100100)*1461/4 + (153*((gm+9)%12)+2)/5 + gd - 34840408
+	return d - (gy+100100+(gm-8)/6)/100*3/4 + 752
+}
+
+// Date returns the Jalali year, month and day of t in its location. Dates
+// before 1 Farvardin 1 (March 622) are not supported.
+func Date(t time.Time) (year, month, day int) {
+	gy, gm, gd := t.Date()
+	jy := gy - 621
+	start, _ := march(gy)
+	k := dayNumber(gy, int(gm), gd) - dayNumber(gy, 3, start)
+	if k >= 0 {
+		if k <= 185 {
+			return jy, 1 + k/31, k%31 + 1
+		}
+		k -= 186
+	} else {
+		// still in the Jalali year that started the March before
+		jy--
+		k += 179
+		if _, leap := march(gy - 1); leap {
+			k++
+		}
+	}
+	return jy, 7 + k/30, k%30 + 1
+}
+
+// Format writes t in its location as 1404/07/26 15:04:05
+func Format(t time.Time) string {
+	y, m, d := Date(t)
+	return fmt.Sprintf("%04d/%02d/%02d %s", y, m, d, t.Format("15:04:05"))
+}
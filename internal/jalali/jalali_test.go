@@ -0,0 +1,47 @@
+package jalali
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDate(t *testing.T) {
+	tests := []struct {
+		date             string
+		year, month, day int
+	}{
+		{"1979-02-11", 1357, 11, 22},
+		{"2023-03-21", 1402, 1, 1},
+		{"2024-03-19", 1402, 12, 29},
+		{"2024-03-20", 1403, 1, 1},
+		{"2024-09-21", 1403, 6, 31},
+		{"2024-09-22", 1403, 7, 1},
+		{"2024-12-21", 1403, 10, 1},
+		{"2025-03-20", 1403, 12, 30}, // 1403 is a leap year
+		{"2025-03-21", 1404, 1, 1},
+		{"2026-03-20", 1404, 12, 29},
+		{"2026-10-18", 1405, 7, 26},
+	}
+	for _, tt := range tests {
+		d, err := time.Parse("2006-01-02", tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if y, m, day := Date(d); y != tt.year || m != tt.month || day != tt.day {
+			t.Errorf("Date(%s) = %d/%d/%d, want %d/%d/%d", tt.date, y, m, day, tt.year, tt.month, tt.day)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tehran := time.FixedZone("+03:30", 3*3600+1800)
+	// the evening of 20 March UTC is already Nowruz in Tehran
+	at := time.Date(2025, 3, 20, 21, 0, 5, 0, time.UTC)
+
+	if got, want := Format(at), "1403/12/30 21:00:05"; got != want {
+		t.Errorf("Format() = %s, want %s", got, want)
+	}
+	if got, want := Format(at.In(tehran)), "1404/01/01 00:30:05"; got != want {
+		t.Errorf("Format() in Tehran = %s, want %s", got, want)
+	}
+}
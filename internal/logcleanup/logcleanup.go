@@ -2,22 +2,189 @@ package logcleanup
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
+	"uptime/config"
 	"uptime/database"
+	"uptime/internal/retention"
+	"uptime/internal/rollup"
 	"uptime/models"
+	"uptime/repositories"
 )
 
-func CleanupOldLogs() {
-	cutoffDate := time.Now().AddDate(0, 0, -31)
-	fmt.Printf("Deleting logs older than: %s\n", cutoffDate.Format("2006-01-02 15:04:05"))
+// Result is what one cleanup pass removed from a tier
+type Result struct {
+	Tier    string    `json:"tier"`
+	GroupID *uint     `json:"group_id,omitempty"` // nil for nodes following the global policy
+	Cutoff  time.Time `json:"cutoff"`
+	Rows    int64     `json:"rows"` // deleted, or that would be deleted in a dry run
+}
+
+// expirer is where the rows of a tier are kept
+type expirer interface {
+	CountExpired(e repositories.Expiry) (int64, error)
+	DeleteExpired(e repositories.Expiry, limit int) (int64, error)
+}
+
+// expirers returns where the rows of a tier are kept. Raw logs and
+// incidents are in the store, which may be in memory; the other tiers are
+// always in the database. Histories are the current state of the nodes and
+// never expire.
+func expirers(store *repositories.Store, tier string) []expirer {
+	switch tier {
+	case retention.RawLogs:
+		return []expirer{store.NodeLogs}
+	case retention.Incidents:
+		return []expirer{store.Incidents}
+	case retention.HourlyRollups:
+		return []expirer{table{name: models.RollupTable(models.RollupHourly), timeColumn: "bucket_start"}}
+	case retention.DailyRollups:
+		return []expirer{table{name: models.RollupTable(models.RollupDaily), timeColumn: "bucket_start"}}
+	case retention.Deliveries:
+		return []expirer{table{name: "notification_deliveries", timeColumn: "created_at"}}
+	}
+	return nil
+}
+
+// table is a tier kept in a database table
+type table struct {
+	name       string
+	timeColumn string
+}
 
-	res := database.DB.Where("created_at < ?", cutoffDate).Delete(&models.NodeLog{})
+func (t table) CountExpired(e repositories.Expiry) (int64, error) {
+	var count int64
+	err := e.Scope(database.DB.Table(t.name), t.timeColumn).Count(&count).Error
+	return count, err
+}
+
+func (t table) DeleteExpired(e repositories.Expiry, limit int) (int64, error) {
+	query := e.Scope(database.DB.Table(t.name), t.timeColumn)
+	return repositories.DeleteBatch(database.DB, query, t.name, limit, nil)
+}
+
+// Run deletes the data of every tier that is past its retention, in batches
+// of CLEANUP_BATCH_SIZE rows per transaction. Groups overriding a tier are
+// cleaned with their own cutoff, together with their subgroups that do not
+// override it themselves. With dryRun nothing is deleted and the rows
+// that would be are counted.
+func Run(store *repositories.Store, dryRun bool) ([]Result, error) {
+	set, err := retention.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	// Raw logs that are not rolled up yet are kept
+	completed, err := rollup.CompletedUntil()
+	if err != nil {
+		return nil, err
+	}
 
-	if res.Error != nil {
-		fmt.Printf("Error deleting old logs: %v\n", res.Error)
-		return
+	// Nodes follow the override of their group or of the nearest group it is
+	// nested in
+	var nodes []models.Node
+	if err := store.Nodes.Unscoped().All(&nodes); err != nil {
+		return nil, err
 	}
 
-	fmt.Printf("Successfully deleted %d old log record(s) from node_logs table.\n", res.RowsAffected)
+	now := time.Now()
+	var results []Result
+	for _, tier := range retention.Tiers {
+		bySource := make(map[uint][]uint)
+		for _, n := range nodes {
+			if source := set.Source(tier, n.GroupID); source != nil {
+				bySource[*source] = append(bySource[*source], n.ID)
+			}
+		}
+		groupIDs := make([]uint, 0, len(bySource))
+		for id := range bySource {
+			groupIDs = append(groupIDs, id)
+		}
+		sort.Slice(groupIDs, func(i, j int) bool { return groupIDs[i] < groupIDs[j] })
+
+		var overridden []uint
+		for _, id := range groupIDs {
+			groupID := id
+			nodeIDs := bySource[id]
+			overridden = append(overridden, nodeIDs...)
+			keep := set.Keep(tier, &groupID)
+			if keep == 0 {
+				continue
+			}
+
+			result := Result{Tier: tier, GroupID: &groupID, Cutoff: cutoff(tier, now, keep, completed)}
+			result.Rows, err = clean(expirers(store, tier), repositories.Expiry{Before: result.Cutoff, NodeIDs: nodeIDs}, dryRun)
+			results = append(results, result)
+			if err != nil {
+				return results, err
+			}
+		}
+
+		keep := set.Global()[tier]
+		if keep == 0 {
+			continue
+		}
+		result := Result{Tier: tier, Cutoff: cutoff(tier, now, keep, completed)}
+		result.Rows, err = clean(expirers(store, tier), repositories.Expiry{Before: result.Cutoff, NodeIDs: overridden, Exclude: true}, dryRun)
+		results = append(results, result)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// cutoff is the time before which a tier's rows are deleted
+func cutoff(tier string, now time.Time, keep time.Duration, completed *time.Time) time.Time {
+	c := now.Add(-keep)
+	if tier == retention.RawLogs && completed != nil && completed.Before(c) {
+		c = *completed
+	}
+	return c
+}
+
+// clean deletes the rows selected by e, one batch per transaction
+func clean(expirers []expirer, e repositories.Expiry, dryRun bool) (int64, error) {
+	batchSize := config.AppConfig.Retention.BatchSize
+	var total int64
+	for _, x := range expirers {
+		if dryRun {
+			count, err := x.CountExpired(e)
+			total += count
+			if err != nil {
+				return total, err
+			}
+			continue
+		}
+		for {
+			deleted, err := x.DeleteExpired(e, batchSize)
+			total += deleted
+			if err != nil {
+				return total, err
+			}
+			if deleted < int64(batchSize) {
+				break
+			}
+		}
+	}
+	return total, nil
+}
+
+// CleanupOldLogs deletes the expired data of every tier and logs what it removed
+func CleanupOldLogs(store *repositories.Store) {
+	results, err := Run(store, false)
+	for _, r := range results {
+		if r.Rows == 0 {
+			continue
+		}
+		scope := "global policy"
+		if r.GroupID != nil {
+			scope = fmt.Sprintf("group %d", *r.GroupID)
+		}
+		fmt.Printf("Deleted %d %s row(s) older than %s (%s)\n", r.Rows, r.Tier, r.Cutoff.Format("2006-01-02 15:04:05"), scope)
+	}
+	if err != nil {
+		fmt.Printf("Error deleting old data: %v\n", err)
+	}
 }
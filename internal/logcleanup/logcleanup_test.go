@@ -0,0 +1,176 @@
+package logcleanup
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"uptime/config"
+	"uptime/database"
+	"uptime/internal/retention"
+	"uptime/models"
+	"uptime/repositories"
+	"uptime/repositories/memory"
+)
+
+// TestMain opens an in-memory SQLite database for the groups and rollup state
+func TestMain(m *testing.M) {
+	config.AppConfig = &config.Config{}
+	config.AppConfig.Database.Driver = database.SQLite
+	config.AppConfig.Database.DSN = ":memory:"
+	config.AppConfig.Retention.BatchSize = 2
+
+	if err := database.Connect(); err != nil {
+		log.Fatal(err)
+	}
+	if err := database.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeExpirer holds a number of expired rows and records the batches deleted
+type fakeExpirer struct {
+	rows    int64
+	batches []int64
+}
+
+func (f *fakeExpirer) CountExpired(e repositories.Expiry) (int64, error) {
+	return f.rows, nil
+}
+
+func (f *fakeExpirer) DeleteExpired(e repositories.Expiry, limit int) (int64, error) {
+	n := min(f.rows, int64(limit))
+	f.rows -= n
+	f.batches = append(f.batches, n)
+	return n, nil
+}
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		rows    int64
+		batches string
+	}{
+		{rows: 0, batches: "[0]"},
+		{rows: 1, batches: "[1]"},
+		{rows: 4, batches: "[2 2 0]"},
+		{rows: 5, batches: "[2 2 1]"},
+	}
+	for _, tt := range tests {
+		dry := &fakeExpirer{rows: tt.rows}
+		if total, err := clean([]expirer{dry}, repositories.Expiry{}, true); err != nil || total != tt.rows || dry.rows != tt.rows || dry.batches != nil {
+			t.Errorf("dry run of %d rows = %d, %v, deleted %v", tt.rows, total, err, dry.batches)
+		}
+
+		x := &fakeExpirer{rows: tt.rows}
+		total, err := clean([]expirer{x}, repositories.Expiry{}, false)
+		if err != nil || total != tt.rows || x.rows != 0 {
+			t.Errorf("clean() of %d rows = %d, %v, %d left", tt.rows, total, err, x.rows)
+		}
+		if got := fmt.Sprint(x.batches); got != tt.batches {
+			t.Errorf("%d rows deleted in batches %s, want %s", tt.rows, got, tt.batches)
+		}
+	}
+}
+
+func createGroup(t *testing.T, name string, parentID *uint, rawLogs *uint) *uint {
+	t.Helper()
+	g := models.Group{Name: name, ParentID: parentID, Retention: models.GroupRetention{RawLogs: rawLogs}}
+	if err := repositories.CreateGroup(&g); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repositories.DeleteGroup(&g) })
+	return &g.ID
+}
+
+func TestRun(t *testing.T) {
+	saved := config.AppConfig.Retention
+	defer func() { config.AppConfig.Retention = saved }()
+	config.AppConfig.Retention.RawLogs = 10 * 24 * time.Hour
+
+	two, zero := uint(2), uint(0)
+	parent := createGroup(t, "parent", nil, &two)
+	child := createGroup(t, "child", parent, nil)
+	forever := createGroup(t, "forever", child, &zero)
+	plain := createGroup(t, "plain", nil, nil)
+
+	// each node has logs of 1, 5 and 20 days ago and an old history
+	store := memory.NewStore()
+	now := time.Now()
+	var logs []models.NodeLog
+	var histories []models.History
+	for i, groupID := range []*uint{nil, parent, child, forever, plain} {
+		n := models.Node{URL: fmt.Sprintf("https://node%d.test", i), Type: models.NodeTypeHTTP, GroupID: groupID}
+		if err := store.Nodes.Create(&n); err != nil {
+			t.Fatal(err)
+		}
+		for _, age := range []int{1, 5, 20} {
+			logs = append(logs, models.NodeLog{NodeID: n.ID, Up: true, CreatedAt: now.AddDate(0, 0, -age)})
+		}
+		histories = append(histories, models.History{ID: n.ID, NodeID: n.ID, Up: true, UpdatedAt: now.AddDate(0, 0, -30)})
+	}
+	if err := store.NodeLogs.CreateMany(logs); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Histories.SaveMany(histories); err != nil {
+		t.Fatal(err)
+	}
+
+	remaining := func() (int, int) {
+		t.Helper()
+		var logs []models.NodeLog
+		var histories []models.History
+		if err := store.NodeLogs.All(&logs); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Histories.All(&histories); err != nil {
+			t.Fatal(err)
+		}
+		return len(logs), len(histories)
+	}
+	describe := func(results []Result) string {
+		var out []string
+		for _, r := range results {
+			scope := "global"
+			if r.GroupID != nil {
+				scope = fmt.Sprintf("group %d", *r.GroupID)
+			}
+			out = append(out, fmt.Sprintf("%s %s %d", r.Tier, scope, r.Rows))
+		}
+		return fmt.Sprint(out)
+	}
+	// the parent's 2 days cover its child, the forever group is skipped and
+	// the other nodes follow the global 10 days
+	want := fmt.Sprint([]string{
+		fmt.Sprintf("%s group %d 4", retention.RawLogs, *parent),
+		fmt.Sprintf("%s global 2", retention.RawLogs),
+	})
+
+	results, err := Run(store, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := describe(results); got != want {
+		t.Errorf("dry run = %s, want %s", got, want)
+	}
+	if l, h := remaining(); l != 15 || h != 5 {
+		t.Errorf("%d logs and %d histories left after a dry run, want 15 and 5", l, h)
+	}
+
+	results, err = Run(store, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := describe(results); got != want {
+		t.Errorf("Run() = %s, want %s", got, want)
+	}
+	// histories are the nodes' current state and are never expired
+	if l, h := remaining(); l != 9 || h != 5 {
+		t.Errorf("%d logs and %d histories left, want 9 and 5", l, h)
+	}
+	if d := results[0].Cutoff.Sub(now.AddDate(0, 0, -2)); d < -time.Minute || d > time.Minute {
+		t.Errorf("group cutoff = %s, want 2 days ago", results[0].Cutoff)
+	}
+}
@@ -0,0 +1,195 @@
+package logquery
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"uptime/models"
+)
+
+// ErrCursor is returned for a cursor that is malformed or was issued for
+// another sort
+var ErrCursor = errors.New("invalid cursor")
+
+// WithID appends an ascending id to orders that do not sort on it, so every
+// row has a distinct position to continue from
+func WithID(orders []Order) []Order {
+	for _, o := range orders {
+		if o.Field == "id" {
+			return orders
+		}
+	}
+	return append(orders[:len(orders):len(orders)], Order{Field: "id"})
+}
+
+// sortKey writes orders the way ParseSort reads them
+func sortKey(orders []Order) string {
+	keys := make([]string, len(orders))
+	for i, o := range orders {
+		keys[i] = o.Field
+		if o.Desc {
+			keys[i] = "-" + o.Field
+		}
+	}
+	return strings.Join(keys, ",")
+}
+
+type cursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+// EncodeCursor returns an opaque cursor for the row whose sorted values are
+// values, in the order of orders
+func EncodeCursor(orders []Order, values []interface{}) string {
+	encoded := make([]interface{}, len(values))
+	for i, v := range values {
+		if t, ok := v.(time.Time); ok {
+			v = t.UTC().Format(time.RFC3339Nano)
+		}
+		encoded[i] = v
+	}
+	b, _ := json.Marshal(cursor{Sort: sortKey(orders), Values: encoded})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor returns the sorted values held by a cursor issued for orders
+func DecodeCursor(s string, orders []Order) ([]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sortKey(orders) || len(c.Values) != len(orders) {
+		return nil, ErrCursor
+	}
+
+	for i, o := range orders {
+		v := c.Values[i]
+		if v == nil {
+			if !Fields[o.Field].Nullable {
+				return nil, ErrCursor
+			}
+			continue
+		}
+		ok := false
+		switch Fields[o.Field].Kind {
+		case Number:
+			_, ok = v.(float64)
+		case Bool:
+			_, ok = v.(bool)
+		case String:
+			_, ok = v.(string)
+		case Time:
+			var s string
+			if s, ok = v.(string); ok {
+				var t time.Time
+				t, err = time.Parse(time.RFC3339Nano, s)
+				ok, c.Values[i] = err == nil, t
+			}
+		}
+		if !ok {
+			return nil, ErrCursor
+		}
+	}
+	return c.Values, nil
+}
+
+// After returns the filter that keeps the rows sorted after the row whose
+// sorted values are values. NULLs sort first ascending and last descending.
+func After(orders []Order, values []interface{}) *Expr {
+	var after, equal *Expr
+	for i, o := range orders {
+		v := values[i]
+
+		var past *Expr
+		switch {
+		case v == nil && !o.Desc:
+			past = &Expr{Op: "!=", Field: o.Field}
+		case v == nil:
+			// nothing sorts after NULL descending
+		case !o.Desc:
+			past = &Expr{Op: ">", Field: o.Field, Value: v}
+		case Fields[o.Field].Nullable:
+			past = &Expr{Op: "or",
+				Left:  &Expr{Op: "<", Field: o.Field, Value: v},
+				Right: &Expr{Op: "=", Field: o.Field}}
+		default:
+			past = &Expr{Op: "<", Field: o.Field, Value: v}
+		}
+		if past != nil {
+			after = or(after, And(equal, past))
+		}
+		equal = And(equal, &Expr{Op: "=", Field: o.Field, Value: v})
+	}
+	if after == nil {
+		// the row was the last one there can be, nothing follows it
+		return &Expr{Op: "<", Field: "id", Value: float64(0)}
+	}
+	return after
+}
+
+func or(a, b *Expr) *Expr {
+	if a == nil {
+		return b
+	}
+	return &Expr{Op: "or", Left: a, Right: b}
+}
+
+// Values returns the sorted values of a row, reading its fields with get
+func Values(orders []Order, get func(field string) interface{}) []interface{} {
+	values := make([]interface{}, len(orders))
+	for i, o := range orders {
+		values[i] = get(o.Field)
+	}
+	return values
+}
+
+// NodeLogField reads a field of a node log the way Eval and Values expect
+func NodeLogField(l *models.NodeLog) func(string) interface{} {
+	return func(field string) interface{} {
+		return value(field, l.ID, l.NodeID, l.Delay, l.Status, l.Up, l.Suspended, l.Degraded, l.Maintenance, l.Exception, l.CreatedAt)
+	}
+}
+
+// HistoryField reads a field of a history the way Eval and Values expect
+func HistoryField(h *models.History) func(string) interface{} {
+	return func(field string) interface{} {
+		return value(field, h.ID, h.NodeID, h.Delay, h.Status, h.Up, h.Suspended, h.Degraded, h.Maintenance, h.Exception, h.CreatedAt)
+	}
+}
+
+func value(field string, id, nodeID uint, delay *float64, status *uint, up, suspended, degraded, maintenance bool, exception *string, createdAt time.Time) interface{} {
+	switch field {
+	case "id":
+		return float64(id)
+	case "node_id":
+		return float64(nodeID)
+	case "delay":
+		if delay != nil {
+			return *delay
+		}
+	case "status":
+		if status != nil {
+			return float64(*status)
+		}
+	case "up":
+		return up
+	case "suspended":
+		return suspended
+	case "degraded":
+		return degraded
+	case "maintenance":
+		return maintenance
+	case "exception":
+		if exception != nil {
+			return *exception
+		}
+	case "created_at":
+		return createdAt
+	}
+	return nil
+}
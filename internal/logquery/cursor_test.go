@@ -0,0 +1,165 @@
+package logquery
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	orders := []Order{{Field: "delay", Desc: true}, {Field: "exception"}, {Field: "up"}, {Field: "created_at"}, {Field: "id"}}
+	at := time.Date(2026, 9, 1, 10, 30, 0, 123456789, time.FixedZone("+03:30", 3*3600+1800))
+
+	values, err := DecodeCursor(EncodeCursor(orders, []interface{}{2.5, nil, true, at, 42.0}), orders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != 2.5 || values[1] != nil || values[2] != true || values[4] != 42.0 {
+		t.Errorf("values = %v", values)
+	}
+	if got, ok := values[3].(time.Time); !ok || !got.Equal(at) {
+		t.Errorf("created_at = %v, want %v", values[3], at)
+	}
+
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	idOnly := []Order{{Field: "id"}}
+	tests := []struct {
+		name   string
+		cursor string
+		orders []Order
+	}{
+		{name: "not base64", cursor: "%%%", orders: idOnly},
+		{name: "not JSON", cursor: raw("nope"), orders: idOnly},
+		{name: "another sort", cursor: EncodeCursor([]Order{{Field: "id", Desc: true}}, []interface{}{1.0}), orders: idOnly},
+		{name: "too few values", cursor: raw(`{"s":"id","v":[]}`), orders: idOnly},
+		{name: "null id", cursor: raw(`{"s":"id","v":[null]}`), orders: idOnly},
+		{name: "text for a number", cursor: raw(`{"s":"id","v":["1"]}`), orders: idOnly},
+		{name: "number for a bool", cursor: raw(`{"s":"up","v":[1]}`), orders: []Order{{Field: "up"}}},
+		{name: "number for a text", cursor: raw(`{"s":"exception","v":[1]}`), orders: []Order{{Field: "exception"}}},
+		{name: "bad time", cursor: raw(`{"s":"created_at","v":["yesterday"]}`), orders: []Order{{Field: "created_at"}}},
+	}
+	for _, tt := range tests {
+		if _, err := DecodeCursor(tt.cursor, tt.orders); err != ErrCursor {
+			t.Errorf("%s: err = %v, want ErrCursor", tt.name, err)
+		}
+	}
+}
+
+func TestWithID(t *testing.T) {
+	tests := []struct {
+		orders []Order
+		want   string
+	}{
+		{orders: nil, want: "id"},
+		{orders: []Order{{Field: "delay", Desc: true}}, want: "-delay,id"},
+		{orders: []Order{{Field: "id", Desc: true}, {Field: "delay"}}, want: "-id,delay"},
+	}
+	for _, tt := range tests {
+		if got := sortKey(WithID(tt.orders)); got != tt.want {
+			t.Errorf("WithID(%v) = %s, want %s", tt.orders, got, tt.want)
+		}
+	}
+}
+
+// row is a node log reduced to the fields After is tested on
+type row struct {
+	id     float64
+	delay  interface{} // nil or float64
+	status interface{}
+	up     bool
+}
+
+func (r row) get(field string) interface{} {
+	switch field {
+	case "id":
+		return r.id
+	case "delay":
+		return r.delay
+	case "status":
+		return r.status
+	case "up":
+		return r.up
+	}
+	return nil
+}
+
+// less sorts the way the database does: NULLs first ascending, last descending
+func less(orders []Order, a, b row) bool {
+	for _, o := range orders {
+		c := compareValues(a.get(o.Field), b.get(o.Field))
+		if o.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c < 0
+		}
+	}
+	return false
+}
+
+func compareValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	switch a := a.(type) {
+	case float64:
+		return compare(a, b.(float64))
+	case bool:
+		return compare(map[bool]float64{false: 0, true: 1}[a], map[bool]float64{false: 0, true: 1}[b.(bool)])
+	}
+	return 0
+}
+
+func TestAfter(t *testing.T) {
+	var rows []row
+	delays := []interface{}{nil, 0.5, 1.5, nil, 0.5, 2.0, 1.5, nil}
+	statuses := []interface{}{200.0, nil, 500.0, 200.0, 200.0, nil, 500.0, 500.0}
+	for i := range delays {
+		rows = append(rows, row{id: float64(i + 1), delay: delays[i], status: statuses[i], up: i%3 != 0})
+	}
+
+	sorts := []string{"id", "-id", "delay", "-delay", "status,-delay", "-status,delay", "up,-delay", "-up,status,-id"}
+	for _, s := range sorts {
+		t.Run(s, func(t *testing.T) {
+			parsed, err := ParseSort(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			orders := WithID(parsed)
+			sorted := append([]row(nil), rows...)
+			sort.Slice(sorted, func(i, j int) bool { return less(orders, sorted[i], sorted[j]) })
+
+			// paging with the cursor of each row must return exactly the rows after it
+			for i, r := range sorted {
+				cursor := EncodeCursor(orders, Values(orders, r.get))
+				values, err := DecodeCursor(cursor, orders)
+				if err != nil {
+					t.Fatal(err)
+				}
+				filter := After(orders, values)
+
+				var got []float64
+				for _, candidate := range sorted {
+					if filter.Eval(candidate.get) {
+						got = append(got, candidate.id)
+					}
+				}
+				var want []float64
+				for _, next := range sorted[i+1:] {
+					want = append(want, next.id)
+				}
+				if fmt.Sprint(got) != fmt.Sprint(want) {
+					sql, args := filter.SQL()
+					t.Errorf("after row %v: got %v, want %v (%s %v)", r.id, got, want, sql, args)
+				}
+			}
+		})
+	}
+}
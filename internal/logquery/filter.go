@@ -0,0 +1,384 @@
+package logquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxConditions is the most comparisons a filter can have
+const MaxConditions = 32
+
+// Comparison operators. ~ matches exceptions containing a text, ignoring case.
+var operators = map[string]bool{
+	"=": true, "!=": true, ">": true, ">=": true, "<": true, "<=": true, "~": true,
+}
+
+// Expr is a parsed filter: either a comparison of a field with a value, or
+// two expressions joined by and / or
+type Expr struct {
+	Op          string // and, or, or a comparison operator
+	Left, Right *Expr  // and / or
+	Field       string // comparison
+	Value       interface{}
+}
+
+// Parse parses a filter such as status>=500 and (delay>2 or exception~timeout).
+// Comparisons take a field, an operator (=, !=, >, >=, <, <=, ~) and a value:
+// a number, true or false, null, a time or a text, quoted when it holds
+// spaces, parentheses or operators. Times without an offset are in loc.
+func Parse(filter string, loc *time.Location) (*Expr, error) {
+	tokens, err := lex(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := &parser{tokens: tokens, loc: loc}
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return e, nil
+}
+
+type tokenKind int
+
+const (
+	word tokenKind = iota
+	quoted
+	operator
+	openParen
+	closeParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func isOperatorChar(r rune) bool {
+	return strings.ContainsRune("=!<>~", r)
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: openParen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: closeParen, text: ")"})
+			i++
+		case r == '"' || r == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				b.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			tokens = append(tokens, token{kind: quoted, text: b.String()})
+			i = j + 1
+		case isOperatorChar(r):
+			j := i + 1
+			if j < len(runes) && runes[j] == '=' {
+				j++
+			}
+			op := string(runes[i:j])
+			if !operators[op] {
+				return nil, fmt.Errorf("unknown operator %q", op)
+			}
+			tokens = append(tokens, token{kind: operator, text: op})
+			i = j
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !isOperatorChar(runes[j]) &&
+				runes[j] != '(' && runes[j] != ')' && runes[j] != '"' && runes[j] != '\'' {
+				j++
+			}
+			tokens = append(tokens, token{kind: word, text: string(runes[i:j])})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens     []token
+	pos        int
+	loc        *time.Location
+	conditions int
+	depth      int
+}
+
+func (p *parser) peek() *token {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+// keyword reports whether the next token is the word kw and consumes it
+func (p *parser) keyword(kw string) bool {
+	if t := p.peek(); t != nil && t.kind == word && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) or() (*Expr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{Op: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (*Expr, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{Op: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) factor() (*Expr, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("filter ends early")
+	}
+	if t.kind == openParen {
+		p.pos++
+		if p.depth++; p.depth > MaxConditions {
+			return nil, fmt.Errorf("filter is nested too deeply")
+		}
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t == nil || t.kind != closeParen {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		p.depth--
+		return e, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (*Expr, error) {
+	t := p.peek()
+	if t.kind != word {
+		return nil, fmt.Errorf("expected a field, got %q", t.text)
+	}
+	name := strings.ToLower(t.text)
+	field, ok := Fields[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q", t.text)
+	}
+	p.pos++
+
+	t = p.peek()
+	if t == nil || t.kind != operator {
+		return nil, fmt.Errorf("expected an operator after %s", name)
+	}
+	op := t.text
+	p.pos++
+
+	t = p.peek()
+	if t == nil || (t.kind != word && t.kind != quoted) {
+		return nil, fmt.Errorf("expected a value after %s%s", name, op)
+	}
+	p.pos++
+
+	if p.conditions++; p.conditions > MaxConditions {
+		return nil, fmt.Errorf("filter cannot have more than %d conditions", MaxConditions)
+	}
+
+	value, err := p.value(name, field, op, *t)
+	if err != nil {
+		return nil, err
+	}
+	return &Expr{Op: op, Field: name, Value: value}, nil
+}
+
+// value converts the text of a comparison to the field's kind
+func (p *parser) value(name string, field Field, op string, t token) (interface{}, error) {
+	if t.kind == word && strings.EqualFold(t.text, "null") {
+		if !field.Nullable {
+			return nil, fmt.Errorf("%s cannot be null", name)
+		}
+		if op != "=" && op != "!=" {
+			return nil, fmt.Errorf("null can only be compared with = or !=")
+		}
+		return nil, nil
+	}
+	if op == "~" && field.Kind != String {
+		return nil, fmt.Errorf("~ only applies to text fields")
+	}
+
+	switch field.Kind {
+	case Number:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s takes a number, got %q", name, t.text)
+		}
+		return n, nil
+	case Bool:
+		if op != "=" && op != "!=" {
+			return nil, fmt.Errorf("%s can only be compared with = or !=", name)
+		}
+		switch strings.ToLower(t.text) {
+		case "true", "1":
+			return true, nil
+		case "false", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%s takes true or false, got %q", name, t.text)
+	case Time:
+		tm, err := ParseTime(t.text, p.loc, op == "<=" || op == ">")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		return tm, nil
+	}
+	return t.text, nil
+}
+
+// And joins two expressions, either of which may be nil
+func And(a, b *Expr) *Expr {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	}
+	return &Expr{Op: "and", Left: a, Right: b}
+}
+
+// SQL returns the expression as a WHERE clause with ? placeholders. Field
+// names come from Fields, so they are safe to put in the query.
+func (e *Expr) SQL() (string, []interface{}) {
+	if e.Left != nil {
+		l, largs := e.Left.SQL()
+		r, rargs := e.Right.SQL()
+		return "(" + l + " " + strings.ToUpper(e.Op) + " " + r + ")", append(largs, rargs...)
+	}
+
+	switch {
+	case e.Value == nil && e.Op == "=":
+		return e.Field + " IS NULL", nil
+	case e.Value == nil:
+		return e.Field + " IS NOT NULL", nil
+	case e.Op == "~":
+		return "LOWER(" + e.Field + ") LIKE ? ESCAPE '!'", []interface{}{"%" + escapeLike(strings.ToLower(e.Value.(string))) + "%"}
+	case e.Op == "!=":
+		return e.Field + " <> ?", []interface{}{e.Value}
+	}
+	return e.Field + " " + e.Op + " ?", []interface{}{e.Value}
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// Eval reports whether a row passes the expression, reading the row's fields
+// with get. get returns nil for NULL, float64 for numbers, bool, string or
+// time.Time. Like SQL, comparisons other than null checks fail on NULL.
+func (e *Expr) Eval(get func(field string) interface{}) bool {
+	switch e.Op {
+	case "and":
+		return e.Left.Eval(get) && e.Right.Eval(get)
+	case "or":
+		return e.Left.Eval(get) || e.Right.Eval(get)
+	}
+
+	v := get(e.Field)
+	if e.Value == nil {
+		return (v == nil) == (e.Op == "=")
+	}
+	if v == nil {
+		return false
+	}
+
+	var c int
+	switch want := e.Value.(type) {
+	case float64:
+		c = compare(v.(float64), want)
+	case bool:
+		// false sorts before true, as in SQL
+		switch got := v.(bool); {
+		case got == want:
+			c = 0
+		case want:
+			c = -1
+		default:
+			c = 1
+		}
+	case time.Time:
+		c = v.(time.Time).Compare(want)
+	case string:
+		if e.Op == "~" {
+			return strings.Contains(strings.ToLower(v.(string)), strings.ToLower(want))
+		}
+		c = strings.Compare(v.(string), want)
+	}
+
+	switch e.Op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
+
+func compare(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,199 @@
+package logquery
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tehran := time.FixedZone("+03:30", 3*3600+1800)
+
+	tests := []struct {
+		filter string
+		sql    string
+		args   []interface{}
+		err    string
+	}{
+		{filter: "", sql: ""},
+		{filter: "   ", sql: ""},
+		{filter: "status>=500", sql: "status >= ?", args: []interface{}{500.0}},
+		{filter: "STATUS = 200", sql: "status = ?", args: []interface{}{200.0}},
+		{filter: "delay>2 and up=false", sql: "(delay > ? AND up = ?)", args: []interface{}{2.0, false}},
+		{filter: "up=1 or suspended=0", sql: "(up = ? OR suspended = ?)", args: []interface{}{true, false}},
+		{
+			filter: "status>=500 and (delay>2 or exception~timeout)",
+			sql:    "(status >= ? AND (delay > ? OR LOWER(exception) LIKE ? ESCAPE '!'))",
+			args:   []interface{}{500.0, 2.0, "%timeout%"},
+		},
+		{
+			// and binds tighter than or
+			filter: "up=true or up=false and delay<1",
+			sql:    "(up = ? OR (up = ? AND delay < ?))",
+			args:   []interface{}{true, false, 1.0},
+		},
+		{filter: "exception~'Connection Refused'", sql: "LOWER(exception) LIKE ? ESCAPE '!'", args: []interface{}{"%connection refused%"}},
+		{filter: `exception~"100%_done!"`, sql: "LOWER(exception) LIKE ? ESCAPE '!'", args: []interface{}{"%100!%!_done!!%"}},
+		{filter: `exception="say \"hi\""`, sql: "exception = ?", args: []interface{}{`say "hi"`}},
+		{filter: "exception=null", sql: "exception IS NULL"},
+		{filter: "status!=NULL", sql: "status IS NOT NULL"},
+		{filter: "node_id!=3", sql: "node_id <> ?", args: []interface{}{3.0}},
+		{filter: "created_at>=2026-09-01", sql: "created_at >= ?", args: []interface{}{time.Date(2026, 9, 1, 0, 0, 0, 0, tehran)}},
+		{filter: "created_at<=2026-09-01", sql: "created_at <= ?", args: []interface{}{time.Date(2026, 9, 1, 23, 59, 59, 0, tehran)}},
+		{filter: "created_at>2026-09-01", sql: "created_at > ?", args: []interface{}{time.Date(2026, 9, 1, 23, 59, 59, 0, tehran)}},
+		{filter: "created_at<'2026-09-01 10:30'", sql: "created_at < ?", args: []interface{}{time.Date(2026, 9, 1, 10, 30, 0, 0, tehran)}},
+		{filter: "created_at<2026-09-01T10:30:00Z", sql: "created_at < ?", args: []interface{}{time.Date(2026, 9, 1, 10, 30, 0, 0, time.UTC)}},
+
+		{filter: "latency>1", err: `unknown field "latency"`},
+		{filter: "status", err: "expected an operator after status"},
+		{filter: "status>", err: "expected a value after status>"},
+		{filter: "status!1", err: `unknown operator "!"`},
+		{filter: "status=>1", err: "expected a value after status="},
+		{filter: "status>abc", err: `status takes a number, got "abc"`},
+		{filter: "up>true", err: "up can only be compared with = or !="},
+		{filter: "up=maybe", err: `up takes true or false, got "maybe"`},
+		{filter: "up=null", err: "up cannot be null"},
+		{filter: "delay>null", err: "null can only be compared with = or !="},
+		{filter: "status~5", err: "~ only applies to text fields"},
+		{filter: "created_at>yesterday", err: `created_at: invalid time "yesterday"`},
+		{filter: "exception='open", err: "unterminated quote"},
+		{filter: "(status=1", err: "missing )"},
+		{filter: "status=1)", err: `unexpected ")"`},
+		{filter: "status=1 and", err: "filter ends early"},
+		{filter: "status=1 status=2", err: `unexpected "status"`},
+		{filter: "=1", err: `expected a field, got "="`},
+		{filter: strings.Repeat("status=1 or ", MaxConditions) + "status=1", err: "more than 32 conditions"},
+		{filter: strings.Repeat("(", MaxConditions+1) + "status=1" + strings.Repeat(")", MaxConditions+1), err: "nested too deeply"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			e, err := Parse(tt.filter, tehran)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e == nil {
+				if tt.sql != "" {
+					t.Fatalf("Parse() = nil, want %s", tt.sql)
+				}
+				return
+			}
+
+			sql, args := e.SQL()
+			if sql != tt.sql {
+				t.Errorf("SQL = %s, want %s", sql, tt.sql)
+			}
+			if fmt.Sprint(args) != fmt.Sprint(tt.args) {
+				t.Errorf("args = %v, want %v", args, tt.args)
+			}
+			for i, arg := range args {
+				if want, ok := tt.args[i].(time.Time); ok && !want.Equal(arg.(time.Time)) {
+					t.Errorf("arg %d = %v, want %v", i, arg, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	delay := 2.5
+	exception := "dial tcp: Connection refused"
+	row := map[string]interface{}{
+		"id":         7.0,
+		"status":     nil,
+		"delay":      delay,
+		"up":         false,
+		"exception":  exception,
+		"created_at": time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC),
+	}
+	get := func(field string) interface{} { return row[field] }
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{"id=7", true},
+		{"delay>2 and delay<=2.5", true},
+		{"delay>=3", false},
+		{"status=null", true},
+		{"status!=null", false},
+		{"status>=500", false}, // NULL fails comparisons, as in SQL
+		{"status<500", false},
+		{"up=false", true},
+		{"exception~refused", true},
+		{"exception~REFUSED and up=true", false},
+		{"exception='dial tcp: Connection refused'", true},
+		{"exception>dial", true},
+		{"created_at>=2026-09-01 and created_at<'2026-09-01 10:00:01'", true},
+		{"created_at>2026-09-01T10:00:00Z or id=8", false},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.filter, time.UTC)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.filter, err)
+		}
+		if got := e.Eval(get); got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		sort string
+		want []Order
+		err  string
+	}{
+		{sort: "", want: nil},
+		{sort: "-delay, status,+id", want: []Order{{Field: "delay", Desc: true}, {Field: "status"}, {Field: "id"}}},
+		{sort: "name", err: `cannot sort by "name"`},
+		{sort: "delay,-delay", err: `"delay" is sorted on twice`},
+		{sort: "id,node_id,delay,status,up,suspended,degraded,maintenance,exception", err: "more than 8 fields"},
+	}
+	for _, tt := range tests {
+		got, err := ParseSort(tt.sort)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseSort(%q) err = %v, want %q", tt.sort, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("ParseSort(%q) = %v, %v, want %v", tt.sort, got, err, tt.want)
+		}
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int
+		err    bool
+	}{
+		{name: "", offset: 0},
+		{name: "+03:30", offset: 3*3600 + 1800},
+		{name: "-05:00", offset: -5 * 3600},
+		{name: "UTC", offset: 0},
+		{name: "+3", err: true},
+		{name: "Mars/Olympus", err: true},
+	}
+	for _, tt := range tests {
+		loc, err := LoadLocation(tt.name)
+		if (err != nil) != tt.err {
+			t.Errorf("LoadLocation(%q) err = %v, want error %v", tt.name, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if _, offset := time.Date(2026, 1, 1, 0, 0, 0, 0, loc).Zone(); offset != tt.offset {
+			t.Errorf("LoadLocation(%q) offset = %d, want %d", tt.name, offset, tt.offset)
+		}
+	}
+}
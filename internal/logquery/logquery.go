@@ -0,0 +1,144 @@
+// Package logquery parses the filtering, sorting and time ranges of the node
+// log and history reports, e.g. filter=status>=500 and delay>2 with
+// sort=-delay,status.
+package logquery
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kind is the type of a field's values
+type Kind int
+
+const (
+	Number Kind = iota
+	Bool
+	String
+	Time
+)
+
+// Field is a column of node logs and histories that can be filtered and sorted on
+type Field struct {
+	Kind     Kind
+	Nullable bool
+}
+
+// Fields are the columns node logs and histories can be filtered and sorted by
+var Fields = map[string]Field{
+	"id":          {Kind: Number},
+	"node_id":     {Kind: Number},
+	"delay":       {Kind: Number, Nullable: true},
+	"status":      {Kind: Number, Nullable: true},
+	"up":          {Kind: Bool},
+	"suspended":   {Kind: Bool},
+	"degraded":    {Kind: Bool},
+	"maintenance": {Kind: Bool},
+	"exception":   {Kind: String, Nullable: true},
+	"created_at":  {Kind: Time},
+}
+
+// MaxSortKeys is the most keys a sort can have
+const MaxSortKeys = 8
+
+// Order sorts by one field
+type Order struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort parses a comma separated list of fields, each sorting descending
+// when prefixed with a minus, e.g. -delay,status. The first key sorts first.
+func ParseSort(s string) ([]Order, error) {
+	var orders []Order
+	seen := make(map[string]bool)
+	for _, key := range strings.Split(s, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		o := Order{Field: key}
+		switch key[0] {
+		case '-':
+			o.Field, o.Desc = key[1:], true
+		case '+':
+			o.Field = key[1:]
+		}
+		if _, ok := Fields[o.Field]; !ok {
+			return nil, fmt.Errorf("cannot sort by %q", o.Field)
+		}
+		if seen[o.Field] {
+			return nil, fmt.Errorf("%q is sorted on twice", o.Field)
+		}
+		seen[o.Field] = true
+		orders = append(orders, o)
+	}
+	if len(orders) > MaxSortKeys {
+		return nil, fmt.Errorf("cannot sort by more than %d fields", MaxSortKeys)
+	}
+	return orders, nil
+}
+
+// ParseList splits a comma separated list, dropping empty items
+func ParseList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// LoadLocation returns the time zone named by an IANA name such as
+// Asia/Tehran, or a fixed offset such as +03:30. Empty is UTC.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if name[0] == '+' || name[0] == '-' {
+		t, err := time.Parse("-07:00", name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q", name)
+		}
+		_, offset := t.Zone()
+		return time.FixedZone(name, offset), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q", name)
+	}
+	return loc, nil
+}
+
+// localLayouts are the timestamps without an offset, read in the query's time zone
+var localLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// ParseTime reads an RFC 3339 timestamp, a timestamp without an offset or a
+// date, the last two in loc. A date stands for its last second when endOfDay
+// is set, and for its start otherwise.
+func ParseTime(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return t, nil
+}
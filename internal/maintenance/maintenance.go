@@ -0,0 +1,280 @@
+// Package maintenance works out when maintenance windows are active.
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// maxOccurrences bounds how many starts of a recurring window are expanded for one range
+const maxOccurrences = 100000
+
+var weekdays = map[string]string{
+	"sun": "0", "mon": "1", "tue": "2", "wed": "3", "thu": "4", "fri": "5", "sat": "6",
+}
+
+// Interval is a half-open time range [Start, End)
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// locations caches loaded time zones, LoadLocation reads the zone database every time
+var locations sync.Map
+
+// Location returns the time zone of the window, UTC when unset
+func Location(w models.MaintenanceWindow) (*time.Location, error) {
+	if w.Timezone == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(w.Timezone); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(w.Timezone, loc)
+	return loc, nil
+}
+
+// CronSpec returns the cron expression a recurring window starts on
+func CronSpec(w models.MaintenanceWindow) (string, error) {
+	switch w.Kind {
+	case models.MaintenanceCron:
+		return w.Cron, nil
+	case models.MaintenanceWeekly:
+		clock, err := time.Parse("15:04", w.StartTime)
+		if err != nil {
+			return "", fmt.Errorf("start_time must be HH:MM")
+		}
+		var days []string
+		for _, day := range strings.Split(w.Weekdays, ",") {
+			day = strings.ToLower(strings.TrimSpace(day))
+			if len(day) > 3 {
+				day = day[:3]
+			}
+			n, ok := weekdays[day]
+			if !ok {
+				return "", fmt.Errorf("unknown weekday %q", day)
+			}
+			days = append(days, n)
+		}
+		return fmt.Sprintf("%d %d * * %s", clock.Minute(), clock.Hour(), strings.Join(days, ",")), nil
+	default:
+		return "", fmt.Errorf("window kind %q has no schedule", w.Kind)
+	}
+}
+
+// Validate checks that the window describes a usable schedule
+func Validate(w models.MaintenanceWindow) error {
+	if _, err := Location(w); err != nil {
+		return fmt.Errorf("unknown timezone %q", w.Timezone)
+	}
+
+	switch w.Kind {
+	case models.MaintenanceOnce:
+		if w.StartsAt == nil || w.EndsAt == nil {
+			return errors.New("starts_at and ends_at are required")
+		}
+		if !w.EndsAt.After(*w.StartsAt) {
+			return errors.New("ends_at must be after starts_at")
+		}
+		return nil
+	case models.MaintenanceCron, models.MaintenanceWeekly:
+		if w.Duration == 0 {
+			return errors.New("duration is required")
+		}
+		spec, err := CronSpec(w)
+		if err != nil {
+			return err
+		}
+		if _, err := cron.ParseStandard(spec); err != nil {
+			return fmt.Errorf("invalid cron expression: %v", err)
+		}
+		return nil
+	default:
+		return errors.New("kind must be once, cron or weekly")
+	}
+}
+
+// Intervals returns the times the window is active within [from, to), merged and sorted
+func Intervals(w models.MaintenanceWindow, from, to time.Time) []Interval {
+	if !to.After(from) {
+		return nil
+	}
+
+	if w.Kind == models.MaintenanceOnce {
+		if w.StartsAt == nil || w.EndsAt == nil {
+			return nil
+		}
+		return clip([]Interval{{Start: *w.StartsAt, End: *w.EndsAt}}, from, to)
+	}
+
+	schedule, loc, err := parse(w)
+	if err != nil {
+		return nil
+	}
+
+	duration := time.Duration(w.Duration) * time.Minute
+	var intervals []Interval
+	// Next returns the first start after t, so step back one duration to catch a
+	// window that began before from and is still running
+	t := from.Add(-duration).In(loc)
+	for i := 0; i < maxOccurrences; i++ {
+		start := schedule.Next(t)
+		if start.IsZero() || !start.Before(to) {
+			break
+		}
+		intervals = append(intervals, Interval{Start: start, End: start.Add(duration)})
+		t = start
+	}
+	return clip(intervals, from, to)
+}
+
+// ActiveAt reports whether the window covers t
+func ActiveAt(w models.MaintenanceWindow, t time.Time) bool {
+	if w.Kind == models.MaintenanceOnce {
+		return w.StartsAt != nil && w.EndsAt != nil && !t.Before(*w.StartsAt) && t.Before(*w.EndsAt)
+	}
+
+	schedule, loc, err := parse(w)
+	if err != nil {
+		return false
+	}
+	// The latest start at or before t is the first one after t - duration
+	duration := time.Duration(w.Duration) * time.Minute
+	start := schedule.Next(t.Add(-duration).In(loc))
+	return !start.IsZero() && !start.After(t)
+}
+
+func parse(w models.MaintenanceWindow) (cron.Schedule, *time.Location, error) {
+	spec, err := CronSpec(w)
+	if err != nil {
+		return nil, nil, err
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	loc, err := Location(w)
+	if err != nil {
+		return nil, nil, err
+	}
+	return schedule, loc, nil
+}
+
+// clip limits intervals to [from, to) and merges overlapping ones
+func clip(intervals []Interval, from, to time.Time) []Interval {
+	var out []Interval
+	for _, iv := range intervals {
+		if iv.Start.Before(from) {
+			iv.Start = from
+		}
+		if iv.End.After(to) {
+			iv.End = to
+		}
+		if iv.End.After(iv.Start) {
+			out = append(out, iv)
+		}
+	}
+	return Merge(out)
+}
+
+// Merge sorts intervals and joins the ones that overlap or touch
+func Merge(intervals []Interval) []Interval {
+	if len(intervals) < 2 {
+		return intervals
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start.Before(intervals[j].Start) })
+	out := []Interval{intervals[0]}
+	for _, iv := range intervals[1:] {
+		last := &out[len(out)-1]
+		if !iv.Start.After(last.End) {
+			if iv.End.After(last.End) {
+				last.End = iv.End
+			}
+			continue
+		}
+		out = append(out, iv)
+	}
+	return out
+}
+
+// Covers reports whether the window applies to a node. groupIDs are the
+// node's group and the groups it is nested in, so a window of a group covers
+// its subgroups.
+func Covers(w models.MaintenanceWindow, nodeID uint, groupIDs []uint) bool {
+	if len(w.NodeIDs) == 0 && len(w.GroupIDs) == 0 {
+		return true
+	}
+	if w.NodeIDs.Contains(nodeID) {
+		return true
+	}
+	for _, id := range groupIDs {
+		if w.GroupIDs.Contains(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Set holds the enabled windows and the nesting of the groups they cover
+type Set struct {
+	windows []models.MaintenanceWindow
+	groups  *models.GroupTree
+}
+
+// Load reads the enabled windows. On error the set is empty, so checks are
+// never silenced by accident.
+func Load() *Set {
+	s := &Set{}
+	if err := repositories.GetEnabledMaintenanceWindows(&s.windows); err != nil {
+		log.Printf("Error fetching maintenance windows: %v", err)
+	}
+	var groups []models.Group
+	if err := repositories.GetAllGroups(&groups); err != nil {
+		log.Printf("Error fetching groups: %v", err)
+	}
+	s.groups = models.NewGroupTree(groups)
+	return s
+}
+
+// Active reports whether a node is in maintenance at t
+func (s *Set) Active(nodeID uint, groupID *uint, t time.Time) bool {
+	if s == nil {
+		return false
+	}
+	groupIDs := s.groups.Ancestry(groupID)
+	for _, w := range s.windows {
+		if Covers(w, nodeID, groupIDs) && ActiveAt(w, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// For returns the merged maintenance intervals of a node within [from, to)
+func (s *Set) For(nodeID uint, groupID *uint, from, to time.Time) []Interval {
+	if s == nil {
+		return nil
+	}
+	groupIDs := s.groups.Ancestry(groupID)
+	var intervals []Interval
+	for _, w := range s.windows {
+		if Covers(w, nodeID, groupIDs) {
+			intervals = append(intervals, Intervals(w, from, to)...)
+		}
+	}
+	return Merge(intervals)
+}
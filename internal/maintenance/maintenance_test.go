@@ -0,0 +1,158 @@
+package maintenance
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"uptime/models"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
+
+// describe renders intervals in UTC for comparison
+func describe(intervals []Interval) string {
+	parts := make([]string, len(intervals))
+	for i, iv := range intervals {
+		parts[i] = iv.Start.UTC().Format("01-02 15:04") + "/" + iv.End.UTC().Format("01-02 15:04")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func TestIntervals(t *testing.T) {
+	once := models.MaintenanceWindow{Kind: models.MaintenanceOnce, StartsAt: ptr(at("2026-09-01T10:00:00Z")), EndsAt: ptr(at("2026-09-01T11:00:00Z"))}
+	// 2026-09-05 is a Saturday; 02:00 in Tehran is 22:30 UTC the day before
+	weekly := models.MaintenanceWindow{Kind: models.MaintenanceWeekly, Weekdays: "sat,Sunday", StartTime: "02:00", Duration: 90, Timezone: "Asia/Tehran"}
+	overlapping := models.MaintenanceWindow{Kind: models.MaintenanceCron, Cron: "*/30 * * * *", Duration: 45}
+	nightly := models.MaintenanceWindow{Kind: models.MaintenanceCron, Cron: "0 3 * * *", Duration: 60}
+
+	tests := []struct {
+		name     string
+		w        models.MaintenanceWindow
+		from, to string
+		want     string
+	}{
+		{name: "once inside", w: once, from: "2026-09-01T00:00:00Z", to: "2026-09-02T00:00:00Z", want: "09-01 10:00/09-01 11:00"},
+		{name: "once clipped", w: once, from: "2026-09-01T10:30:00Z", to: "2026-09-01T10:45:00Z", want: "09-01 10:30/09-01 10:45"},
+		{name: "once outside", w: once, from: "2026-09-01T11:00:00Z", to: "2026-09-02T00:00:00Z", want: ""},
+		{name: "once without an end", w: models.MaintenanceWindow{Kind: models.MaintenanceOnce, StartsAt: once.StartsAt}, from: "2026-09-01T00:00:00Z", to: "2026-09-02T00:00:00Z", want: ""},
+		{name: "weekly in a time zone", w: weekly, from: "2026-09-04T00:00:00Z", to: "2026-09-07T00:00:00Z", want: "09-04 22:30/09-05 00:00, 09-05 22:30/09-06 00:00"},
+		{name: "weekly already running at from", w: weekly, from: "2026-09-04T23:00:00Z", to: "2026-09-05T12:00:00Z", want: "09-04 23:00/09-05 00:00"},
+		{name: "overlapping occurrences merged", w: overlapping, from: "2026-09-01T10:00:00Z", to: "2026-09-01T12:00:00Z", want: "09-01 10:00/09-01 12:00"},
+		{name: "daily cron", w: nightly, from: "2026-09-01T00:00:00Z", to: "2026-09-03T03:30:00Z", want: "09-01 03:00/09-01 04:00, 09-02 03:00/09-02 04:00, 09-03 03:00/09-03 03:30"},
+		{name: "invalid cron", w: models.MaintenanceWindow{Kind: models.MaintenanceCron, Cron: "every night", Duration: 60}, from: "2026-09-01T00:00:00Z", to: "2026-09-02T00:00:00Z", want: ""},
+		{name: "empty range", w: nightly, from: "2026-09-02T00:00:00Z", to: "2026-09-01T00:00:00Z", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describe(Intervals(tt.w, at(tt.from), at(tt.to))); got != tt.want {
+				t.Errorf("Intervals() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveAt(t *testing.T) {
+	weekly := models.MaintenanceWindow{Kind: models.MaintenanceWeekly, Weekdays: "sat", StartTime: "02:00", Duration: 90, Timezone: "Asia/Tehran"}
+	once := models.MaintenanceWindow{Kind: models.MaintenanceOnce, StartsAt: ptr(at("2026-09-01T10:00:00Z")), EndsAt: ptr(at("2026-09-01T11:00:00Z"))}
+
+	tests := []struct {
+		w    models.MaintenanceWindow
+		t    string
+		want bool
+	}{
+		{weekly, "2026-09-04T22:29:59Z", false},
+		{weekly, "2026-09-04T22:30:00Z", true},
+		{weekly, "2026-09-04T23:59:59Z", true},
+		{weekly, "2026-09-05T00:00:00Z", false},
+		{weekly, "2026-09-05T22:45:00Z", false}, // Sunday
+		{once, "2026-09-01T10:00:00Z", true},
+		{once, "2026-09-01T11:00:00Z", false},
+	}
+	for _, tt := range tests {
+		if got := ActiveAt(tt.w, at(tt.t)); got != tt.want {
+			t.Errorf("ActiveAt(%s, %s) = %v, want %v", tt.w.Kind, tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	iv := func(start, end string) Interval {
+		return Interval{Start: at("2026-09-01T" + start + ":00Z"), End: at("2026-09-01T" + end + ":00Z")}
+	}
+	tests := []struct {
+		in   []Interval
+		want string
+	}{
+		{in: nil, want: ""},
+		{in: []Interval{iv("10:00", "11:00")}, want: "09-01 10:00/09-01 11:00"},
+		{in: []Interval{iv("12:00", "13:00"), iv("10:00", "11:00")}, want: "09-01 10:00/09-01 11:00, 09-01 12:00/09-01 13:00"},
+		{in: []Interval{iv("10:00", "11:00"), iv("11:00", "12:00")}, want: "09-01 10:00/09-01 12:00"},
+		{in: []Interval{iv("10:00", "13:00"), iv("11:00", "12:00")}, want: "09-01 10:00/09-01 13:00"},
+	}
+	for _, tt := range tests {
+		if got := describe(Merge(tt.in)); got != tt.want {
+			t.Errorf("Merge(%s) = %s, want %s", describe(tt.in), got, tt.want)
+		}
+	}
+}
+
+func TestCovers(t *testing.T) {
+	tests := []struct {
+		name     string
+		w        models.MaintenanceWindow
+		nodeID   uint
+		groupIDs []uint
+		want     bool
+	}{
+		{name: "unscoped", w: models.MaintenanceWindow{}, nodeID: 1, want: true},
+		{name: "listed node", w: models.MaintenanceWindow{NodeIDs: models.IDList{1, 2}}, nodeID: 2, want: true},
+		{name: "other node", w: models.MaintenanceWindow{NodeIDs: models.IDList{1}}, nodeID: 2, groupIDs: []uint{5}, want: false},
+		{name: "own group", w: models.MaintenanceWindow{GroupIDs: models.IDList{5}}, nodeID: 2, groupIDs: []uint{5, 1}, want: true},
+		{name: "parent group", w: models.MaintenanceWindow{GroupIDs: models.IDList{1}}, nodeID: 2, groupIDs: []uint{5, 1}, want: true},
+		{name: "ungrouped node", w: models.MaintenanceWindow{GroupIDs: models.IDList{1}}, nodeID: 2, want: false},
+	}
+	for _, tt := range tests {
+		if got := Covers(tt.w, tt.nodeID, tt.groupIDs); got != tt.want {
+			t.Errorf("%s: Covers() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		w   models.MaintenanceWindow
+		err string
+	}{
+		{w: models.MaintenanceWindow{Kind: models.MaintenanceWeekly, Weekdays: "mon,fri", StartTime: "23:30", Duration: 30, Timezone: "Asia/Tehran"}},
+		{w: models.MaintenanceWindow{Kind: models.MaintenanceCron, Cron: "0 3 * * *", Duration: 60}},
+		{w: models.MaintenanceWindow{Kind: models.MaintenanceOnce, StartsAt: ptr(at("2026-09-01T10:00:00Z")), EndsAt: ptr(at("2026-09-01T11:00:00Z"))}},
+		{w: models.MaintenanceWindow{Kind: models.MaintenanceOnce, StartsAt: ptr(at("2026-09-01T10:00:00Z")), EndsAt: ptr(at("2026-09-01T10:00:00Z"))}, err: "ends_at must be after starts_at"},
+		{w: models.MaintenanceWindow{Kind: models.MaintenanceOnce}, err: "starts_at and ends_at are required"},
+		{w: models.MaintenanceWindow{Kind: models.MaintenanceCron, Cron: "0 3 * * *"}, err: "duration is required"},
+		{w: models.MaintenanceWindow{Kind: models.MaintenanceCron, Cron: "0 25 * * *", Duration: 60}, err: "invalid cron expression"},
+		{w: models.MaintenanceWindow{Kind: models.MaintenanceWeekly, Weekdays: "funday", StartTime: "02:00", Duration: 60}, err: `unknown weekday "fun"`},
+		{w: models.MaintenanceWindow{Kind: models.MaintenanceWeekly, Weekdays: "sat", StartTime: "2am", Duration: 60}, err: "start_time must be HH:MM"},
+		{w: models.MaintenanceWindow{Kind: models.MaintenanceCron, Cron: "0 3 * * *", Duration: 60, Timezone: "Mars/Olympus"}, err: `unknown timezone "Mars/Olympus"`},
+		{w: models.MaintenanceWindow{Kind: "daily"}, err: "kind must be once, cron or weekly"},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.w)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("Validate(%+v) = %v, want nil", tt.w, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("Validate(%+v) = %v, want %q", tt.w, err, tt.err)
+		}
+	}
+}
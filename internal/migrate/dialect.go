@@ -0,0 +1,120 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Drivers are the database drivers migrations are kept for
+var Drivers = []string{"mysql", "postgres", "sqlite"}
+
+// lockName is the advisory lock held while migrating, so two instances
+// starting at once do not run the same migration twice
+const lockName = "uptime_schema_migrations"
+
+// lockKey is lockName as a PostgreSQL advisory lock key
+const lockKey = 7311950263
+
+// lockTimeout is how long a runner waits for another one to finish
+const lockTimeout = 5 * time.Minute
+
+// dialect holds what differs between drivers when running migrations
+type dialect struct {
+	createTable string
+	// begin starts a transaction around each migration, empty where DDL
+	// commits implicitly
+	begin  string
+	lock   func(ctx context.Context, conn *sql.Conn) error // nil where begin already serializes runners
+	unlock func(ctx context.Context, conn *sql.Conn)
+	bind   func(query string) string
+}
+
+var dialects = map[string]dialect{
+	"mysql": {
+		createTable: "CREATE TABLE IF NOT EXISTS schema_migrations (" +
+			"version bigint unsigned NOT NULL PRIMARY KEY, " +
+			"name varchar(255) NOT NULL, " +
+			"dirty boolean NOT NULL DEFAULT false, " +
+			"applied_at datetime(3) NOT NULL)",
+		lock: func(ctx context.Context, conn *sql.Conn) error {
+			var got sql.NullInt64
+			if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(lockTimeout.Seconds())).Scan(&got); err != nil {
+				return fmt.Errorf("failed to take the migration lock: %w", err)
+			}
+			if got.Int64 != 1 {
+				return fmt.Errorf("another migration did not finish within %v", lockTimeout)
+			}
+			return nil
+		},
+		unlock: func(ctx context.Context, conn *sql.Conn) {
+			conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", lockName)
+		},
+		bind: func(query string) string { return query },
+	},
+	"postgres": {
+		createTable: "CREATE TABLE IF NOT EXISTS schema_migrations (" +
+			"version bigint NOT NULL PRIMARY KEY, " +
+			"name varchar(255) NOT NULL, " +
+			"dirty boolean NOT NULL DEFAULT false, " +
+			"applied_at timestamptz NOT NULL)",
+		begin: "BEGIN",
+		lock: func(ctx context.Context, conn *sql.Conn) error {
+			deadline := time.Now().Add(lockTimeout)
+			for {
+				var got bool
+				if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockKey).Scan(&got); err != nil {
+					return fmt.Errorf("failed to take the migration lock: %w", err)
+				}
+				if got {
+					return nil
+				}
+				if time.Now().After(deadline) {
+					return fmt.Errorf("another migration did not finish within %v", lockTimeout)
+				}
+				time.Sleep(time.Second)
+			}
+		},
+		unlock: func(ctx context.Context, conn *sql.Conn) {
+			conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey)
+		},
+		bind: numbered,
+	},
+	// BEGIN IMMEDIATE takes the database write lock, so concurrent runners
+	// wait for each other one migration at a time
+	"sqlite": {
+		createTable: "CREATE TABLE IF NOT EXISTS schema_migrations (" +
+			"version integer NOT NULL PRIMARY KEY, " +
+			"name varchar(255) NOT NULL, " +
+			"dirty boolean NOT NULL DEFAULT false, " +
+			"applied_at datetime NOT NULL)",
+		begin: "BEGIN IMMEDIATE",
+		bind:  func(query string) string { return query },
+	},
+}
+
+func dialectFor(driver string) (dialect, error) {
+	d, ok := dialects[driver]
+	if !ok {
+		return dialect{}, fmt.Errorf("no migrations for driver %q", driver)
+	}
+	return d, nil
+}
+
+// numbered rewrites ? placeholders to PostgreSQL's $1, $2, ...
+func numbered(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,408 @@
+// Package migrate applies the versioned SQL migrations embedded in the binary
+// and records them in the schema_migrations table.
+//
+// Migrations live in migrations/<driver>/ as NNNN_name.up.sql and
+// NNNN_name.down.sql, with the same versions for every driver. Statements are
+// separated by a semicolon at the end of a line.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*/*.sql
+var files embed.FS
+
+var (
+	fileName  = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	separator = regexp.MustCompile(`;[ \t]*(\r?\n|$)`)
+)
+
+// Migration is one schema change and the statements undoing it
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is whether a migration has been applied
+type Status struct {
+	Version   uint64     `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+	Dirty     bool       `json:"dirty"` // it failed half way and the schema needs a manual fix
+}
+
+// Migrations returns the embedded migrations of a driver in version order
+func Migrations(driver string) ([]Migration, error) {
+	if _, err := dialectFor(driver); err != nil {
+		return nil, err
+	}
+	return load(files, "migrations/"+driver)
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, e := range entries {
+		m := fileName.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", e.Name())
+		}
+		version, _ := strconv.ParseUint(m[1], 10, 64)
+		body, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies the pending migrations in order, at most steps of them when
+// steps is positive, and returns the ones it applied
+func Up(db *sql.DB, driver string, steps int) ([]Migration, error) {
+	migrations, err := Migrations(driver)
+	if err != nil {
+		return nil, err
+	}
+	d, _ := dialectFor(driver)
+
+	var done []Migration
+	err = withLock(db, d, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(conn, d)
+		if err != nil {
+			return err
+		}
+		if err := checkClean(applied); err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if steps > 0 && len(done) == steps {
+				break
+			}
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			ran, err := apply(conn, d, m)
+			if err != nil {
+				return err
+			}
+			if ran {
+				done = append(done, m)
+			}
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rolls back the last steps applied migrations, newest first, and
+// returns the ones it rolled back
+func Down(db *sql.DB, driver string, steps int) ([]Migration, error) {
+	migrations, err := Migrations(driver)
+	if err != nil {
+		return nil, err
+	}
+	d, _ := dialectFor(driver)
+
+	var done []Migration
+	err = withLock(db, d, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(conn, d)
+		if err != nil {
+			return err
+		}
+		if err := checkClean(applied); err != nil {
+			return err
+		}
+		for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			if err := revert(conn, d, m); err != nil {
+				return err
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// GetStatus lists every embedded migration with the time it was applied.
+// Versions recorded in the database but missing from the binary come last.
+func GetStatus(db *sql.DB, driver string) ([]Status, error) {
+	migrations, err := Migrations(driver)
+	if err != nil {
+		return nil, err
+	}
+	d, _ := dialectFor(driver)
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(context.Background(), d.createTable); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(conn, d)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		s := Status{Version: m.Version, Name: m.Name}
+		if a, ok := applied[m.Version]; ok {
+			s.AppliedAt, s.Dirty = &a.AppliedAt, a.Dirty
+			delete(applied, m.Version)
+		}
+		statuses = append(statuses, s)
+	}
+
+	var unknown []Status
+	for _, a := range applied {
+		appliedAt := a.AppliedAt
+		unknown = append(unknown, Status{Version: a.Version, Name: a.Name, AppliedAt: &appliedAt, Dirty: a.Dirty})
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Version < unknown[j].Version })
+	statuses = append(statuses, unknown...)
+	return statuses, nil
+}
+
+// Create writes empty up and down files for a new migration into the driver
+// directories under dir, numbered after the highest version found there, and
+// returns their paths
+func Create(dir, name string) ([]string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(name, "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return nil, errors.New("migration name is required")
+	}
+
+	var version uint64 = 1
+	for _, driver := range Drivers {
+		migrations, err := load(os.DirFS(filepath.Join(dir, driver)), ".")
+		if err != nil {
+			return nil, err
+		}
+		if n := len(migrations); n > 0 && migrations[n-1].Version >= version {
+			version = migrations[n-1].Version + 1
+		}
+	}
+
+	var paths []string
+	for _, driver := range Drivers {
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(dir, driver, fmt.Sprintf("%04d_%s.%s.sql", version, name, direction))
+			if err := os.WriteFile(file, []byte(fmt.Sprintf("-- %s: %s\n", name, direction)), 0o644); err != nil {
+				return paths, err
+			}
+			paths = append(paths, file)
+		}
+	}
+	return paths, nil
+}
+
+// Statements splits a migration into the statements run one by one
+func Statements(body string) []string {
+	var statements []string
+	for _, part := range separator.Split(body, -1) {
+		var lines []string
+		for _, line := range strings.Split(part, "\n") {
+			if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "--") {
+				continue
+			}
+			lines = append(lines, line)
+		}
+		if stmt := strings.TrimSpace(strings.Join(lines, "\n")); stmt != "" {
+			statements = append(statements, strings.TrimSuffix(stmt, ";"))
+		}
+	}
+	return statements
+}
+
+type appliedMigration struct {
+	Version   uint64
+	Name      string
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+// withLock runs fn on a single connection holding the migration lock
+func withLock(db *sql.DB, d dialect, fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if d.lock != nil {
+		if err := d.lock(ctx, conn); err != nil {
+			return err
+		}
+		defer d.unlock(ctx, conn)
+	}
+
+	if _, err := conn.ExecContext(ctx, d.createTable); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// appliedVersions loads the recorded migrations by version
+func appliedVersions(conn *sql.Conn, d dialect) (map[uint64]appliedMigration, error) {
+	rows, err := conn.QueryContext(context.Background(), "SELECT version, name, dirty, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[uint64]appliedMigration)
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Dirty, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[a.Version] = a
+	}
+	return applied, rows.Err()
+}
+
+// checkClean fails when a migration was left half applied
+func checkClean(applied map[uint64]appliedMigration) error {
+	for _, a := range applied {
+		if a.Dirty {
+			return fmt.Errorf("migration %d_%s is dirty: fix the schema by hand, then delete its row from schema_migrations", a.Version, a.Name)
+		}
+	}
+	return nil
+}
+
+// apply runs the up statements of m and reports whether it did. On drivers
+// with transactional DDL the migration and its row are committed together.
+// MySQL commits DDL implicitly, so there the row is written dirty first and
+// a failure leaves it for a manual fix.
+func apply(conn *sql.Conn, d dialect, m Migration) (bool, error) {
+	ctx := context.Background()
+	if d.begin == "" {
+		if _, err := conn.ExecContext(ctx, d.bind("INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES (?, ?, ?, ?)"),
+			m.Version, m.Name, true, time.Now()); err != nil {
+			return false, err
+		}
+		if err := run(conn, m.Up); err != nil {
+			return false, fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		_, err := conn.ExecContext(ctx, d.bind("UPDATE schema_migrations SET dirty = ?, applied_at = ? WHERE version = ?"), false, time.Now(), m.Version)
+		return err == nil, err
+	}
+
+	ran := false
+	err := inTransaction(conn, d, func() error {
+		// a runner without a lock may have applied it meanwhile
+		var count int
+		if err := conn.QueryRowContext(ctx, d.bind("SELECT COUNT(*) FROM schema_migrations WHERE version = ?"), m.Version).Scan(&count); err != nil || count > 0 {
+			return err
+		}
+		if err := run(conn, m.Up); err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		if _, err := conn.ExecContext(ctx, d.bind("INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES (?, ?, ?, ?)"),
+			m.Version, m.Name, false, time.Now()); err != nil {
+			return err
+		}
+		ran = true
+		return nil
+	})
+	return ran, err
+}
+
+// revert runs the down statements of m and forgets it
+func revert(conn *sql.Conn, d dialect, m Migration) error {
+	ctx := context.Background()
+	if strings.TrimSpace(m.Down) == "" {
+		return fmt.Errorf("migration %d_%s cannot be rolled back", m.Version, m.Name)
+	}
+
+	down := func() error {
+		if err := run(conn, m.Down); err != nil {
+			return fmt.Errorf("rolling back migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		_, err := conn.ExecContext(ctx, d.bind("DELETE FROM schema_migrations WHERE version = ?"), m.Version)
+		return err
+	}
+	if d.begin != "" {
+		return inTransaction(conn, d, down)
+	}
+
+	if _, err := conn.ExecContext(ctx, d.bind("UPDATE schema_migrations SET dirty = ? WHERE version = ?"), true, m.Version); err != nil {
+		return err
+	}
+	return down()
+}
+
+// run executes the statements of a migration one by one
+func run(conn *sql.Conn, body string) error {
+	for _, stmt := range Statements(body) {
+		if _, err := conn.ExecContext(context.Background(), stmt); err != nil {
+			return fmt.Errorf("%w\n%s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// inTransaction runs fn between the dialect's BEGIN and a COMMIT, or a
+// ROLLBACK when fn fails. The statements are issued on conn directly so fn
+// can keep using it.
+func inTransaction(conn *sql.Conn, d dialect, fn func() error) error {
+	ctx := context.Background()
+	if _, err := conn.ExecContext(ctx, d.begin); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		conn.ExecContext(ctx, "ROLLBACK")
+		return err
+	}
+	_, err := conn.ExecContext(ctx, "COMMIT")
+	return err
+}
@@ -0,0 +1,272 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/glebarez/go-sqlite"
+)
+
+func TestStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "empty", body: "", want: nil},
+		{name: "only comments", body: "-- nothing to do\n\n  -- really\n", want: nil},
+		{
+			name: "one per line end",
+			body: "CREATE TABLE a (id int);\nCREATE INDEX idx_a ON a (id);\n",
+			want: []string{"CREATE TABLE a (id int)", "CREATE INDEX idx_a ON a (id)"},
+		},
+		{
+			name: "multi line with comments",
+			body: "-- the table\nCREATE TABLE a (\n  id int, -- key\n  -- dropped later\n  name text\n);  \n\nDROP TABLE b;",
+			want: []string{"CREATE TABLE a (\n  id int, -- key\n  name text\n)", "DROP TABLE b"},
+		},
+		{
+			name: "semicolon within a line",
+			body: "INSERT INTO a (name) VALUES ('x;y');\r\nUPDATE a SET name = 'z'",
+			want: []string{"INSERT INTO a (name) VALUES ('x;y')", "UPDATE a SET name = 'z'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Statements(tt.body)
+			if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+				t.Errorf("Statements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0010_tenth.up.sql":       {Data: []byte("up 10")},
+		"m/0002_second.up.sql":      {Data: []byte("up 2")},
+		"m/0002_second.down.sql":    {Data: []byte("down 2")},
+		"m/0001_first.up.sql":       {Data: []byte("up 1")},
+		"m/0001_first.down.sql":     {Data: []byte("down 1")},
+		"bad/0001_first.up.sql":     {Data: []byte("up 1")},
+		"bad/0001_renamed.down.sql": {Data: []byte("down 1")},
+		"odd/0001_first.sql":        {Data: []byte("up 1")},
+	}
+
+	migrations, err := load(fsys, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range migrations {
+		got = append(got, fmt.Sprintf("%d %s %q %q", m.Version, m.Name, m.Up, m.Down))
+	}
+	want := []string{`1 first "up 1" "down 1"`, `2 second "up 2" "down 2"`, `10 tenth "up 10" ""`}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("load() = %v, want %v", got, want)
+	}
+
+	if _, err := load(fsys, "bad"); err == nil || !strings.Contains(err.Error(), "two names") {
+		t.Errorf("load() of mismatched names = %v", err)
+	}
+	if _, err := load(fsys, "odd"); err == nil || !strings.Contains(err.Error(), "unexpected migration file") {
+		t.Errorf("load() of a misnamed file = %v", err)
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	// every driver has the same versions, each with both directions
+	var versions string
+	for _, driver := range Drivers {
+		migrations, err := Migrations(driver)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for i, m := range migrations {
+			if m.Version != uint64(i+1) {
+				t.Errorf("%s: migration %d_%s out of sequence", driver, m.Version, m.Name)
+			}
+			if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+				t.Errorf("%s: migration %d_%s lacks an up or down file", driver, m.Version, m.Name)
+			}
+			names = append(names, fmt.Sprintf("%d_%s", m.Version, m.Name))
+		}
+		if versions == "" {
+			versions = fmt.Sprint(names)
+		} else if fmt.Sprint(names) != versions {
+			t.Errorf("%s migrations = %v, want %s", driver, names, versions)
+		}
+	}
+	if _, err := Migrations("oracle"); err == nil {
+		t.Error("Migrations() of an unknown driver succeeded")
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	for _, driver := range Drivers {
+		if err := os.Mkdir(filepath.Join(dir, driver), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the next version follows the highest of any driver
+	for _, name := range []string{"0001_first.up.sql", "0003_third.up.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, "postgres", name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := Create(dir, "  Add Users' table!  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for _, driver := range Drivers {
+		for _, direction := range []string{"up", "down"} {
+			want = append(want, filepath.Join(dir, driver, "0004_add_users_table."+direction+".sql"))
+		}
+	}
+	if fmt.Sprint(paths) != fmt.Sprint(want) {
+		t.Errorf("Create() = %v, want %v", paths, want)
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(p); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, err := Create(dir, " !? "); err == nil {
+		t.Error("Create() without a name succeeded")
+	}
+}
+
+// openSQLite opens a SQLite database file in a temporary directory; every
+// call with the same dir opens its own pool on the same file
+func openSQLite(t *testing.T, dir string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "uptime.db")+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func versions(migrations []Migration) string {
+	out := make([]uint64, len(migrations))
+	for i, m := range migrations {
+		out[i] = m.Version
+	}
+	return fmt.Sprint(out)
+}
+
+func TestUpDown(t *testing.T) {
+	db := openSQLite(t, t.TempDir())
+	all, err := Migrations("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := len(all)
+
+	applied, err := Up(db, "sqlite", 2)
+	if err != nil || versions(applied) != "[1 2]" {
+		t.Fatalf("Up(2) = %s, %v, want [1 2]", versions(applied), err)
+	}
+	applied, err = Up(db, "sqlite", 0)
+	if err != nil || versions(applied) != versions(all[2:]) {
+		t.Fatalf("Up(0) = %s, %v, want %s", versions(applied), err, versions(all[2:]))
+	}
+	if applied, err := Up(db, "sqlite", 0); err != nil || len(applied) != 0 {
+		t.Errorf("Up() when up to date = %s, %v", versions(applied), err)
+	}
+
+	statuses, err := GetStatus(db, "sqlite")
+	if err != nil || len(statuses) != n {
+		t.Fatalf("GetStatus() = %d statuses, %v, want %d", len(statuses), err, n)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt == nil || s.Dirty {
+			t.Errorf("status of %d_%s = %+v, want applied", s.Version, s.Name, s)
+		}
+	}
+
+	// Down rolls back the newest first
+	reverted, err := Down(db, "sqlite", 2)
+	want := fmt.Sprint([]uint64{all[n-1].Version, all[n-2].Version})
+	if err != nil || versions(reverted) != want {
+		t.Fatalf("Down(2) = %s, %v, want %s", versions(reverted), err, want)
+	}
+	statuses, _ = GetStatus(db, "sqlite")
+	if statuses[n-3].AppliedAt == nil || statuses[n-2].AppliedAt != nil || statuses[n-1].AppliedAt != nil {
+		t.Errorf("after Down(2) statuses = %+v", statuses)
+	}
+
+	// everything rolls back and applies again
+	if reverted, err := Down(db, "sqlite", n); err != nil || len(reverted) != n-2 {
+		t.Fatalf("Down(%d) = %s, %v", n, versions(reverted), err)
+	}
+	var tables int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')").Scan(&tables); err != nil || tables != 0 {
+		t.Errorf("%d tables left after rolling back everything, %v", tables, err)
+	}
+	if applied, err := Up(db, "sqlite", 0); err != nil || len(applied) != n {
+		t.Errorf("Up() after rolling back = %s, %v", versions(applied), err)
+	}
+
+	// a migration left half applied stops every runner
+	if _, err := db.Exec("UPDATE schema_migrations SET dirty = true WHERE version = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Up(db, "sqlite", 0); err == nil || !strings.Contains(err.Error(), "is dirty") {
+		t.Errorf("Up() with a dirty migration = %v", err)
+	}
+	if _, err := Down(db, "sqlite", 1); err == nil || !strings.Contains(err.Error(), "is dirty") {
+		t.Errorf("Down() with a dirty migration = %v", err)
+	}
+}
+
+func TestUpConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	all, err := Migrations("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// runners starting at once wait for each other and apply each migration once
+	const runners = 4
+	results := make(chan []Migration, runners)
+	errs := make(chan error, runners)
+	for i := 0; i < runners; i++ {
+		db := openSQLite(t, dir)
+		go func() {
+			applied, err := Up(db, "sqlite", 0)
+			results <- applied
+			errs <- err
+		}()
+	}
+	count := make(map[uint64]int)
+	for i := 0; i < runners; i++ {
+		for _, m := range <-results {
+			count[m.Version]++
+		}
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+	for _, m := range all {
+		if count[m.Version] != 1 {
+			t.Errorf("migration %d_%s applied %d times", m.Version, m.Name, count[m.Version])
+		}
+	}
+
+	var rows int
+	if err := openSQLite(t, dir).QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&rows); err != nil || rows != len(all) {
+		t.Errorf("%d rows in schema_migrations, %v, want %d", rows, err, len(all))
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `histories`;
+DROP TABLE IF EXISTS `node_logs`;
+DROP TABLE IF EXISTS `nodes`;
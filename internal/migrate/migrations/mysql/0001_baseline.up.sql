@@ -0,0 +1,47 @@
+-- Schema of the original release, including the report indexes optimize.Run
+-- used to recreate on every start. Existing databases already have these
+-- tables and indexes, so they are only created when missing.
+CREATE TABLE IF NOT EXISTS `nodes` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `url` varchar(255),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_nodes_url` (`url`)
+);
+
+CREATE TABLE IF NOT EXISTS `node_logs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `node_id` bigint unsigned,
+  `delay` double,
+  `status` bigint unsigned,
+  `up` boolean DEFAULT false,
+  `suspended` boolean DEFAULT false,
+  `exception` longtext,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_node_logs_node_id` (`node_id`),
+  INDEX `idx_node_logs_node_id_created_at` (`node_id`, `created_at` DESC),
+  INDEX `idx_node_logs_node_id_id` (`node_id`, `id` DESC),
+  INDEX `idx_node_logs_status` (`status`),
+  INDEX `idx_node_logs_node_id_status` (`node_id`, `status`),
+  INDEX `idx_node_logs_up` (`up`),
+  INDEX `idx_node_logs_composite` (`node_id`, `created_at` DESC, `status`),
+  INDEX `idx_node_logs_created_at` (`created_at` DESC),
+  INDEX `idx_node_logs_uptime` (`node_id`, `up`, `created_at` DESC)
+);
+
+CREATE TABLE IF NOT EXISTS `histories` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `node_id` bigint unsigned,
+  `delay` double,
+  `status` bigint unsigned,
+  `up` boolean DEFAULT false,
+  `suspended` boolean DEFAULT false,
+  `exception` longtext,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_histories_node_id` (`node_id`)
+);
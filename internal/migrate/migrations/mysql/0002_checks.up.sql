@@ -0,0 +1,82 @@
+ALTER TABLE `nodes`
+  ADD COLUMN `type` varchar(10) DEFAULT 'http',
+  ADD COLUMN `method` varchar(10) DEFAULT 'GET',
+  ADD COLUMN `headers` text,
+  ADD COLUMN `body` text,
+  ADD COLUMN `expected_status` varchar(255),
+  ADD COLUMN `timeout` bigint unsigned DEFAULT 0,
+  ADD COLUMN `skip_default_rules` boolean DEFAULT false,
+  ADD COLUMN `expect` text,
+  ADD COLUMN `record_type` varchar(10),
+  ADD COLUMN `resolver` varchar(255);
+
+ALTER TABLE `node_logs`
+  ADD COLUMN `degraded` boolean DEFAULT false,
+  ADD COLUMN `attempts` bigint unsigned DEFAULT 1,
+  ADD COLUMN `unconfirmed` boolean DEFAULT false,
+  ADD COLUMN `dns_time` double,
+  ADD COLUMN `connect_time` double,
+  ADD COLUMN `tls_time` double,
+  ADD COLUMN `ttfb` double,
+  ADD COLUMN `transfer_time` double,
+  ADD COLUMN `response_size` bigint;
+
+ALTER TABLE `histories`
+  ADD COLUMN `degraded` boolean DEFAULT false,
+  ADD COLUMN `failure_streak` bigint unsigned DEFAULT 0;
+
+CREATE TABLE `assertion_rules` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `node_id` bigint unsigned,
+  `name` varchar(255),
+  `type` varchar(20),
+  `target` varchar(255),
+  `value` text,
+  `ignore_case` boolean DEFAULT false,
+  `severity` varchar(20) DEFAULT 'down',
+  `enabled` boolean DEFAULT true,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_assertion_rules_node_id` (`node_id`)
+);
+
+-- Global default rules, reproducing the suspended-page and directory-listing
+-- detection the checker used to hard-code
+INSERT INTO `assertion_rules` (`name`, `type`, `target`, `value`, `ignore_case`, `severity`, `enabled`, `created_at`, `updated_at`) VALUES
+  ('suspended page', 'not_contains', '', 'suspended', true, 'suspended', true, NOW(3), NOW(3)),
+  ('blocked page (fa)', 'not_contains', '', 'مسدود', true, 'suspended', true, NOW(3), NOW(3)),
+  ('directory listing', 'not_contains', '', 'Index of /', false, 'down', true, NOW(3), NOW(3)),
+  ('litespeed default page', 'not_contains', '', 'proudly served by litespeed web server', true, 'down', true, NOW(3), NOW(3));
+
+CREATE TABLE `node_certificates` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `node_id` bigint unsigned,
+  `subject` varchar(512),
+  `issuer` varchar(512),
+  `sans` text,
+  `not_before` datetime(3) NULL,
+  `not_after` datetime(3) NULL,
+  `chain_valid` boolean DEFAULT false,
+  `chain_error` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_node_certificates_node_id` (`node_id`),
+  INDEX `idx_node_certificates_not_after` (`not_after`)
+);
+
+CREATE TABLE `check_attempts` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `node_id` bigint unsigned,
+  `node_log_id` bigint unsigned,
+  `attempt` bigint unsigned,
+  `delay` double,
+  `status` bigint unsigned,
+  `up` boolean DEFAULT false,
+  `exception` longtext,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_check_attempts_node_id` (`node_id`),
+  INDEX `idx_check_attempts_node_log_id` (`node_log_id`)
+);
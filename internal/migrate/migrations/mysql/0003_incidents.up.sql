@@ -0,0 +1,31 @@
+CREATE TABLE `incidents` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `node_id` bigint unsigned,
+  `status` varchar(20) DEFAULT 'open',
+  `state` varchar(20),
+  `started_at` datetime(3) NULL,
+  `resolved_at` datetime(3) NULL,
+  `duration` double,
+  `first_error` text,
+  `trigger_log_id` bigint unsigned,
+  `resolve_log_id` bigint unsigned,
+  `acknowledged` boolean DEFAULT false,
+  `acknowledged_at` datetime(3) NULL,
+  `acknowledged_by` varchar(255),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_incidents_node_id` (`node_id`),
+  INDEX `idx_incidents_status` (`status`),
+  INDEX `idx_incidents_started_at` (`started_at`)
+);
+
+CREATE TABLE `incident_notes` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `incident_id` bigint unsigned,
+  `author` varchar(255),
+  `body` text,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_incident_notes_incident_id` (`incident_id`)
+);
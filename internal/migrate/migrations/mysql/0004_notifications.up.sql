@@ -0,0 +1,89 @@
+CREATE TABLE `webhooks` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255),
+  `url` varchar(1024),
+  `secret` varchar(255),
+  `events` varchar(255),
+  `max_attempts` bigint unsigned DEFAULT 5,
+  `enabled` boolean DEFAULT true,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `notification_deliveries` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `channel` varchar(20),
+  `target_id` bigint unsigned,
+  `node_id` bigint unsigned,
+  `event` varchar(20),
+  `payload` text,
+  `status` varchar(20) DEFAULT 'pending',
+  `attempts` bigint unsigned,
+  `status_code` bigint,
+  `last_error` text,
+  `delivered_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_deliveries_target` (`channel`, `target_id`),
+  INDEX `idx_notification_deliveries_node_id` (`node_id`),
+  INDEX `idx_notification_deliveries_status` (`status`),
+  INDEX `idx_notification_deliveries_created_at` (`created_at`)
+);
+
+CREATE TABLE `node_groups` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255),
+  `description` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_node_groups_name` (`name`)
+);
+
+ALTER TABLE `nodes`
+  ADD COLUMN `group_id` bigint unsigned,
+  ADD INDEX `idx_nodes_group_id` (`group_id`);
+
+CREATE TABLE `email_channels` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255),
+  `host` varchar(255),
+  `port` bigint unsigned,
+  `username` varchar(255),
+  `password` varchar(255),
+  `tls_mode` varchar(10) DEFAULT 'starttls',
+  `skip_verify` boolean DEFAULT false,
+  `from` varchar(255),
+  `to` text,
+  `node_ids` text,
+  `group_ids` text,
+  `events` varchar(255),
+  `subject_template` text,
+  `text_template` text,
+  `html_template` text,
+  `max_attempts` bigint unsigned DEFAULT 3,
+  `enabled` boolean DEFAULT true,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `chat_channels` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255),
+  `kind` varchar(20),
+  `bot_token` varchar(255),
+  `chat_id` varchar(255),
+  `webhook_url` varchar(1024),
+  `api_base_url` varchar(255),
+  `node_ids` text,
+  `group_ids` text,
+  `events` varchar(255),
+  `max_attempts` bigint unsigned DEFAULT 3,
+  `enabled` boolean DEFAULT true,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`)
+);
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS `maintenance_windows`;
+
+ALTER TABLE `histories`
+  DROP COLUMN `maintenance`;
+
+ALTER TABLE `node_logs`
+  DROP INDEX `idx_node_logs_cycle_id`,
+  DROP COLUMN `cycle_id`,
+  DROP COLUMN `maintenance`;
+
+ALTER TABLE `nodes`
+  DROP COLUMN `interval`;
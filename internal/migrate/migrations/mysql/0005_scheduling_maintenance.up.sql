@@ -0,0 +1,30 @@
+ALTER TABLE `nodes`
+  ADD COLUMN `interval` bigint unsigned DEFAULT 0;
+
+ALTER TABLE `node_logs`
+  ADD COLUMN `maintenance` boolean DEFAULT false,
+  ADD COLUMN `cycle_id` varchar(32),
+  ADD INDEX `idx_node_logs_cycle_id` (`cycle_id`);
+
+ALTER TABLE `histories`
+  ADD COLUMN `maintenance` boolean DEFAULT false;
+
+CREATE TABLE `maintenance_windows` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255),
+  `description` text,
+  `kind` varchar(10),
+  `starts_at` datetime(3) NULL,
+  `ends_at` datetime(3) NULL,
+  `cron` varchar(255),
+  `weekdays` varchar(64),
+  `start_time` varchar(5),
+  `duration` bigint unsigned,
+  `timezone` varchar(64),
+  `node_ids` text,
+  `group_ids` text,
+  `enabled` boolean DEFAULT true,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`)
+);
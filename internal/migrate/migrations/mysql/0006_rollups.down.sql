@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `rollup_states`;
+DROP TABLE IF EXISTS `node_log_daily`;
+DROP TABLE IF EXISTS `node_log_hourly`;
@@ -0,0 +1,60 @@
+CREATE TABLE `node_log_hourly` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `node_id` bigint unsigned,
+  `bucket_start` datetime(3) NULL,
+  `bucket_end` datetime(3) NULL,
+  `checks` bigint unsigned,
+  `up_count` bigint unsigned,
+  `down_count` bigint unsigned,
+  `suspended_count` bigint unsigned,
+  `maintenance_count` bigint unsigned,
+  `min_delay` double,
+  `avg_delay` double,
+  `max_delay` double,
+  `p95_delay` double,
+  `status_codes` text,
+  `delay_buckets` text,
+  `up_time` double,
+  `down_time` double,
+  `suspended_time` double,
+  `maintenance_time` double,
+  `unknown_time` double,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_node_log_hourly_node_bucket` (`node_id`, `bucket_start`)
+);
+
+CREATE TABLE `node_log_daily` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `node_id` bigint unsigned,
+  `bucket_start` datetime(3) NULL,
+  `bucket_end` datetime(3) NULL,
+  `checks` bigint unsigned,
+  `up_count` bigint unsigned,
+  `down_count` bigint unsigned,
+  `suspended_count` bigint unsigned,
+  `maintenance_count` bigint unsigned,
+  `min_delay` double,
+  `avg_delay` double,
+  `max_delay` double,
+  `p95_delay` double,
+  `status_codes` text,
+  `delay_buckets` text,
+  `up_time` double,
+  `down_time` double,
+  `suspended_time` double,
+  `maintenance_time` double,
+  `unknown_time` double,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_node_log_daily_node_bucket` (`node_id`, `bucket_start`)
+);
+
+CREATE TABLE `rollup_states` (
+  `tier` varchar(20),
+  `completed_until` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`tier`)
+);
@@ -0,0 +1,6 @@
+ALTER TABLE `node_groups`
+  ADD COLUMN `retention_raw_logs` bigint unsigned,
+  ADD COLUMN `retention_hourly_rollups` bigint unsigned,
+  ADD COLUMN `retention_daily_rollups` bigint unsigned,
+  ADD COLUMN `retention_incidents` bigint unsigned,
+  ADD COLUMN `retention_deliveries` bigint unsigned;
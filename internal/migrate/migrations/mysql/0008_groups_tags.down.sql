@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS `node_tags`;
+
+DROP TABLE IF EXISTS `tags`;
+
+ALTER TABLE `node_groups`
+  DROP INDEX `idx_node_groups_parent_id`,
+  DROP COLUMN `parent_id`;
@@ -0,0 +1,20 @@
+ALTER TABLE `node_groups`
+  ADD COLUMN `parent_id` bigint unsigned,
+  ADD INDEX `idx_node_groups_parent_id` (`parent_id`);
+
+CREATE TABLE `tags` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(64),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_tags_name` (`name`)
+);
+
+CREATE TABLE `node_tags` (
+  `node_id` bigint unsigned,
+  `tag_id` bigint unsigned,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`node_id`, `tag_id`),
+  INDEX `idx_node_tags_tag_id` (`tag_id`)
+);
@@ -0,0 +1,6 @@
+ALTER TABLE `nodes`
+  DROP INDEX `idx_nodes_deleted_at`,
+  DROP INDEX `idx_nodes_lifecycle`,
+  DROP COLUMN `deleted_at`,
+  DROP COLUMN `paused_until`,
+  DROP COLUMN `lifecycle`;
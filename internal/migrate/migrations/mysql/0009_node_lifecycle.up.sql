@@ -0,0 +1,6 @@
+ALTER TABLE `nodes`
+  ADD COLUMN `lifecycle` varchar(16) DEFAULT 'active',
+  ADD COLUMN `paused_until` datetime(3) NULL,
+  ADD COLUMN `deleted_at` datetime(3) NULL,
+  ADD INDEX `idx_nodes_lifecycle` (`lifecycle`),
+  ADD INDEX `idx_nodes_deleted_at` (`deleted_at`);
@@ -0,0 +1 @@
+UPDATE `nodes` SET `method` = 'GET' WHERE `method` = '';
@@ -0,0 +1,42 @@
+-- Schema of the original release, including the report indexes of node_logs
+CREATE TABLE "nodes" (
+  "id" bigserial PRIMARY KEY,
+  "url" varchar(255),
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL
+);
+CREATE UNIQUE INDEX "idx_nodes_url" ON "nodes" ("url");
+
+CREATE TABLE "node_logs" (
+  "id" bigserial PRIMARY KEY,
+  "node_id" bigint,
+  "delay" double precision,
+  "status" bigint,
+  "up" boolean DEFAULT false,
+  "suspended" boolean DEFAULT false,
+  "exception" text,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL
+);
+CREATE INDEX "idx_node_logs_node_id" ON "node_logs" ("node_id");
+CREATE INDEX "idx_node_logs_node_id_created_at" ON "node_logs" ("node_id", "created_at" DESC);
+CREATE INDEX "idx_node_logs_node_id_id" ON "node_logs" ("node_id", "id" DESC);
+CREATE INDEX "idx_node_logs_status" ON "node_logs" ("status");
+CREATE INDEX "idx_node_logs_node_id_status" ON "node_logs" ("node_id", "status");
+CREATE INDEX "idx_node_logs_up" ON "node_logs" ("up");
+CREATE INDEX "idx_node_logs_composite" ON "node_logs" ("node_id", "created_at" DESC, "status");
+CREATE INDEX "idx_node_logs_created_at" ON "node_logs" ("created_at" DESC);
+CREATE INDEX "idx_node_logs_uptime" ON "node_logs" ("node_id", "up", "created_at" DESC);
+
+CREATE TABLE "histories" (
+  "id" bigserial PRIMARY KEY,
+  "node_id" bigint,
+  "delay" double precision,
+  "status" bigint,
+  "up" boolean DEFAULT false,
+  "suspended" boolean DEFAULT false,
+  "exception" text,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL
+);
+CREATE INDEX "idx_histories_node_id" ON "histories" ("node_id");
@@ -0,0 +1,32 @@
+DROP TABLE IF EXISTS "check_attempts";
+
+DROP TABLE IF EXISTS "node_certificates";
+
+DROP TABLE IF EXISTS "assertion_rules";
+
+ALTER TABLE "histories"
+  DROP COLUMN "failure_streak",
+  DROP COLUMN "degraded";
+
+ALTER TABLE "node_logs"
+  DROP COLUMN "response_size",
+  DROP COLUMN "transfer_time",
+  DROP COLUMN "ttfb",
+  DROP COLUMN "tls_time",
+  DROP COLUMN "connect_time",
+  DROP COLUMN "dns_time",
+  DROP COLUMN "unconfirmed",
+  DROP COLUMN "attempts",
+  DROP COLUMN "degraded";
+
+ALTER TABLE "nodes"
+  DROP COLUMN "resolver",
+  DROP COLUMN "record_type",
+  DROP COLUMN "expect",
+  DROP COLUMN "skip_default_rules",
+  DROP COLUMN "timeout",
+  DROP COLUMN "expected_status",
+  DROP COLUMN "body",
+  DROP COLUMN "headers",
+  DROP COLUMN "method",
+  DROP COLUMN "type";
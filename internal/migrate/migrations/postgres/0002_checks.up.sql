@@ -0,0 +1,79 @@
+ALTER TABLE "nodes"
+  ADD COLUMN "type" varchar(10) DEFAULT 'http',
+  ADD COLUMN "method" varchar(10) DEFAULT 'GET',
+  ADD COLUMN "headers" text,
+  ADD COLUMN "body" text,
+  ADD COLUMN "expected_status" varchar(255),
+  ADD COLUMN "timeout" bigint DEFAULT 0,
+  ADD COLUMN "skip_default_rules" boolean DEFAULT false,
+  ADD COLUMN "expect" text,
+  ADD COLUMN "record_type" varchar(10),
+  ADD COLUMN "resolver" varchar(255);
+
+ALTER TABLE "node_logs"
+  ADD COLUMN "degraded" boolean DEFAULT false,
+  ADD COLUMN "attempts" bigint DEFAULT 1,
+  ADD COLUMN "unconfirmed" boolean DEFAULT false,
+  ADD COLUMN "dns_time" double precision,
+  ADD COLUMN "connect_time" double precision,
+  ADD COLUMN "tls_time" double precision,
+  ADD COLUMN "ttfb" double precision,
+  ADD COLUMN "transfer_time" double precision,
+  ADD COLUMN "response_size" bigint;
+
+ALTER TABLE "histories"
+  ADD COLUMN "degraded" boolean DEFAULT false,
+  ADD COLUMN "failure_streak" bigint DEFAULT 0;
+
+CREATE TABLE "assertion_rules" (
+  "id" bigserial PRIMARY KEY,
+  "node_id" bigint,
+  "name" varchar(255),
+  "type" varchar(20),
+  "target" varchar(255),
+  "value" text,
+  "ignore_case" boolean DEFAULT false,
+  "severity" varchar(20) DEFAULT 'down',
+  "enabled" boolean DEFAULT true,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL
+);
+CREATE INDEX "idx_assertion_rules_node_id" ON "assertion_rules" ("node_id");
+
+-- Global default rules, reproducing the suspended-page and directory-listing
+-- detection the checker used to hard-code
+INSERT INTO "assertion_rules" ("name", "type", "target", "value", "ignore_case", "severity", "enabled", "created_at", "updated_at") VALUES
+  ('suspended page', 'not_contains', '', 'suspended', true, 'suspended', true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+  ('blocked page (fa)', 'not_contains', '', 'مسدود', true, 'suspended', true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+  ('directory listing', 'not_contains', '', 'Index of /', false, 'down', true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+  ('litespeed default page', 'not_contains', '', 'proudly served by litespeed web server', true, 'down', true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+CREATE TABLE "node_certificates" (
+  "id" bigserial PRIMARY KEY,
+  "node_id" bigint,
+  "subject" varchar(512),
+  "issuer" varchar(512),
+  "sans" text,
+  "not_before" timestamptz NULL,
+  "not_after" timestamptz NULL,
+  "chain_valid" boolean DEFAULT false,
+  "chain_error" text,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL
+);
+CREATE UNIQUE INDEX "idx_node_certificates_node_id" ON "node_certificates" ("node_id");
+CREATE INDEX "idx_node_certificates_not_after" ON "node_certificates" ("not_after");
+
+CREATE TABLE "check_attempts" (
+  "id" bigserial PRIMARY KEY,
+  "node_id" bigint,
+  "node_log_id" bigint,
+  "attempt" bigint,
+  "delay" double precision,
+  "status" bigint,
+  "up" boolean DEFAULT false,
+  "exception" text,
+  "created_at" timestamptz NULL
+);
+CREATE INDEX "idx_check_attempts_node_id" ON "check_attempts" ("node_id");
+CREATE INDEX "idx_check_attempts_node_log_id" ON "check_attempts" ("node_log_id");
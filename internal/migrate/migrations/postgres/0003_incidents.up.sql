@@ -0,0 +1,29 @@
+CREATE TABLE "incidents" (
+  "id" bigserial PRIMARY KEY,
+  "node_id" bigint,
+  "status" varchar(20) DEFAULT 'open',
+  "state" varchar(20),
+  "started_at" timestamptz NULL,
+  "resolved_at" timestamptz NULL,
+  "duration" double precision,
+  "first_error" text,
+  "trigger_log_id" bigint,
+  "resolve_log_id" bigint,
+  "acknowledged" boolean DEFAULT false,
+  "acknowledged_at" timestamptz NULL,
+  "acknowledged_by" varchar(255),
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL
+);
+CREATE INDEX "idx_incidents_node_id" ON "incidents" ("node_id");
+CREATE INDEX "idx_incidents_status" ON "incidents" ("status");
+CREATE INDEX "idx_incidents_started_at" ON "incidents" ("started_at");
+
+CREATE TABLE "incident_notes" (
+  "id" bigserial PRIMARY KEY,
+  "incident_id" bigint,
+  "author" varchar(255),
+  "body" text,
+  "created_at" timestamptz NULL
+);
+CREATE INDEX "idx_incident_notes_incident_id" ON "incident_notes" ("incident_id");
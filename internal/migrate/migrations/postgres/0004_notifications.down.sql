@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS "chat_channels";
+
+DROP TABLE IF EXISTS "email_channels";
+
+DROP INDEX "idx_nodes_group_id";
+ALTER TABLE "nodes"
+  DROP COLUMN "group_id";
+
+DROP TABLE IF EXISTS "node_groups";
+
+DROP TABLE IF EXISTS "notification_deliveries";
+
+DROP TABLE IF EXISTS "webhooks";
@@ -0,0 +1,58 @@
+CREATE TABLE "node_log_hourly" (
+  "id" bigserial PRIMARY KEY,
+  "node_id" bigint,
+  "bucket_start" timestamptz NULL,
+  "bucket_end" timestamptz NULL,
+  "checks" bigint,
+  "up_count" bigint,
+  "down_count" bigint,
+  "suspended_count" bigint,
+  "maintenance_count" bigint,
+  "min_delay" double precision,
+  "avg_delay" double precision,
+  "max_delay" double precision,
+  "p95_delay" double precision,
+  "status_codes" text,
+  "delay_buckets" text,
+  "up_time" double precision,
+  "down_time" double precision,
+  "suspended_time" double precision,
+  "maintenance_time" double precision,
+  "unknown_time" double precision,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL
+);
+CREATE UNIQUE INDEX "idx_node_log_hourly_node_bucket" ON "node_log_hourly" ("node_id", "bucket_start");
+
+CREATE TABLE "node_log_daily" (
+  "id" bigserial PRIMARY KEY,
+  "node_id" bigint,
+  "bucket_start" timestamptz NULL,
+  "bucket_end" timestamptz NULL,
+  "checks" bigint,
+  "up_count" bigint,
+  "down_count" bigint,
+  "suspended_count" bigint,
+  "maintenance_count" bigint,
+  "min_delay" double precision,
+  "avg_delay" double precision,
+  "max_delay" double precision,
+  "p95_delay" double precision,
+  "status_codes" text,
+  "delay_buckets" text,
+  "up_time" double precision,
+  "down_time" double precision,
+  "suspended_time" double precision,
+  "maintenance_time" double precision,
+  "unknown_time" double precision,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL
+);
+CREATE UNIQUE INDEX "idx_node_log_daily_node_bucket" ON "node_log_daily" ("node_id", "bucket_start");
+
+CREATE TABLE "rollup_states" (
+  "tier" varchar(20),
+  "completed_until" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("tier")
+);
@@ -0,0 +1,6 @@
+ALTER TABLE "node_groups"
+  DROP COLUMN "retention_deliveries",
+  DROP COLUMN "retention_incidents",
+  DROP COLUMN "retention_daily_rollups",
+  DROP COLUMN "retention_hourly_rollups",
+  DROP COLUMN "retention_raw_logs";
@@ -0,0 +1,6 @@
+ALTER TABLE "node_groups"
+  ADD COLUMN "retention_raw_logs" bigint,
+  ADD COLUMN "retention_hourly_rollups" bigint,
+  ADD COLUMN "retention_daily_rollups" bigint,
+  ADD COLUMN "retention_incidents" bigint,
+  ADD COLUMN "retention_deliveries" bigint;
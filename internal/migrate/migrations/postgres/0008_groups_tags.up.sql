@@ -0,0 +1,19 @@
+ALTER TABLE "node_groups"
+  ADD COLUMN "parent_id" bigint;
+CREATE INDEX "idx_node_groups_parent_id" ON "node_groups" ("parent_id");
+
+CREATE TABLE "tags" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar(64),
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL
+);
+CREATE UNIQUE INDEX "idx_tags_name" ON "tags" ("name");
+
+CREATE TABLE "node_tags" (
+  "node_id" bigint,
+  "tag_id" bigint,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("node_id", "tag_id")
+);
+CREATE INDEX "idx_node_tags_tag_id" ON "node_tags" ("tag_id");
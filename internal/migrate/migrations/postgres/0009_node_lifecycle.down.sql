@@ -0,0 +1,6 @@
+DROP INDEX "idx_nodes_deleted_at";
+DROP INDEX "idx_nodes_lifecycle";
+ALTER TABLE "nodes"
+  DROP COLUMN "deleted_at",
+  DROP COLUMN "paused_until",
+  DROP COLUMN "lifecycle";
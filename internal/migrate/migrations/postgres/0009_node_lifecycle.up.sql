@@ -0,0 +1,6 @@
+ALTER TABLE "nodes"
+  ADD COLUMN "lifecycle" varchar(16) DEFAULT 'active',
+  ADD COLUMN "paused_until" timestamptz NULL,
+  ADD COLUMN "deleted_at" timestamptz NULL;
+CREATE INDEX "idx_nodes_lifecycle" ON "nodes" ("lifecycle");
+CREATE INDEX "idx_nodes_deleted_at" ON "nodes" ("deleted_at");
@@ -0,0 +1 @@
+UPDATE "nodes" SET "method" = '' WHERE "type" <> 'http';
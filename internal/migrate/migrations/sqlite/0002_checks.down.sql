@@ -0,0 +1,29 @@
+DROP TABLE IF EXISTS "check_attempts";
+
+DROP TABLE IF EXISTS "node_certificates";
+
+DROP TABLE IF EXISTS "assertion_rules";
+
+ALTER TABLE "histories" DROP COLUMN "failure_streak";
+ALTER TABLE "histories" DROP COLUMN "degraded";
+
+ALTER TABLE "node_logs" DROP COLUMN "response_size";
+ALTER TABLE "node_logs" DROP COLUMN "transfer_time";
+ALTER TABLE "node_logs" DROP COLUMN "ttfb";
+ALTER TABLE "node_logs" DROP COLUMN "tls_time";
+ALTER TABLE "node_logs" DROP COLUMN "connect_time";
+ALTER TABLE "node_logs" DROP COLUMN "dns_time";
+ALTER TABLE "node_logs" DROP COLUMN "unconfirmed";
+ALTER TABLE "node_logs" DROP COLUMN "attempts";
+ALTER TABLE "node_logs" DROP COLUMN "degraded";
+
+ALTER TABLE "nodes" DROP COLUMN "resolver";
+ALTER TABLE "nodes" DROP COLUMN "record_type";
+ALTER TABLE "nodes" DROP COLUMN "expect";
+ALTER TABLE "nodes" DROP COLUMN "skip_default_rules";
+ALTER TABLE "nodes" DROP COLUMN "timeout";
+ALTER TABLE "nodes" DROP COLUMN "expected_status";
+ALTER TABLE "nodes" DROP COLUMN "body";
+ALTER TABLE "nodes" DROP COLUMN "headers";
+ALTER TABLE "nodes" DROP COLUMN "method";
+ALTER TABLE "nodes" DROP COLUMN "type";
@@ -0,0 +1,76 @@
+ALTER TABLE "nodes" ADD COLUMN "type" varchar(10) DEFAULT 'http';
+ALTER TABLE "nodes" ADD COLUMN "method" varchar(10) DEFAULT 'GET';
+ALTER TABLE "nodes" ADD COLUMN "headers" text;
+ALTER TABLE "nodes" ADD COLUMN "body" text;
+ALTER TABLE "nodes" ADD COLUMN "expected_status" varchar(255);
+ALTER TABLE "nodes" ADD COLUMN "timeout" bigint DEFAULT 0;
+ALTER TABLE "nodes" ADD COLUMN "skip_default_rules" boolean DEFAULT false;
+ALTER TABLE "nodes" ADD COLUMN "expect" text;
+ALTER TABLE "nodes" ADD COLUMN "record_type" varchar(10);
+ALTER TABLE "nodes" ADD COLUMN "resolver" varchar(255);
+
+ALTER TABLE "node_logs" ADD COLUMN "degraded" boolean DEFAULT false;
+ALTER TABLE "node_logs" ADD COLUMN "attempts" bigint DEFAULT 1;
+ALTER TABLE "node_logs" ADD COLUMN "unconfirmed" boolean DEFAULT false;
+ALTER TABLE "node_logs" ADD COLUMN "dns_time" real;
+ALTER TABLE "node_logs" ADD COLUMN "connect_time" real;
+ALTER TABLE "node_logs" ADD COLUMN "tls_time" real;
+ALTER TABLE "node_logs" ADD COLUMN "ttfb" real;
+ALTER TABLE "node_logs" ADD COLUMN "transfer_time" real;
+ALTER TABLE "node_logs" ADD COLUMN "response_size" bigint;
+
+ALTER TABLE "histories" ADD COLUMN "degraded" boolean DEFAULT false;
+ALTER TABLE "histories" ADD COLUMN "failure_streak" bigint DEFAULT 0;
+
+CREATE TABLE "assertion_rules" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "node_id" bigint,
+  "name" varchar(255),
+  "type" varchar(20),
+  "target" varchar(255),
+  "value" text,
+  "ignore_case" boolean DEFAULT false,
+  "severity" varchar(20) DEFAULT 'down',
+  "enabled" boolean DEFAULT true,
+  "created_at" datetime NULL,
+  "updated_at" datetime NULL
+);
+CREATE INDEX "idx_assertion_rules_node_id" ON "assertion_rules" ("node_id");
+
+-- Global default rules, reproducing the suspended-page and directory-listing
+-- detection the checker used to hard-code
+INSERT INTO "assertion_rules" ("name", "type", "target", "value", "ignore_case", "severity", "enabled", "created_at", "updated_at") VALUES
+  ('suspended page', 'not_contains', '', 'suspended', true, 'suspended', true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+  ('blocked page (fa)', 'not_contains', '', 'مسدود', true, 'suspended', true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+  ('directory listing', 'not_contains', '', 'Index of /', false, 'down', true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+  ('litespeed default page', 'not_contains', '', 'proudly served by litespeed web server', true, 'down', true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+CREATE TABLE "node_certificates" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "node_id" bigint,
+  "subject" varchar(512),
+  "issuer" varchar(512),
+  "sans" text,
+  "not_before" datetime NULL,
+  "not_after" datetime NULL,
+  "chain_valid" boolean DEFAULT false,
+  "chain_error" text,
+  "created_at" datetime NULL,
+  "updated_at" datetime NULL
+);
+CREATE UNIQUE INDEX "idx_node_certificates_node_id" ON "node_certificates" ("node_id");
+CREATE INDEX "idx_node_certificates_not_after" ON "node_certificates" ("not_after");
+
+CREATE TABLE "check_attempts" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "node_id" bigint,
+  "node_log_id" bigint,
+  "attempt" bigint,
+  "delay" real,
+  "status" bigint,
+  "up" boolean DEFAULT false,
+  "exception" text,
+  "created_at" datetime NULL
+);
+CREATE INDEX "idx_check_attempts_node_id" ON "check_attempts" ("node_id");
+CREATE INDEX "idx_check_attempts_node_log_id" ON "check_attempts" ("node_log_id");
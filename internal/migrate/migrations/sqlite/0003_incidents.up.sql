@@ -0,0 +1,29 @@
+CREATE TABLE "incidents" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "node_id" bigint,
+  "status" varchar(20) DEFAULT 'open',
+  "state" varchar(20),
+  "started_at" datetime NULL,
+  "resolved_at" datetime NULL,
+  "duration" real,
+  "first_error" text,
+  "trigger_log_id" bigint,
+  "resolve_log_id" bigint,
+  "acknowledged" boolean DEFAULT false,
+  "acknowledged_at" datetime NULL,
+  "acknowledged_by" varchar(255),
+  "created_at" datetime NULL,
+  "updated_at" datetime NULL
+);
+CREATE INDEX "idx_incidents_node_id" ON "incidents" ("node_id");
+CREATE INDEX "idx_incidents_status" ON "incidents" ("status");
+CREATE INDEX "idx_incidents_started_at" ON "incidents" ("started_at");
+
+CREATE TABLE "incident_notes" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "incident_id" bigint,
+  "author" varchar(255),
+  "body" text,
+  "created_at" datetime NULL
+);
+CREATE INDEX "idx_incident_notes_incident_id" ON "incident_notes" ("incident_id");
@@ -0,0 +1,83 @@
+CREATE TABLE "webhooks" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" varchar(255),
+  "url" varchar(1024),
+  "secret" varchar(255),
+  "events" varchar(255),
+  "max_attempts" bigint DEFAULT 5,
+  "enabled" boolean DEFAULT true,
+  "created_at" datetime NULL,
+  "updated_at" datetime NULL
+);
+
+CREATE TABLE "notification_deliveries" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "channel" varchar(20),
+  "target_id" bigint,
+  "node_id" bigint,
+  "event" varchar(20),
+  "payload" text,
+  "status" varchar(20) DEFAULT 'pending',
+  "attempts" bigint,
+  "status_code" bigint,
+  "last_error" text,
+  "delivered_at" datetime NULL,
+  "created_at" datetime NULL,
+  "updated_at" datetime NULL
+);
+CREATE INDEX "idx_deliveries_target" ON "notification_deliveries" ("channel", "target_id");
+CREATE INDEX "idx_notification_deliveries_node_id" ON "notification_deliveries" ("node_id");
+CREATE INDEX "idx_notification_deliveries_status" ON "notification_deliveries" ("status");
+CREATE INDEX "idx_notification_deliveries_created_at" ON "notification_deliveries" ("created_at");
+
+CREATE TABLE "node_groups" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" varchar(255),
+  "description" text,
+  "created_at" datetime NULL,
+  "updated_at" datetime NULL
+);
+CREATE UNIQUE INDEX "idx_node_groups_name" ON "node_groups" ("name");
+
+ALTER TABLE "nodes" ADD COLUMN "group_id" bigint;
+CREATE INDEX "idx_nodes_group_id" ON "nodes" ("group_id");
+
+CREATE TABLE "email_channels" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" varchar(255),
+  "host" varchar(255),
+  "port" bigint,
+  "username" varchar(255),
+  "password" varchar(255),
+  "tls_mode" varchar(10) DEFAULT 'starttls',
+  "skip_verify" boolean DEFAULT false,
+  "from" varchar(255),
+  "to" text,
+  "node_ids" text,
+  "group_ids" text,
+  "events" varchar(255),
+  "subject_template" text,
+  "text_template" text,
+  "html_template" text,
+  "max_attempts" bigint DEFAULT 3,
+  "enabled" boolean DEFAULT true,
+  "created_at" datetime NULL,
+  "updated_at" datetime NULL
+);
+
+CREATE TABLE "chat_channels" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" varchar(255),
+  "kind" varchar(20),
+  "bot_token" varchar(255),
+  "chat_id" varchar(255),
+  "webhook_url" varchar(1024),
+  "api_base_url" varchar(255),
+  "node_ids" text,
+  "group_ids" text,
+  "events" varchar(255),
+  "max_attempts" bigint DEFAULT 3,
+  "enabled" boolean DEFAULT true,
+  "created_at" datetime NULL,
+  "updated_at" datetime NULL
+);
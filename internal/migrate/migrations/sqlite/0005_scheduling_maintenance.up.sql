@@ -0,0 +1,26 @@
+ALTER TABLE "nodes" ADD COLUMN "interval" bigint DEFAULT 0;
+
+ALTER TABLE "node_logs" ADD COLUMN "maintenance" boolean DEFAULT false;
+ALTER TABLE "node_logs" ADD COLUMN "cycle_id" varchar(32);
+CREATE INDEX "idx_node_logs_cycle_id" ON "node_logs" ("cycle_id");
+
+ALTER TABLE "histories" ADD COLUMN "maintenance" boolean DEFAULT false;
+
+CREATE TABLE "maintenance_windows" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" varchar(255),
+  "description" text,
+  "kind" varchar(10),
+  "starts_at" datetime NULL,
+  "ends_at" datetime NULL,
+  "cron" varchar(255),
+  "weekdays" varchar(64),
+  "start_time" varchar(5),
+  "duration" bigint,
+  "timezone" varchar(64),
+  "node_ids" text,
+  "group_ids" text,
+  "enabled" boolean DEFAULT true,
+  "created_at" datetime NULL,
+  "updated_at" datetime NULL
+);
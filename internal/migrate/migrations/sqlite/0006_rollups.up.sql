@@ -0,0 +1,58 @@
+CREATE TABLE "node_log_hourly" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "node_id" bigint,
+  "bucket_start" datetime NULL,
+  "bucket_end" datetime NULL,
+  "checks" bigint,
+  "up_count" bigint,
+  "down_count" bigint,
+  "suspended_count" bigint,
+  "maintenance_count" bigint,
+  "min_delay" real,
+  "avg_delay" real,
+  "max_delay" real,
+  "p95_delay" real,
+  "status_codes" text,
+  "delay_buckets" text,
+  "up_time" real,
+  "down_time" real,
+  "suspended_time" real,
+  "maintenance_time" real,
+  "unknown_time" real,
+  "created_at" datetime NULL,
+  "updated_at" datetime NULL
+);
+CREATE UNIQUE INDEX "idx_node_log_hourly_node_bucket" ON "node_log_hourly" ("node_id", "bucket_start");
+
+CREATE TABLE "node_log_daily" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "node_id" bigint,
+  "bucket_start" datetime NULL,
+  "bucket_end" datetime NULL,
+  "checks" bigint,
+  "up_count" bigint,
+  "down_count" bigint,
+  "suspended_count" bigint,
+  "maintenance_count" bigint,
+  "min_delay" real,
+  "avg_delay" real,
+  "max_delay" real,
+  "p95_delay" real,
+  "status_codes" text,
+  "delay_buckets" text,
+  "up_time" real,
+  "down_time" real,
+  "suspended_time" real,
+  "maintenance_time" real,
+  "unknown_time" real,
+  "created_at" datetime NULL,
+  "updated_at" datetime NULL
+);
+CREATE UNIQUE INDEX "idx_node_log_daily_node_bucket" ON "node_log_daily" ("node_id", "bucket_start");
+
+CREATE TABLE "rollup_states" (
+  "tier" varchar(20),
+  "completed_until" datetime NULL,
+  "updated_at" datetime NULL,
+  PRIMARY KEY ("tier")
+);
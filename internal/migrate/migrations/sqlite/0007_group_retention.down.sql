@@ -0,0 +1,5 @@
+ALTER TABLE "node_groups" DROP COLUMN "retention_deliveries";
+ALTER TABLE "node_groups" DROP COLUMN "retention_incidents";
+ALTER TABLE "node_groups" DROP COLUMN "retention_daily_rollups";
+ALTER TABLE "node_groups" DROP COLUMN "retention_hourly_rollups";
+ALTER TABLE "node_groups" DROP COLUMN "retention_raw_logs";
@@ -0,0 +1,5 @@
+ALTER TABLE "node_groups" ADD COLUMN "retention_raw_logs" bigint;
+ALTER TABLE "node_groups" ADD COLUMN "retention_hourly_rollups" bigint;
+ALTER TABLE "node_groups" ADD COLUMN "retention_daily_rollups" bigint;
+ALTER TABLE "node_groups" ADD COLUMN "retention_incidents" bigint;
+ALTER TABLE "node_groups" ADD COLUMN "retention_deliveries" bigint;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "node_tags";
+
+DROP TABLE IF EXISTS "tags";
+
+DROP INDEX "idx_node_groups_parent_id";
+ALTER TABLE "node_groups" DROP COLUMN "parent_id";
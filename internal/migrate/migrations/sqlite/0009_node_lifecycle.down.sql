@@ -0,0 +1,5 @@
+DROP INDEX "idx_nodes_deleted_at";
+DROP INDEX "idx_nodes_lifecycle";
+ALTER TABLE "nodes" DROP COLUMN "deleted_at";
+ALTER TABLE "nodes" DROP COLUMN "paused_until";
+ALTER TABLE "nodes" DROP COLUMN "lifecycle";
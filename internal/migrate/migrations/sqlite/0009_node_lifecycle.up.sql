@@ -0,0 +1,5 @@
+ALTER TABLE "nodes" ADD COLUMN "lifecycle" varchar(16) DEFAULT 'active';
+ALTER TABLE "nodes" ADD COLUMN "paused_until" datetime NULL;
+ALTER TABLE "nodes" ADD COLUMN "deleted_at" datetime NULL;
+CREATE INDEX "idx_nodes_lifecycle" ON "nodes" ("lifecycle");
+CREATE INDEX "idx_nodes_deleted_at" ON "nodes" ("deleted_at");
@@ -0,0 +1,250 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"uptime/config"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// maxChatMessage keeps messages below Telegram's 4096 character limit
+const maxChatMessage = 4000
+
+// EventBatch is the delivery log event of a message that groups different events
+const EventBatch = "batch"
+
+var chatIcons = map[string]string{
+	EventDown:      "🔴",
+	EventSuspended: "🟠",
+	EventUp:        "✅",
+	EventTest:      "🔔",
+}
+
+// chatEventOrder is the order of the sections of a grouped message
+var chatEventOrder = []string{EventDown, EventSuspended, EventUp, EventTest}
+
+// chatBatch collects the events of one channel until its window closes
+type chatBatch struct {
+	channel models.ChatChannel
+	events  []Event
+}
+
+// chatBatcher rate limits chat channels: the first event of a channel opens a
+// CHAT_BATCH_WINDOW long window and everything arriving during it is sent as a
+// single message when the window closes
+type chatBatcher struct {
+	mu      sync.Mutex
+	pending map[uint]*chatBatch
+}
+
+var chatBatches = &chatBatcher{pending: make(map[uint]*chatBatch)}
+
+func publishChats(e Event) {
+	var channels []models.ChatChannel
+	if err := repositories.GetEnabledChatChannels(&channels); err != nil {
+		log.Printf("Error fetching chat channels: %v", err)
+		return
+	}
+
+	groups := ancestry(e.Node)
+	for _, c := range channels {
+		if !subscribed(c.Events, e.Event) || !covers(c.NodeIDs, c.GroupIDs, e.Node.ID, groups) {
+			continue
+		}
+		chatBatches.add(c, e)
+	}
+}
+
+func (b *chatBatcher) add(c models.ChatChannel, e Event) {
+	window := config.AppConfig.Notifications.ChatBatchWindow
+	if window <= 0 {
+		go deliverChat(c, []Event{e}, c.MaxAttempts)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if batch, ok := b.pending[c.ID]; ok {
+		batch.events = append(batch.events, e)
+		return
+	}
+	b.pending[c.ID] = &chatBatch{channel: c, events: []Event{e}}
+	time.AfterFunc(window, func() { b.flush(c.ID, c.MaxAttempts) })
+}
+
+func (b *chatBatcher) flush(channelID uint, maxAttempts uint) {
+	b.mu.Lock()
+	batch := b.pending[channelID]
+	delete(b.pending, channelID)
+	b.mu.Unlock()
+
+	if batch != nil {
+		deliverChat(batch.channel, batch.events, maxAttempts)
+	}
+}
+
+// Flush sends every pending chat batch right away with a single attempt, so
+// that events collected during the current window are not lost on shutdown
+func Flush() {
+	chatBatches.mu.Lock()
+	ids := make([]uint, 0, len(chatBatches.pending))
+	for id := range chatBatches.pending {
+		ids = append(ids, id)
+	}
+	chatBatches.mu.Unlock()
+
+	for _, id := range ids {
+		chatBatches.flush(id, 1)
+	}
+}
+
+// TestChatChannel sends a single test message through c, bypassing the batch window
+func TestChatChannel(c models.ChatChannel) *models.NotificationDelivery {
+	return deliverChat(c, []Event{testEvent()}, 1)
+}
+
+// deliverChat sends events as one message and records the delivery
+func deliverChat(c models.ChatChannel, events []Event, maxAttempts uint) *models.NotificationDelivery {
+	payload, _ := json.Marshal(events)
+	delivery := &models.NotificationDelivery{
+		Channel:  c.Kind,
+		TargetID: c.ID,
+		Event:    events[0].Event,
+		Payload:  string(payload),
+	}
+	if len(events) == 1 {
+		delivery.NodeID = events[0].Node.ID
+	}
+	for _, e := range events[1:] {
+		if e.Event != delivery.Event {
+			delivery.Event = EventBatch
+			break
+		}
+	}
+
+	text := chatMessage(events, config.AppConfig.Notifications.ChatMaxBatchSize)
+	return deliver(delivery, maxAttempts, func(uint) (*int, error) {
+		switch c.Kind {
+		case models.ChannelTelegram:
+			base := c.APIBaseURL
+			if base == "" {
+				base = config.AppConfig.Notifications.TelegramAPIURL
+			}
+			return postJSON(strings.TrimRight(base, "/")+"/bot"+c.BotToken+"/sendMessage", map[string]interface{}{
+				"chat_id":                  c.ChatID,
+				"text":                     text,
+				"disable_web_page_preview": true,
+			})
+		case models.ChannelSlack:
+			return postJSON(c.WebhookURL, map[string]interface{}{"text": text})
+		default:
+			return nil, fmt.Errorf("unsupported chat channel kind %q", c.Kind)
+		}
+	})
+}
+
+// chatMessage renders a single event in detail, or several events as one
+// section per event kind listing at most maxLines nodes each
+func chatMessage(events []Event, maxLines int) string {
+	var sb strings.Builder
+
+	if len(events) == 1 {
+		e := events[0]
+		data := newMessageData(e)
+		fmt.Fprintf(&sb, "%s %s: %s\n", chatIcons[e.Event], strings.ToUpper(e.NewState), data.URL)
+		fmt.Fprintf(&sb, "Previous: %s\n", data.PreviousState)
+		fmt.Fprintf(&sb, "Status: %s\n", data.Status)
+		if data.Exception != "" {
+			fmt.Fprintf(&sb, "Exception: %s\n", data.Exception)
+		}
+		if data.Duration != "" {
+			fmt.Fprintf(&sb, "Outage: %s\n", data.Duration)
+		}
+		fmt.Fprintf(&sb, "Time: %s", data.Time.Format("2006-01-02 15:04:05 MST"))
+		return truncateMessage(sb.String())
+	}
+
+	byEvent := make(map[string][]Event)
+	for _, e := range events {
+		byEvent[e.Event] = append(byEvent[e.Event], e)
+	}
+
+	for _, name := range chatEventOrder {
+		group := byEvent[name]
+		if len(group) == 0 {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		noun := "nodes"
+		if len(group) == 1 {
+			noun = "node"
+		}
+		fmt.Fprintf(&sb, "%s %d %s %s\n", chatIcons[name], len(group), noun, name)
+		for i, e := range group {
+			if i == maxLines {
+				fmt.Fprintf(&sb, "… and %d more\n", len(group)-maxLines)
+				break
+			}
+			data := newMessageData(e)
+			line := "• " + data.URL
+			switch {
+			case data.Exception != "":
+				line += " - " + data.Exception
+			case data.Duration != "":
+				line += " - after " + data.Duration
+			case data.Status != "-":
+				line += " - " + data.Status
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	return truncateMessage(strings.TrimRight(sb.String(), "\n"))
+}
+
+func truncateMessage(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxChatMessage {
+		return text
+	}
+	return string(runes[:maxChatMessage-1]) + "…"
+}
+
+// postJSON posts body as JSON. A non 2xx answer is an error carrying the start of the response.
+func postJSON(url string, body interface{}) (*int, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "uptime-notifier")
+
+	client := *HTTPClient
+	client.Timeout = config.AppConfig.Notifications.WebhookTimeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	code := resp.StatusCode
+	if code < 200 || code > 299 {
+		return &code, fmt.Errorf("unexpected status %d: %s", code, strings.TrimSpace(string(respBody)))
+	}
+	return &code, nil
+}
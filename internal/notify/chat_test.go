@@ -0,0 +1,198 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"uptime/config"
+	"uptime/models"
+)
+
+// chatEvent is an event of node id moving into state
+func chatEvent(id uint, state string, exception string) Event {
+	e := Event{
+		Event:         state,
+		Node:          NodeInfo{ID: id, URL: fmt.Sprintf("https://n%d.example.com", id)},
+		PreviousState: models.StateUp,
+		NewState:      state,
+		Time:          time.Date(2026, 9, 1, 10, 30, 0, 0, time.UTC),
+	}
+	if exception != "" {
+		e.Exception = &exception
+	}
+	return e
+}
+
+func TestDeliverChat(t *testing.T) {
+	rec, srv := newRecorder(t)
+	failing, failingSrv := newRecorder(t, http.StatusBadRequest, http.StatusBadRequest)
+
+	tests := []struct {
+		name     string
+		rec      *recorder
+		channel  models.ChatChannel
+		path     string
+		body     map[string]interface{}
+		status   string
+		lastErr  string
+		attempts uint
+	}{
+		{
+			name:    "telegram",
+			rec:     rec,
+			channel: models.ChatChannel{Kind: models.ChannelTelegram, BotToken: "123:abc", ChatID: "@ops", APIBaseURL: srv.URL + "/"},
+			path:    "/bot123:abc/sendMessage",
+			body: map[string]interface{}{
+				"chat_id":                  "@ops",
+				"text":                     "🔴 DOWN: https://n1.example.com\nPrevious: up\nStatus: -\nException: timeout\nTime: 2026-09-01 10:30:00 UTC",
+				"disable_web_page_preview": true,
+			},
+			status:   models.DeliveryDelivered,
+			attempts: 1,
+		},
+		{
+			name:     "slack",
+			rec:      rec,
+			channel:  models.ChatChannel{Kind: models.ChannelSlack, WebhookURL: srv.URL + "/hooks/T0/B0"},
+			path:     "/hooks/T0/B0",
+			body:     map[string]interface{}{"text": "🔴 DOWN: https://n1.example.com\nPrevious: up\nStatus: -\nException: timeout\nTime: 2026-09-01 10:30:00 UTC"},
+			status:   models.DeliveryDelivered,
+			attempts: 1,
+		},
+		{
+			name:     "rejected",
+			rec:      failing,
+			channel:  models.ChatChannel{Kind: models.ChannelSlack, WebhookURL: failingSrv.URL},
+			path:     "/",
+			status:   models.DeliveryFailed,
+			lastErr:  `unexpected status 400: {"ok":true}`,
+			attempts: 2,
+		},
+		{
+			name:     "unsupported kind",
+			channel:  models.ChatChannel{Kind: "pager"},
+			status:   models.DeliveryFailed,
+			lastErr:  `unsupported chat channel kind "pager"`,
+			attempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := 0
+			if tt.rec != nil {
+				before = tt.rec.calls()
+			}
+
+			delivery := deliverChat(tt.channel, []Event{chatEvent(1, EventDown, "timeout")}, tt.attempts)
+			if delivery.Status != tt.status || delivery.Attempts != tt.attempts || delivery.NodeID != 1 {
+				t.Errorf("delivery = %+v, want %s after %d attempts for node 1", delivery, tt.status, tt.attempts)
+			}
+			if tt.lastErr != "" && (delivery.LastError == nil || *delivery.LastError != tt.lastErr) {
+				t.Errorf("LastError = %v, want %q", delivery.LastError, tt.lastErr)
+			}
+			if tt.rec == nil {
+				return
+			}
+
+			if got := tt.rec.calls() - before; got != int(tt.attempts) {
+				t.Fatalf("endpoint called %d times, want %d", got, tt.attempts)
+			}
+			r := tt.rec.requests[len(tt.rec.requests)-1]
+			if r.URL.Path != tt.path || r.Header.Get("Content-Type") != "application/json" {
+				t.Errorf("request = %s %s, want a JSON post to %s", r.Method, r.URL.Path, tt.path)
+			}
+			if tt.body != nil {
+				var body map[string]interface{}
+				json.Unmarshal(tt.rec.bodies[len(tt.rec.bodies)-1], &body)
+				got, _ := json.Marshal(body)
+				want, _ := json.Marshal(tt.body)
+				if string(got) != string(want) {
+					t.Errorf("body = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestChatMessage(t *testing.T) {
+	duration := 90.0
+	up := chatEvent(3, EventUp, "")
+	up.OutageDuration = &duration
+	status := uint(200)
+	upWithStatus := chatEvent(4, EventUp, "")
+	upWithStatus.Status = &status
+
+	tests := []struct {
+		name     string
+		events   []Event
+		maxLines int
+		want     string
+	}{
+		{
+			name:     "single recovery",
+			events:   []Event{up},
+			maxLines: 30,
+			want:     "✅ UP: https://n3.example.com\nPrevious: up\nStatus: -\nOutage: 1m30s\nTime: 2026-09-01 10:30:00 UTC",
+		},
+		{
+			name:     "grouped by event, downs first",
+			events:   []Event{up, chatEvent(1, EventDown, "timeout"), chatEvent(2, EventSuspended, ""), upWithStatus},
+			maxLines: 30,
+			want: "🔴 1 node down\n• https://n1.example.com - timeout\n\n" +
+				"🟠 1 node suspended\n• https://n2.example.com\n\n" +
+				"✅ 2 nodes up\n• https://n3.example.com - after 1m30s\n• https://n4.example.com - 200",
+		},
+		{
+			name:     "long sections are cut",
+			events:   []Event{chatEvent(1, EventDown, ""), chatEvent(2, EventDown, ""), chatEvent(3, EventDown, "")},
+			maxLines: 2,
+			want:     "🔴 3 nodes down\n• https://n1.example.com\n• https://n2.example.com\n… and 1 more",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chatMessage(tt.events, tt.maxLines); got != tt.want {
+				t.Errorf("chatMessage() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	long := chatEvent(1, EventDown, strings.Repeat("x", 5000))
+	if got := []rune(chatMessage([]Event{long}, 30)); len(got) != maxChatMessage || got[len(got)-1] != '…' {
+		t.Errorf("long message has %d runes, want %d ending with …", len(got), maxChatMessage)
+	}
+}
+
+func TestChatBatching(t *testing.T) {
+	defer func(window time.Duration) { config.AppConfig.Notifications.ChatBatchWindow = window }(config.AppConfig.Notifications.ChatBatchWindow)
+	config.AppConfig.Notifications.ChatBatchWindow = time.Hour
+
+	rec, srv := newRecorder(t)
+	batcher := &chatBatcher{pending: make(map[uint]*chatBatch)}
+	c := models.ChatChannel{ID: 7, Kind: models.ChannelSlack, WebhookURL: srv.URL, MaxAttempts: 1}
+	for id := uint(1); id <= 100; id++ {
+		batcher.add(c, chatEvent(id, EventDown, ""))
+	}
+	if rec.calls() != 0 {
+		t.Fatalf("sent %d messages before the window closed", rec.calls())
+	}
+
+	batcher.flush(c.ID, 1)
+	if rec.calls() != 1 {
+		t.Fatalf("sent %d messages for a batch, want 1", rec.calls())
+	}
+	var body struct{ Text string }
+	json.Unmarshal(rec.bodies[0], &body)
+	if !strings.HasPrefix(body.Text, "🔴 100 nodes down\n") || !strings.HasSuffix(body.Text, "… and 70 more") {
+		t.Errorf("text = %q, want a single grouped message", body.Text)
+	}
+
+	batcher.flush(c.ID, 1)
+	if rec.calls() != 1 {
+		t.Errorf("an empty batch sent a message")
+	}
+}
@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"uptime/config"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// maxBackoff caps the delay between two delivery attempts
+const maxBackoff = 10 * time.Minute
+
+// sendFunc performs a single delivery attempt. The status code is optional.
+type sendFunc func(deliveryID uint) (*int, error)
+
+// deliver runs send until it succeeds or maxAttempts is reached, waiting
+// NOTIFY_RETRY_DELAY before the first retry and doubling the delay after each
+// failure. The delivery log is updated after every attempt.
+func deliver(delivery *models.NotificationDelivery, maxAttempts uint, send sendFunc) *models.NotificationDelivery {
+	if maxAttempts == 0 {
+		maxAttempts = 1
+	}
+
+	delivery.Status = models.DeliveryPending
+	if err := repositories.CreateNotificationDelivery(delivery); err != nil {
+		log.Printf("Error recording %s delivery to target %d: %v", delivery.Channel, delivery.TargetID, err)
+	}
+
+	delay := config.AppConfig.Notifications.RetryDelay
+	for attempt := uint(1); attempt <= maxAttempts; attempt++ {
+		code, err := send(delivery.ID)
+
+		delivery.Attempts = attempt
+		delivery.StatusCode = code
+		if err == nil {
+			now := time.Now()
+			delivery.Status = models.DeliveryDelivered
+			delivery.DeliveredAt = &now
+			delivery.LastError = nil
+		} else {
+			msg := err.Error()
+			delivery.LastError = &msg
+			if attempt == maxAttempts {
+				delivery.Status = models.DeliveryFailed
+			}
+		}
+		if dbErr := repositories.UpdateNotificationDelivery(delivery); dbErr != nil {
+			log.Printf("Error updating delivery %d: %v", delivery.ID, dbErr)
+		}
+
+		if err == nil {
+			break
+		}
+		log.Printf("%s %d delivery attempt %d/%d failed: %v", delivery.Channel, delivery.TargetID, attempt, maxAttempts, err)
+		if attempt < maxAttempts {
+			time.Sleep(delay)
+			delay *= 2
+			if delay > maxBackoff {
+				delay = maxBackoff
+			}
+		}
+	}
+	return delivery
+}
+
+// subscribed reports whether a comma separated event list accepts event.
+// An empty list accepts everything and test events are always accepted.
+func subscribed(events, event string) bool {
+	if strings.TrimSpace(events) == "" || event == EventTest {
+		return true
+	}
+	for _, name := range strings.Split(events, ",") {
+		if strings.TrimSpace(name) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// covers reports whether a channel scoped to nodeIDs and groupIDs applies to
+// the node nodeID, whose group and the groups it is nested in are nodeGroups.
+// A channel without any scope covers every node.
+func covers(nodeIDs, groupIDs models.IDList, nodeID uint, nodeGroups []uint) bool {
+	if len(nodeIDs) == 0 && len(groupIDs) == 0 {
+		return true
+	}
+	if nodeIDs.Contains(nodeID) {
+		return true
+	}
+	for _, id := range nodeGroups {
+		if groupIDs.Contains(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// ancestry returns the node's group followed by the groups it is nested in,
+// so channels of a group also cover its subgroups
+func ancestry(node NodeInfo) []uint {
+	if node.GroupID == nil {
+		return nil
+	}
+	var groups []models.Group
+	if err := repositories.GetAllGroups(&groups); err != nil {
+		log.Printf("Error fetching groups: %v", err)
+	}
+	return models.NewGroupTree(groups).Ancestry(node.GroupID)
+}
+
+// testEvent is sent by the test endpoints of every channel
+func testEvent() Event {
+	return Event{
+		Event:         EventTest,
+		Node:          NodeInfo{URL: "https://example.com", Type: models.NodeTypeHTTP},
+		PreviousState: models.StateUp,
+		NewState:      models.StateUp,
+		Time:          time.Now(),
+	}
+}
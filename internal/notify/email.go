@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"uptime/config"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+func publishEmails(e Event) {
+	var channels []models.EmailChannel
+	if err := repositories.GetEnabledEmailChannels(&channels); err != nil {
+		log.Printf("Error fetching email channels: %v", err)
+		return
+	}
+
+	groups := ancestry(e.Node)
+	for _, c := range channels {
+		if !subscribed(c.Events, e.Event) || !covers(c.NodeIDs, c.GroupIDs, e.Node.ID, groups) {
+			continue
+		}
+		go deliverEmail(c, e, c.MaxAttempts)
+	}
+}
+
+// TestEmailChannel sends a single test message through c and returns the recorded delivery
+func TestEmailChannel(c models.EmailChannel) *models.NotificationDelivery {
+	return deliverEmail(c, testEvent(), 1)
+}
+
+// deliverEmail renders the channel templates for e, sends the message and records the delivery
+func deliverEmail(c models.EmailChannel, e Event, maxAttempts uint) *models.NotificationDelivery {
+	payload, _ := json.Marshal(e)
+	delivery := &models.NotificationDelivery{
+		Channel:  models.ChannelEmail,
+		TargetID: c.ID,
+		NodeID:   e.Node.ID,
+		Event:    e.Event,
+		Payload:  string(payload),
+	}
+
+	subject, text, html, err := renderEmail(c.SubjectTemplate, c.TextTemplate, c.HTMLTemplate, e)
+	if err != nil {
+		// A broken template will not fix itself, fail without retrying
+		return deliver(delivery, 1, func(uint) (*int, error) { return nil, err })
+	}
+
+	return deliver(delivery, maxAttempts, func(deliveryID uint) (*int, error) {
+		message, err := buildMessage(c, subject, text, html, deliveryID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, sendMail(c, message)
+	})
+}
+
+// buildMessage assembles a multipart/alternative message with a text and an html part
+func buildMessage(c models.EmailChannel, subject, text, html string, deliveryID uint) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	domain := "localhost"
+	if i := strings.LastIndex(c.From, "@"); i >= 0 {
+		domain = strings.Trim(c.From[i+1:], "> ")
+	}
+
+	to := make([]string, len(c.To))
+	for i, addr := range c.To {
+		to[i] = formatAddress(addr)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", formatAddress(c.From))
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: <%d.%d@%s>\r\n", time.Now().UnixNano(), deliveryID, domain)
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// sendMail delivers message to every recipient of c, honouring its TLS mode
+func sendMail(c models.EmailChannel, message []byte) error {
+	if len(c.To) == 0 {
+		return errors.New("no recipients")
+	}
+
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(int(c.Port)))
+	timeout := config.AppConfig.Notifications.SMTPTimeout
+	tlsConfig := &tls.Config{ServerName: c.Host, InsecureSkipVerify: c.SkipVerify}
+
+	var conn net.Conn
+	var err error
+	if c.TLSMode == models.TLSModeTLS {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, timeout)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if c.TLSMode == models.TLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if c.Username != "" {
+		// PlainAuth refuses to send credentials over an unencrypted connection to a remote host
+		if err := client.Auth(smtp.PlainAuth("", c.Username, c.Password, c.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(addressOf(c.From)); err != nil {
+		return err
+	}
+	for _, to := range c.To {
+		if err := client.Rcpt(addressOf(to)); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// formatAddress encodes non ASCII display names for use in a header
+func formatAddress(s string) string {
+	if a, err := mail.ParseAddress(s); err == nil {
+		return a.String()
+	}
+	return s
+}
+
+// addressOf extracts the bare address from "Name <user@host>"
+func addressOf(s string) string {
+	if a, err := mail.ParseAddress(s); err == nil {
+		return a.Address
+	}
+	return s
+}
@@ -0,0 +1,423 @@
+package notify
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"uptime/models"
+)
+
+// smtpMessage is what the fake server received in one session
+type smtpMessage struct {
+	auth     string // user name of AUTH PLAIN, empty without it
+	startTLS bool
+	from     string
+	to       []string
+	data     string
+}
+
+// smtpServer is a fake SMTP server that accepts every message except for
+// recipients starting with "reject", and offers STARTTLS when asked to
+type smtpServer struct {
+	host     string
+	port     uint
+	startTLS bool
+	cert     tls.Certificate
+
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+func newSMTPServer(t *testing.T, startTLS bool) *smtpServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &smtpServer{host: "127.0.0.1", port: uint(ln.Addr().(*net.TCPAddr).Port), startTLS: startTLS}
+	if startTLS {
+		// borrow the self-signed certificate of httptest
+		srv := httptest.NewUnstartedServer(nil)
+		srv.StartTLS()
+		s.cert = srv.TLS.Certificates[0]
+		srv.Close()
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	var msg smtpMessage
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case verb == "EHLO":
+			reply("250-fake")
+			if s.startTLS && !msg.startTLS {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN")
+		case verb == "STARTTLS":
+			reply("220 ready")
+			tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{s.cert}})
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, r, msg.startTLS = tlsConn, bufio.NewReader(tlsConn), true
+		case verb == "AUTH":
+			fields := strings.Fields(line)
+			decoded, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+			parts := strings.Split(string(decoded), "\x00")
+			if len(parts) != 3 || parts[2] != "secret" {
+				reply("535 authentication failed")
+				continue
+			}
+			msg.auth = parts[1]
+			reply("235 ok")
+		case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+			msg.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 ok")
+		case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+			to := strings.Trim(line[len("RCPT TO:"):], "<>")
+			if strings.HasPrefix(to, "reject") {
+				reply("550 no such user")
+				continue
+			}
+			msg.to = append(msg.to, to)
+			reply("250 ok")
+		case verb == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			msg.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 queued")
+		case verb == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (s *smtpServer) received() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func (s *smtpServer) channel(tlsMode string) models.EmailChannel {
+	return models.EmailChannel{
+		ID:      1,
+		Host:    s.host,
+		Port:    s.port,
+		TLSMode: tlsMode,
+		From:    "Uptime <uptime@example.com>",
+		To:      models.StringList{"ops@example.com", "Oncall <oncall@example.com>"},
+	}
+}
+
+func TestSendMail(t *testing.T) {
+	plain := newSMTPServer(t, false)
+	secure := newSMTPServer(t, true)
+
+	tests := []struct {
+		name     string
+		server   *smtpServer
+		channel  func(c *models.EmailChannel)
+		err      string
+		auth     string
+		startTLS bool
+	}{
+		{name: "plain", server: plain},
+		{
+			name:   "plain with auth to localhost",
+			server: plain,
+			channel: func(c *models.EmailChannel) {
+				c.Username, c.Password = "alerts", "secret"
+			},
+			auth: "alerts",
+		},
+		{
+			name:   "starttls with auth",
+			server: secure,
+			channel: func(c *models.EmailChannel) {
+				c.TLSMode, c.SkipVerify = models.TLSModeStartTLS, true
+				c.Username, c.Password = "alerts", "secret"
+			},
+			auth:     "alerts",
+			startTLS: true,
+		},
+		{
+			name:   "starttls with an untrusted certificate",
+			server: secure,
+			channel: func(c *models.EmailChannel) {
+				c.TLSMode = models.TLSModeStartTLS
+			},
+			err: "certificate",
+		},
+		{
+			name:    "starttls not offered",
+			server:  plain,
+			channel: func(c *models.EmailChannel) { c.TLSMode = models.TLSModeStartTLS },
+			err:     "server does not support STARTTLS",
+		},
+		{
+			name:    "wrong password",
+			server:  plain,
+			channel: func(c *models.EmailChannel) { c.Username, c.Password = "alerts", "guess" },
+			err:     "535",
+		},
+		{
+			name:    "recipient rejected",
+			server:  plain,
+			channel: func(c *models.EmailChannel) { c.To = append(c.To, "reject@example.com") },
+			err:     "550",
+		},
+		{
+			name:    "no recipients",
+			server:  plain,
+			channel: func(c *models.EmailChannel) { c.To = nil },
+			err:     "no recipients",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.server.channel(models.TLSModeNone)
+			if tt.channel != nil {
+				tt.channel(&c)
+			}
+			before := len(tt.server.received())
+
+			err := sendMail(c, []byte("Subject: test\r\n\r\nbody\r\n"))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			received := tt.server.received()
+			if len(received) != before+1 {
+				t.Fatalf("server received %d messages, want 1", len(received)-before)
+			}
+			msg := received[len(received)-1]
+			if msg.auth != tt.auth || msg.startTLS != tt.startTLS {
+				t.Errorf("auth, startTLS = %q, %v, want %q, %v", msg.auth, msg.startTLS, tt.auth, tt.startTLS)
+			}
+			if msg.from != "uptime@example.com" || strings.Join(msg.to, ",") != "ops@example.com,oncall@example.com" {
+				t.Errorf("envelope = %s to %v", msg.from, msg.to)
+			}
+			if !strings.Contains(msg.data, "body") {
+				t.Errorf("data = %q", msg.data)
+			}
+		})
+	}
+}
+
+func TestDeliverEmail(t *testing.T) {
+	server := newSMTPServer(t, false)
+	status := uint(503)
+	exception := "HTTP error: status 503 <script>"
+	e := Event{
+		Event:         EventDown,
+		Node:          NodeInfo{ID: 4, URL: "https://shop.example.com", Type: models.NodeTypeHTTP},
+		PreviousState: models.StateUp,
+		NewState:      models.StateDown,
+		Status:        &status,
+		Exception:     &exception,
+		Time:          time.Date(2026, 9, 1, 10, 30, 0, 0, time.UTC),
+	}
+
+	delivery := deliverEmail(server.channel(models.TLSModeNone), e, 1)
+	if delivery.Status != models.DeliveryDelivered || delivery.Attempts != 1 || delivery.NodeID != 4 {
+		t.Fatalf("delivery = %+v, want delivered once for node 4", delivery)
+	}
+
+	received := server.received()
+	if len(received) != 1 {
+		t.Fatalf("server received %d messages, want 1", len(received))
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(received[0].data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.Header.Get("Subject"); got != "[DOWN] https://shop.example.com" {
+		t.Errorf("Subject = %q", got)
+	}
+	if got := msg.Header.Get("From"); got != `"Uptime" <uptime@example.com>` {
+		t.Errorf("From = %q", got)
+	}
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	bodies := map[string]string{}
+	for {
+		part, err := parts.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(quotedprintable.NewReader(part))
+		mediaType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[mediaType] = string(body)
+	}
+	if text := bodies["text/plain"]; !strings.Contains(text, "Exception: HTTP error: status 503 <script>") || !strings.Contains(text, "Status:    503") {
+		t.Errorf("text part = %q", text)
+	}
+	if html := bodies["text/html"]; !strings.Contains(html, "&lt;script&gt;") || strings.Contains(html, "<script>") {
+		t.Errorf("html part = %q, want the exception escaped", html)
+	}
+}
+
+func TestDeliverEmailBrokenTemplate(t *testing.T) {
+	server := newSMTPServer(t, false)
+	c := server.channel(models.TLSModeNone)
+	c.TextTemplate = "{{.Missing}}"
+
+	delivery := deliverEmail(c, testEvent(), 3)
+	if delivery.Status != models.DeliveryFailed || delivery.Attempts != 1 {
+		t.Errorf("delivery = %+v, want failed after a single attempt", delivery)
+	}
+	if delivery.LastError == nil || !strings.Contains(*delivery.LastError, "text template") {
+		t.Errorf("LastError = %v, want the template error", delivery.LastError)
+	}
+	if n := len(server.received()); n != 0 {
+		t.Errorf("server received %d messages, want none", n)
+	}
+}
+
+func TestRenderEmail(t *testing.T) {
+	exception := "timeout"
+	duration := 3723.0
+	down := Event{
+		Event:         EventDown,
+		Node:          NodeInfo{ID: 1, URL: "https://example.com"},
+		PreviousState: models.StateUp,
+		NewState:      models.StateDown,
+		Exception:     &exception,
+		Time:          time.Date(2026, 9, 1, 10, 30, 0, 0, time.UTC),
+	}
+	up := down
+	up.Event, up.PreviousState, up.NewState, up.Exception, up.OutageDuration = EventUp, models.StateDown, models.StateUp, nil, &duration
+
+	tests := []struct {
+		name             string
+		subject, text    string
+		e                Event
+		wantSubject      string
+		wantText, noText []string
+		err              string
+	}{
+		{
+			name:        "defaults for an outage",
+			e:           down,
+			wantSubject: "[DOWN] https://example.com",
+			wantText:    []string{"https://example.com is down (was up).", "Status:    -", "Exception: timeout", "Time:      2026-09-01 10:30:00 UTC"},
+			noText:      []string{"Outage:"},
+		},
+		{
+			name:        "defaults for a recovery",
+			e:           up,
+			wantSubject: "[UP] https://example.com",
+			wantText:    []string{"Outage:    1h2m3s"},
+			noText:      []string{"Exception:"},
+		},
+		{
+			name:        "custom templates",
+			subject:     "{{lower .Event}} #{{.NodeID}}",
+			text:        "{{.URL}} {{.Exception}}",
+			e:           down,
+			wantSubject: "down #1",
+			wantText:    []string{"https://example.com timeout"},
+		},
+		{
+			name:        "subject kept on one line",
+			subject:     "{{.URL}}\r\nBcc: victim@example.com",
+			e:           down,
+			wantSubject: "https://example.com Bcc: victim@example.com",
+		},
+		{name: "unknown field", text: "{{.Nope}}", e: down, err: "text template"},
+		{name: "bad syntax", subject: "{{", e: down, err: "subject template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, text, _, err := renderEmail(tt.subject, tt.text, "", tt.e)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if subject != tt.wantSubject {
+				t.Errorf("subject = %q, want %q", subject, tt.wantSubject)
+			}
+			for _, want := range tt.wantText {
+				if !strings.Contains(text, want) {
+					t.Errorf("text = %q, want it to contain %q", text, want)
+				}
+			}
+			for _, unwanted := range tt.noText {
+				if strings.Contains(text, unwanted) {
+					t.Errorf("text = %q, want no %q", text, unwanted)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"uptime/config"
+	"uptime/database"
+)
+
+// TestMain records deliveries in an in-memory SQLite database
+func TestMain(m *testing.M) {
+	config.AppConfig = &config.Config{}
+	config.AppConfig.Database.Driver = database.SQLite
+	config.AppConfig.Database.DSN = ":memory:"
+	config.AppConfig.Notifications.WebhookTimeout = 5 * time.Second
+	config.AppConfig.Notifications.SMTPTimeout = 5 * time.Second
+	config.AppConfig.Notifications.RetryDelay = time.Millisecond
+	config.AppConfig.Notifications.ChatMaxBatchSize = 30
+
+	if err := database.Connect(); err != nil {
+		log.Fatal(err)
+	}
+	if err := database.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
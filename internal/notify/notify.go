@@ -0,0 +1,75 @@
+// Package notify delivers node state transitions to the configured notification channels.
+package notify
+
+import (
+	"time"
+
+	"uptime/models"
+)
+
+// Event names, one per state a node can move into
+const (
+	EventDown      = "down"
+	EventUp        = "up"
+	EventSuspended = "suspended"
+	EventTest      = "test"
+)
+
+// NodeInfo identifies the node an event is about
+type NodeInfo struct {
+	ID      uint   `json:"id"`
+	URL     string `json:"url"`
+	Type    string `json:"type"`
+	GroupID *uint  `json:"group_id,omitempty"`
+}
+
+// Event is the payload sent to every channel when a node changes state
+type Event struct {
+	Event          string    `json:"event"`
+	Node           NodeInfo  `json:"node"`
+	PreviousState  string    `json:"previous_state"`
+	NewState       string    `json:"new_state"`
+	Status         *uint     `json:"status"`
+	Exception      *string   `json:"exception"`
+	Delay          *float64  `json:"delay"`
+	IncidentID     *uint     `json:"incident_id,omitempty"`
+	OutageDuration *float64  `json:"outage_duration,omitempty"` // seconds, set when a node recovers
+	Time           time.Time `json:"time"`
+}
+
+// NewEvent builds the event for a node moving from previous to the state recorded in nodeLog.
+// ok is false when the state did not change.
+func NewEvent(n models.Node, previous string, nodeLog *models.NodeLog, incident *models.Incident) (Event, bool) {
+	current := models.NodeState(nodeLog.Up, nodeLog.Suspended)
+	if current == previous {
+		return Event{}, false
+	}
+
+	e := Event{
+		Event:         current,
+		Node:          NodeInfo{ID: n.ID, URL: n.URL, Type: n.Type, GroupID: n.GroupID},
+		PreviousState: previous,
+		NewState:      current,
+		Status:        nodeLog.Status,
+		Exception:     nodeLog.Exception,
+		Delay:         nodeLog.Delay,
+		Time:          nodeLog.CreatedAt,
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if incident != nil {
+		e.IncidentID = &incident.ID
+		if current == models.StateUp {
+			e.OutageDuration = incident.Duration
+		}
+	}
+	return e, true
+}
+
+// Publish hands the event to every channel in the background
+func Publish(e Event) {
+	go publishWebhooks(e)
+	go publishEmails(e)
+	go publishChats(e)
+}
@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Default email templates, used when a channel leaves a template empty
+const (
+	DefaultEmailSubject = `[{{upper .NewState}}] {{.URL}}`
+
+	DefaultEmailText = `{{.URL}} is {{.NewState}} (was {{.PreviousState}}).
+
+Status:    {{.Status}}
+Delay:     {{.Delay}}
+{{if .Exception}}Exception: {{.Exception}}
+{{end}}{{if .Duration}}Outage:    {{.Duration}}
+{{end}}Time:      {{.Time.Format "2006-01-02 15:04:05 MST"}}
+`
+
+	DefaultEmailHTML = `<p><a href="{{.URL}}">{{.URL}}</a> is <strong>{{.NewState}}</strong> (was {{.PreviousState}}).</p>
+<table>
+<tr><td>Status</td><td>{{.Status}}</td></tr>
+<tr><td>Delay</td><td>{{.Delay}}</td></tr>
+{{if .Exception}}<tr><td>Exception</td><td>{{.Exception}}</td></tr>
+{{end}}{{if .Duration}}<tr><td>Outage</td><td>{{.Duration}}</td></tr>
+{{end}}<tr><td>Time</td><td>{{.Time.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+</table>
+`
+)
+
+// MessageData is what message templates are executed with
+type MessageData struct {
+	Event         string
+	PreviousState string
+	NewState      string
+	NodeID        uint
+	URL           string
+	Type          string
+	Status        string // "-" when there was no response
+	Exception     string
+	Delay         string
+	Duration      string // outage duration, only set when the node recovered
+	Time          time.Time
+}
+
+var templateFuncs = map[string]interface{}{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+func newMessageData(e Event) MessageData {
+	data := MessageData{
+		Event:         e.Event,
+		PreviousState: e.PreviousState,
+		NewState:      e.NewState,
+		NodeID:        e.Node.ID,
+		URL:           e.Node.URL,
+		Type:          e.Node.Type,
+		Status:        "-",
+		Delay:         "-",
+		Time:          e.Time,
+	}
+	if e.Status != nil {
+		data.Status = fmt.Sprint(*e.Status)
+	}
+	if e.Exception != nil {
+		data.Exception = *e.Exception
+	}
+	if e.Delay != nil {
+		data.Delay = fmt.Sprintf("%.2fs", *e.Delay)
+	}
+	if e.OutageDuration != nil {
+		data.Duration = formatDuration(*e.OutageDuration)
+	}
+	return data
+}
+
+// formatDuration renders seconds as e.g. "1h2m3s"
+func formatDuration(seconds float64) string {
+	return (time.Duration(seconds * float64(time.Second))).Round(time.Second).String()
+}
+
+func orDefault(tmpl, fallback string) string {
+	if strings.TrimSpace(tmpl) == "" {
+		return fallback
+	}
+	return tmpl
+}
+
+// ValidateEmailTemplates parses the templates without executing them. Empty
+// templates are valid and fall back to the defaults.
+func ValidateEmailTemplates(subject, text, html string) error {
+	if _, err := texttemplate.New("subject").Funcs(templateFuncs).Parse(orDefault(subject, DefaultEmailSubject)); err != nil {
+		return fmt.Errorf("subject template: %v", err)
+	}
+	if _, err := texttemplate.New("text").Funcs(templateFuncs).Parse(orDefault(text, DefaultEmailText)); err != nil {
+		return fmt.Errorf("text template: %v", err)
+	}
+	if _, err := htmltemplate.New("html").Funcs(templateFuncs).Parse(orDefault(html, DefaultEmailHTML)); err != nil {
+		return fmt.Errorf("html template: %v", err)
+	}
+	return nil
+}
+
+// renderEmail executes the subject, text and html templates for e
+func renderEmail(subject, text, html string, e Event) (string, string, string, error) {
+	data := newMessageData(e)
+
+	var subjectBuf, textBuf, htmlBuf bytes.Buffer
+
+	st, err := texttemplate.New("subject").Funcs(templateFuncs).Parse(orDefault(subject, DefaultEmailSubject))
+	if err != nil {
+		return "", "", "", fmt.Errorf("subject template: %v", err)
+	}
+	if err := st.Execute(&subjectBuf, data); err != nil {
+		return "", "", "", fmt.Errorf("subject template: %v", err)
+	}
+
+	tt, err := texttemplate.New("text").Funcs(templateFuncs).Parse(orDefault(text, DefaultEmailText))
+	if err != nil {
+		return "", "", "", fmt.Errorf("text template: %v", err)
+	}
+	if err := tt.Execute(&textBuf, data); err != nil {
+		return "", "", "", fmt.Errorf("text template: %v", err)
+	}
+
+	ht, err := htmltemplate.New("html").Funcs(templateFuncs).Parse(orDefault(html, DefaultEmailHTML))
+	if err != nil {
+		return "", "", "", fmt.Errorf("html template: %v", err)
+	}
+	if err := ht.Execute(&htmlBuf, data); err != nil {
+		return "", "", "", fmt.Errorf("html template: %v", err)
+	}
+
+	// Header injection guard, the subject ends up in a mail header
+	subjectLine := strings.Join(strings.Fields(subjectBuf.String()), " ")
+	return subjectLine, textBuf.String(), htmlBuf.String(), nil
+}
@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"uptime/config"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body, keyed by the webhook secret
+const SignatureHeader = "X-Uptime-Signature"
+
+// HTTPClient is used for webhook and chat deliveries, the timeout comes from WEBHOOK_TIMEOUT
+var HTTPClient = &http.Client{}
+
+func publishWebhooks(e Event) {
+	var webhooks []models.Webhook
+	if err := repositories.GetEnabledWebhooks(&webhooks); err != nil {
+		log.Printf("Error fetching webhooks: %v", err)
+		return
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("Error encoding %s event for node %d: %v", e.Event, e.Node.ID, err)
+		return
+	}
+
+	for _, w := range webhooks {
+		if !subscribed(w.Events, e.Event) {
+			continue
+		}
+		go deliverWebhook(w, e, payload, w.MaxAttempts)
+	}
+}
+
+// TestWebhook sends a single test event to w and returns the recorded delivery
+func TestWebhook(w models.Webhook) (*models.NotificationDelivery, error) {
+	e := testEvent()
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return deliverWebhook(w, e, payload, 1), nil
+}
+
+// Sign returns the signature header value for body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliverWebhook posts payload to w and records the delivery
+func deliverWebhook(w models.Webhook, e Event, payload []byte, maxAttempts uint) *models.NotificationDelivery {
+	delivery := &models.NotificationDelivery{
+		Channel:  models.ChannelWebhook,
+		TargetID: w.ID,
+		NodeID:   e.Node.ID,
+		Event:    e.Event,
+		Payload:  string(payload),
+	}
+	return deliver(delivery, maxAttempts, func(deliveryID uint) (*int, error) {
+		return postWebhook(w, e.Event, deliveryID, payload)
+	})
+}
+
+// postWebhook performs a single delivery attempt. A non 2xx answer is an error.
+func postWebhook(w models.Webhook, event string, deliveryID uint, payload []byte) (*int, error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "uptime-webhook")
+	req.Header.Set("X-Uptime-Event", event)
+	req.Header.Set("X-Uptime-Delivery", strconv.FormatUint(uint64(deliveryID), 10))
+	if w.HasSecret() {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, payload))
+	}
+
+	client := *HTTPClient
+	client.Timeout = config.AppConfig.Notifications.WebhookTimeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	code := resp.StatusCode
+	if code < 200 || code > 299 {
+		return &code, fmt.Errorf("unexpected status %d", code)
+	}
+	return &code, nil
+}
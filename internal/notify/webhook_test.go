@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"uptime/models"
+)
+
+// recorder is a stub endpoint answering with the queued status codes, then 200
+type recorder struct {
+	mu       sync.Mutex
+	codes    []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newRecorder(t *testing.T, codes ...int) (*recorder, *httptest.Server) {
+	t.Helper()
+	rec := &recorder{codes: codes}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		rec.mu.Lock()
+		rec.requests = append(rec.requests, r)
+		rec.bodies = append(rec.bodies, body)
+		code := http.StatusOK
+		if len(rec.codes) > 0 {
+			code, rec.codes = rec.codes[0], rec.codes[1:]
+		}
+		rec.mu.Unlock()
+
+		w.WriteHeader(code)
+		io.WriteString(w, `{"ok":true}`)
+	}))
+	t.Cleanup(srv.Close)
+	return rec, srv
+}
+
+func (rec *recorder) calls() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return len(rec.requests)
+}
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		secret, body, want string
+	}{
+		// RFC 4231 test case 2
+		{"Jefe", "what do ya want for nothing?", "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"},
+		{"secret", "", "sha256=f9e66e179b6747ae54108f82f8ade8b3c25d76fd30afde6c395822c530196169"},
+	}
+	for _, tt := range tests {
+		if got := Sign(tt.secret, []byte(tt.body)); got != tt.want {
+			t.Errorf("Sign(%q, %q) = %s, want %s", tt.secret, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestDeliverWebhook(t *testing.T) {
+	tests := []struct {
+		name        string
+		secret      string
+		codes       []int
+		maxAttempts uint
+		status      string
+		attempts    uint
+		code        int
+	}{
+		{name: "signed", secret: "s3cret", maxAttempts: 3, status: models.DeliveryDelivered, attempts: 1, code: 200},
+		{name: "unsigned", maxAttempts: 3, status: models.DeliveryDelivered, attempts: 1, code: 200},
+		{name: "retried until delivered", secret: "s3cret", codes: []int{500, 502}, maxAttempts: 3, status: models.DeliveryDelivered, attempts: 3, code: 200},
+		{name: "failed after the last attempt", codes: []int{500, 404}, maxAttempts: 2, status: models.DeliveryFailed, attempts: 2, code: 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, srv := newRecorder(t, tt.codes...)
+			w := models.Webhook{ID: 9, URL: srv.URL, Secret: tt.secret}
+			e := testEvent()
+			e.Event, e.Node.ID = EventDown, 5
+			payload, _ := json.Marshal(e)
+
+			delivery := deliverWebhook(w, e, payload, tt.maxAttempts)
+			if delivery.Status != tt.status || delivery.Attempts != tt.attempts {
+				t.Errorf("Status, Attempts = %s, %d, want %s, %d", delivery.Status, delivery.Attempts, tt.status, tt.attempts)
+			}
+			if delivery.StatusCode == nil || *delivery.StatusCode != tt.code {
+				t.Errorf("StatusCode = %v, want %d", delivery.StatusCode, tt.code)
+			}
+			if rec.calls() != int(tt.attempts) {
+				t.Fatalf("endpoint called %d times, want %d", rec.calls(), tt.attempts)
+			}
+
+			for i, r := range rec.requests {
+				if string(rec.bodies[i]) != string(payload) {
+					t.Errorf("body = %s, want %s", rec.bodies[i], payload)
+				}
+				if r.Header.Get("X-Uptime-Event") != EventDown || r.Header.Get("X-Uptime-Delivery") != strconv.FormatUint(uint64(delivery.ID), 10) {
+					t.Errorf("event, delivery headers = %q, %q", r.Header.Get("X-Uptime-Event"), r.Header.Get("X-Uptime-Delivery"))
+				}
+				signature := r.Header.Get(SignatureHeader)
+				if tt.secret == "" {
+					if signature != "" {
+						t.Errorf("%s = %q, want none without a secret", SignatureHeader, signature)
+					}
+					continue
+				}
+				// what a receiver does to verify the payload
+				if !hmac.Equal([]byte(signature), []byte(Sign(tt.secret, rec.bodies[i]))) {
+					t.Errorf("%s = %q does not verify", SignatureHeader, signature)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package retention resolves how long each tier of data is kept.
+package retention
+
+import (
+	"time"
+
+	"uptime/config"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// Data tiers
+const (
+	RawLogs       = "raw_logs"
+	HourlyRollups = "hourly_rollups"
+	DailyRollups  = "daily_rollups"
+	Incidents     = "incidents"
+	Deliveries    = "notification_deliveries"
+)
+
+// Tiers lists every tier in cleanup order
+var Tiers = []string{RawLogs, HourlyRollups, DailyRollups, Incidents, Deliveries}
+
+// Policy is how long each tier is kept, 0 keeps it forever
+type Policy map[string]time.Duration
+
+// Global returns the configured policy
+func Global() Policy {
+	r := config.AppConfig.Retention
+	return Policy{
+		RawLogs:       r.RawLogs,
+		HourlyRollups: r.HourlyRollups,
+		DailyRollups:  r.DailyRollups,
+		Incidents:     r.Incidents,
+		Deliveries:    r.Deliveries,
+	}
+}
+
+// Override returns the days a group keeps a tier, nil when it uses the global setting
+func Override(r models.GroupRetention, tier string) *uint {
+	switch tier {
+	case RawLogs:
+		return r.RawLogs
+	case HourlyRollups:
+		return r.HourlyRollups
+	case DailyRollups:
+		return r.DailyRollups
+	case Incidents:
+		return r.Incidents
+	case Deliveries:
+		return r.Deliveries
+	}
+	return nil
+}
+
+// Set holds the global policy and the overrides of every group. A group
+// without an override of a tier follows the group it is nested in.
+type Set struct {
+	global Policy
+	groups map[uint]models.GroupRetention
+	tree   *models.GroupTree
+}
+
+// Load reads the group overrides
+func Load() (*Set, error) {
+	var groups []models.Group
+	if err := repositories.GetAllGroups(&groups); err != nil {
+		return nil, err
+	}
+	s := &Set{global: Global(), groups: make(map[uint]models.GroupRetention), tree: models.NewGroupTree(groups)}
+	for _, g := range groups {
+		s.groups[g.ID] = g.Retention
+	}
+	return s, nil
+}
+
+// For returns the policy of the nodes in a group, or the global policy when groupID is nil
+func (s *Set) For(groupID *uint) Policy {
+	policy := make(Policy, len(s.global))
+	for _, tier := range Tiers {
+		policy[tier] = s.Keep(tier, groupID)
+	}
+	return policy
+}
+
+// Keep returns how long the nodes of a group keep a tier
+func (s *Set) Keep(tier string, groupID *uint) time.Duration {
+	if source := s.Source(tier, groupID); source != nil {
+		return time.Duration(*Override(s.groups[*source], tier)) * 24 * time.Hour
+	}
+	return s.global[tier]
+}
+
+// Source returns the group whose override of a tier applies to the nodes of
+// groupID: the group itself or the nearest group it is nested in that
+// overrides the tier. It is nil when the global policy applies.
+func (s *Set) Source(tier string, groupID *uint) *uint {
+	for _, id := range s.tree.Ancestry(groupID) {
+		if Override(s.groups[id], tier) != nil {
+			id := id
+			return &id
+		}
+	}
+	return nil
+}
+
+// Global returns the policy of nodes outside overriding groups
+func (s *Set) Global() Policy {
+	return s.global
+}
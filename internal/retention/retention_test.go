@@ -0,0 +1,67 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"uptime/models"
+)
+
+func days(n uint) *uint {
+	return &n
+}
+
+// testSet nests group 2 in 1 and 3 in 2; group 4 is on its own
+func testSet() *Set {
+	groups := []models.Group{
+		{ID: 1, Retention: models.GroupRetention{RawLogs: days(7), Incidents: days(0)}},
+		{ID: 2, ParentID: days(1)},
+		{ID: 3, ParentID: days(2), Retention: models.GroupRetention{RawLogs: days(3)}},
+		{ID: 4},
+	}
+	s := &Set{
+		global: Policy{RawLogs: 31 * 24 * time.Hour, HourlyRollups: 90 * 24 * time.Hour, Incidents: 365 * 24 * time.Hour},
+		groups: make(map[uint]models.GroupRetention),
+		tree:   models.NewGroupTree(groups),
+	}
+	for _, g := range groups {
+		s.groups[g.ID] = g.Retention
+	}
+	return s
+}
+
+func TestSetKeep(t *testing.T) {
+	s := testSet()
+	tests := []struct {
+		name    string
+		tier    string
+		groupID *uint
+		keep    time.Duration
+		source  uint // 0 for the global policy
+	}{
+		{name: "without a group", tier: RawLogs, keep: 31 * 24 * time.Hour},
+		{name: "group override", tier: RawLogs, groupID: days(1), keep: 7 * 24 * time.Hour, source: 1},
+		{name: "inherited from the parent", tier: RawLogs, groupID: days(2), keep: 7 * 24 * time.Hour, source: 1},
+		{name: "nearest ancestor wins", tier: RawLogs, groupID: days(3), keep: 3 * 24 * time.Hour, source: 3},
+		{name: "group without overrides", tier: RawLogs, groupID: days(4), keep: 31 * 24 * time.Hour},
+		{name: "tier not overridden", tier: HourlyRollups, groupID: days(3), keep: 90 * 24 * time.Hour},
+		{name: "zero keeps forever", tier: Incidents, groupID: days(3), keep: 0, source: 1},
+		{name: "unknown group", tier: RawLogs, groupID: days(9), keep: 31 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Keep(tt.tier, tt.groupID); got != tt.keep {
+				t.Errorf("Keep() = %s, want %s", got, tt.keep)
+			}
+			source := s.Source(tt.tier, tt.groupID)
+			if (source == nil) != (tt.source == 0) || (source != nil && *source != tt.source) {
+				t.Errorf("Source() = %v, want %d", source, tt.source)
+			}
+		})
+	}
+
+	p := s.For(days(2))
+	if p[RawLogs] != 7*24*time.Hour || p[HourlyRollups] != 90*24*time.Hour || p[Incidents] != 0 || p[DailyRollups] != 0 {
+		t.Errorf("For(2) = %v", p)
+	}
+}
@@ -0,0 +1,252 @@
+// Package rollup summarizes raw node logs into hourly and daily tables, so
+// reports can cover ranges older than the raw log retention.
+package rollup
+
+import (
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"uptime/internal/maintenance"
+	"uptime/internal/retention"
+	"uptime/internal/sla"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// settle is how long after a bucket ends its last checks may still be written
+const settle = 5 * time.Minute
+
+// longRange is the span past which reports read daily instead of hourly rollups
+const longRange = 31 * 24 * time.Hour
+
+var tiers = []string{models.RollupHourly, models.RollupDaily}
+
+// Truncate returns the start of the bucket holding t. Buckets follow the
+// local time zone of the server, like the report date filters.
+func Truncate(t time.Time, tier string) time.Time {
+	t = t.Local()
+	if tier == models.RollupDaily {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	}
+	// shift by the zone offset so half-hour zones get local hours
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(time.Hour).Add(-shift)
+}
+
+// Next returns the start of the bucket after the one starting at t
+func Next(t time.Time, tier string) time.Time {
+	if tier == models.RollupDaily {
+		return t.AddDate(0, 0, 1)
+	}
+	return t.Add(time.Hour)
+}
+
+// Run brings every tier up to the last complete bucket, reading the logs
+// from store. It runs before the log cleanup, which never deletes logs that
+// are not rolled up yet.
+func Run(store *repositories.Store) {
+	for _, tier := range tiers {
+		if err := runTier(store, tier, time.Now()); err != nil {
+			log.Printf("Error rolling up %s node logs: %v", tier, err)
+		}
+	}
+}
+
+func runTier(store *repositories.Store, tier string, now time.Time) error {
+	until := Truncate(now.Add(-settle), tier)
+
+	state, err := repositories.GetRollupState(tier)
+	if err != nil {
+		return err
+	}
+	var from time.Time
+	if state != nil {
+		from = state.CompletedUntil
+	} else {
+		oldest, err := store.NodeLogs.OldestTime()
+		if err != nil {
+			return err
+		}
+		if oldest == nil {
+			return repositories.SaveRollupState(&models.RollupState{Tier: tier, CompletedUntil: until})
+		}
+		from = Truncate(*oldest, tier)
+	}
+	if !until.After(from) {
+		return nil
+	}
+
+	// archived nodes are rolled up too, their logs still report
+	var nodes []models.Node
+	if err := store.Nodes.Unscoped().All(&nodes); err != nil {
+		return err
+	}
+
+	started := time.Now()
+	windows := maintenance.Load()
+	buckets := 0
+	for _, n := range nodes {
+		var logs []models.NodeLog
+		if err := store.NodeLogs.InRange(n.ID, from, until, &logs); err != nil {
+			return err
+		}
+		rollups := Summarize(n, logs, tier, from, until, windows.For(n.ID, n.GroupID, from, until))
+		if err := repositories.SaveRollups(tier, rollups); err != nil {
+			return err
+		}
+		buckets += len(rollups)
+	}
+
+	log.Printf("Rolled up %d %s bucket(s) of %d node(s) up to %s in %v",
+		buckets, tier, len(nodes), until.Format("2006-01-02 15:04"), time.Since(started))
+	return repositories.SaveRollupState(&models.RollupState{Tier: tier, CompletedUntil: until})
+}
+
+// CompletedUntil returns the time up to which every tier is rolled up, nil
+// before the first run
+func CompletedUntil() (*time.Time, error) {
+	var until *time.Time
+	for _, tier := range tiers {
+		state, err := repositories.GetRollupState(tier)
+		if err != nil || state == nil {
+			return nil, err
+		}
+		if until == nil || state.CompletedUntil.Before(*until) {
+			t := state.CompletedUntil
+			until = &t
+		}
+	}
+	return until, nil
+}
+
+// Summarize builds the rollups of a node's logs for the buckets within
+// [from, to). logs is ascending and may start with the last log before from.
+// Buckets without checks or time covered by a check are skipped.
+func Summarize(n models.Node, logs []models.NodeLog, tier string, from, to time.Time, windows []maintenance.Interval) []models.NodeLogRollup {
+	maxGap := sla.MaxGap(n)
+	var rollups []models.NodeLogRollup
+
+	i := 0
+	for start := from; start.Before(to); start = Next(start, tier) {
+		end := Next(start, tier)
+
+		// the last log before the bucket carries its state into it
+		for i+1 < len(logs) && !logs[i+1].CreatedAt.After(start) {
+			i++
+		}
+		last := i
+		for last < len(logs) && logs[last].CreatedAt.Before(end) {
+			last++
+		}
+
+		r := summarizeBucket(n.ID, logs[i:last], start, end, maxGap, windows)
+		if r.Checks > 0 || r.UnknownTime < end.Sub(start).Seconds() {
+			rollups = append(rollups, r)
+		}
+	}
+	return rollups
+}
+
+func summarizeBucket(nodeID uint, logs []models.NodeLog, start, end time.Time, maxGap time.Duration, windows []maintenance.Interval) models.NodeLogRollup {
+	r := models.NodeLogRollup{NodeID: nodeID, BucketStart: start, BucketEnd: end}
+
+	samples := make([]sla.Sample, len(logs))
+	var delays []float64
+	for j, l := range logs {
+		samples[j] = sla.Sample{
+			Time:        l.CreatedAt,
+			State:       models.NodeState(l.Up, l.Suspended),
+			Maintenance: l.Maintenance,
+			Delay:       l.Delay,
+		}
+		if l.CreatedAt.Before(start) {
+			continue
+		}
+
+		r.Checks++
+		switch {
+		case l.Maintenance:
+			r.MaintenanceCount++
+		case samples[j].State == models.StateUp:
+			r.UpCount++
+		case samples[j].State == models.StateSuspended:
+			r.SuspendedCount++
+		default:
+			r.DownCount++
+		}
+		if l.Status != nil {
+			if r.StatusCodes == nil {
+				r.StatusCodes = models.Counts{}
+			}
+			r.StatusCodes[strconv.FormatUint(uint64(*l.Status), 10)]++
+		}
+		if l.Delay != nil && l.Up && !l.Maintenance {
+			delays = append(delays, *l.Delay)
+		}
+	}
+
+	totals := sla.Weigh(samples, start, end, maxGap, windows)
+	r.UpTime = totals.Up.Seconds()
+	r.DownTime = totals.Down.Seconds()
+	r.SuspendedTime = totals.Suspended.Seconds()
+	r.MaintenanceTime = totals.Maintenance.Seconds()
+	r.UnknownTime = totals.Unknown.Seconds()
+
+	if len(delays) > 0 {
+		sort.Float64s(delays)
+		var sum float64
+		for _, d := range delays {
+			sum += d
+		}
+		avg := sum / float64(len(delays))
+		p95 := delays[int(math.Ceil(0.95*float64(len(delays))))-1]
+		r.MinDelay = &delays[0]
+		r.MaxDelay = &delays[len(delays)-1]
+		r.AvgDelay = &avg
+		r.P95Delay = &p95
+		r.DelayBuckets = sla.DelayBuckets(delays)
+	}
+	return r
+}
+
+// Plan splits a report range into the part read from rollups and the part
+// still covered by raw logs
+type Plan struct {
+	Tier       string    // empty when the whole range is covered by raw logs
+	RollupFrom time.Time // the range start, truncated to its bucket
+	RawFrom    time.Time // raw logs cover [RawFrom, to)
+}
+
+// RollupTo is where the rollup part of a range ending at to stops
+func (p Plan) RollupTo(to time.Time) time.Time {
+	if to.Before(p.RawFrom) {
+		return to
+	}
+	return p.RawFrom
+}
+
+// PlanRange picks where a report over [from, to) reads its data under a
+// retention policy. Ranges longer than longRange, or reaching back past the
+// hourly rollup retention, use daily rollups and others hourly ones.
+func PlanRange(from, to, now time.Time, policy retention.Policy) Plan {
+	raw := policy[retention.RawLogs]
+	if raw == 0 {
+		return Plan{RawFrom: from}
+	}
+
+	tier := models.RollupHourly
+	hourly := policy[retention.HourlyRollups]
+	if to.Sub(from) > longRange || (hourly > 0 && from.Before(now.Add(-hourly))) {
+		tier = models.RollupDaily
+	}
+
+	rawFrom := Next(Truncate(now.Add(-raw), tier), tier)
+	if !from.Before(rawFrom) {
+		return Plan{RawFrom: from}
+	}
+	return Plan{Tier: tier, RollupFrom: Truncate(from, tier), RawFrom: rawFrom}
+}
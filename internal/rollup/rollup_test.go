@@ -0,0 +1,192 @@
+package rollup
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"uptime/internal/maintenance"
+	"uptime/internal/retention"
+	"uptime/models"
+)
+
+// inZone runs the test with loc as the server's time zone
+func inZone(t *testing.T, loc *time.Location) {
+	t.Helper()
+	local := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = local })
+}
+
+var t0 = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+func minute(m int) time.Time {
+	return t0.Add(time.Duration(m) * time.Minute)
+}
+
+func TestTruncate(t *testing.T) {
+	inZone(t, time.FixedZone("+03:30", 3*3600+1800))
+
+	tests := []struct {
+		t    string
+		tier string
+		want string
+		next string
+	}{
+		{t: "2026-09-01T10:45:00Z", tier: models.RollupHourly, want: "2026-09-01T10:30:00Z", next: "2026-09-01T11:30:00Z"},
+		{t: "2026-09-01T10:30:00Z", tier: models.RollupHourly, want: "2026-09-01T10:30:00Z", next: "2026-09-01T11:30:00Z"},
+		{t: "2026-09-01T10:29:59Z", tier: models.RollupHourly, want: "2026-09-01T09:30:00Z", next: "2026-09-01T10:30:00Z"},
+		{t: "2026-09-01T10:45:00Z", tier: models.RollupDaily, want: "2026-08-31T20:30:00Z", next: "2026-09-01T20:30:00Z"},
+		{t: "2026-09-01T20:30:00Z", tier: models.RollupDaily, want: "2026-09-01T20:30:00Z", next: "2026-09-02T20:30:00Z"},
+	}
+	for _, tt := range tests {
+		in, _ := time.Parse(time.RFC3339, tt.t)
+		got := Truncate(in, tt.tier)
+		if s := got.UTC().Format(time.RFC3339); s != tt.want {
+			t.Errorf("Truncate(%s, %s) = %s, want %s", tt.t, tt.tier, s, tt.want)
+		}
+		if s := Next(got, tt.tier).UTC().Format(time.RFC3339); s != tt.next {
+			t.Errorf("Next(%s, %s) = %s, want %s", tt.want, tt.tier, s, tt.next)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	inZone(t, time.UTC)
+	f := func(v float64) *float64 { return &v }
+	code := func(v uint) *uint { return &v }
+	logs := []models.NodeLog{
+		{CreatedAt: minute(-1), Up: true, Status: code(200), Delay: f(9)}, // carries its state into the first bucket
+		{CreatedAt: minute(30), Status: code(500), Delay: f(1)},
+		{CreatedAt: minute(31), Up: true, Status: code(200), Delay: f(0.2)},
+		{CreatedAt: minute(32), Up: true, Maintenance: true, Delay: f(0.1)},
+		{CreatedAt: minute(130), Up: true, Suspended: true, Status: code(200), Delay: f(0.3)},
+	}
+	// checks every minute, so a result holds for two minutes at most
+	node := models.Node{ID: 4, Interval: 60}
+
+	rollups := Summarize(node, logs, models.RollupHourly, t0, minute(180), nil)
+	// the hour without checks or covered time is skipped
+	if len(rollups) != 2 {
+		t.Fatalf("got %d rollups, want 2: %+v", len(rollups), rollups)
+	}
+
+	tests := []struct {
+		got  models.NodeLogRollup
+		want string
+	}{
+		{
+			got: rollups[0],
+			want: "node 4 [00:00, 01:00) checks 3: up 1 down 1 suspended 0 maintenance 1; " +
+				"seconds up 120 down 60 suspended 0 maintenance 120 unknown 3300; " +
+				"delay 0.2-0.2 avg 0.2 p95 0.2 buckets map[200:1]; status map[200:1 500:1]",
+		},
+		{
+			got: rollups[1],
+			want: "node 4 [02:00, 03:00) checks 1: up 0 down 0 suspended 1 maintenance 0; " +
+				"seconds up 0 down 0 suspended 120 maintenance 0 unknown 3480; " +
+				"delay 0.3-0.3 avg 0.3 p95 0.3 buckets map[300:1]; status map[200:1]",
+		},
+	}
+	for i, tt := range tests {
+		if got := describe(tt.got); got != tt.want {
+			t.Errorf("rollup %d =\n%s\nwant\n%s", i, got, tt.want)
+		}
+	}
+
+	// a maintenance window turns the covered time into maintenance
+	windows := []maintenance.Interval{{Start: minute(30), End: minute(31)}}
+	if r := Summarize(node, logs, models.RollupHourly, t0, minute(60), windows); r[0].DownTime != 0 || r[0].MaintenanceTime != 180 {
+		t.Errorf("with a window: down %v maintenance %v, want 0 and 180", r[0].DownTime, r[0].MaintenanceTime)
+	}
+
+	daily := Summarize(node, logs, models.RollupDaily, t0, t0.AddDate(0, 0, 2), nil)
+	if len(daily) != 1 || daily[0].Checks != 4 || daily[0].UpTime != 120 || !daily[0].BucketEnd.Equal(t0.AddDate(0, 0, 1)) {
+		t.Errorf("daily = %+v, want one bucket of 4 checks", daily)
+	}
+
+	if r := Summarize(node, nil, models.RollupHourly, t0, minute(180), nil); len(r) != 0 {
+		t.Errorf("without logs: %+v, want no rollups", r)
+	}
+}
+
+func describe(r models.NodeLogRollup) string {
+	v := func(p *float64) string {
+		if p == nil {
+			return "-"
+		}
+		return fmt.Sprint(*p)
+	}
+	return fmt.Sprintf("node %d [%s, %s) checks %d: up %d down %d suspended %d maintenance %d; "+
+		"seconds up %v down %v suspended %v maintenance %v unknown %v; "+
+		"delay %s-%s avg %s p95 %s buckets %v; status %v",
+		r.NodeID, r.BucketStart.UTC().Format("15:04"), r.BucketEnd.UTC().Format("15:04"),
+		r.Checks, r.UpCount, r.DownCount, r.SuspendedCount, r.MaintenanceCount,
+		r.UpTime, r.DownTime, r.SuspendedTime, r.MaintenanceTime, r.UnknownTime,
+		v(r.MinDelay), v(r.MaxDelay), v(r.AvgDelay), v(r.P95Delay), r.DelayBuckets, r.StatusCodes)
+}
+
+func TestPlanRange(t *testing.T) {
+	inZone(t, time.UTC)
+	now := time.Date(2026, 9, 30, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	policy := retention.Policy{retention.RawLogs: 7 * day, retention.HourlyRollups: 30 * day, retention.DailyRollups: 365 * day}
+	at := func(s string) time.Time {
+		t, _ := time.Parse(time.RFC3339, s)
+		return t
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		policy   retention.Policy
+		want     Plan
+	}{
+		{
+			name: "raw logs kept forever",
+			from: now.Add(-400 * day), to: now,
+			policy: retention.Policy{},
+			want:   Plan{RawFrom: now.Add(-400 * day)},
+		},
+		{
+			name: "within the raw retention",
+			from: now.Add(-2 * day), to: now,
+			policy: policy,
+			want:   Plan{RawFrom: now.Add(-2 * day)},
+		},
+		{
+			name: "past the raw retention",
+			from: now.Add(-10*day - 30*time.Minute), to: now,
+			policy: policy,
+			want:   Plan{Tier: models.RollupHourly, RollupFrom: at("2026-09-20T11:00:00Z"), RawFrom: at("2026-09-23T13:00:00Z")},
+		},
+		{
+			name: "longer than a month",
+			from: now.Add(-40 * day), to: now,
+			policy: policy,
+			want:   Plan{Tier: models.RollupDaily, RollupFrom: at("2026-08-21T00:00:00Z"), RawFrom: at("2026-09-24T00:00:00Z")},
+		},
+		{
+			name: "short but past the hourly retention",
+			from: now.Add(-60 * day), to: now.Add(-59 * day),
+			policy: policy,
+			want:   Plan{Tier: models.RollupDaily, RollupFrom: at("2026-08-01T00:00:00Z"), RawFrom: at("2026-09-24T00:00:00Z")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PlanRange(tt.from, tt.to, now, tt.policy)
+			if got.Tier != tt.want.Tier || !got.RollupFrom.Equal(tt.want.RollupFrom) || !got.RawFrom.Equal(tt.want.RawFrom) {
+				t.Errorf("PlanRange() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	plan := PlanRange(now.Add(-60*day), now.Add(-59*day), now, policy)
+	if to := now.Add(-59 * day); !plan.RollupTo(to).Equal(to) {
+		t.Errorf("RollupTo(%s) = %s, want the range end", to, plan.RollupTo(to))
+	}
+	if !plan.RollupTo(now).Equal(plan.RawFrom) {
+		t.Errorf("RollupTo(now) = %s, want RawFrom", plan.RollupTo(now))
+	}
+}
@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"time"
+
+	"uptime/models"
+)
+
+// entry is a node waiting for its next check
+type entry struct {
+	node  models.Node
+	next  time.Time
+	index int // position in the queue, -1 while the node is being checked
+}
+
+// queue is a min-heap of entries ordered by next run time
+type queue []*entry
+
+func (q queue) Len() int { return len(q) }
+
+func (q queue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+
+func (q queue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *queue) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *queue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*q = old[:n-1]
+	return e
+}
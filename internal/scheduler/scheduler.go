@@ -0,0 +1,384 @@
+// Package scheduler checks every node on its own interval. Next run times are
+// kept in a min-heap, runs are spread with jitter and the node list is reloaded
+// periodically and whenever a node is created, updated or deleted. Paused and
+// archived nodes are not checked.
+package scheduler
+
+import (
+	"container/heap"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"uptime/config"
+	"uptime/models"
+	"uptime/monitoring"
+	"uptime/repositories"
+)
+
+// job is a node handed to a worker, with the time it was due
+type job struct {
+	entry *entry
+	node  models.Node
+	due   time.Time
+	cycle *monitoring.Cycle
+}
+
+// Scheduler dispatches due nodes to a pool of MAX_WORKERS workers
+type Scheduler struct {
+	store          *repositories.Store
+	runner         *monitoring.Runner
+	workers        int
+	interval       time.Duration // CHECK_INTERVAL, used by nodes without their own interval
+	jitter         float64
+	reloadInterval time.Duration
+
+	mu      sync.Mutex
+	entries map[uint]*entry
+	queue   queue
+	cycle   *monitoring.Cycle // the current CHECK_INTERVAL window
+
+	jobs       chan job
+	wake       chan struct{}
+	invalidate chan struct{}
+	stop       chan struct{}
+	loopDone   chan struct{}
+	workerWG   sync.WaitGroup
+}
+
+var (
+	currentMu sync.Mutex
+	current   *Scheduler
+)
+
+// New creates a scheduler configured from the checker settings, checking the
+// nodes of store
+func New(store *repositories.Store) *Scheduler {
+	workers := config.AppConfig.UptimeChecker.MaxWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	return &Scheduler{
+		store:          store,
+		workers:        workers,
+		interval:       config.AppConfig.UptimeChecker.CheckInterval,
+		jitter:         config.AppConfig.UptimeChecker.Jitter,
+		reloadInterval: config.AppConfig.UptimeChecker.ReloadInterval,
+		entries:        make(map[uint]*entry),
+		jobs:           make(chan job, workers),
+		wake:           make(chan struct{}, 1),
+		invalidate:     make(chan struct{}, 1),
+		stop:           make(chan struct{}),
+		loopDone:       make(chan struct{}),
+	}
+}
+
+// Start loads the nodes and starts the workers and the dispatch loop
+func (s *Scheduler) Start() error {
+	runner, err := monitoring.NewRunner(s.store)
+	if err != nil {
+		return err
+	}
+	s.runner = runner
+	s.reload()
+
+	for i := 0; i < s.workers; i++ {
+		s.workerWG.Add(1)
+		go s.work()
+	}
+	go s.loop()
+
+	currentMu.Lock()
+	current = s
+	currentMu.Unlock()
+	return nil
+}
+
+// Stop stops dispatching and waits for the checks in flight to finish
+func (s *Scheduler) Stop() {
+	currentMu.Lock()
+	if current == s {
+		current = nil
+	}
+	currentMu.Unlock()
+
+	close(s.stop)
+	<-s.loopDone
+	close(s.jobs)
+	s.workerWG.Wait()
+}
+
+// Invalidate asks the running scheduler, if any, to reload the node list now
+func Invalidate() {
+	currentMu.Lock()
+	s := current
+	currentMu.Unlock()
+
+	if s != nil {
+		select {
+		case s.invalidate <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// CheckNow runs a full cycle over nodes right away with the scheduler's
+// Runner, so manual checks see and update the same histories, failure
+// streaks and open incidents as scheduled ones
+func (s *Scheduler) CheckNow(nodes []models.Node) error {
+	return s.runner.Check(nodes)
+}
+
+// CheckNow checks nodes through the running scheduler when it checks the
+// nodes of store, or with a Runner of their own when none is running
+func CheckNow(store *repositories.Store, nodes []models.Node) error {
+	currentMu.Lock()
+	s := current
+	currentMu.Unlock()
+
+	if s != nil && s.store == store {
+		return s.CheckNow(nodes)
+	}
+	return monitoring.Check(store, nodes)
+}
+
+// CloseIncident resolves the open incident of a node that is no longer
+// checked, through the running scheduler when it checks the nodes of store
+func CloseIncident(store *repositories.Store, nodeID uint, reason string) error {
+	currentMu.Lock()
+	s := current
+	currentMu.Unlock()
+
+	if s != nil && s.store == store {
+		return s.runner.CloseIncident(nodeID, reason)
+	}
+	return monitoring.CloseIncident(store, nodeID, reason)
+}
+
+func (s *Scheduler) loop() {
+	defer close(s.loopDone)
+
+	reload := time.NewTicker(s.reloadInterval)
+	defer reload.Stop()
+
+	for {
+		timer := time.NewTimer(s.untilNext())
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-reload.C:
+			s.reload()
+		case <-s.invalidate:
+			s.reload()
+		case <-s.wake:
+		case <-timer.C:
+			s.dispatch()
+		}
+		timer.Stop()
+	}
+}
+
+// untilNext returns how long to sleep until the earliest entry is due
+func (s *Scheduler) untilNext() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 {
+		return s.reloadInterval
+	}
+	wait := time.Until(s.queue[0].next)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// dispatch hands every due entry to the workers. It blocks while all workers
+// are busy, so a slow cycle delays checks instead of piling them up.
+func (s *Scheduler) dispatch() {
+	now := time.Now()
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 || s.queue[0].next.After(now) {
+			s.mu.Unlock()
+			return
+		}
+		e := heap.Pop(&s.queue).(*entry)
+		j := job{entry: e, node: e.node, due: e.next, cycle: s.currentCycle(now)}
+		s.mu.Unlock()
+
+		select {
+		case s.jobs <- j:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) work() {
+	defer s.workerWG.Done()
+	for j := range s.jobs {
+		s.runner.CheckNode(j.node, j.cycle)
+		s.reschedule(j)
+	}
+}
+
+// reschedule puts a checked node back in the queue, one interval after it was
+// due so that slow checks do not make the schedule drift
+func (s *Scheduler) reschedule(j job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The node was deleted while it was being checked
+	if s.entries[j.node.ID] != j.entry {
+		return
+	}
+
+	interval := s.intervalOf(j.entry.node)
+	now := time.Now()
+	next := j.due.Add(interval)
+	if next.Before(now) {
+		next = now
+	}
+	j.entry.next = next.Add(s.jitterOffset(interval))
+	heap.Push(&s.queue, j.entry)
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// reload synchronizes the queue with the nodes table and refreshes the checker settings
+func (s *Scheduler) reload() {
+	// paused nodes come back on the first reload after their PausedUntil
+	if resumed, err := s.store.Nodes.ResumeDue(time.Now()); err != nil {
+		log.Println("Error resuming paused nodes:", err)
+	} else if resumed > 0 {
+		log.Printf("Resumed %d paused nodes", resumed)
+	}
+
+	var nodes []models.Node
+	if err := s.store.Nodes.All(&nodes); err != nil {
+		log.Println("Error fetching nodes:", err)
+		return
+	}
+	lastChecks, err := s.store.Histories.LastCheckTimes()
+	if err != nil {
+		log.Println("Error fetching last check times:", err)
+		lastChecks = map[uint]time.Time{}
+	}
+
+	now := time.Now()
+	seen := make(map[uint]bool, len(nodes))
+
+	s.mu.Lock()
+	for _, n := range nodes {
+		if !n.IsActive() {
+			continue
+		}
+		seen[n.ID] = true
+		interval := s.intervalOf(n)
+
+		if e, ok := s.entries[n.ID]; ok {
+			previous := s.intervalOf(e.node)
+			e.node = n
+			if interval != previous && e.index >= 0 {
+				e.next = e.next.Add(interval - previous)
+				if e.next.Before(now) {
+					e.next = now
+				}
+				heap.Fix(&s.queue, e.index)
+			}
+			continue
+		}
+
+		// New nodes continue from their last check; overdue ones are spread
+		// over the jitter window instead of all starting at once
+		e := &entry{node: n}
+		if last, ok := lastChecks[n.ID]; ok && last.Add(interval).After(now) {
+			e.next = last.Add(interval)
+		} else {
+			e.next = now.Add(time.Duration(rand.Float64() * s.jitter * float64(interval)))
+		}
+		heap.Push(&s.queue, e)
+		s.entries[n.ID] = e
+	}
+
+	for id, e := range s.entries {
+		if seen[id] {
+			continue
+		}
+		if e.index >= 0 {
+			heap.Remove(&s.queue, e.index)
+		}
+		delete(s.entries, id)
+	}
+	s.mu.Unlock()
+
+	s.runner.Refresh()
+}
+
+// currentCycle returns the cycle of the window containing now, starting a new
+// one when the previous window has ended. s.mu must be held.
+func (s *Scheduler) currentCycle(now time.Time) *monitoring.Cycle {
+	if s.cycle == nil || !now.Before(*s.cycle.EndsAt) {
+		s.cycle = monitoring.NewCycle(monitoring.CycleScheduler, 0, s.workers)
+		endsAt := s.cycle.StartedAt.Add(s.interval)
+		s.cycle.EndsAt = &endsAt
+	}
+	return s.cycle
+}
+
+// Progress returns the progress of the current window. Pending counts the
+// nodes due before the window ends that have not been dispatched yet.
+func (s *Scheduler) Progress() monitoring.Progress {
+	now := time.Now()
+
+	s.mu.Lock()
+	cycle := s.currentCycle(now)
+	pending := 0
+	var lastDue time.Time
+	for _, e := range s.queue {
+		if e.next.Before(*cycle.EndsAt) {
+			pending++
+			if e.next.After(lastDue) {
+				lastDue = e.next
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	p := cycle.Progress(pending)
+	// Nodes due later in the window cannot finish before they are due
+	if p.ETA != nil && lastDue.After(*p.ETA) {
+		p.ETA = &lastDue
+	}
+	return p
+}
+
+// CurrentProgress returns the progress of the running scheduler, if any
+func CurrentProgress() (monitoring.Progress, bool) {
+	currentMu.Lock()
+	s := current
+	currentMu.Unlock()
+
+	if s == nil {
+		return monitoring.Progress{}, false
+	}
+	return s.Progress(), true
+}
+
+func (s *Scheduler) intervalOf(n models.Node) time.Duration {
+	if n.Interval > 0 {
+		return time.Duration(n.Interval) * time.Second
+	}
+	return s.interval
+}
+
+// jitterOffset returns a random offset within ±jitter/2 of the interval
+func (s *Scheduler) jitterOffset(interval time.Duration) time.Duration {
+	return time.Duration((rand.Float64() - 0.5) * s.jitter * float64(interval))
+}
@@ -0,0 +1,282 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"math/rand"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"uptime/config"
+	"uptime/database"
+	"uptime/models"
+	"uptime/monitoring"
+	"uptime/repositories"
+	"uptime/repositories/memory"
+)
+
+// TestMain opens an in-memory SQLite database for the settings a Runner loads
+func TestMain(m *testing.M) {
+	config.AppConfig = &config.Config{}
+	config.AppConfig.Database.Driver = database.SQLite
+	config.AppConfig.Database.DSN = ":memory:"
+	config.AppConfig.UptimeChecker.MaxWorkers = 2
+	config.AppConfig.UptimeChecker.CheckInterval = time.Hour
+	config.AppConfig.UptimeChecker.ReloadInterval = time.Hour
+
+	if err := database.Connect(); err != nil {
+		log.Fatal(err)
+	}
+	if err := database.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	monitoring.RegisterChecker(testType, func(*monitoring.CycleEnv) monitoring.Checker { return &checks })
+	os.Exit(m.Run())
+}
+
+// testType is the node type whose checks are counted instead of run
+const testType = "test"
+
+type countingChecker struct{ n atomic.Int64 }
+
+func (c *countingChecker) Check(ctx context.Context, n models.Node) monitoring.Result {
+	c.n.Add(1)
+	return monitoring.Result{Up: true}
+}
+
+var checks countingChecker
+
+// testScheduler returns a scheduler over store that is not started
+func testScheduler(t *testing.T, store *repositories.Store, jitter float64) *Scheduler {
+	t.Helper()
+	s := New(store)
+	s.jitter = jitter
+	runner, err := monitoring.NewRunner(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.runner = runner
+	return s
+}
+
+func createNode(t *testing.T, store *repositories.Store, n models.Node) models.Node {
+	t.Helper()
+	n.Type = testType
+	if n.Lifecycle == "" {
+		n.Lifecycle = models.NodeActive
+	}
+	if err := store.Nodes.Create(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestQueueOrder(t *testing.T) {
+	t0 := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	var q queue
+	entries := make([]*entry, 20)
+	for i, m := range rand.Perm(len(entries)) {
+		entries[i] = &entry{node: models.Node{ID: uint(m)}, next: t0.Add(time.Duration(m) * time.Minute)}
+		heap.Push(&q, entries[i])
+	}
+	for i, e := range q {
+		if e.index != i {
+			t.Fatalf("entry at %d has index %d", i, e.index)
+		}
+	}
+
+	// moving an entry keeps the heap ordered
+	last := entries[0]
+	last.next = t0.Add(time.Hour)
+	heap.Fix(&q, last.index)
+
+	var previous time.Time
+	for q.Len() > 0 {
+		e := heap.Pop(&q).(*entry)
+		if e.next.Before(previous) {
+			t.Fatalf("popped %s after %s", e.next, previous)
+		}
+		if e.index != -1 {
+			t.Errorf("popped entry has index %d, want -1", e.index)
+		}
+		previous = e.next
+	}
+	if previous != last.next {
+		t.Errorf("last popped = %s, want the moved entry", previous)
+	}
+}
+
+func TestJitterOffset(t *testing.T) {
+	tests := []struct {
+		jitter   float64
+		interval time.Duration
+		max      time.Duration
+	}{
+		{jitter: 0, interval: time.Minute, max: 0},
+		{jitter: 0.1, interval: time.Minute, max: 3 * time.Second},
+		{jitter: 0.5, interval: time.Hour, max: 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		s := &Scheduler{jitter: tt.jitter}
+		var low, high time.Duration
+		for i := 0; i < 1000; i++ {
+			d := s.jitterOffset(tt.interval)
+			if d < -tt.max || d > tt.max {
+				t.Fatalf("jitterOffset(%s) with jitter %v = %s, want within ±%s", tt.interval, tt.jitter, d, tt.max)
+			}
+			low, high = min(low, d), max(high, d)
+		}
+		// the offsets spread over the window, both ways
+		if tt.max > 0 && (low > -tt.max/2 || high < tt.max/2) {
+			t.Errorf("jitter %v: offsets span [%s, %s], want most of ±%s", tt.jitter, low, high, tt.max)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	store := memory.NewStore()
+	s := testScheduler(t, store, 0.2)
+	now := time.Now()
+
+	fresh := createNode(t, store, models.Node{URL: "test://fresh", Interval: 600})
+	recent := createNode(t, store, models.Node{URL: "test://recent", Interval: 600})
+	overdue := createNode(t, store, models.Node{URL: "test://overdue", Interval: 600})
+	createNode(t, store, models.Node{URL: "test://paused", Lifecycle: models.NodePaused})
+	err := store.Histories.SaveMany([]models.History{
+		{ID: 1, NodeID: recent.ID, UpdatedAt: now.Add(-time.Minute)},
+		{ID: 2, NodeID: overdue.ID, UpdatedAt: now.Add(-time.Hour)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.reload()
+	if len(s.entries) != 3 || s.queue.Len() != 3 {
+		t.Fatalf("%d entries, %d queued, want the 3 active nodes", len(s.entries), s.queue.Len())
+	}
+	// nodes without a recent check start within the jitter window
+	for _, id := range []uint{fresh.ID, overdue.ID} {
+		if next := s.entries[id].next; next.Before(now) || next.After(now.Add(2*time.Minute)) {
+			t.Errorf("node %d is due at %s, want within 2m of %s", id, next, now)
+		}
+	}
+	// a recently checked node continues its interval
+	if next := s.entries[recent.ID].next; !next.Equal(now.Add(9 * time.Minute)) {
+		t.Errorf("recent node is due at %s, want %s", next, now.Add(9*time.Minute))
+	}
+
+	// a longer interval moves the next run by the difference
+	recent.Interval = 900
+	if err := store.Nodes.Update(&recent); err != nil {
+		t.Fatal(err)
+	}
+	// pausing and deleting drop the node from the queue
+	fresh.Lifecycle = models.NodePaused
+	if err := store.Nodes.Update(&fresh); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Nodes.Delete(&overdue); err != nil {
+		t.Fatal(err)
+	}
+
+	s.reload()
+	if len(s.entries) != 1 || s.queue.Len() != 1 || s.entries[recent.ID] == nil {
+		t.Fatalf("entries = %v, want only node %d", s.entries, recent.ID)
+	}
+	if e := s.entries[recent.ID]; !e.next.Equal(now.Add(14*time.Minute)) || e.node.Interval != 900 {
+		t.Errorf("recent node is due at %s with interval %d, want %s and 900", e.next, e.node.Interval, now.Add(14*time.Minute))
+	}
+
+	// a shorter interval never schedules the node in the past
+	recent.Interval = 10
+	if err := store.Nodes.Update(&recent); err != nil {
+		t.Fatal(err)
+	}
+	s.reload()
+	if next := s.entries[recent.ID].next; next.Before(now) || next.After(time.Now()) {
+		t.Errorf("recent node is due at %s, want now", next)
+	}
+}
+
+func TestReschedule(t *testing.T) {
+	store := memory.NewStore()
+	s := testScheduler(t, store, 0)
+	n := createNode(t, store, models.Node{URL: "test://node", Interval: 60})
+	s.reload()
+
+	e := heap.Pop(&s.queue).(*entry)
+	tests := []struct {
+		name string
+		due  time.Time
+		want time.Time
+	}{
+		{name: "one interval after it was due", due: time.Now().Add(-10 * time.Second), want: time.Now().Add(50 * time.Second)},
+		{name: "not in the past after a slow check", due: time.Now().Add(-5 * time.Minute), want: time.Now()},
+	}
+	for _, tt := range tests {
+		s.reschedule(job{entry: e, node: n, due: tt.due})
+		if s.queue.Len() != 1 || e.index != 0 {
+			t.Fatalf("%s: entry not queued", tt.name)
+		}
+		if d := e.next.Sub(tt.want); d < -time.Second || d > time.Second {
+			t.Errorf("%s: next = %s, want %s", tt.name, e.next, tt.want)
+		}
+		heap.Pop(&s.queue)
+	}
+
+	// a node deleted while it was checked is not queued again
+	delete(s.entries, n.ID)
+	s.reschedule(job{entry: e, node: n, due: time.Now()})
+	if s.queue.Len() != 0 {
+		t.Errorf("deleted node queued again")
+	}
+}
+
+func TestRunAndInvalidate(t *testing.T) {
+	store := memory.NewStore()
+	s := New(store)
+	s.jitter = 0
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	// a node created after the start is only picked up once invalidated,
+	// the reload interval being an hour
+	before := checks.n.Load()
+	n := createNode(t, store, models.Node{URL: "test://invalidated", Interval: 60})
+	Invalidate()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for checks.n.Load() == before {
+		if time.Now().After(deadline) {
+			t.Fatal("the node was not checked after Invalidate()")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// once checked it waits for its next interval
+	var next time.Time
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		e := s.entries[n.ID]
+		queued := e != nil && e.index >= 0
+		if queued {
+			next = e.next
+		}
+		s.mu.Unlock()
+		if queued {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if d := time.Until(next); d < 50*time.Second || d > time.Minute {
+		t.Errorf("next check in %s, want about a minute", d)
+	}
+	if got := checks.n.Load() - before; got != 1 {
+		t.Errorf("%d checks, want 1", got)
+	}
+}
@@ -0,0 +1,341 @@
+// Package sla computes uptime, reliability and latency figures from check results.
+package sla
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"uptime/config"
+	"uptime/internal/maintenance"
+	"uptime/models"
+)
+
+// delayBounds are the upper bounds in milliseconds of the delay histogram
+// stored with rollups. Slower checks are counted under overflowBucket.
+var delayBounds = []uint{50, 100, 200, 300, 500, 750, 1000, 1500, 2000, 3000, 5000, 10000, 30000, 60000}
+
+const overflowBucket = "inf"
+
+// Sample is one check result. Its state holds until the next sample, or for
+// at most the input's MaxGap when no further check was recorded.
+type Sample struct {
+	Time        time.Time
+	State       string // models.StateUp, StateDown or StateSuspended
+	Maintenance bool
+	Delay       *float64
+}
+
+// Input is the data of one node
+type Input struct {
+	Samples     []Sample               // ascending; may start with the last sample before the range
+	RawFrom     time.Time              // samples cover [RawFrom, to), zero means the whole range
+	Rollups     []models.NodeLogRollup // summaries of the time before RawFrom
+	MaxGap      time.Duration
+	Maintenance []maintenance.Interval // merged windows, see maintenance.Set.For
+	Incidents   []models.Incident
+}
+
+// MaxGap is how long a check result of n holds when no later one was recorded
+func MaxGap(n models.Node) time.Duration {
+	if n.Interval > 0 {
+		return 2 * time.Duration(n.Interval) * time.Second
+	}
+	return 2 * config.AppConfig.UptimeChecker.CheckInterval
+}
+
+// Totals is the time spent in each state within a range
+type Totals struct {
+	Up          time.Duration
+	Down        time.Duration
+	Suspended   time.Duration
+	Maintenance time.Duration
+	Unknown     time.Duration // no check result covers it
+}
+
+// Monitored is the time that counts towards the uptime percentage
+func (t Totals) Monitored() time.Duration {
+	return t.Up + t.Down + t.Suspended
+}
+
+func (t *Totals) add(state string, d time.Duration) {
+	switch state {
+	case models.StateUp:
+		t.Up += d
+	case models.StateSuspended:
+		t.Suspended += d
+	default:
+		t.Down += d
+	}
+}
+
+// Latency holds delay percentiles in seconds, nil when there were no successful checks
+type Latency struct {
+	P50         *float64 `json:"p50"`
+	P95         *float64 `json:"p95"`
+	P99         *float64 `json:"p99"`
+	Samples     int      `json:"samples"`
+	Approximate bool     `json:"approximate,omitempty"` // read from rollup histograms, each value is a bucket's upper bound
+}
+
+// Report is the SLA summary of a node or a set of nodes. Durations are in seconds.
+type Report struct {
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+	UptimePercent   *float64  `json:"uptime_percent"` // nil when nothing was monitored
+	MonitoredTime   float64   `json:"monitored_time"`
+	Downtime        float64   `json:"downtime"` // down and suspended
+	SuspendedTime   float64   `json:"suspended_time"`
+	MaintenanceTime float64   `json:"maintenance_time"`
+	UnknownTime     float64   `json:"unknown_time"`
+	Checks          int       `json:"checks"`
+	Incidents       int       `json:"incidents"`
+	MTTR            *float64  `json:"mttr"` // mean duration of the resolved incidents
+	MTBF            *float64  `json:"mtbf"` // uptime divided by the number of incidents, nil without uptime
+	Latency         Latency   `json:"latency"`
+}
+
+// Compute builds the report of the given nodes over [from, to)
+func Compute(from, to time.Time, inputs ...Input) Report {
+	var (
+		totals    Totals
+		delays    []float64
+		histogram = models.Counts{}
+		checks    int
+		incidents int
+		repaired  int
+		repair    time.Duration
+	)
+
+	for _, in := range inputs {
+		rawFrom := from
+		if in.RawFrom.After(from) {
+			rawFrom = in.RawFrom
+		}
+		totals = addTotals(totals, Weigh(in.Samples, rawFrom, to, in.MaxGap, in.Maintenance))
+
+		for _, r := range in.Rollups {
+			totals = addTotals(totals, Totals{
+				Up:          seconds(r.UpTime),
+				Down:        seconds(r.DownTime),
+				Suspended:   seconds(r.SuspendedTime),
+				Maintenance: seconds(r.MaintenanceTime),
+				Unknown:     seconds(r.UnknownTime),
+			})
+			checks += int(r.Checks)
+			for bucket, n := range r.DelayBuckets {
+				histogram[bucket] += n
+			}
+		}
+
+		for _, s := range in.Samples {
+			if s.Time.Before(rawFrom) || !s.Time.Before(to) {
+				continue
+			}
+			checks++
+			if s.Delay != nil && s.State == models.StateUp && !s.Maintenance {
+				delays = append(delays, *s.Delay)
+			}
+		}
+
+		for _, inc := range in.Incidents {
+			if inc.StartedAt.Before(from) || !inc.StartedAt.Before(to) {
+				continue
+			}
+			incidents++
+			if inc.ResolvedAt != nil {
+				repaired++
+				repair += inc.ResolvedAt.Sub(inc.StartedAt)
+			}
+		}
+	}
+
+	report := Report{
+		From:            from,
+		To:              to,
+		MonitoredTime:   totals.Monitored().Seconds(),
+		Downtime:        (totals.Down + totals.Suspended).Seconds(),
+		SuspendedTime:   totals.Suspended.Seconds(),
+		MaintenanceTime: totals.Maintenance.Seconds(),
+		UnknownTime:     totals.Unknown.Seconds(),
+		Checks:          checks,
+		Incidents:       incidents,
+		Latency:         Percentiles(delays),
+	}
+	if len(histogram) > 0 {
+		for bucket, n := range DelayBuckets(delays) {
+			histogram[bucket] += n
+		}
+		report.Latency = HistogramPercentiles(histogram)
+	}
+	if monitored := totals.Monitored(); monitored > 0 {
+		report.UptimePercent = ratio(float64(totals.Up)*100, float64(monitored))
+	}
+	if repaired > 0 {
+		report.MTTR = ratio(repair.Seconds(), float64(repaired))
+	}
+	// Without any uptime there is no time between failures to speak of
+	if incidents > 0 && totals.Up > 0 {
+		report.MTBF = ratio(totals.Up.Seconds(), float64(incidents))
+	}
+	return report
+}
+
+// Weigh spreads [from, to) over the states of the samples. A sample covers
+// the time until the next one, capped at maxGap; maintenance samples and the
+// maintenance intervals count as maintenance and everything else as unknown.
+func Weigh(samples []Sample, from, to time.Time, maxGap time.Duration, windows []maintenance.Interval) Totals {
+	var totals Totals
+	if !to.After(from) {
+		return totals
+	}
+
+	w := 0
+	for i, s := range samples {
+		end := to
+		if i+1 < len(samples) && samples[i+1].Time.Before(end) {
+			end = samples[i+1].Time
+		}
+		if maxGap > 0 && s.Time.Add(maxGap).Before(end) {
+			end = s.Time.Add(maxGap)
+		}
+		start := s.Time
+		if start.Before(from) {
+			start = from
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		span := end.Sub(start)
+		if s.Maintenance {
+			totals.Maintenance += span
+			continue
+		}
+
+		for w < len(windows) && !windows[w].End.After(start) {
+			w++
+		}
+		var overlap time.Duration
+		for j := w; j < len(windows) && windows[j].Start.Before(end); j++ {
+			overlap += overlapOf(windows[j], start, end)
+		}
+		totals.Maintenance += overlap
+		totals.add(s.State, span-overlap)
+	}
+
+	covered := totals.Monitored() + totals.Maintenance
+	if total := to.Sub(from); total > covered {
+		totals.Unknown = total - covered
+	}
+	return totals
+}
+
+// Percentiles returns the nearest-rank p50, p95 and p99 of delays
+func Percentiles(delays []float64) Latency {
+	latency := Latency{Samples: len(delays)}
+	if len(delays) == 0 {
+		return latency
+	}
+	sorted := append([]float64(nil), delays...)
+	sort.Float64s(sorted)
+	latency.P50 = rank(sorted, 50)
+	latency.P95 = rank(sorted, 95)
+	latency.P99 = rank(sorted, 99)
+	return latency
+}
+
+// DelayBuckets counts delays, given in seconds, per histogram bucket
+func DelayBuckets(delays []float64) models.Counts {
+	if len(delays) == 0 {
+		return nil
+	}
+	counts := models.Counts{}
+	for _, d := range delays {
+		ms := d * 1000
+		bucket := overflowBucket
+		for _, bound := range delayBounds {
+			if ms <= float64(bound) {
+				bucket = strconv.FormatUint(uint64(bound), 10)
+				break
+			}
+		}
+		counts[bucket]++
+	}
+	return counts
+}
+
+// HistogramPercentiles returns the p50, p95 and p99 of a delay histogram as
+// the upper bound of the bucket holding each rank. The overflow bucket is
+// reported as the largest bound.
+func HistogramPercentiles(counts models.Counts) Latency {
+	var total uint
+	for _, n := range counts {
+		total += n
+	}
+	latency := Latency{Samples: int(total), Approximate: true}
+	if total == 0 {
+		return latency
+	}
+
+	at := func(p float64) *float64 {
+		target := uint(math.Ceil(p / 100 * float64(total)))
+		var seen uint
+		for _, bound := range delayBounds {
+			seen += counts[strconv.FormatUint(uint64(bound), 10)]
+			if seen >= target {
+				v := float64(bound) / 1000
+				return &v
+			}
+		}
+		v := float64(delayBounds[len(delayBounds)-1]) / 1000
+		return &v
+	}
+	latency.P50 = at(50)
+	latency.P95 = at(95)
+	latency.P99 = at(99)
+	return latency
+}
+
+func rank(sorted []float64, p float64) *float64 {
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	v := sorted[i]
+	return &v
+}
+
+func overlapOf(in maintenance.Interval, start, end time.Time) time.Duration {
+	if in.Start.After(start) {
+		start = in.Start
+	}
+	if in.End.Before(end) {
+		end = in.End
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+func addTotals(a, b Totals) Totals {
+	return Totals{
+		Up:          a.Up + b.Up,
+		Down:        a.Down + b.Down,
+		Suspended:   a.Suspended + b.Suspended,
+		Maintenance: a.Maintenance + b.Maintenance,
+		Unknown:     a.Unknown + b.Unknown,
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// ratio rounds a/b to four decimals
+func ratio(a, b float64) *float64 {
+	v := math.Round(a/b*10000) / 10000
+	return &v
+}
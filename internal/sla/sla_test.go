@@ -0,0 +1,188 @@
+package sla
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"uptime/internal/maintenance"
+	"uptime/models"
+)
+
+var t0 = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+// minute returns t0 plus m minutes
+func minute(m int) time.Time {
+	return t0.Add(time.Duration(m) * time.Minute)
+}
+
+func sample(m int, state string) Sample {
+	return Sample{Time: minute(m), State: state}
+}
+
+func TestWeigh(t *testing.T) {
+	up, down, suspended := models.StateUp, models.StateDown, models.StateSuspended
+
+	tests := []struct {
+		name    string
+		samples []Sample
+		from    int
+		to      int
+		maxGap  time.Duration
+		windows []maintenance.Interval
+		want    Totals
+	}{
+		{name: "no samples", from: 0, to: 60, want: Totals{Unknown: time.Hour}},
+		{name: "empty range", samples: []Sample{sample(0, up)}, from: 60, to: 60, want: Totals{}},
+		{
+			name:    "each sample holds until the next",
+			samples: []Sample{sample(0, up), sample(10, down), sample(15, up), sample(40, suspended)},
+			from:    0, to: 60,
+			want: Totals{Up: 35 * time.Minute, Down: 5 * time.Minute, Suspended: 20 * time.Minute},
+		},
+		{
+			name:    "sample before the range",
+			samples: []Sample{sample(-30, down), sample(20, up)},
+			from:    0, to: 60,
+			want: Totals{Down: 20 * time.Minute, Up: 40 * time.Minute},
+		},
+		{
+			name:    "samples after the range are ignored",
+			samples: []Sample{sample(0, up), sample(60, down), sample(70, up)},
+			from:    0, to: 60,
+			want: Totals{Up: time.Hour},
+		},
+		{
+			name:    "gaps over maxGap are unknown",
+			samples: []Sample{sample(0, up), sample(30, down)},
+			from:    0, to: 60, maxGap: 10 * time.Minute,
+			want: Totals{Up: 10 * time.Minute, Down: 10 * time.Minute, Unknown: 40 * time.Minute},
+		},
+		{
+			name:    "unknown until the first sample",
+			samples: []Sample{sample(15, up)},
+			from:    0, to: 60,
+			want: Totals{Up: 45 * time.Minute, Unknown: 15 * time.Minute},
+		},
+		{
+			name:    "maintenance samples",
+			samples: []Sample{sample(0, down), {Time: minute(20), State: down, Maintenance: true}, sample(30, up)},
+			from:    0, to: 60,
+			want: Totals{Down: 20 * time.Minute, Maintenance: 10 * time.Minute, Up: 30 * time.Minute},
+		},
+		{
+			name:    "maintenance windows carve out of states",
+			samples: []Sample{sample(0, down), sample(30, up)},
+			from:    0, to: 60,
+			windows: []maintenance.Interval{{Start: minute(10), End: minute(20)}, {Start: minute(25), End: minute(35)}, {Start: minute(50), End: minute(70)}},
+			want:    Totals{Down: 15 * time.Minute, Up: 15 * time.Minute, Maintenance: 30 * time.Minute},
+		},
+		{
+			name:    "windows do not count twice over maintenance samples",
+			samples: []Sample{{Time: minute(0), State: down, Maintenance: true}},
+			from:    0, to: 60,
+			windows: []maintenance.Interval{{Start: minute(0), End: minute(60)}},
+			want:    Totals{Maintenance: time.Hour},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Weigh(tt.samples, minute(tt.from), minute(tt.to), tt.maxGap, tt.windows)
+			if got != tt.want {
+				t.Errorf("Weigh() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	delay := func(d float64) *float64 { return &d }
+	resolvedAt := minute(40)
+	in := Input{
+		Samples: []Sample{
+			{Time: minute(-5), State: models.StateUp, Delay: delay(9)}, // before the range, not a check
+			{Time: minute(0), State: models.StateUp, Delay: delay(0.2)},
+			{Time: minute(30), State: models.StateDown, Delay: delay(5)},
+			{Time: minute(40), State: models.StateUp, Delay: delay(0.4)},
+		},
+		Incidents: []models.Incident{
+			{StartedAt: minute(30), ResolvedAt: &resolvedAt},
+			{StartedAt: minute(-60)}, // started before the range
+		},
+	}
+
+	r := Compute(minute(0), minute(60), in)
+	if r.UptimePercent == nil || *r.UptimePercent != 83.3333 {
+		t.Errorf("UptimePercent = %v, want 83.3333", r.UptimePercent)
+	}
+	if r.MonitoredTime != 3600 || r.Downtime != 600 || r.Checks != 3 || r.Incidents != 1 {
+		t.Errorf("report = %+v", r)
+	}
+	if r.MTTR == nil || *r.MTTR != 600 || r.MTBF == nil || *r.MTBF != 3000 {
+		t.Errorf("MTTR, MTBF = %v, %v, want 600, 3000", r.MTTR, r.MTBF)
+	}
+	if r.Latency.Samples != 2 || *r.Latency.P50 != 0.2 || *r.Latency.P99 != 0.4 || r.Latency.Approximate {
+		t.Errorf("Latency = %+v, want 2 exact samples", r.Latency)
+	}
+
+	rolled := in
+	rolled.RawFrom = minute(30)
+	rolled.Rollups = []models.NodeLogRollup{{Checks: 10, UpTime: 1800, DelayBuckets: models.Counts{"100": 10}}}
+	r = Compute(minute(0), minute(60), rolled)
+	if r.Checks != 12 || r.MonitoredTime != 3600 || *r.UptimePercent != 83.3333 {
+		t.Errorf("with rollups: report = %+v", r)
+	}
+	if !r.Latency.Approximate || r.Latency.Samples != 11 || *r.Latency.P50 != 0.1 || *r.Latency.P99 != 0.5 {
+		t.Errorf("with rollups: Latency = %+v", r.Latency)
+	}
+
+	if r := Compute(minute(0), minute(60)); r.UptimePercent != nil || r.MTTR != nil || r.MTBF != nil {
+		t.Errorf("without input: report = %+v, want no ratios", r)
+	}
+
+	// down the whole range with one open incident: no time between failures
+	down := Input{
+		Samples:   []Sample{{Time: minute(0), State: models.StateDown}},
+		Incidents: []models.Incident{{StartedAt: minute(0)}},
+	}
+	if r := Compute(minute(0), minute(60), down); r.Incidents != 1 || r.MTBF != nil || r.MTTR != nil || *r.UptimePercent != 0 {
+		t.Errorf("always down: MTBF, MTTR = %v, %v, want nil", r.MTBF, r.MTTR)
+	}
+}
+
+func TestPercentiles(t *testing.T) {
+	tests := []struct {
+		delays        []float64
+		p50, p95, p99 string
+	}{
+		{delays: nil, p50: "<nil>", p95: "<nil>", p99: "<nil>"},
+		{delays: []float64{3}, p50: "3", p95: "3", p99: "3"},
+		{delays: []float64{4, 1, 3, 2}, p50: "2", p95: "4", p99: "4"},
+	}
+	for _, tt := range tests {
+		got := Percentiles(tt.delays)
+		str := func(v *float64) string {
+			if v == nil {
+				return "<nil>"
+			}
+			return fmt.Sprint(*v)
+		}
+		if str(got.P50) != tt.p50 || str(got.P95) != tt.p95 || str(got.P99) != tt.p99 || got.Samples != len(tt.delays) {
+			t.Errorf("Percentiles(%v) = %s, %s, %s", tt.delays, str(got.P50), str(got.P95), str(got.P99))
+		}
+	}
+
+	// 100 evenly spread checks, from 10 to 1000 ms
+	var delays []float64
+	for i := 1; i <= 100; i++ {
+		delays = append(delays, float64(i)/100)
+	}
+	buckets := DelayBuckets(append(delays, 120))
+	if buckets["50"] != 5 || buckets["100"] != 5 || buckets["1000"] != 25 || buckets["inf"] != 1 {
+		t.Errorf("DelayBuckets() = %v", buckets)
+	}
+	h := HistogramPercentiles(buckets)
+	if h.Samples != 101 || *h.P50 != 0.75 || *h.P95 != 1 || *h.P99 != 1 {
+		t.Errorf("HistogramPercentiles() = %v, %v, %v", *h.P50, *h.P95, *h.P99)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -18,10 +19,14 @@ import (
 	"uptime/database"
 	_ "uptime/docs"
 	"uptime/internal/logcleanup"
-	"uptime/models"
+	"uptime/internal/notify"
+	"uptime/internal/rollup"
+	"uptime/internal/scheduler"
 	"uptime/monitoring"
+	"uptime/repositories"
+	"uptime/repositories/memory"
 	"uptime/routes"
-	"uptime/internal/optimize"
+	"uptime/services"
 )
 
 // @title Uptime Monitoring API
@@ -39,33 +44,47 @@ import (
 // @in header
 // @name X-API-Key
 
-func startUptimeChecker() *cron.Cron {
-	c := cron.New()
+func startUptimeChecker(store *repositories.Store) *scheduler.Scheduler {
+	s := scheduler.New(store)
+	if err := s.Start(); err != nil {
+		log.Fatal("Failed to start uptime scheduler:", err)
+	}
+	log.Println("Uptime scheduler started")
+	return s
+}
 
-	checkInterval := "@every " + config.AppConfig.UptimeChecker.CheckInterval.String()
-	_, err := c.AddFunc(checkInterval, func() {
-		var nodes []models.Node
-		if err := database.DB.Find(&nodes).Error; err != nil {
-			log.Println("Error fetching nodes:", err)
-			return
-		}
-		if len(nodes) == 0 {
-			log.Println("No nodes found")
-			return
-		}
+// openStore connects to the database and returns where nodes, logs,
+// histories and incidents are kept. In memory mode the other tables live in an
+// in-memory SQLite database, so nothing outside the process is needed.
+func openStore(storage string) *repositories.Store {
+	switch storage {
+	case "sql":
+	case "memory":
+		config.AppConfig.Database.Driver = database.SQLite
+		config.AppConfig.Database.DSN = ":memory:"
+	default:
+		log.Fatalf("Unknown storage %q, use sql or memory", storage)
+	}
 
-		monitoring.Check(nodes)
-		log.Println("Uptime check completed")
-	})
-	if err != nil {
-		log.Println("Failed to schedule uptime checker:", err)
+	if err := database.Connect(); err != nil {
+		log.Fatal(err)
 	}
 
-	c.Start()
-	return c
+	if err := database.Migrate(); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	if storage == "memory" {
+		log.Println("Keeping nodes, logs, histories, incidents and certificates in memory, they are lost on exit")
+		return memory.NewStore()
+	}
+	return repositories.NewSQLStore(database.DB)
 }
 
 func main() {
+	storage := flag.String("storage", "sql", "where nodes, logs, histories, incidents and certificates are kept: sql or memory")
+	flag.Parse()
+
 	envLoadingErr := godotenv.Load()
 	if envLoadingErr != nil {
 		log.Println("No .env file found, using system environment variables")
@@ -75,22 +94,20 @@ func main() {
 	config.Load()
 
 	// Connect to database
-	database.Connect()
+	store := openStore(*storage)
+	services.SetStore(store)
 
-	// Run optimize logic after DB connection
-	dbSQL, err := database.DB.DB()
-	if err != nil {
-		log.Fatal("Failed to get database connection:", err)
-	}
-	optimize.Run(dbSQL)
+	// Write check results in batches
+	resultWriter := monitoring.StartWriter(store)
 
-	// Start uptime checker cron
-	uptimeCron := startUptimeChecker()
+	// Start uptime checker
+	uptimeScheduler := startUptimeChecker(store)
 
-	// Start log cleanup cron every 5 minutes
-	logCleanupCron := cron.New()
-	_, err = logCleanupCron.AddFunc("@every 5m", func() {
-		logcleanup.CleanupOldLogs()
+	// Start log rollup and cleanup cron on CLEANUP_SCHEDULE
+	logCleanupCron := cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	_, err := logCleanupCron.AddFunc(config.AppConfig.Retention.Schedule, func() {
+		rollup.Run(store)
+		logcleanup.CleanupOldLogs(store)
 	})
 	if err != nil {
 		log.Println("Failed to schedule log cleanup:", err)
@@ -134,9 +151,15 @@ func main() {
 	<-quit
 	log.Println("Shutting down server...")
 
-	// Stop crons
-	uptimeCron.Stop()
-	logCleanupCron.Stop()
+	// Stop crons, waiting for the checks and the cleanup in flight
+	uptimeScheduler.Stop()
+	<-logCleanupCron.Stop().Done()
+
+	// Write the check results still queued
+	resultWriter.Stop()
+
+	// Send chat notifications still waiting for their batch window
+	notify.Flush()
 
 	if err := app.Shutdown(); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
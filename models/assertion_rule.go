@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// Assertion rule types
+const (
+	RuleContains    = "contains"
+	RuleNotContains = "not_contains"
+	RuleRegex       = "regex"
+	RuleJSONPath    = "json_path"
+	RuleHeader      = "header"
+)
+
+// Assertion rule severities, applied to the check result when a rule fails
+const (
+	SeverityInfo      = "info"      // recorded in the exception, node stays up
+	SeveritySuspended = "suspended" // node is flagged as suspended
+	SeverityDown      = "down"      // node is marked down
+)
+
+// AssertionRule is a content assertion evaluated against every HTTP response.
+// Rules without a NodeID form the global default set.
+type AssertionRule struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	NodeID     *uint     `gorm:"index" json:"node_id"`
+	Name       string    `gorm:"size:255" json:"name"`
+	Type       string    `gorm:"size:20" json:"type"`
+	Target     string    `gorm:"size:255" json:"target"` // header name or JSON path
+	Value      string    `gorm:"type:text" json:"value"`
+	IgnoreCase bool      `gorm:"default:false" json:"ignore_case"`
+	Severity   string    `gorm:"size:20;default:down" json:"severity"`
+	Enabled    bool      `gorm:"default:true" json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name used by AssertionRule to `assertion_rules`
+func (AssertionRule) TableName() string {
+	return "assertion_rules"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// ChatChannel posts state change notifications to a Telegram chat or a Slack-style incoming webhook
+type ChatChannel struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"size:255" json:"name"`
+	Kind        string    `gorm:"size:20" json:"kind"`               // telegram or slack
+	BotToken    string    `gorm:"size:255" json:"-"`                 // telegram bot token
+	ChatID      string    `gorm:"size:255" json:"chat_id,omitempty"` // telegram chat, user or @channel
+	WebhookURL  string    `gorm:"size:1024" json:"-"`                // slack incoming webhook URL
+	APIBaseURL  string    `gorm:"size:255" json:"api_base_url"`      // telegram: overrides TELEGRAM_API_URL
+	NodeIDs     IDList    `gorm:"type:text" json:"node_ids"`         // nodes covered, together with GroupIDs
+	GroupIDs    IDList    `gorm:"type:text" json:"group_ids"`        // both empty means every node
+	Events      string    `gorm:"size:255" json:"events"`            // comma separated new states, empty means all
+	MaxAttempts uint      `gorm:"default:3" json:"max_attempts"`
+	Enabled     bool      `gorm:"default:true" json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name used by ChatChannel to `chat_channels`
+func (ChatChannel) TableName() string {
+	return "chat_channels"
+}
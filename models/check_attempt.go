@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// CheckAttempt is a single try of a check that was retried before its result was logged
+type CheckAttempt struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	NodeID    uint      `gorm:"index" json:"node_id"`
+	NodeLogID uint      `gorm:"index" json:"node_log_id"`
+	Attempt   uint      `json:"attempt"`
+	Delay     *float64  `json:"delay,omitempty"`
+	Status    *uint     `json:"status,omitempty"`
+	Up        bool      `gorm:"default:false" json:"up"`
+	Exception *string   `json:"exception,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name used by CheckAttempt to `check_attempts`
+func (CheckAttempt) TableName() string {
+	return "check_attempts"
+}
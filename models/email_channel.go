@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// SMTP TLS modes
+const (
+	TLSModeNone     = "none"     // plain connection
+	TLSModeStartTLS = "starttls" // upgrade with STARTTLS, usually port 587
+	TLSModeTLS      = "tls"      // implicit TLS, usually port 465
+)
+
+// EmailChannel sends state change notifications through an SMTP server
+type EmailChannel struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	Name            string     `gorm:"size:255" json:"name"`
+	Host            string     `gorm:"size:255" json:"host"`
+	Port            uint       `json:"port"`
+	Username        string     `gorm:"size:255" json:"username"`
+	Password        string     `gorm:"size:255" json:"-"`
+	TLSMode         string     `gorm:"size:10;default:starttls" json:"tls_mode"`
+	SkipVerify      bool       `gorm:"default:false" json:"skip_verify"` // accept self-signed server certificates
+	From            string     `gorm:"size:255" json:"from"`
+	To              StringList `gorm:"type:text" json:"to"`
+	NodeIDs         IDList     `gorm:"type:text" json:"node_ids"`  // nodes covered, together with GroupIDs
+	GroupIDs        IDList     `gorm:"type:text" json:"group_ids"` // both empty means every node
+	Events          string     `gorm:"size:255" json:"events"`     // comma separated new states, empty means all
+	SubjectTemplate string     `gorm:"type:text" json:"subject_template"`
+	TextTemplate    string     `gorm:"type:text" json:"text_template"` // text/template, empty uses the default
+	HTMLTemplate    string     `gorm:"type:text" json:"html_template"` // html/template, empty uses the default
+	MaxAttempts     uint       `gorm:"default:3" json:"max_attempts"`
+	Enabled         bool       `gorm:"default:true" json:"enabled"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the table name used by EmailChannel to `email_channels`
+func (EmailChannel) TableName() string {
+	return "email_channels"
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+)
+
+// Group organizes nodes, e.g. by customer or hosting server. Groups nest:
+// filters and summaries of a group include its subgroups.
+type Group struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Name        string         `gorm:"uniqueIndex;size:255" json:"name"`
+	Description string         `gorm:"type:text" json:"description"`
+	ParentID    *uint          `gorm:"index" json:"parent_id,omitempty"` // nil for a top level group
+	Retention   GroupRetention `gorm:"embedded;embeddedPrefix:retention_" json:"retention"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// TableName overrides the table name used by Group to `node_groups`
+func (Group) TableName() string {
+	return "node_groups"
+}
+
+// GroupRetention overrides how many days the data of a group's nodes is kept.
+// Nil keeps the global setting and 0 keeps the data forever.
+type GroupRetention struct {
+	RawLogs       *uint `json:"raw_logs"`
+	HourlyRollups *uint `json:"hourly_rollups"`
+	DailyRollups  *uint `json:"daily_rollups"`
+	Incidents     *uint `json:"incidents"`
+	Deliveries    *uint `json:"notification_deliveries"`
+}
+
+// GroupTree is the nesting of a set of groups
+type GroupTree struct {
+	parents  map[uint]uint
+	children map[uint][]uint
+}
+
+// NewGroupTree builds the tree of groups
+func NewGroupTree(groups []Group) *GroupTree {
+	t := &GroupTree{parents: make(map[uint]uint), children: make(map[uint][]uint)}
+	for _, g := range groups {
+		if g.ParentID != nil {
+			t.parents[g.ID] = *g.ParentID
+			t.children[*g.ParentID] = append(t.children[*g.ParentID], g.ID)
+		}
+	}
+	return t
+}
+
+// Subtree returns the ID of the group followed by the IDs of all its
+// subgroups, at any depth
+func (t *GroupTree) Subtree(id uint) []uint {
+	ids := []uint{id}
+	seen := map[uint]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range t.children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// Ancestry returns the ID of the group followed by the IDs of the groups it
+// is nested in, nearest first. It is empty for a node without a group.
+func (t *GroupTree) Ancestry(groupID *uint) []uint {
+	if groupID == nil {
+		return nil
+	}
+	ids := []uint{*groupID}
+	seen := map[uint]bool{*groupID: true}
+	for {
+		parent, ok := t.parents[ids[len(ids)-1]]
+		if !ok || seen[parent] {
+			return ids
+		}
+		seen[parent] = true
+		ids = append(ids, parent)
+	}
+}
@@ -5,15 +5,18 @@ import (
 )
 
 type History struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	NodeID    uint      `gorm:"index" json:"node_id"`
-	Delay     *float64  `json:"delay,omitempty"`
-	Status    *uint     `json:"status,omitempty"`
-	Up        bool      `gorm:"default:false" json:"up"`
-	Suspended bool      `gorm:"default:false" json:"suspended"`
-	Exception *string   `json:"exception,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	NodeID        uint      `gorm:"index" json:"node_id"`
+	Delay         *float64  `json:"delay,omitempty"`
+	Status        *uint     `json:"status,omitempty"`
+	Up            bool      `gorm:"default:false" json:"up"`
+	Suspended     bool      `gorm:"default:false" json:"suspended"`
+	Degraded      bool      `gorm:"default:false" json:"degraded"` // up, but e.g. the certificate is about to expire
+	Exception     *string   `json:"exception,omitempty"`
+	Maintenance   bool      `gorm:"default:false" json:"maintenance"` // checked during a maintenance window
+	FailureStreak uint      `gorm:"default:0" json:"failure_streak"`  // consecutive failing cycles
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 	// DeletedAt removed - table doesn't have this column
 }
 
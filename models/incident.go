@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+)
+
+// Node states
+const (
+	StateUp        = "up"
+	StateDown      = "down"
+	StateSuspended = "suspended"
+)
+
+// Incident statuses
+const (
+	IncidentOpen     = "open"
+	IncidentResolved = "resolved"
+)
+
+// Incident is an outage of a node, opened when it leaves the up state and
+// resolved when it recovers
+type Incident struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	NodeID         uint           `gorm:"index" json:"node_id"`
+	Status         string         `gorm:"size:20;index;default:open" json:"status"`
+	State          string         `gorm:"size:20" json:"state"` // down or suspended
+	StartedAt      time.Time      `gorm:"index" json:"started_at"`
+	ResolvedAt     *time.Time     `json:"resolved_at,omitempty"`
+	Duration       *float64       `json:"duration,omitempty"` // seconds, set once resolved
+	FirstError     *string        `gorm:"type:text" json:"first_error,omitempty"`
+	TriggerLogID   uint           `json:"trigger_log_id"`
+	ResolveLogID   *uint          `json:"resolve_log_id,omitempty"`
+	Acknowledged   bool           `gorm:"default:false" json:"acknowledged"`
+	AcknowledgedAt *time.Time     `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string         `gorm:"size:255" json:"acknowledged_by,omitempty"`
+	Notes          []IncidentNote `gorm:"foreignKey:IncidentID" json:"notes,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// TableName overrides the table name used by Incident to `incidents`
+func (Incident) TableName() string {
+	return "incidents"
+}
+
+// IncidentNote is a free-text annotation on an incident
+type IncidentNote struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	IncidentID uint      `gorm:"index" json:"incident_id"`
+	Author     string    `gorm:"size:255" json:"author"`
+	Body       string    `gorm:"type:text" json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name used by IncidentNote to `incident_notes`
+func (IncidentNote) TableName() string {
+	return "incident_notes"
+}
+
+// NodeState maps the check flags to a node state. Degraded nodes count as up.
+func NodeState(up, suspended bool) string {
+	switch {
+	case !up:
+		return StateDown
+	case suspended:
+		return StateSuspended
+	default:
+		return StateUp
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Maintenance window kinds
+const (
+	MaintenanceOnce   = "once"   // between StartsAt and EndsAt
+	MaintenanceCron   = "cron"   // starts on a cron schedule and lasts Duration minutes
+	MaintenanceWeekly = "weekly" // starts at StartTime on Weekdays and lasts Duration minutes
+)
+
+// MaintenanceWindow is planned downtime. Checks keep running but their logs are
+// flagged, no incidents or notifications are raised and reports leave the time out.
+type MaintenanceWindow struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Name        string     `gorm:"size:255" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	Kind        string     `gorm:"size:10" json:"kind"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`                // once
+	EndsAt      *time.Time `json:"ends_at,omitempty"`                  // once
+	Cron        string     `gorm:"size:255" json:"cron,omitempty"`     // cron: standard 5 field expression
+	Weekdays    string     `gorm:"size:64" json:"weekdays,omitempty"`  // weekly: comma separated, e.g. "sat,sun"
+	StartTime   string     `gorm:"size:5" json:"start_time,omitempty"` // weekly: HH:MM
+	Duration    uint       `json:"duration,omitempty"`                 // cron and weekly: minutes
+	Timezone    string     `gorm:"size:64" json:"timezone"`            // IANA name the schedule is read in
+	NodeIDs     IDList     `gorm:"type:text" json:"node_ids"`          // nodes covered, together with GroupIDs
+	GroupIDs    IDList     `gorm:"type:text" json:"group_ids"`         // both empty means every node
+	Enabled     bool       `gorm:"default:true" json:"enabled"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the table name used by MaintenanceWindow to `maintenance_windows`
+func (MaintenanceWindow) TableName() string {
+	return "maintenance_windows"
+}
@@ -2,16 +2,46 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Node struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	URL       string    `gorm:"uniqueIndex;size:255" json:"url"`
-	NodeLogs  []NodeLog `gorm:"foreignKey:NodeID" json:"node_logs"`
-	Histories []History `gorm:"foreignKey:NodeID" json:"histories"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	// DeletedAt removed - check if table has this column
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	URL              string         `gorm:"uniqueIndex;size:255" json:"url"` // http(s) URL, tcp://host:port or dns://name
+	Type             string         `gorm:"size:10;default:http" json:"type"`
+	Method           string         `gorm:"size:10" json:"method,omitempty"` // http only, empty means GET
+	Headers          Headers        `gorm:"type:text" json:"headers,omitempty"`
+	Body             *string        `gorm:"type:text" json:"body,omitempty"`
+	ExpectedStatus   string         `gorm:"size:255" json:"expected_status"` // e.g. "200-299,301,401", empty means 2xx
+	Timeout          uint           `gorm:"default:0" json:"timeout"`        // seconds, 0 falls back to REQUEST_TIMEOUT
+	Interval         uint           `gorm:"default:0" json:"interval"`       // seconds between checks, 0 falls back to CHECK_INTERVAL
+	SkipDefaultRules bool           `gorm:"default:false" json:"skip_default_rules"`
+	Expect           string         `gorm:"type:text" json:"expect,omitempty"`    // tcp: banner substring, dns: comma separated record values
+	RecordType       string         `gorm:"size:10" json:"record_type,omitempty"` // dns: A, AAAA, CNAME, MX, NS or TXT
+	Resolver         string         `gorm:"size:255" json:"resolver,omitempty"`   // dns: host[:port], empty uses the system resolver
+	GroupID          *uint          `gorm:"index" json:"group_id,omitempty"`
+	Lifecycle        string         `gorm:"size:16;default:active;index" json:"lifecycle"` // active, paused or archived
+	PausedUntil      *time.Time     `json:"paused_until,omitempty"`                        // when a paused node resumes, nil waits for a resume call
+	NodeLogs         []NodeLog      `gorm:"foreignKey:NodeID" json:"node_logs"`
+	Histories        []History      `gorm:"foreignKey:NodeID" json:"histories"`
+	Tags             []Tag          `gorm:"-" json:"tags,omitempty"` // stored in node_tags, filled in by the node listing
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at" swaggertype:"string" format:"date-time"` // set while the node is archived
+}
+
+// Node lifecycle states. Only active nodes are checked; archived nodes are
+// soft deleted and keep their logs and histories until restored.
+const (
+	NodeActive   = "active"
+	NodePaused   = "paused"
+	NodeArchived = "archived"
+)
+
+// IsActive reports whether the node is checked
+func (n Node) IsActive() bool {
+	return n.Lifecycle != NodePaused && n.Lifecycle != NodeArchived && !n.DeletedAt.Valid
 }
 
 // TableName overrides the table name used by Node to `nodes`
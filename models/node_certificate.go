@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// NodeCertificate holds the latest TLS leaf certificate seen for an https node
+type NodeCertificate struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	NodeID     uint      `gorm:"uniqueIndex" json:"node_id"`
+	Subject    string    `gorm:"size:512" json:"subject"`
+	Issuer     string    `gorm:"size:512" json:"issuer"`
+	SANs       string    `gorm:"column:sans;type:text" json:"sans"` // comma separated DNS names and IPs
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `gorm:"index" json:"not_after"`
+	ChainValid bool      `gorm:"default:false" json:"chain_valid"`
+	ChainError *string   `gorm:"type:text" json:"chain_error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name used by NodeCertificate to `node_certificates`
+func (NodeCertificate) TableName() string {
+	return "node_certificates"
+}
+
+// DaysToExpiry returns the whole days left until NotAfter, negative once expired
+func (c NodeCertificate) DaysToExpiry(now time.Time) int {
+	return int(c.NotAfter.Sub(now).Hours() / 24)
+}
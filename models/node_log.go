@@ -5,15 +5,27 @@ import (
 )
 
 type NodeLog struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	NodeID    uint      `gorm:"index" json:"node_id"` // کلید خارجی
-	Delay     *float64  `json:"delay,omitempty"`
-	Status    *uint     `json:"status,omitempty"`
-	Up        bool      `gorm:"default:false" json:"up"`
-	Suspended bool      `gorm:"default:false" json:"suspended"`
-	Exception *string   `json:"exception,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uint     `gorm:"primaryKey" json:"id"`
+	NodeID      uint     `gorm:"index" json:"node_id"` // کلید خارجی
+	Delay       *float64 `json:"delay,omitempty"`
+	Status      *uint    `json:"status,omitempty"`
+	Up          bool     `gorm:"default:false" json:"up"`
+	Suspended   bool     `gorm:"default:false" json:"suspended"`
+	Degraded    bool     `gorm:"default:false" json:"degraded"` // up, but e.g. the certificate is about to expire
+	Exception   *string  `json:"exception,omitempty"`
+	Attempts    uint     `gorm:"default:1" json:"attempts"`
+	Unconfirmed bool     `gorm:"default:false" json:"unconfirmed"` // the check failed but the node has not failed CHECK_CONFIRM_CYCLES times in a row yet
+	// Request phase durations in seconds, nil when the phase did not happen
+	DNSTime      *float64  `json:"dns_time,omitempty"`
+	ConnectTime  *float64  `json:"connect_time,omitempty"`
+	TLSTime      *float64  `json:"tls_time,omitempty"`
+	TTFB         *float64  `json:"ttfb,omitempty"`
+	TransferTime *float64  `json:"transfer_time,omitempty"`
+	ResponseSize *int64    `json:"response_size,omitempty"`                 // bytes
+	Maintenance  bool      `gorm:"default:false" json:"maintenance"`        // checked during a maintenance window
+	CycleID      string    `gorm:"size:32;index" json:"cycle_id,omitempty"` // the check cycle that produced the log
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 	// DeletedAt removed - check if table has this column
 }
 
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+)
+
+// Rollup tiers
+const (
+	RollupHourly = "hourly"
+	RollupDaily  = "daily"
+)
+
+// NodeLogRollup summarizes the node logs of one node over an hour or a day.
+// Durations are time-weighted like the SLA report and given in seconds; the
+// delay figures only cover successful checks outside maintenance.
+type NodeLogRollup struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	NodeID           uint      `gorm:"uniqueIndex:,composite:node_bucket" json:"node_id"`
+	BucketStart      time.Time `gorm:"uniqueIndex:,composite:node_bucket" json:"bucket_start"`
+	BucketEnd        time.Time `json:"bucket_end"`
+	Checks           uint      `json:"checks"`
+	UpCount          uint      `json:"up_count"`
+	DownCount        uint      `json:"down_count"`
+	SuspendedCount   uint      `json:"suspended_count"`
+	MaintenanceCount uint      `json:"maintenance_count"`
+	MinDelay         *float64  `json:"min_delay"`
+	AvgDelay         *float64  `json:"avg_delay"`
+	MaxDelay         *float64  `json:"max_delay"`
+	P95Delay         *float64  `json:"p95_delay"`
+	StatusCodes      Counts    `gorm:"type:text" json:"status_codes"`  // status code to checks
+	DelayBuckets     Counts    `gorm:"type:text" json:"delay_buckets"` // upper bound in ms to checks, for percentiles across buckets
+	UpTime           float64   `json:"up_time"`
+	DownTime         float64   `json:"down_time"`
+	SuspendedTime    float64   `json:"suspended_time"`
+	MaintenanceTime  float64   `json:"maintenance_time"`
+	UnknownTime      float64   `json:"unknown_time"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// HourlyRollup is the schema of the hourly rollup table
+type HourlyRollup NodeLogRollup
+
+// TableName overrides the table name used by HourlyRollup to `node_log_hourly`
+func (HourlyRollup) TableName() string {
+	return "node_log_hourly"
+}
+
+// DailyRollup is the schema of the daily rollup table
+type DailyRollup NodeLogRollup
+
+// TableName overrides the table name used by DailyRollup to `node_log_daily`
+func (DailyRollup) TableName() string {
+	return "node_log_daily"
+}
+
+// RollupTable returns the table of a rollup tier
+func RollupTable(tier string) string {
+	if tier == RollupDaily {
+		return DailyRollup{}.TableName()
+	}
+	return HourlyRollup{}.TableName()
+}
+
+// RollupState records up to when a rollup tier is complete
+type RollupState struct {
+	Tier           string    `gorm:"primaryKey;size:20" json:"tier"`
+	CompletedUntil time.Time `json:"completed_until"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name used by RollupState to `rollup_states`
+func (RollupState) TableName() string {
+	return "rollup_states"
+}
@@ -0,0 +1,18 @@
+package models
+
+// Node check types
+const (
+	NodeTypeHTTP = "http"
+	NodeTypeTCP  = "tcp"
+	NodeTypeDNS  = "dns"
+)
+
+// DNS record types supported by dns checks
+const (
+	DNSRecordA     = "A"
+	DNSRecordAAAA  = "AAAA"
+	DNSRecordCNAME = "CNAME"
+	DNSRecordMX    = "MX"
+	DNSRecordNS    = "NS"
+	DNSRecordTXT   = "TXT"
+)
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// Notification channels
+const (
+	ChannelWebhook  = "webhook"
+	ChannelEmail    = "email"
+	ChannelTelegram = "telegram"
+	ChannelSlack    = "slack"
+)
+
+// Delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// NotificationDelivery records the delivery of one notification to one target
+type NotificationDelivery struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Channel     string     `gorm:"size:20;index:idx_deliveries_target" json:"channel"`
+	TargetID    uint       `gorm:"index:idx_deliveries_target" json:"target_id"`
+	NodeID      uint       `gorm:"index" json:"node_id"`
+	Event       string     `gorm:"size:20" json:"event"`
+	Payload     string     `gorm:"type:text" json:"payload"`
+	Status      string     `gorm:"size:20;index;default:pending" json:"status"`
+	Attempts    uint       `json:"attempts"`
+	StatusCode  *int       `json:"status_code,omitempty"`
+	LastError   *string    `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the table name used by NotificationDelivery to `notification_deliveries`
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag labels nodes across groups, e.g. by plan or environment
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"uniqueIndex;size:64" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name used by Tag to `tags`
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// NodeTag links a node to one of its tags
+type NodeTag struct {
+	NodeID    uint      `gorm:"primaryKey;autoIncrement:false" json:"node_id"`
+	TagID     uint      `gorm:"primaryKey;autoIncrement:false;index" json:"tag_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name used by NodeTag to `node_tags`
+func (NodeTag) TableName() string {
+	return "node_tags"
+}
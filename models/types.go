@@ -0,0 +1,132 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// Headers is a set of HTTP request headers stored as a JSON object column
+type Headers map[string]string
+
+// Value implements driver.Valuer
+func (h Headers) Value() (driver.Value, error) {
+	if len(h) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (h *Headers) Scan(value interface{}) error {
+	data, err := columnBytes(value)
+	if err != nil || len(data) == 0 {
+		*h = nil
+		return err
+	}
+	return json.Unmarshal(data, h)
+}
+
+// IDList is a list of record IDs stored as a JSON array column
+type IDList []uint
+
+// Value implements driver.Valuer
+func (l IDList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (l *IDList) Scan(value interface{}) error {
+	data, err := columnBytes(value)
+	if err != nil || len(data) == 0 {
+		*l = nil
+		return err
+	}
+	return json.Unmarshal(data, l)
+}
+
+// Contains reports whether id is in the list
+func (l IDList) Contains(id uint) bool {
+	for _, v := range l {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// StringList is a list of strings stored as a JSON array column
+type StringList []string
+
+// Value implements driver.Valuer
+func (l StringList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (l *StringList) Scan(value interface{}) error {
+	data, err := columnBytes(value)
+	if err != nil || len(data) == 0 {
+		*l = nil
+		return err
+	}
+	return json.Unmarshal(data, l)
+}
+
+// Counts maps a label, such as a status code, to a number of checks and is
+// stored as a JSON object column
+type Counts map[string]uint
+
+// Value implements driver.Valuer
+func (c Counts) Value() (driver.Value, error) {
+	if len(c) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (c *Counts) Scan(value interface{}) error {
+	data, err := columnBytes(value)
+	if err != nil || len(data) == 0 {
+		*c = nil
+		return err
+	}
+	return json.Unmarshal(data, c)
+}
+
+// columnBytes returns the raw bytes of a text column
+func columnBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, errors.New("unsupported column type")
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Webhook is an HTTP endpoint notified of node state transitions
+type Webhook struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"size:255" json:"name"`
+	URL         string    `gorm:"size:1024" json:"url"`
+	Secret      string    `gorm:"size:255" json:"-"`             // HMAC-SHA256 key, never returned by the API
+	Events      string    `gorm:"size:255" json:"events"`        // comma separated new states, empty means all
+	MaxAttempts uint      `gorm:"default:5" json:"max_attempts"` // deliveries are retried with exponential backoff
+	Enabled     bool      `gorm:"default:true" json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name used by Webhook to `webhooks`
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// HasSecret reports whether payloads are signed
+func (w Webhook) HasSecret() bool {
+	return w.Secret != ""
+}
@@ -0,0 +1,204 @@
+package monitoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+var severityRank = map[string]int{
+	models.SeverityInfo:      1,
+	models.SeveritySuspended: 2,
+	models.SeverityDown:      3,
+}
+
+type assertion struct {
+	rule models.AssertionRule
+	re   *regexp.Regexp
+}
+
+// assertionFailure describes the rule that failed and why
+type assertionFailure struct {
+	rule   models.AssertionRule
+	reason string
+}
+
+func (f *assertionFailure) Error() string {
+	return fmt.Sprintf("assertion %q (rule #%d) failed: %s", f.rule.Name, f.rule.ID, f.reason)
+}
+
+// assertionSet holds the enabled rules for one check cycle
+type assertionSet struct {
+	defaults []assertion
+	byNode   map[uint][]assertion
+}
+
+// loadAssertions fetches and compiles every enabled rule. Rules that fail to
+// compile are logged and skipped.
+func loadAssertions() *assertionSet {
+	set := &assertionSet{byNode: make(map[uint][]assertion)}
+
+	var rules []models.AssertionRule
+	if err := repositories.GetEnabledAssertionRules(&rules); err != nil {
+		log.Printf("Error fetching assertion rules: %v", err)
+		return set
+	}
+
+	for _, r := range rules {
+		a := assertion{rule: r}
+		if r.Type == models.RuleRegex {
+			pattern := r.Value
+			if r.IgnoreCase {
+				pattern = "(?i)" + pattern
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				log.Printf("Skipping assertion rule #%d: %v", r.ID, err)
+				continue
+			}
+			a.re = re
+		}
+
+		if r.NodeID == nil {
+			set.defaults = append(set.defaults, a)
+		} else {
+			set.byNode[*r.NodeID] = append(set.byNode[*r.NodeID], a)
+		}
+	}
+	return set
+}
+
+// forNode returns the node's own rules followed by the global defaults
+// unless the node opted out of them
+func (s *assertionSet) forNode(n models.Node) []assertion {
+	rules := s.byNode[n.ID]
+	if n.SkipDefaultRules {
+		return rules
+	}
+	return append(append([]assertion(nil), rules...), s.defaults...)
+}
+
+// evaluateAssertions runs every rule and returns the most severe failure, or nil
+func evaluateAssertions(rules []assertion, header http.Header, body []byte) *assertionFailure {
+	var (
+		worst      *assertionFailure
+		parsedJSON interface{}
+		jsonErr    error
+		jsonParsed bool
+	)
+
+	for _, a := range rules {
+		var reason string
+		switch a.rule.Type {
+		case models.RuleContains:
+			if !containsText(body, a.rule.Value, a.rule.IgnoreCase) {
+				reason = fmt.Sprintf("body does not contain %q", a.rule.Value)
+			}
+		case models.RuleNotContains:
+			if containsText(body, a.rule.Value, a.rule.IgnoreCase) {
+				reason = fmt.Sprintf("body contains %q", a.rule.Value)
+			}
+		case models.RuleRegex:
+			if !a.re.Match(body) {
+				reason = fmt.Sprintf("body does not match /%s/", a.rule.Value)
+			}
+		case models.RuleHeader:
+			actual := header.Get(a.rule.Target)
+			if !equalText(actual, a.rule.Value, a.rule.IgnoreCase) {
+				reason = fmt.Sprintf("header %s is %q, expected %q", a.rule.Target, actual, a.rule.Value)
+			}
+		case models.RuleJSONPath:
+			if !jsonParsed {
+				jsonParsed = true
+				decoder := json.NewDecoder(bytes.NewReader(body))
+				decoder.UseNumber()
+				jsonErr = decoder.Decode(&parsedJSON)
+			}
+			if jsonErr != nil {
+				reason = fmt.Sprintf("body is not valid JSON: %v", jsonErr)
+				break
+			}
+			actual, ok := lookupJSONPath(parsedJSON, a.rule.Target)
+			if !ok {
+				reason = fmt.Sprintf("JSON path %s not found", a.rule.Target)
+			} else if !equalText(actual, a.rule.Value, a.rule.IgnoreCase) {
+				reason = fmt.Sprintf("JSON path %s is %q, expected %q", a.rule.Target, actual, a.rule.Value)
+			}
+		default:
+			continue
+		}
+
+		if reason == "" {
+			continue
+		}
+		if worst == nil || severityRank[a.rule.Severity] > severityRank[worst.rule.Severity] {
+			worst = &assertionFailure{rule: a.rule, reason: reason}
+		}
+	}
+	return worst
+}
+
+func containsText(body []byte, value string, ignoreCase bool) bool {
+	if ignoreCase {
+		return strings.Contains(strings.ToLower(string(body)), strings.ToLower(value))
+	}
+	return bytes.Contains(body, []byte(value))
+}
+
+func equalText(actual, expected string, ignoreCase bool) bool {
+	if ignoreCase {
+		return strings.EqualFold(actual, expected)
+	}
+	return actual == expected
+}
+
+// lookupJSONPath resolves a dotted path such as "$.data.items.0.status" and
+// renders the value as text. Objects and arrays are rendered as JSON.
+func lookupJSONPath(doc interface{}, path string) (string, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	current := doc
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			switch v := current.(type) {
+			case map[string]interface{}:
+				next, ok := v[key]
+				if !ok {
+					return "", false
+				}
+				current = next
+			case []interface{}:
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 || i >= len(v) {
+					return "", false
+				}
+				current = v[i]
+			default:
+				return "", false
+			}
+		}
+	}
+
+	switch v := current.(type) {
+	case nil:
+		return "null", true
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+}
@@ -0,0 +1,80 @@
+package monitoring
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"uptime/models"
+)
+
+func TestHTTPCheckerAssertions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		io.WriteString(w, `{"status":"ok","items":[{"id":7}]}`)
+	}))
+	defer srv.Close()
+
+	rule := func(name, ruleType, target, value, severity string) models.AssertionRule {
+		return models.AssertionRule{ID: 1, Name: name, Type: ruleType, Target: target, Value: value, Severity: severity}
+	}
+	tests := []struct {
+		name      string
+		path      string
+		rule      models.AssertionRule
+		up        bool
+		suspended bool
+		exc       []string
+	}{
+		{name: "contains passes", path: "/", rule: rule("ok", models.RuleContains, "", `"ok"`, models.SeverityDown), up: true},
+		{name: "json path passes", path: "/", rule: rule("id", models.RuleJSONPath, "$.items.0.id", "7", models.SeverityDown), up: true},
+		{name: "header passes", path: "/", rule: rule("type", models.RuleHeader, "Content-Type", "application/json", models.SeverityDown), up: true},
+		{
+			name: "down rule fails",
+			path: "/",
+			rule: rule("maintenance", models.RuleContains, "", "maintenance", models.SeverityDown),
+			exc:  []string{`assertion "maintenance" (rule #1) failed: body does not contain "maintenance"`},
+		},
+		{
+			name:      "suspended rule fails",
+			path:      "/",
+			rule:      rule("status", models.RuleJSONPath, "$.status", "blocked", models.SeveritySuspended),
+			up:        true,
+			suspended: true,
+			exc:       []string{`JSON path $.status is "ok", expected "blocked"`},
+		},
+		{
+			name: "info rule fails",
+			path: "/",
+			rule: rule("status", models.RuleNotContains, "", "ok", models.SeverityInfo),
+			up:   true,
+			exc:  []string{`body contains "ok"`},
+		},
+		{
+			name: "rule named when the status failed",
+			path: "/error",
+			rule: rule("maintenance", models.RuleContains, "", "maintenance", models.SeverityDown),
+			exc:  []string{"HTTP error: status 500", `assertion "maintenance" (rule #1) failed`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := checkHTTP(t, models.Node{ID: 1, URL: srv.URL + tt.path}, tt.rule)
+			if r.Up != tt.up || r.Suspended != tt.suspended {
+				t.Errorf("Up, Suspended = %v, %v, want %v, %v", r.Up, r.Suspended, tt.up, tt.suspended)
+			}
+			if len(tt.exc) == 0 && r.Exception != nil {
+				t.Errorf("Exception = %q, want none", *r.Exception)
+			}
+			for _, want := range tt.exc {
+				if !strings.Contains(deref(r.Exception), want) {
+					t.Errorf("Exception = %q, want it to contain %q", deref(r.Exception), want)
+				}
+			}
+		})
+	}
+}
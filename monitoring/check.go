@@ -1,193 +1,308 @@
 package monitoring
 
 import (
-	"context"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"strings"
 	"sync"
 	"time"
 	"uptime/config"
-	"uptime/database"
+	"uptime/internal/maintenance"
+	"uptime/internal/notify"
 	"uptime/models"
+	"uptime/repositories"
 )
 
-var SuspendedWords = []string{"suspended", "Suspended", "account suspended", "سایت مسدود است", "مسدود"}
+// nodeTimeout returns the per-node timeout, falling back to the global REQUEST_TIMEOUT
+func nodeTimeout(n models.Node, fallback time.Duration) time.Duration {
+	if n.Timeout > 0 {
+		return time.Duration(n.Timeout) * time.Second
+	}
+	return fallback
+}
 
-func readBodyWithTimeout(body io.ReadCloser, ctx context.Context) ([]byte, error) {
-	done := make(chan struct {
-		data []byte
-		err  error
-	}, 1)
+// Runner checks nodes one at a time and records the results. It keeps the
+// state shared between checks (histories, open incidents, assertion rules) so
+// both full cycles and the per-node scheduler can use it.
+type Runner struct {
+	store *repositories.Store
 
-	go func() {
-		data, err := io.ReadAll(body)
-		done <- struct {
-			data []byte
-			err  error
-		}{data, err}
-	}()
+	mu        sync.RWMutex
+	built     map[string]Checker
+	policy    retryPolicy
+	timeout   time.Duration
+	overlap   string
+	windows   *maintenance.Set
+	incidents *incidentTracker
 
-	select {
-	case result := <-done:
-		return result.data, result.err
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
+	historyMu  sync.Mutex
+	historyMap map[uint]*models.History
 }
 
-func Check(nodes []models.Node) {
-	maxWorkers := config.AppConfig.UptimeChecker.MaxWorkers
-	requestTimeout := config.AppConfig.UptimeChecker.RequestTimeout
-
+// NewRunner loads the current histories, open incidents and assertion rules.
+// Check results are written to store.
+func NewRunner(store *repositories.Store) (*Runner, error) {
 	var histories []models.History
-	if err := database.DB.Find(&histories).Error; err != nil {
-		log.Printf("Error fetching histories: %v", err)
-		return
+	if err := store.Histories.All(&histories); err != nil {
+		return nil, err
 	}
 
-	historyMap := make(map[uint]*models.History)
+	rn := &Runner{
+		store:      store,
+		incidents:  loadIncidentTracker(store.Incidents),
+		historyMap: make(map[uint]*models.History, len(histories)),
+	}
 	for i := range histories {
 		h := &histories[i]
-		historyMap[h.NodeID] = h
+		rn.historyMap[h.NodeID] = h
+	}
+	rn.Refresh()
+	return rn, nil
+}
+
+// Refresh reloads the assertion rules, maintenance windows and the checker configuration
+func (rn *Runner) Refresh() {
+	env := &CycleEnv{
+		assertions:   loadAssertions(),
+		certWarning:  config.AppConfig.UptimeChecker.CertExpiryWarning,
+		certificates: rn.store.Certificates,
+	}
+	windows := maintenance.Load()
+	policy := retryPolicy{
+		retries:       config.AppConfig.UptimeChecker.Retries,
+		delay:         config.AppConfig.UptimeChecker.RetryDelay,
+		confirmCycles: config.AppConfig.UptimeChecker.ConfirmCycles,
+	}
+
+	rn.mu.Lock()
+	rn.built = newCheckers(env)
+	rn.policy = policy
+	rn.timeout = config.AppConfig.UptimeChecker.RequestTimeout
+	rn.overlap = config.AppConfig.UptimeChecker.OverlapPolicy
+	rn.windows = windows
+	rn.mu.Unlock()
+}
+
+// fullCycle is held while a full cycle runs
+var fullCycle = make(chan struct{}, 1)
+
+// Check runs a full cycle over nodes with a Runner of its own. It is used
+// when no scheduler is running; otherwise checks go through the scheduler's
+// Runner so they share its histories and open incidents.
+func Check(store *repositories.Store, nodes []models.Node) error {
+	runner, err := NewRunner(store)
+	if err != nil {
+		return err
+	}
+	return runner.Check(nodes)
+}
+
+// Check runs a full cycle over nodes with MAX_WORKERS workers, after
+// reloading the checker settings. When another full cycle is running it
+// waits for it (queue policy) or returns ErrCycleRunning (skip policy).
+func (rn *Runner) Check(nodes []models.Node) error {
+	if config.AppConfig.UptimeChecker.OverlapPolicy == OverlapQueue {
+		fullCycle <- struct{}{}
+	} else {
+		select {
+		case fullCycle <- struct{}{}:
+		default:
+			return ErrCycleRunning
+		}
 	}
+	defer func() { <-fullCycle }()
+
+	rn.Refresh()
+
+	maxWorkers := config.AppConfig.UptimeChecker.MaxWorkers
+	cycle := NewCycle(CycleManual, len(nodes), maxWorkers)
+	activeMu.Lock()
+	activeCycles[cycle] = true
+	activeMu.Unlock()
+	defer func() {
+		activeMu.Lock()
+		delete(activeCycles, cycle)
+		activeMu.Unlock()
+	}()
 
 	jobs := make(chan models.Node, len(nodes))
 	var wg sync.WaitGroup
 
-	worker := func() {
-		defer wg.Done()
-		for n := range jobs {
-			start := time.Now()
-
-			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
-			req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.URL, nil)
-			if err != nil {
-				cancel()
-				log.Printf("Error creating request for %s: %v", n.URL, err)
-				continue
+	for i := 0; i < maxWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				rn.CheckNode(n, cycle)
 			}
+		}()
+	}
 
-			resp, err := http.DefaultClient.Do(req)
-			var (
-				delay     float64
-				status    uint
-				up        bool
-				suspended bool
-				exception *string
-			)
-
-			if err != nil {
-				delay = time.Since(start).Seconds()
-				exc := fmt.Sprintf("request error: %v", err)
-				exception = &exc
-				status = 0
-				up = false
-				suspended = false
-
-				if strings.Contains(err.Error(), "context deadline exceeded") {
-					delay = requestTimeout.Seconds()
-				}
-			} else {
-				defer resp.Body.Close()
-				status = uint(resp.StatusCode)
-				up = status >= 200 && status < 300
-				if !up {
-					exc := fmt.Sprintf("HTTP error: status %d", status)
-					exception = &exc
-				}
+	for _, n := range nodes {
+		jobs <- n
+	}
+	close(jobs)
+	wg.Wait()
 
-				// خواندن کامل بدنه برای فول‌لود
-				bodyCtx, bodyCancel := context.WithTimeout(context.Background(), 10*time.Second)
-				bodyBytes, readErr := readBodyWithTimeout(resp.Body, bodyCtx)
-				bodyCancel()
-
-				delay = time.Since(start).Seconds()
-
-				if readErr != nil {
-					exc := fmt.Sprintf("body read error: %v", readErr)
-					exception = &exc
-					log.Printf("Error reading response body for %s: %v", n.URL, readErr)
-				} else {
-					body := string(bodyBytes)
-
-					for _, word := range SuspendedWords {
-						if strings.Contains(strings.ToLower(body), strings.ToLower(word)) {
-							suspended = true
-							exc := "page contains suspended keywords"
-							exception = &exc
-							break
-						}
-					}
-
-					if exception == nil {
-						if strings.Contains(body, "Index of /") ||
-							strings.Contains(strings.ToLower(body), "proudly served by litespeed web server") {
-							status = 443
-							up = false
-							exc := "directory listing detected (Index of /)"
-							exception = &exc
-						}
-					}
-				}
-			}
+	// the cycle is complete once its results are written
+	if w := writerFor(rn.store); w != nil {
+		w.Flush()
+	}
+	return nil
+}
 
-			cancel()
+// CheckNode checks a single node and records the log, attempts, incident,
+// notifications and history. If the node is already being checked by another
+// run it is skipped or waited for, depending on CHECK_OVERLAP_POLICY. cycle
+// may be nil.
+func (rn *Runner) CheckNode(n models.Node, cycle *Cycle) {
+	rn.mu.RLock()
+	built, policy, requestTimeout, overlap, windows := rn.built, rn.policy, rn.timeout, rn.overlap, rn.windows
+	rn.mu.RUnlock()
 
-			nodeLog := models.NodeLog{
-				NodeID:    n.ID,
-				Delay:     &delay,
-				Status:    &status,
-				Up:        up,
-				Suspended: suspended,
-				Exception: exception,
-			}
-			if err := database.DB.Create(&nodeLog).Error; err != nil {
-				log.Printf("Error creating node log for %s: %v", n.URL, err)
+	if overlap == OverlapQueue {
+		guard.acquire(n.ID)
+	} else if !guard.tryAcquire(n.ID) {
+		log.Printf("Skipping %s, a check is already in flight", n.URL)
+		cycle.skip()
+		return
+	}
+	defer guard.release(n.ID)
+
+	started := time.Now()
+	cycle.begin()
+	inMaintenance := windows.Active(n.ID, n.GroupID, started)
+
+	r, attempts := checkWithRetries(built, n, nodeTimeout(n, requestTimeout), policy)
+
+	rn.historyMu.Lock()
+	h, ok := rn.historyMap[n.ID]
+	rn.historyMu.Unlock()
+
+	// The log keeps the actual result. Until a failure is confirmed the node
+	// is still reported up: its history, incident and notifications hold back.
+	streak, unconfirmed := policy.confirm(r, h)
+	reportedUp := r.Up || unconfirmed
+
+	// Nodes without history are assumed up, so a first failing check still
+	// notifies. After a maintenance window the history may hold a state nobody
+	// was told about, so the open incident decides instead.
+	previous := models.StateUp
+	if ok && h.Maintenance {
+		previous = rn.incidents.state(n.ID)
+	} else if ok {
+		previous = models.NodeState(h.Up, h.Suspended)
+	}
+
+	nodeLog := models.NodeLog{
+		NodeID:       n.ID,
+		Delay:        &r.Delay,
+		Status:       r.Status,
+		Up:           r.Up,
+		Suspended:    r.Suspended,
+		Degraded:     r.Degraded,
+		Exception:    r.Exception,
+		Attempts:     uint(len(attempts)),
+		Unconfirmed:  unconfirmed,
+		Maintenance:  inMaintenance,
+		CycleID:      cycle.id(),
+		DNSTime:      r.DNSTime,
+		ConnectTime:  r.ConnectTime,
+		TLSTime:      r.TLSTime,
+		TTFB:         r.TTFB,
+		TransferTime: r.TransferTime,
+		ResponseSize: r.ResponseSize,
+		CreatedAt:    time.Now(),
+	}
+	// The history is updated in place so the node's next check sees it
+	// before it is written
+	rn.historyMu.Lock()
+	if !ok {
+		h = &models.History{NodeID: n.ID}
+		rn.historyMap[n.ID] = h
+	}
+	h.Delay = &r.Delay
+	h.Status = r.Status
+	h.Up = reportedUp
+	h.Suspended = r.Suspended
+	h.Degraded = r.Degraded
+	h.Exception = r.Exception
+	h.Maintenance = inMaintenance
+	h.FailureStreak = streak
+	h.UpdatedAt = nodeLog.CreatedAt
+	history := *h
+	rn.historyMu.Unlock()
+
+	var rows []models.CheckAttempt
+	if len(attempts) > 1 {
+		rows = checkAttempts(n.ID, attempts)
+	}
+
+	rn.record(&result{
+		log:      nodeLog,
+		attempts: rows,
+		history:  history,
+		stored: func(nodeLog *models.NodeLog, historyID uint) {
+			rn.historyMu.Lock()
+			if h.ID == 0 {
+				h.ID = historyID
 			}
+			rn.historyMu.Unlock()
 
-			if h, ok := historyMap[n.ID]; ok {
-				h.Delay = &delay
-				h.Status = &status
-				h.Up = up
-				h.Suspended = suspended
-				h.Exception = exception
-				if err := database.DB.Save(h).Error; err != nil {
-					log.Printf("Error updating history for %s: %v", n.URL, err)
-				}
-			} else {
-				h := models.History{
-					NodeID:    n.ID,
-					Delay:     &delay,
-					Status:    &status,
-					Up:        up,
-					Suspended: suspended,
-					Exception: exception,
-				}
-				if err := database.DB.Create(&h).Error; err != nil {
-					log.Printf("Error creating history for %s: %v", n.URL, err)
-				} else {
-					historyMap[n.ID] = &h
+			// Planned downtime raises no incidents or notifications
+			if !inMaintenance {
+				reported := *nodeLog
+				reported.Up = reportedUp
+				incident := rn.incidents.track(&reported)
+				if event, changed := notify.NewEvent(n, previous, &reported, incident); changed {
+					notify.Publish(event)
 				}
 			}
+		},
+	})
+
+	cycle.done(r.Delay, time.Since(started))
+
+	fmt.Printf(
+		"Checked: %s | Status: %v | Up: %v | Suspended: %v | Degraded: %v | Attempts: %d | Delay: %.2fs | Exception: %v\n",
+		n.URL, formatStatus(r.Status), r.Up, r.Suspended, r.Degraded, len(attempts), r.Delay, formatException(r.Exception),
+	)
+}
 
-			fmt.Printf(
-				"Checked: %s | Status: %d | Up: %v | Suspended: %v | Delay: %.2fs | Exception: %v\n",
-				n.URL, status, up, suspended, delay, exception,
-			)
+// checkAttempts builds the rows of every attempt of a retried check
+func checkAttempts(nodeID uint, attempts []Result) []models.CheckAttempt {
+	rows := make([]models.CheckAttempt, len(attempts))
+	for i := range attempts {
+		rows[i] = models.CheckAttempt{
+			NodeID:    nodeID,
+			Attempt:   uint(i + 1),
+			Delay:     &attempts[i].Delay,
+			Status:    attempts[i].Status,
+			Up:        attempts[i].Up,
+			Exception: attempts[i].Exception,
 		}
 	}
+	return rows
+}
 
-	for i := 0; i < maxWorkers; i++ {
-		wg.Add(1)
-		go worker()
+// record writes the result of a check through the running writer, or right
+// away when there is none
+func (rn *Runner) record(r *result) {
+	if w := writerFor(rn.store); w != nil && w.enqueue(r) {
+		return
 	}
+	writeResults(rn.store, []*result{r}, make(map[uint]uint))
+}
 
-	for _, n := range nodes {
-		jobs <- n
+func formatStatus(status *uint) string {
+	if status == nil {
+		return "-"
 	}
-	close(jobs)
-	wg.Wait()
+	return fmt.Sprint(*status)
+}
+
+func formatException(exception *string) string {
+	if exception == nil {
+		return "<nil>"
+	}
+	return *exception
 }
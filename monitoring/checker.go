@@ -0,0 +1,95 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// Result is the outcome of checking a node once
+type Result struct {
+	Delay        float64
+	Status       *uint // HTTP status, nil for non-HTTP checks
+	Up           bool
+	Suspended    bool
+	Degraded     bool
+	Exception    *string
+	DNSTime      *float64
+	ConnectTime  *float64
+	TLSTime      *float64
+	TTFB         *float64
+	TransferTime *float64
+	ResponseSize *int64
+}
+
+// fail marks the result as down with the given exception
+func (r *Result) fail(format string, args ...interface{}) {
+	exc := fmt.Sprintf(format, args...)
+	r.Up = false
+	r.Exception = &exc
+}
+
+// Checker checks a single node of one type. ctx carries the node's timeout.
+type Checker interface {
+	Check(ctx context.Context, n models.Node) Result
+}
+
+// CheckerFactory builds a Checker for one check cycle
+type CheckerFactory func(env *CycleEnv) Checker
+
+// CycleEnv holds the state shared by all checks of one cycle
+type CycleEnv struct {
+	assertions   *assertionSet
+	certWarning  time.Duration
+	certificates repositories.CertificateRepository // where the certificates seen are saved, nil to not save them
+}
+
+var (
+	checkersMu sync.RWMutex
+	checkers   = map[string]CheckerFactory{
+		models.NodeTypeHTTP: func(env *CycleEnv) Checker { return &httpChecker{env: env} },
+		models.NodeTypeTCP:  func(env *CycleEnv) Checker { return tcpChecker{} },
+		models.NodeTypeDNS:  func(env *CycleEnv) Checker { return dnsChecker{} },
+	}
+)
+
+// RegisterChecker adds or replaces the checker used for a node type
+func RegisterChecker(nodeType string, factory CheckerFactory) {
+	checkersMu.Lock()
+	defer checkersMu.Unlock()
+	checkers[nodeType] = factory
+}
+
+// newCheckers builds one checker per registered node type for a cycle
+func newCheckers(env *CycleEnv) map[string]Checker {
+	checkersMu.RLock()
+	defer checkersMu.RUnlock()
+
+	built := make(map[string]Checker, len(checkers))
+	for nodeType, factory := range checkers {
+		built[nodeType] = factory(env)
+	}
+	return built
+}
+
+// runCheck dispatches the node to the checker of its type
+func runCheck(built map[string]Checker, n models.Node, timeout time.Duration) Result {
+	nodeType := n.Type
+	if nodeType == "" {
+		nodeType = models.NodeTypeHTTP
+	}
+
+	checker, ok := built[nodeType]
+	if !ok {
+		var r Result
+		r.fail("unsupported node type %q", nodeType)
+		return r
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return checker.Check(ctx, n)
+}
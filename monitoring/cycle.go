@@ -0,0 +1,208 @@
+package monitoring
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Cycle sources
+const (
+	CycleScheduler = "scheduler" // a CHECK_INTERVAL window of the per-node scheduler
+	CycleManual    = "manual"    // a full run over every node, e.g. /api/check-uptime
+)
+
+// Overlap policies, set with CHECK_OVERLAP_POLICY
+const (
+	OverlapSkip  = "skip"  // drop the new run while the previous one is still going
+	OverlapQueue = "queue" // wait for the previous run to finish
+)
+
+// ErrCycleRunning is returned by Check when a full cycle is already running and the policy is skip
+var ErrCycleRunning = errors.New("a check cycle is already running")
+
+// Cycle counts the checks made in one cycle. Every method accepts a nil
+// receiver so checks made outside of a cycle need no special casing.
+type Cycle struct {
+	ID        string
+	Source    string
+	StartedAt time.Time
+	EndsAt    *time.Time // end of the window for scheduler cycles
+
+	workers int
+	total   int // known up front for manual cycles, 0 for scheduler cycles
+
+	mu          sync.Mutex
+	inFlight    int
+	completed   int
+	skipped     int
+	delaySum    float64
+	durationSum float64
+}
+
+// Progress is a snapshot of a cycle
+type Progress struct {
+	CycleID     string     `json:"cycle_id"`
+	Source      string     `json:"source"`
+	StartedAt   time.Time  `json:"started_at"`
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+	Total       int        `json:"total"`
+	Pending     int        `json:"pending"`
+	InFlight    int        `json:"in_flight"`
+	Completed   int        `json:"completed"`
+	Skipped     int        `json:"skipped"`
+	AvgLatency  float64    `json:"avg_latency"`  // mean check delay in seconds
+	AvgDuration float64    `json:"avg_duration"` // mean wall time per check in seconds, retries included
+	ETA         *time.Time `json:"eta,omitempty"`
+}
+
+// NewCycle starts a cycle. total is the number of checks if known.
+func NewCycle(source string, total, workers int) *Cycle {
+	now := time.Now()
+	if workers < 1 {
+		workers = 1
+	}
+	return &Cycle{
+		ID:        source + "-" + now.UTC().Format("20060102T150405.000"),
+		Source:    source,
+		StartedAt: now,
+		workers:   workers,
+		total:     total,
+	}
+}
+
+func (c *Cycle) id() string {
+	if c == nil {
+		return ""
+	}
+	return c.ID
+}
+
+func (c *Cycle) begin() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.inFlight++
+	c.mu.Unlock()
+}
+
+func (c *Cycle) done(delay float64, duration time.Duration) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.inFlight--
+	c.completed++
+	c.delaySum += delay
+	c.durationSum += duration.Seconds()
+	c.mu.Unlock()
+}
+
+func (c *Cycle) skip() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.skipped++
+	c.mu.Unlock()
+}
+
+// Progress returns a snapshot. pending is only used by cycles without a known total.
+func (c *Cycle) Progress(pending int) Progress {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := Progress{
+		CycleID:   c.ID,
+		Source:    c.Source,
+		StartedAt: c.StartedAt,
+		EndsAt:    c.EndsAt,
+		InFlight:  c.inFlight,
+		Completed: c.completed,
+		Skipped:   c.skipped,
+		Pending:   pending,
+	}
+	if c.total > 0 {
+		p.Pending = c.total - c.completed - c.inFlight - c.skipped
+	}
+	p.Total = p.Pending + p.InFlight + p.Completed + p.Skipped
+
+	if c.completed > 0 {
+		p.AvgLatency = c.delaySum / float64(c.completed)
+		p.AvgDuration = c.durationSum / float64(c.completed)
+
+		// Remaining checks run MAX_WORKERS at a time
+		rounds := (p.Pending + p.InFlight + c.workers - 1) / c.workers
+		eta := time.Now().Add(time.Duration(float64(rounds) * p.AvgDuration * float64(time.Second)))
+		p.ETA = &eta
+	}
+	return p
+}
+
+// activeCycles holds the manual cycles currently running
+var (
+	activeMu     sync.Mutex
+	activeCycles = make(map[*Cycle]bool)
+)
+
+// ActiveCycles returns the progress of the manual cycles currently running
+func ActiveCycles() []Progress {
+	activeMu.Lock()
+	cycles := make([]*Cycle, 0, len(activeCycles))
+	for c := range activeCycles {
+		cycles = append(cycles, c)
+	}
+	activeMu.Unlock()
+
+	progress := make([]Progress, len(cycles))
+	for i, c := range cycles {
+		progress[i] = c.Progress(0)
+	}
+	sort.Slice(progress, func(i, j int) bool { return progress[i].StartedAt.Before(progress[j].StartedAt) })
+	return progress
+}
+
+// nodeGuard keeps a node from being checked by two runs at the same time
+type nodeGuard struct {
+	mu   sync.Mutex
+	busy map[uint]chan struct{}
+}
+
+var guard = &nodeGuard{busy: make(map[uint]chan struct{})}
+
+// tryAcquire claims the node unless it is already being checked
+func (g *nodeGuard) tryAcquire(id uint) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.busy[id]; ok {
+		return false
+	}
+	g.busy[id] = make(chan struct{})
+	return true
+}
+
+// acquire claims the node, waiting for a running check of it to finish
+func (g *nodeGuard) acquire(id uint) {
+	for {
+		g.mu.Lock()
+		ch, ok := g.busy[id]
+		if !ok {
+			g.busy[id] = make(chan struct{})
+			g.mu.Unlock()
+			return
+		}
+		g.mu.Unlock()
+		<-ch
+	}
+}
+
+func (g *nodeGuard) release(id uint) {
+	g.mu.Lock()
+	if ch, ok := g.busy[id]; ok {
+		close(ch)
+		delete(g.busy, id)
+	}
+	g.mu.Unlock()
+}
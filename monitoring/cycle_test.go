@@ -0,0 +1,199 @@
+package monitoring
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"uptime/config"
+	"uptime/models"
+)
+
+// blockingChecker signals each check it starts and holds it until released
+type blockingChecker struct {
+	started chan uint
+	release chan struct{}
+}
+
+func newBlockingChecker() *blockingChecker {
+	return &blockingChecker{started: make(chan uint, 10), release: make(chan struct{})}
+}
+
+func (c *blockingChecker) Check(ctx context.Context, n models.Node) Result {
+	c.started <- n.ID
+	<-c.release
+	return Result{Up: true, Delay: 0.1}
+}
+
+func TestNodeGuard(t *testing.T) {
+	g := &nodeGuard{busy: make(map[uint]chan struct{})}
+	if !g.tryAcquire(1) || g.tryAcquire(1) {
+		t.Fatal("tryAcquire() claimed a busy node or missed a free one")
+	}
+	if !g.tryAcquire(2) {
+		t.Error("tryAcquire() of another node failed")
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		g.acquire(1)
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquire() did not wait for the running check")
+	case <-time.After(50 * time.Millisecond):
+	}
+	g.release(1)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("acquire() still waiting after release()")
+	}
+	if g.tryAcquire(1) {
+		t.Error("node acquired after the wait is free")
+	}
+	g.release(1)
+	g.release(1) // releasing a free node is harmless
+	if !g.tryAcquire(1) {
+		t.Error("node still busy after release()")
+	}
+}
+
+func TestCycleProgress(t *testing.T) {
+	c := NewCycle(CycleManual, 5, 2)
+	if !strings.HasPrefix(c.ID, "manual-") || c.Source != CycleManual {
+		t.Errorf("cycle %q from %q", c.ID, c.Source)
+	}
+	for i := 0; i < 3; i++ {
+		c.begin()
+	}
+	c.done(0.25, time.Second)
+	c.done(0.75, 3*time.Second)
+	c.skip()
+
+	p := c.Progress(0)
+	want := Progress{Total: 5, Pending: 1, InFlight: 1, Completed: 2, Skipped: 1, AvgLatency: 0.5, AvgDuration: 2}
+	if p.Total != want.Total || p.Pending != want.Pending || p.InFlight != want.InFlight || p.Completed != want.Completed ||
+		p.Skipped != want.Skipped || p.AvgLatency != want.AvgLatency || p.AvgDuration != want.AvgDuration {
+		t.Errorf("Progress() = %+v, want %+v", p, want)
+	}
+	// the pending and in flight checks take one more round of two workers
+	if p.ETA == nil || p.ETA.Sub(time.Now()) < 1900*time.Millisecond || p.ETA.Sub(time.Now()) > 2*time.Second {
+		t.Errorf("ETA = %v, want in about 2s", p.ETA)
+	}
+
+	// scheduler cycles take the pending count from the queue
+	s := NewCycle(CycleScheduler, 0, 0)
+	if p := s.Progress(4); p.Total != 4 || p.Pending != 4 || p.ETA != nil {
+		t.Errorf("scheduler Progress(4) = %+v, want 4 pending and no ETA", p)
+	}
+
+	// checks outside of a cycle count nowhere
+	var none *Cycle
+	none.begin()
+	none.done(1, time.Second)
+	none.skip()
+	if none.id() != "" {
+		t.Errorf("nil cycle id = %q", none.id())
+	}
+}
+
+func TestCheckNodeOverlap(t *testing.T) {
+	tests := []struct {
+		policy  string
+		logs    int
+		skipped int
+	}{
+		{policy: OverlapSkip, logs: 1, skipped: 1},
+		{policy: OverlapQueue, logs: 2, skipped: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			checker := newBlockingChecker()
+			rn, store := testRunner(t, checker, retryPolicy{confirmCycles: 1})
+			rn.overlap = tt.policy
+			node := models.Node{ID: 1, URL: "http://node.test", Type: models.NodeTypeHTTP}
+			cycle := NewCycle(CycleManual, 2, 2)
+
+			first := make(chan struct{})
+			go func() {
+				rn.CheckNode(node, cycle)
+				close(first)
+			}()
+			<-checker.started
+
+			second := make(chan struct{})
+			go func() {
+				rn.CheckNode(node, cycle)
+				close(second)
+			}()
+			if tt.policy == OverlapSkip {
+				<-second
+			}
+			// the second run never checks while the first is in flight
+			select {
+			case <-checker.started:
+				t.Fatal("the node was checked twice at once")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			close(checker.release)
+			<-first
+			<-second
+
+			var logs []models.NodeLog
+			if err := store.NodeLogs.All(&logs); err != nil {
+				t.Fatal(err)
+			}
+			p := cycle.Progress(0)
+			if len(logs) != tt.logs || p.Skipped != tt.skipped || p.Completed != tt.logs || p.InFlight != 0 {
+				t.Errorf("%d logs, progress %+v, want %d logs and %d skipped", len(logs), p, tt.logs, tt.skipped)
+			}
+			for _, l := range logs {
+				if l.CycleID != cycle.ID {
+					t.Errorf("log cycle_id = %q, want %q", l.CycleID, cycle.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestRunnerCheckOverlap(t *testing.T) {
+	checker := newBlockingChecker()
+	RegisterChecker("blocking", func(*CycleEnv) Checker { return checker })
+	saved := config.AppConfig.UptimeChecker
+	defer func() { config.AppConfig.UptimeChecker = saved }()
+	config.AppConfig.UptimeChecker.MaxWorkers = 2
+	config.AppConfig.UptimeChecker.OverlapPolicy = OverlapSkip
+
+	rn, _ := testRunner(t, checker, retryPolicy{})
+	nodes := []models.Node{
+		{ID: 1, URL: "blocking://one", Type: "blocking"},
+		{ID: 2, URL: "blocking://two", Type: "blocking"},
+		{ID: 3, URL: "blocking://three", Type: "blocking"},
+	}
+
+	done := make(chan error)
+	go func() { done <- rn.Check(nodes) }()
+	<-checker.started
+	<-checker.started
+
+	// two workers check, the third node waits; the cycle is listed meanwhile
+	active := ActiveCycles()
+	if len(active) != 1 || active[0].Total != 3 || active[0].InFlight != 2 || active[0].Pending != 1 {
+		t.Errorf("ActiveCycles() = %+v, want one cycle of 3 with 2 in flight", active)
+	}
+	if err := rn.Check(nodes); err != ErrCycleRunning {
+		t.Errorf("second Check() = %v, want ErrCycleRunning", err)
+	}
+
+	close(checker.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if active := ActiveCycles(); len(active) != 0 {
+		t.Errorf("ActiveCycles() after the run = %+v, want none", active)
+	}
+}
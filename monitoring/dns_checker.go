@@ -0,0 +1,135 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+	"uptime/models"
+)
+
+// dnsChecker resolves the node's name and compares the records to the expected values
+type dnsChecker struct{}
+
+func (dnsChecker) Check(ctx context.Context, n models.Node) Result {
+	var r Result
+	name := strings.TrimPrefix(n.URL, "dns://")
+	recordType := strings.ToUpper(n.RecordType)
+	if recordType == "" {
+		recordType = models.DNSRecordA
+	}
+
+	start := time.Now()
+	records, err := lookupRecords(ctx, newResolver(n.Resolver), name, recordType)
+	r.Delay = time.Since(start).Seconds()
+	r.DNSTime = &r.Delay
+	if err != nil {
+		r.fail("dns error: %v", err)
+		return r
+	}
+	if len(records) == 0 {
+		r.fail("dns error: no %s records for %s", recordType, name)
+		return r
+	}
+
+	r.Up = true
+	if missing := missingRecords(records, n.Expect); len(missing) > 0 {
+		r.fail("dns %s records [%s] missing expected [%s]", recordType, strings.Join(records, ", "), strings.Join(missing, ", "))
+	}
+	return r
+}
+
+// newResolver returns a resolver that queries address (host or host:port), or
+// the system resolver when address is empty
+func newResolver(address string) *net.Resolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
+
+func lookupRecords(ctx context.Context, resolver *net.Resolver, name, recordType string) ([]string, error) {
+	var records []string
+	switch recordType {
+	case models.DNSRecordA, models.DNSRecordAAAA:
+		network := "ip4"
+		if recordType == models.DNSRecordAAAA {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			records = append(records, ip.String())
+		}
+	case models.DNSRecordCNAME:
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, cname)
+	case models.DNSRecordMX:
+		mxs, err := resolver.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			records = append(records, mx.Host)
+		}
+	case models.DNSRecordNS:
+		nss, err := resolver.LookupNS(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			records = append(records, ns.Host)
+		}
+	case models.DNSRecordTXT:
+		txts, err := resolver.LookupTXT(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, txts...)
+	default:
+		return nil, fmt.Errorf("unsupported record type %q", recordType)
+	}
+
+	sort.Strings(records)
+	return records, nil
+}
+
+// missingRecords returns the comma separated expected values that are not in records.
+// Names are compared case-insensitively and without the trailing dot.
+func missingRecords(records []string, expect string) []string {
+	normalize := func(s string) string {
+		return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), ".")
+	}
+
+	found := make(map[string]bool, len(records))
+	for _, rec := range records {
+		found[normalize(rec)] = true
+	}
+
+	var missing []string
+	for _, want := range strings.Split(expect, ",") {
+		if normalize(want) == "" {
+			continue
+		}
+		if !found[normalize(want)] {
+			missing = append(missing, strings.TrimSpace(want))
+		}
+	}
+	return missing
+}
@@ -0,0 +1,161 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"uptime/models"
+)
+
+const (
+	dnsTypeA   = 1
+	dnsTypeTXT = 16
+)
+
+// dnsServer answers A and TXT questions over UDP from records, keyed by
+// lower-case name without the trailing dot. Unknown names get NXDOMAIN.
+func dnsServer(t *testing.T, records map[string]map[uint16][]string) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply := dnsReply(buf[:n], records); reply != nil {
+				conn.WriteTo(reply, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+// dnsReply builds the response to a single question query
+func dnsReply(query []byte, records map[string]map[uint16][]string) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+	// the question name is a sequence of length prefixed labels ending with 0
+	var labels []string
+	i := 12
+	for i < len(query) && query[i] != 0 {
+		size := int(query[i])
+		if i+1+size > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[i+1:i+1+size]))
+		i += 1 + size
+	}
+	end := i + 5 // the terminating 0, type and class
+	if end > len(query) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(query[i+1:])
+	byType, known := records[strings.ToLower(strings.Join(labels, "."))]
+
+	var answers [][]byte
+	for _, value := range byType[qtype] {
+		var data []byte
+		switch qtype {
+		case dnsTypeA:
+			data = net.ParseIP(value).To4()
+		case dnsTypeTXT:
+			data = append([]byte{byte(len(value))}, value...)
+		}
+		answer := []byte{0xc0, 12} // pointer to the question name
+		answer = binary.BigEndian.AppendUint16(answer, qtype)
+		answer = binary.BigEndian.AppendUint16(answer, 1) // IN
+		answer = binary.BigEndian.AppendUint32(answer, 60)
+		answer = binary.BigEndian.AppendUint16(answer, uint16(len(data)))
+		answers = append(answers, append(answer, data...))
+	}
+
+	reply := append([]byte(nil), query[:2]...) // id
+	flags := uint16(0x8180)                    // response, recursion desired and available
+	if !known {
+		flags |= 3 // NXDOMAIN
+	}
+	reply = binary.BigEndian.AppendUint16(reply, flags)
+	reply = binary.BigEndian.AppendUint16(reply, 1)
+	reply = binary.BigEndian.AppendUint16(reply, uint16(len(answers)))
+	reply = binary.BigEndian.AppendUint32(reply, 0) // no authority or additional records
+	reply = append(reply, query[12:end]...)
+	for _, answer := range answers {
+		reply = append(reply, answer...)
+	}
+	return reply
+}
+
+func TestDNSChecker(t *testing.T) {
+	resolver := dnsServer(t, map[string]map[uint16][]string{
+		"app.example.test": {
+			dnsTypeA:   {"10.0.0.2", "10.0.0.1"},
+			dnsTypeTXT: {"v=spf1 -all"},
+		},
+		"empty.example.test": {},
+	})
+
+	tests := []struct {
+		name       string
+		url        string
+		recordType string
+		expect     string
+		up         bool
+		exc        string
+	}{
+		{name: "resolves", url: "dns://app.example.test", up: true},
+		{name: "expected records present", url: "app.example.test", expect: "10.0.0.1, 10.0.0.2", up: true},
+		{name: "record type is case insensitive", url: "app.example.test", recordType: "txt", expect: "v=spf1 -all", up: true},
+		{name: "expected record missing", url: "app.example.test", expect: "10.0.0.1,10.0.0.3", exc: "dns A records [10.0.0.1, 10.0.0.2] missing expected [10.0.0.3]"},
+		{name: "unknown name", url: "missing.example.test", exc: "dns error"},
+		{name: "no records", url: "empty.example.test", exc: "dns error"},
+		{name: "unsupported type", url: "app.example.test", recordType: "SRV", exc: `unsupported record type "SRV"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			n := models.Node{URL: tt.url, Type: models.NodeTypeDNS, RecordType: tt.recordType, Expect: tt.expect, Resolver: resolver}
+			r := dnsChecker{}.Check(ctx, n)
+
+			if r.Up != tt.up {
+				t.Errorf("Up = %v, want %v (exception %q)", r.Up, tt.up, deref(r.Exception))
+			}
+			if !strings.Contains(deref(r.Exception), tt.exc) || (tt.exc == "" && r.Exception != nil) {
+				t.Errorf("Exception = %q, want %q", deref(r.Exception), tt.exc)
+			}
+			if r.DNSTime == nil {
+				t.Error("DNSTime = nil")
+			}
+		})
+	}
+}
+
+func TestMissingRecords(t *testing.T) {
+	tests := []struct {
+		records []string
+		expect  string
+		want    []string
+	}{
+		{records: []string{"mail.example.com."}, expect: "MAIL.example.com", want: nil},
+		{records: []string{"1.1.1.1"}, expect: "", want: nil},
+		{records: []string{"1.1.1.1"}, expect: " 1.1.1.1 , ,2.2.2.2", want: []string{"2.2.2.2"}},
+		{records: nil, expect: "a,b", want: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		got := missingRecords(tt.records, tt.expect)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("missingRecords(%q, %q) = %q, want %q", tt.records, tt.expect, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"uptime/models"
+	"uptime/utils"
+)
+
+// HTTPClient performs the HTTP checks. It can be replaced to trust custom
+// roots, e.g. with httptest.NewTLSServer(...).Client().
+var HTTPClient = http.DefaultClient
+
+func readBodyWithTimeout(body io.ReadCloser, ctx context.Context) ([]byte, error) {
+	done := make(chan struct {
+		data []byte
+		err  error
+	}, 1)
+
+	go func() {
+		data, err := io.ReadAll(body)
+		done <- struct {
+			data []byte
+			err  error
+		}{data, err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.data, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// newRequest builds the HTTP request described by the node's check configuration
+func newRequest(ctx context.Context, n models.Node) (*http.Request, error) {
+	method := n.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if n.Body != nil {
+		body = strings.NewReader(*n.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, n.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range n.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+// httpChecker sends the node's HTTP request and evaluates the response
+type httpChecker struct {
+	env *CycleEnv
+}
+
+func (c *httpChecker) Check(ctx context.Context, n models.Node) Result {
+	var r Result
+
+	expected, err := utils.ParseStatusSet(n.ExpectedStatus)
+	if err != nil {
+		log.Printf("Invalid expected status for %s: %v, falling back to 2xx", n.URL, err)
+		expected = utils.DefaultStatusSet
+	}
+
+	start := time.Now()
+
+	ctx, timer := withPhaseTimer(ctx)
+	req, err := newRequest(ctx, n)
+	if err != nil {
+		r.fail("request error: %v", err)
+		return r
+	}
+
+	var cert *certResult
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		r.Delay = time.Since(start).Seconds()
+		status := uint(0)
+		r.Status = &status
+		if cert = certFromError(err); cert != nil {
+			r.fail("certificate error: %v", cert.chainErr)
+		} else {
+			r.fail("request error: %v", err)
+		}
+
+		if strings.Contains(err.Error(), "context deadline exceeded") {
+			if deadline, ok := ctx.Deadline(); ok {
+				r.Delay = deadline.Sub(start).Seconds()
+			}
+		}
+	} else {
+		cert = certFromResponse(resp)
+		status := uint(resp.StatusCode)
+		r.Status = &status
+		r.Up = expected.Contains(resp.StatusCode)
+		if !r.Up {
+			r.fail("HTTP error: status %d", status)
+		}
+
+		// خواندن کامل بدنه برای فول‌لود
+		bodyCtx, bodyCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		bodyBytes, readErr := readBodyWithTimeout(resp.Body, bodyCtx)
+		bodyCancel()
+		resp.Body.Close()
+		timer.finish()
+
+		r.Delay = time.Since(start).Seconds()
+
+		if readErr != nil {
+			exc := fmt.Sprintf("body read error: %v", readErr)
+			r.Exception = &exc
+			log.Printf("Error reading response body for %s: %v", n.URL, readErr)
+		} else {
+			size := int64(len(bodyBytes))
+			r.ResponseSize = &size
+
+			if failure := evaluateAssertions(c.env.assertions.forNode(n), resp.Header, bodyBytes); failure != nil {
+				switch failure.rule.Severity {
+				case models.SeverityDown:
+					r.Up = false
+				case models.SeveritySuspended:
+					r.Suspended = true
+				}
+				// The rule is named even when the status already failed the check
+				exc := failure.Error()
+				if r.Exception != nil {
+					exc = *r.Exception + "; " + exc
+				}
+				r.Exception = &exc
+			}
+		}
+	}
+
+	if warning := cert.expiryWarning(c.env.certWarning, time.Now()); warning != nil && r.Up {
+		r.Degraded = true
+		if r.Exception == nil {
+			r.Exception = warning
+		}
+	}
+	saveCertificate(c.env.certificates, n.ID, cert)
+
+	p := timer.phases()
+	r.DNSTime = p.DNS
+	r.ConnectTime = p.Connect
+	r.TLSTime = p.TLS
+	r.TTFB = p.TTFB
+	r.TransferTime = p.Transfer
+	return r
+}
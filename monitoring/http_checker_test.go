@@ -0,0 +1,136 @@
+package monitoring
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"uptime/models"
+)
+
+// checkHTTP checks url with the rules given as the node's own
+func checkHTTP(t *testing.T, n models.Node, rules ...models.AssertionRule) Result {
+	t.Helper()
+	set := &assertionSet{byNode: map[uint][]assertion{}}
+	for _, rule := range rules {
+		set.byNode[n.ID] = append(set.byNode[n.ID], assertion{rule: rule})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	checker := &httpChecker{env: &CycleEnv{assertions: set}}
+	return checker.Check(ctx, n)
+}
+
+func TestHTTPCheckerStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := http.StatusOK
+		switch r.URL.Path {
+		case "/missing":
+			code = http.StatusNotFound
+		case "/moved":
+			code = http.StatusMovedPermanently
+			w.Header().Set("Location", "/")
+		}
+		w.WriteHeader(code)
+		io.WriteString(w, "hello")
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+		up       bool
+		status   uint
+		exc      string
+	}{
+		{name: "default 2xx", path: "/", up: true, status: 200},
+		{name: "404 is down", path: "/missing", status: 404, exc: "HTTP error: status 404"},
+		{name: "404 expected", path: "/missing", expected: "200,404", up: true, status: 404},
+		{name: "redirect followed", path: "/moved", up: true, status: 200},
+		{name: "invalid spec falls back to 2xx", path: "/missing", expected: "abc", status: 404, exc: "status 404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := checkHTTP(t, models.Node{ID: 1, URL: srv.URL + tt.path, ExpectedStatus: tt.expected})
+			if r.Up != tt.up {
+				t.Errorf("Up = %v, want %v (exception %v)", r.Up, tt.up, deref(r.Exception))
+			}
+			if r.Status == nil || *r.Status != tt.status {
+				t.Errorf("Status = %v, want %d", r.Status, tt.status)
+			}
+			if tt.exc == "" && r.Exception != nil {
+				t.Errorf("Exception = %q, want none", *r.Exception)
+			}
+			if tt.exc != "" && !strings.Contains(deref(r.Exception), tt.exc) {
+				t.Errorf("Exception = %q, want it to contain %q", deref(r.Exception), tt.exc)
+			}
+			if r.ResponseSize == nil || *r.ResponseSize != 5 {
+				t.Errorf("ResponseSize = %v, want 5", r.ResponseSize)
+			}
+		})
+	}
+}
+
+func TestHTTPCheckerRequest(t *testing.T) {
+	var got struct {
+		method, host, header, body string
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got.method, got.host, got.header, got.body = r.Method, r.Host, r.Header.Get("X-Token"), string(body)
+	}))
+	defer srv.Close()
+
+	body := `{"ping":true}`
+	n := models.Node{
+		ID:      1,
+		URL:     srv.URL,
+		Method:  http.MethodPost,
+		Body:    &body,
+		Headers: models.Headers{"Host": "example.test", "X-Token": "secret"},
+	}
+	if r := checkHTTP(t, n); !r.Up {
+		t.Fatalf("Up = false, exception %q", deref(r.Exception))
+	}
+	if got.method != http.MethodPost || got.host != "example.test" || got.header != "secret" || got.body != body {
+		t.Errorf("request = %+v", got)
+	}
+}
+
+func TestHTTPCheckerTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	checker := &httpChecker{env: &CycleEnv{assertions: &assertionSet{}}}
+	r := checker.Check(ctx, models.Node{ID: 1, URL: srv.URL})
+	if r.Up {
+		t.Fatal("Up = true, want false")
+	}
+	if r.Status == nil || *r.Status != 0 {
+		t.Errorf("Status = %v, want 0", r.Status)
+	}
+	if !strings.Contains(deref(r.Exception), "request error") {
+		t.Errorf("Exception = %q, want a request error", deref(r.Exception))
+	}
+	if r.Delay < 0.09 {
+		t.Errorf("Delay = %v, want about the timeout", r.Delay)
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,132 @@
+package monitoring
+
+import (
+	"log"
+	"sync"
+	"time"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// incidentTracker opens and resolves incidents as node states change
+type incidentTracker struct {
+	incidents repositories.IncidentRepository
+
+	mu   sync.Mutex
+	open map[uint]*models.Incident
+}
+
+// loadIncidentTracker starts from the incidents that are currently open
+func loadIncidentTracker(repo repositories.IncidentRepository) *incidentTracker {
+	t := &incidentTracker{incidents: repo, open: make(map[uint]*models.Incident)}
+
+	var incidents []models.Incident
+	if err := repo.Open(&incidents); err != nil {
+		log.Printf("Error fetching open incidents: %v", err)
+		return t
+	}
+	for i := range incidents {
+		t.open[incidents[i].NodeID] = &incidents[i]
+	}
+	return t
+}
+
+// track opens an incident when the node is not up and none is open, and
+// resolves the open one once the node is up again. It returns the incident the
+// log belongs to, if any. The lock is held throughout so an incident closed
+// meanwhile is not written back as open.
+func (t *incidentTracker) track(nodeLog *models.NodeLog) *models.Incident {
+	state := models.NodeState(nodeLog.Up, nodeLog.Suspended)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	incident := t.open[nodeLog.NodeID]
+
+	switch {
+	case state != models.StateUp && incident == nil:
+		incident = &models.Incident{
+			NodeID:       nodeLog.NodeID,
+			Status:       models.IncidentOpen,
+			State:        state,
+			StartedAt:    nodeLog.CreatedAt,
+			FirstError:   nodeLog.Exception,
+			TriggerLogID: nodeLog.ID,
+		}
+		if err := t.incidents.Create(incident); err != nil {
+			log.Printf("Error opening incident for node %d: %v", nodeLog.NodeID, err)
+			return nil
+		}
+		t.open[nodeLog.NodeID] = incident
+
+	case state != models.StateUp && incident.State != state:
+		incident.State = state
+		if err := t.incidents.Update(incident); err != nil {
+			log.Printf("Error updating incident %d: %v", incident.ID, err)
+		}
+
+	case state == models.StateUp && incident != nil:
+		resolvedAt := nodeLog.CreatedAt
+		if resolvedAt.IsZero() {
+			resolvedAt = time.Now()
+		}
+		duration := resolvedAt.Sub(incident.StartedAt).Seconds()
+		logID := nodeLog.ID
+
+		incident.Status = models.IncidentResolved
+		incident.ResolvedAt = &resolvedAt
+		incident.Duration = &duration
+		incident.ResolveLogID = &logID
+		if err := t.incidents.Update(incident); err != nil {
+			log.Printf("Error resolving incident %d: %v", incident.ID, err)
+			return incident
+		}
+		delete(t.open, nodeLog.NodeID)
+	}
+	return incident
+}
+
+// close resolves the node's open incident without a recovering check, when
+// the node is no longer checked, and leaves a note saying why
+func (t *incidentTracker) close(nodeID uint, reason string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	incident := t.open[nodeID]
+	if incident == nil {
+		return nil
+	}
+	resolvedAt := time.Now()
+	duration := resolvedAt.Sub(incident.StartedAt).Seconds()
+
+	incident.Status = models.IncidentResolved
+	incident.ResolvedAt = &resolvedAt
+	incident.Duration = &duration
+	if err := t.incidents.Update(incident); err != nil {
+		return err
+	}
+	delete(t.open, nodeID)
+	return t.incidents.CreateNote(&models.IncidentNote{IncidentID: incident.ID, Author: "uptime", Body: reason})
+}
+
+// CloseIncident resolves the open incident of a node that is no longer
+// checked, noting reason. It is used when no Runner is running; otherwise
+// the incident goes through the Runner that tracks it.
+func CloseIncident(store *repositories.Store, nodeID uint, reason string) error {
+	return loadIncidentTracker(store.Incidents).close(nodeID, reason)
+}
+
+// CloseIncident resolves the open incident of a node that is no longer
+// checked, noting reason
+func (rn *Runner) CloseIncident(nodeID uint, reason string) error {
+	return rn.incidents.close(nodeID, reason)
+}
+
+// state returns the state of the node's open incident, or up when there is none
+func (t *incidentTracker) state(nodeID uint) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if incident := t.open[nodeID]; incident != nil {
+		return incident.State
+	}
+	return models.StateUp
+}
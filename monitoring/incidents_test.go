@@ -0,0 +1,146 @@
+package monitoring
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"uptime/models"
+	"uptime/repositories"
+	"uptime/repositories/memory"
+)
+
+// describeIncidents renders the incidents of a store, newest first
+func describeIncidents(t *testing.T, store *repositories.Store) string {
+	t.Helper()
+	var incidents []models.Incident
+	if err := store.Incidents.Find(repositories.IncidentFilter{}, &incidents); err != nil {
+		t.Fatal(err)
+	}
+	parts := make([]string, len(incidents))
+	for i, inc := range incidents {
+		parts[i] = fmt.Sprintf("node %d %s %s", inc.NodeID, inc.State, inc.Status)
+		if inc.ResolveLogID != nil {
+			parts[i] += fmt.Sprintf(" by log %d", *inc.ResolveLogID)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func TestIncidentTrackerTrack(t *testing.T) {
+	t0 := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	exception := "connection refused"
+	logAt := func(id uint, node uint, m int, up, suspended bool) *models.NodeLog {
+		l := &models.NodeLog{ID: id, NodeID: node, Up: up, Suspended: suspended, CreatedAt: t0.Add(time.Duration(m) * time.Minute)}
+		if !up {
+			l.Exception = &exception
+		}
+		return l
+	}
+
+	tests := []struct {
+		name string
+		logs []*models.NodeLog
+		want string
+	}{
+		{name: "up opens nothing", logs: []*models.NodeLog{logAt(1, 1, 0, true, false)}, want: ""},
+		{name: "down opens", logs: []*models.NodeLog{logAt(1, 1, 0, false, false)}, want: "node 1 down open"},
+		{
+			name: "repeated failures keep one incident",
+			logs: []*models.NodeLog{logAt(1, 1, 0, false, false), logAt(2, 1, 1, false, false)},
+			want: "node 1 down open",
+		},
+		{
+			name: "state change updates the open incident",
+			logs: []*models.NodeLog{logAt(1, 1, 0, false, false), logAt(2, 1, 1, true, true)},
+			want: "node 1 suspended open",
+		},
+		{
+			name: "recovery resolves",
+			logs: []*models.NodeLog{logAt(1, 1, 0, false, false), logAt(2, 1, 5, true, false)},
+			want: "node 1 down resolved by log 2",
+		},
+		{
+			name: "a new failure reopens",
+			logs: []*models.NodeLog{logAt(1, 1, 0, false, false), logAt(2, 1, 5, true, false), logAt(3, 1, 6, false, false)},
+			want: "node 1 down open, node 1 down resolved by log 2",
+		},
+		{
+			name: "nodes are tracked apart",
+			logs: []*models.NodeLog{logAt(1, 1, 0, false, false), logAt(2, 2, 0, false, false), logAt(3, 2, 1, true, false)},
+			want: "node 2 down resolved by log 3, node 1 down open",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.NewStore()
+			tracker := loadIncidentTracker(store.Incidents)
+			for _, l := range tt.logs {
+				tracker.track(l)
+			}
+			if got := describeIncidents(t, store); got != tt.want {
+				t.Errorf("incidents = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// the resolved incident records when and for how long
+	store := memory.NewStore()
+	tracker := loadIncidentTracker(store.Incidents)
+	opened := tracker.track(logAt(7, 1, 0, false, false))
+	if opened == nil || opened.TriggerLogID != 7 || opened.FirstError == nil || *opened.FirstError != exception {
+		t.Fatalf("opened = %+v", opened)
+	}
+	resolved := tracker.track(logAt(8, 1, 5, true, false))
+	if resolved != opened || resolved.Duration == nil || *resolved.Duration != 300 || !resolved.ResolvedAt.Equal(t0.Add(5*time.Minute)) {
+		t.Errorf("resolved = %+v, want a 300s incident", resolved)
+	}
+	if tracker.state(1) != models.StateUp {
+		t.Errorf("state after recovery = %s, want up", tracker.state(1))
+	}
+}
+
+func TestIncidentTrackerLoadAndClose(t *testing.T) {
+	store := memory.NewStore()
+	started := time.Now().Add(-time.Hour)
+	if err := store.Incidents.Create(&models.Incident{NodeID: 3, Status: models.IncidentOpen, State: models.StateSuspended, StartedAt: started}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a tracker started later picks up the open incident
+	tracker := loadIncidentTracker(store.Incidents)
+	if got := tracker.state(3); got != models.StateSuspended {
+		t.Errorf("state = %s, want suspended", got)
+	}
+	if err := tracker.close(3, "Closed because the node was paused"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.close(3, "again"); err != nil {
+		t.Errorf("closing a node without an open incident: %v", err)
+	}
+	if err := CloseIncident(store, 4, "unknown node"); err != nil {
+		t.Errorf("CloseIncident() of a node without incidents: %v", err)
+	}
+
+	var incident models.Incident
+	var incidents []models.Incident
+	if err := store.Incidents.Find(repositories.IncidentFilter{}, &incidents); err != nil || len(incidents) != 1 {
+		t.Fatalf("incidents = %+v, %v", incidents, err)
+	}
+	if err := store.Incidents.ByID(incidents[0].ID, &incident); err != nil {
+		t.Fatal(err)
+	}
+	if incident.Status != models.IncidentResolved || incident.Duration == nil || *incident.Duration < 3600 || incident.ResolveLogID != nil {
+		t.Errorf("incident = %+v, want resolved without a log", incident)
+	}
+	if len(incident.Notes) != 1 || incident.Notes[0].Author != "uptime" || incident.Notes[0].Body != "Closed because the node was paused" {
+		t.Errorf("notes = %+v, want one note giving the reason", incident.Notes)
+	}
+
+	// the next failure opens a new incident
+	tracker.track(&models.NodeLog{ID: 9, NodeID: 3, CreatedAt: time.Now()})
+	if got := describeIncidents(t, store); got != "node 3 down open, node 3 suspended resolved" {
+		t.Errorf("incidents = %q", got)
+	}
+}
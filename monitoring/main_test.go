@@ -0,0 +1,26 @@
+package monitoring
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"uptime/config"
+	"uptime/database"
+)
+
+// TestMain opens an in-memory SQLite database for the assertion rules,
+// maintenance windows and notification channels a Runner loads
+func TestMain(m *testing.M) {
+	config.AppConfig = &config.Config{}
+	config.AppConfig.Database.Driver = database.SQLite
+	config.AppConfig.Database.DSN = ":memory:"
+
+	if err := database.Connect(); err != nil {
+		log.Fatal(err)
+	}
+	if err := database.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
@@ -0,0 +1,45 @@
+package monitoring
+
+import (
+	"time"
+	"uptime/models"
+)
+
+// retryPolicy controls how a failing check is retried and confirmed
+type retryPolicy struct {
+	retries       int           // extra attempts after a failure
+	delay         time.Duration // backoff before the first retry, doubled after each one
+	confirmCycles int           // consecutive failing cycles before an up node is reported down
+}
+
+// checkWithRetries runs the check and retries it while it fails. It returns the
+// final result and every attempt made, the final one included.
+func checkWithRetries(built map[string]Checker, n models.Node, timeout time.Duration, policy retryPolicy) (Result, []Result) {
+	attempts := []Result{runCheck(built, n, timeout)}
+
+	backoff := policy.delay
+	for i := 0; i < policy.retries && !attempts[len(attempts)-1].Up; i++ {
+		time.Sleep(backoff)
+		backoff *= 2
+		attempts = append(attempts, runCheck(built, n, timeout))
+	}
+	return attempts[len(attempts)-1], attempts
+}
+
+// confirm applies the confirmation window to a result. It returns the failure
+// streak to store and whether the failure is still unconfirmed, in which case
+// the node keeps being reported up.
+func (p retryPolicy) confirm(r Result, prev *models.History) (streak uint, unconfirmed bool) {
+	if r.Up {
+		return 0, false
+	}
+
+	prevUp := true
+	if prev != nil {
+		streak = prev.FailureStreak
+		prevUp = prev.Up
+	}
+	streak++
+
+	return streak, prevUp && streak < uint(p.confirmCycles)
+}
@@ -0,0 +1,116 @@
+package monitoring
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"uptime/models"
+	"uptime/repositories"
+	"uptime/repositories/memory"
+)
+
+// scriptedChecker returns up or down results in the order given
+type scriptedChecker struct {
+	mu  sync.Mutex
+	ups []bool
+}
+
+func (c *scriptedChecker) Check(ctx context.Context, n models.Node) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := Result{Up: c.ups[0], Delay: 0.1}
+	c.ups = c.ups[1:]
+	if !r.Up {
+		r.fail("connection refused")
+	}
+	return r
+}
+
+// testRunner returns a Runner over a memory store whose HTTP checks come from checker
+func testRunner(t *testing.T, checker Checker, policy retryPolicy) (*Runner, *repositories.Store) {
+	t.Helper()
+	store := memory.NewStore()
+	rn, err := NewRunner(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rn.built = map[string]Checker{models.NodeTypeHTTP: checker}
+	rn.policy = policy
+	return rn, store
+}
+
+func TestCheckNodeConfirmation(t *testing.T) {
+	checker := &scriptedChecker{ups: []bool{false, false, false, true}}
+	rn, store := testRunner(t, checker, retryPolicy{confirmCycles: 3})
+	node := models.Node{ID: 1, URL: "http://node.test", Type: models.NodeTypeHTTP}
+
+	steps := []struct {
+		logUp, unconfirmed bool
+		historyUp          bool
+		streak             uint
+		incident           string // status of the node's incident, empty when there is none
+	}{
+		// the first failures are logged as down but not yet reported
+		{logUp: false, unconfirmed: true, historyUp: true, streak: 1},
+		{logUp: false, unconfirmed: true, historyUp: true, streak: 2},
+		{logUp: false, unconfirmed: false, historyUp: false, streak: 3, incident: models.IncidentOpen},
+		{logUp: true, unconfirmed: false, historyUp: true, streak: 0, incident: models.IncidentResolved},
+	}
+	for i, want := range steps {
+		rn.CheckNode(node, nil)
+
+		var logs []models.NodeLog
+		if err := store.NodeLogs.All(&logs); err != nil || len(logs) != i+1 {
+			t.Fatalf("check %d: %d logs, %v", i+1, len(logs), err)
+		}
+		if l := logs[i]; l.Up != want.logUp || l.Unconfirmed != want.unconfirmed {
+			t.Errorf("check %d: log up %v unconfirmed %v, want %v %v", i+1, l.Up, l.Unconfirmed, want.logUp, want.unconfirmed)
+		}
+
+		var histories []models.History
+		if err := store.Histories.All(&histories); err != nil || len(histories) != 1 {
+			t.Fatalf("check %d: %d histories, %v", i+1, len(histories), err)
+		}
+		if h := histories[0]; h.Up != want.historyUp || h.FailureStreak != want.streak {
+			t.Errorf("check %d: history up %v streak %d, want %v %d", i+1, h.Up, h.FailureStreak, want.historyUp, want.streak)
+		}
+
+		var incidents []models.Incident
+		if err := store.Incidents.Find(repositories.IncidentFilter{}, &incidents); err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		if len(incidents) > 0 {
+			got = incidents[len(incidents)-1].Status
+		}
+		if len(incidents) > 1 || got != want.incident {
+			t.Errorf("check %d: incidents %+v, want one %q", i+1, incidents, want.incident)
+		}
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	down := Result{Up: false}
+	tests := []struct {
+		name        string
+		r           Result
+		prev        *models.History
+		cycles      int
+		streak      uint
+		unconfirmed bool
+	}{
+		{name: "up resets the streak", r: Result{Up: true}, prev: &models.History{FailureStreak: 4}, cycles: 3},
+		{name: "first failure without history", r: down, cycles: 3, streak: 1, unconfirmed: true},
+		{name: "confirmed at once", r: down, cycles: 1, streak: 1},
+		{name: "still unconfirmed", r: down, prev: &models.History{Up: true, FailureStreak: 1}, cycles: 3, streak: 2, unconfirmed: true},
+		{name: "confirmed on the last cycle", r: down, prev: &models.History{Up: true, FailureStreak: 2}, cycles: 3, streak: 3},
+		{name: "already down", r: down, prev: &models.History{Up: false, FailureStreak: 5}, cycles: 3, streak: 6},
+	}
+	for _, tt := range tests {
+		streak, unconfirmed := retryPolicy{confirmCycles: tt.cycles}.confirm(tt.r, tt.prev)
+		if streak != tt.streak || unconfirmed != tt.unconfirmed {
+			t.Errorf("%s: confirm() = %d, %v, want %d, %v", tt.name, streak, unconfirmed, tt.streak, tt.unconfirmed)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package monitoring
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+	"uptime/models"
+)
+
+// maxBannerSize caps how much of a server greeting is read
+const maxBannerSize = 4096
+
+// tcpChecker connects to host:port and optionally waits for an expected banner
+type tcpChecker struct{}
+
+func (tcpChecker) Check(ctx context.Context, n models.Node) Result {
+	var r Result
+	address := strings.TrimPrefix(n.URL, "tcp://")
+
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	connectTime := time.Since(start).Seconds()
+	r.ConnectTime = &connectTime
+	if err != nil {
+		r.Delay = connectTime
+		r.fail("connect error: %v", err)
+		return r
+	}
+	defer conn.Close()
+
+	r.Up = true
+	if n.Expect != "" {
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetReadDeadline(deadline)
+		}
+
+		banner, readErr := readBanner(conn, n.Expect)
+		size := int64(len(banner))
+		r.ResponseSize = &size
+		if !strings.Contains(banner, n.Expect) {
+			if readErr != nil && banner == "" {
+				r.fail("banner read error: %v", readErr)
+			} else {
+				r.fail("banner %q does not contain %q", banner, n.Expect)
+			}
+		}
+	}
+
+	r.Delay = time.Since(start).Seconds()
+	return r
+}
+
+// readBanner reads from conn until expect is seen, the peer stops sending or
+// maxBannerSize is reached
+func readBanner(conn net.Conn, expect string) (string, error) {
+	buf := make([]byte, 0, 512)
+	chunk := make([]byte, 512)
+	for len(buf) < maxBannerSize {
+		nRead, err := conn.Read(chunk)
+		buf = append(buf, chunk[:nRead]...)
+		if strings.Contains(string(buf), expect) {
+			return string(buf), nil
+		}
+		if err != nil {
+			return string(buf), err
+		}
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,102 @@
+package monitoring
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"uptime/models"
+)
+
+// bannerServer accepts connections and greets each with banner
+func bannerServer(t *testing.T, banner string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte(banner))
+			conn.Close()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// closedAddress returns an address nothing listens on
+func closedAddress(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := ln.Addr().String()
+	ln.Close()
+	return address
+}
+
+func TestTCPChecker(t *testing.T) {
+	address := bannerServer(t, "SSH-2.0-OpenSSH_9.6\r\n")
+
+	tests := []struct {
+		name   string
+		url    string
+		expect string
+		up     bool
+		exc    string
+	}{
+		{name: "connects", url: address, up: true},
+		{name: "tcp scheme", url: "tcp://" + address, up: true},
+		{name: "banner matches", url: address, expect: "SSH-2.0", up: true},
+		{name: "banner differs", url: address, expect: "220 ", exc: `banner "SSH-2.0-OpenSSH_9.6\r\n" does not contain "220 "`},
+		{name: "refused", url: closedAddress(t), exc: "connect error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			r := tcpChecker{}.Check(ctx, models.Node{URL: tt.url, Type: models.NodeTypeTCP, Expect: tt.expect})
+
+			if r.Up != tt.up {
+				t.Errorf("Up = %v, want %v (exception %q)", r.Up, tt.up, deref(r.Exception))
+			}
+			if !strings.Contains(deref(r.Exception), tt.exc) || (tt.exc == "" && r.Exception != nil) {
+				t.Errorf("Exception = %q, want %q", deref(r.Exception), tt.exc)
+			}
+			if r.ConnectTime == nil {
+				t.Error("ConnectTime = nil")
+			}
+		})
+	}
+}
+
+func TestTCPCheckerSilentServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		// hold the connection open without greeting
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	r := tcpChecker{}.Check(ctx, models.Node{URL: ln.Addr().String(), Expect: "220"})
+	if r.Up || !strings.Contains(deref(r.Exception), "banner read error") {
+		t.Errorf("Up = %v, Exception = %q, want a banner read error", r.Up, deref(r.Exception))
+	}
+}
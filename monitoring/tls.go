@@ -0,0 +1,86 @@
+package monitoring
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// certResult is the leaf certificate observed during a check
+type certResult struct {
+	leaf       *x509.Certificate
+	chainValid bool
+	chainErr   error
+}
+
+// certFromResponse returns the leaf certificate of a successful TLS response.
+// The chain was verified during the handshake, otherwise there would be no response.
+func certFromResponse(resp *http.Response) *certResult {
+	if resp == nil || resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return &certResult{leaf: resp.TLS.PeerCertificates[0], chainValid: true}
+}
+
+// certFromError extracts the presented certificate from a failed verification
+func certFromError(err error) *certResult {
+	var verifyErr *tls.CertificateVerificationError
+	if !errors.As(err, &verifyErr) || len(verifyErr.UnverifiedCertificates) == 0 {
+		return nil
+	}
+	return &certResult{
+		leaf:       verifyErr.UnverifiedCertificates[0],
+		chainValid: false,
+		chainErr:   verifyErr.Err,
+	}
+}
+
+// expiryWarning returns a message when the certificate expires within window
+func (c *certResult) expiryWarning(window time.Duration, now time.Time) *string {
+	if c == nil || window <= 0 {
+		return nil
+	}
+	left := c.leaf.NotAfter.Sub(now)
+	if left > window {
+		return nil
+	}
+	msg := fmt.Sprintf("certificate expires in %d day(s) on %s", int(left.Hours()/24), c.leaf.NotAfter.UTC().Format("2006-01-02"))
+	return &msg
+}
+
+// saveCertificate stores the certificate as the latest one seen for the node
+func saveCertificate(repo repositories.CertificateRepository, nodeID uint, c *certResult) {
+	if c == nil || repo == nil {
+		return
+	}
+
+	sans := append([]string(nil), c.leaf.DNSNames...)
+	for _, ip := range c.leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	cert := &models.NodeCertificate{
+		NodeID:     nodeID,
+		Subject:    c.leaf.Subject.String(),
+		Issuer:     c.leaf.Issuer.String(),
+		SANs:       strings.Join(sans, ","),
+		NotBefore:  c.leaf.NotBefore,
+		NotAfter:   c.leaf.NotAfter,
+		ChainValid: c.chainValid,
+	}
+	if c.chainErr != nil {
+		msg := c.chainErr.Error()
+		cert.ChainError = &msg
+	}
+
+	if err := repo.Save(cert); err != nil {
+		log.Printf("Error saving certificate for node %d: %v", nodeID, err)
+	}
+}
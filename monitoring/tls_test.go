@@ -0,0 +1,84 @@
+package monitoring
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"uptime/models"
+	"uptime/repositories/memory"
+)
+
+func TestHTTPCheckerCertificate(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	leaf := srv.Certificate()
+
+	tests := []struct {
+		name     string
+		client   *http.Client
+		warning  time.Duration
+		up       bool
+		degraded bool
+		valid    bool
+		exc      string
+	}{
+		{name: "trusted", client: srv.Client(), warning: 14 * 24 * time.Hour, up: true, valid: true},
+		{name: "expiring within the window", client: srv.Client(), warning: time.Until(leaf.NotAfter) + time.Hour, up: true, degraded: true, valid: true, exc: "certificate expires in"},
+		{name: "warning disabled", client: srv.Client(), up: true, valid: true},
+		{name: "untrusted", client: &http.Client{}, warning: time.Until(leaf.NotAfter) + time.Hour, exc: "certificate error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(client *http.Client) { HTTPClient = client }(HTTPClient)
+			HTTPClient = tt.client
+
+			store := memory.NewStore()
+			checker := &httpChecker{env: &CycleEnv{assertions: &assertionSet{}, certWarning: tt.warning, certificates: store.Certificates}}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			r := checker.Check(ctx, models.Node{ID: 3, URL: srv.URL})
+
+			if r.Up != tt.up || r.Degraded != tt.degraded {
+				t.Errorf("Up, Degraded = %v, %v, want %v, %v (exception %q)", r.Up, r.Degraded, tt.up, tt.degraded, deref(r.Exception))
+			}
+			if tt.exc == "" && r.Exception != nil {
+				t.Errorf("Exception = %q, want none", *r.Exception)
+			}
+			if !strings.Contains(deref(r.Exception), tt.exc) {
+				t.Errorf("Exception = %q, want it to contain %q", deref(r.Exception), tt.exc)
+			}
+
+			var cert models.NodeCertificate
+			if err := store.Certificates.ByNodeID(3, &cert); err != nil {
+				t.Fatalf("certificate not saved: %v", err)
+			}
+			if !cert.NotAfter.Equal(leaf.NotAfter) || cert.Subject != leaf.Subject.String() || cert.Issuer != leaf.Issuer.String() {
+				t.Errorf("certificate = %+v, want the server's leaf", cert)
+			}
+			if !strings.Contains(cert.SANs, "127.0.0.1") {
+				t.Errorf("SANs = %q, want 127.0.0.1", cert.SANs)
+			}
+			if cert.ChainValid != tt.valid || (cert.ChainError == nil) != tt.valid {
+				t.Errorf("ChainValid = %v, ChainError = %v, want valid %v", cert.ChainValid, cert.ChainError, tt.valid)
+			}
+		})
+	}
+}
+
+func TestHTTPCheckerPlainHTTPHasNoCertificate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	store := memory.NewStore()
+	checker := &httpChecker{env: &CycleEnv{assertions: &assertionSet{}, certWarning: time.Hour, certificates: store.Certificates}}
+	if r := checker.Check(context.Background(), models.Node{ID: 3, URL: srv.URL}); !r.Up || r.Degraded {
+		t.Fatalf("Up, Degraded = %v, %v, want true, false", r.Up, r.Degraded)
+	}
+	var cert models.NodeCertificate
+	if err := store.Certificates.ByNodeID(3, &cert); err == nil {
+		t.Errorf("certificate saved for a plain HTTP node: %+v", cert)
+	}
+}
@@ -0,0 +1,98 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// phaseTimer records the timestamps of each phase of an HTTP request
+type phaseTimer struct {
+	mu           sync.Mutex
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	bodyDone     time.Time
+}
+
+// phases holds the duration of each request phase in seconds.
+// A nil phase did not happen, e.g. DNS and connect on a reused connection.
+type phases struct {
+	DNS      *float64
+	Connect  *float64
+	TLS      *float64
+	TTFB     *float64 // request written to first response byte
+	Transfer *float64 // first response byte to end of body
+}
+
+// withPhaseTimer attaches an httptrace.ClientTrace to ctx
+func withPhaseTimer(ctx context.Context) (context.Context, *phaseTimer) {
+	t := &phaseTimer{}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		ConnectStart: func(string, string) {
+			t.markOnce(&t.connectStart)
+		},
+		ConnectDone: func(string, string, error) {
+			t.mark(&t.connectDone)
+		},
+		TLSHandshakeStart: func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mark(&t.tlsDone)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mark(&t.wroteRequest)
+		},
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+	}
+	return httptrace.WithClientTrace(ctx, trace), t
+}
+
+func (t *phaseTimer) mark(field *time.Time) {
+	t.mu.Lock()
+	*field = time.Now()
+	t.mu.Unlock()
+}
+
+// markOnce keeps the first timestamp, since dual-stack dialing may start several connects
+func (t *phaseTimer) markOnce(field *time.Time) {
+	t.mu.Lock()
+	if field.IsZero() {
+		*field = time.Now()
+	}
+	t.mu.Unlock()
+}
+
+// finish marks the end of the body transfer
+func (t *phaseTimer) finish() {
+	t.mark(&t.bodyDone)
+}
+
+func (t *phaseTimer) phases() phases {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return phases{
+		DNS:      between(t.dnsStart, t.dnsDone),
+		Connect:  between(t.connectStart, t.connectDone),
+		TLS:      between(t.tlsStart, t.tlsDone),
+		TTFB:     between(t.wroteRequest, t.firstByte),
+		Transfer: between(t.firstByte, t.bodyDone),
+	}
+}
+
+func between(from, to time.Time) *float64 {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return nil
+	}
+	d := to.Sub(from).Seconds()
+	return &d
+}
@@ -0,0 +1,67 @@
+package monitoring
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"uptime/models"
+)
+
+func TestBetween(t *testing.T) {
+	t0 := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     string
+	}{
+		{name: "both set", from: t0, to: t0.Add(1500 * time.Millisecond), want: "1.5"},
+		{name: "same time", from: t0, to: t0, want: "0"},
+		{name: "phase not started", to: t0, want: "nil"},
+		{name: "phase not done", from: t0, want: "nil"},
+		{name: "out of order", from: t0.Add(time.Second), to: t0, want: "nil"},
+	}
+	for _, tt := range tests {
+		got := "nil"
+		if d := between(tt.from, tt.to); d != nil {
+			got = fmt.Sprint(*d)
+		}
+		if got != tt.want {
+			t.Errorf("%s: between() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPCheckerTimings(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		io.WriteString(w, "first")
+		w.(http.Flusher).Flush()
+		time.Sleep(30 * time.Millisecond)
+		io.WriteString(w, "second")
+	}))
+	defer srv.Close()
+
+	r := checkHTTP(t, models.Node{ID: 1, URL: srv.URL})
+	if !r.Up {
+		t.Fatalf("Up = false, exception %q", deref(r.Exception))
+	}
+	// the URL holds an IP address and plain HTTP, so there is no lookup or handshake
+	if r.DNSTime != nil || r.TLSTime != nil {
+		t.Errorf("DNSTime, TLSTime = %v, %v, want nil", r.DNSTime, r.TLSTime)
+	}
+	if r.ConnectTime == nil {
+		t.Error("ConnectTime = nil")
+	}
+	if r.TTFB == nil || *r.TTFB < 0.04 {
+		t.Errorf("TTFB = %v, want about 0.05", r.TTFB)
+	}
+	if r.TransferTime == nil || *r.TransferTime < 0.02 {
+		t.Errorf("TransferTime = %v, want about 0.03", r.TransferTime)
+	}
+	if r.ResponseSize == nil || *r.ResponseSize != int64(len("firstsecond")) {
+		t.Errorf("ResponseSize = %v, want %d", r.ResponseSize, len("firstsecond"))
+	}
+}
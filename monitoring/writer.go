@@ -0,0 +1,348 @@
+package monitoring
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"uptime/config"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// result is everything one check writes: its log, the attempts of a retried
+// check and the node's history. stored runs once the log has been written
+// and has its ID.
+type result struct {
+	log      models.NodeLog
+	attempts []models.CheckAttempt
+	history  models.History
+	stored   func(nodeLog *models.NodeLog, historyID uint)
+}
+
+// Writer buffers check results and writes them in batches, once
+// WRITE_BATCH_SIZE of them are queued or every WRITE_FLUSH_INTERVAL. When
+// WRITE_QUEUE_SIZE results are waiting, checks block until there is room,
+// so a slow database slows the checks down instead of growing the queue.
+type Writer struct {
+	store         *repositories.Store
+	batchSize     int
+	flushInterval time.Duration
+
+	closeMu sync.RWMutex
+	closed  bool
+
+	queue    chan *result
+	flushReq chan chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+
+	// historyIDs maps node IDs to the histories created by earlier batches,
+	// so a node checked again before its history ID came back is not given
+	// a second history. Only the flush goroutine uses it.
+	historyIDs map[uint]uint
+
+	buffered int64 // results taken off the queue, not written yet
+	blocked  int64
+
+	mu    sync.Mutex
+	stats WriterStats
+}
+
+// WriterStats is a snapshot of the writer's counters
+type WriterStats struct {
+	QueueDepth       int        `json:"queue_depth"` // results waiting to be written
+	QueueSize        int        `json:"queue_size"`
+	BatchSize        int        `json:"batch_size"`
+	Flushes          int64      `json:"flushes"`
+	Written          int64      `json:"written"` // check results written
+	Failed           int64      `json:"failed"`  // check results whose log could not be written
+	Blocked          int64      `json:"blocked"` // checks that waited for room in the queue
+	LastFlushSize    int        `json:"last_flush_size"`
+	LastFlushLatency float64    `json:"last_flush_latency"` // seconds
+	AvgFlushLatency  float64    `json:"avg_flush_latency"`  // seconds
+	MaxFlushLatency  float64    `json:"max_flush_latency"`  // seconds
+	LastFlushAt      *time.Time `json:"last_flush_at,omitempty"`
+
+	latencySum float64
+}
+
+var (
+	writerMu sync.Mutex
+	writer   *Writer
+)
+
+// StartWriter starts batching the check results written to store
+func StartWriter(store *repositories.Store) *Writer {
+	w := &Writer{
+		store:         store,
+		batchSize:     config.AppConfig.UptimeChecker.WriteBatchSize,
+		flushInterval: config.AppConfig.UptimeChecker.WriteFlushInterval,
+		queue:         make(chan *result, config.AppConfig.UptimeChecker.WriteQueueSize),
+		flushReq:      make(chan chan struct{}),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+		historyIDs:    make(map[uint]uint),
+	}
+	if w.batchSize < 1 {
+		w.batchSize = 1
+	}
+	if w.flushInterval <= 0 {
+		w.flushInterval = time.Second
+	}
+	go w.run()
+
+	writerMu.Lock()
+	writer = w
+	writerMu.Unlock()
+	return w
+}
+
+// Stop writes the results still queued and stops the writer. Results
+// recorded afterwards are written right away.
+func (w *Writer) Stop() {
+	writerMu.Lock()
+	if writer == w {
+		writer = nil
+	}
+	writerMu.Unlock()
+
+	w.closeMu.Lock()
+	w.closed = true
+	w.closeMu.Unlock()
+
+	close(w.stop)
+	<-w.done
+}
+
+// Flush waits until every result queued so far is written
+func (w *Writer) Flush() {
+	w.closeMu.RLock()
+	defer w.closeMu.RUnlock()
+	if w.closed {
+		return
+	}
+	done := make(chan struct{})
+	w.flushReq <- done
+	<-done
+}
+
+// Stats returns the writer's counters
+func (w *Writer) Stats() WriterStats {
+	w.mu.Lock()
+	stats := w.stats
+	w.mu.Unlock()
+
+	stats.QueueDepth = len(w.queue) + int(atomic.LoadInt64(&w.buffered))
+	stats.QueueSize = cap(w.queue)
+	stats.BatchSize = w.batchSize
+	stats.Blocked = atomic.LoadInt64(&w.blocked)
+	return stats
+}
+
+// CurrentWriterStats returns the counters of the running writer, if any
+func CurrentWriterStats() (WriterStats, bool) {
+	writerMu.Lock()
+	w := writer
+	writerMu.Unlock()
+	if w == nil {
+		return WriterStats{}, false
+	}
+	return w.Stats(), true
+}
+
+// writerFor returns the running writer when it writes to store
+func writerFor(store *repositories.Store) *Writer {
+	writerMu.Lock()
+	defer writerMu.Unlock()
+	if writer != nil && writer.store == store {
+		return writer
+	}
+	return nil
+}
+
+// enqueue hands r to the flush goroutine, waiting while the queue is full.
+// It returns false once the writer is stopped.
+func (w *Writer) enqueue(r *result) bool {
+	w.closeMu.RLock()
+	defer w.closeMu.RUnlock()
+	if w.closed {
+		return false
+	}
+
+	select {
+	case w.queue <- r:
+	default:
+		atomic.AddInt64(&w.blocked, 1)
+		w.queue <- r
+	}
+	return true
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	var batch []*result
+	add := func(r *result) {
+		batch = append(batch, r)
+		atomic.AddInt64(&w.buffered, 1)
+		if len(batch) >= w.batchSize {
+			w.flush(batch)
+			batch = nil
+		}
+	}
+	// drain takes whatever is queued right now
+	drain := func() {
+		for {
+			select {
+			case r := <-w.queue:
+				add(r)
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case r := <-w.queue:
+			add(r)
+		case <-ticker.C:
+			w.flush(batch)
+			batch = nil
+		case done := <-w.flushReq:
+			drain()
+			w.flush(batch)
+			batch = nil
+			close(done)
+		case <-w.stop:
+			drain()
+			w.flush(batch)
+			return
+		}
+	}
+}
+
+func (w *Writer) flush(batch []*result) {
+	if len(batch) == 0 {
+		return
+	}
+
+	started := time.Now()
+	failed := writeResults(w.store, batch, w.historyIDs)
+	latency := time.Since(started).Seconds()
+	atomic.AddInt64(&w.buffered, -int64(len(batch)))
+
+	w.mu.Lock()
+	s := &w.stats
+	s.Flushes++
+	s.Written += int64(len(batch) - failed)
+	s.Failed += int64(failed)
+	s.LastFlushSize = len(batch)
+	s.LastFlushLatency = latency
+	s.latencySum += latency
+	s.AvgFlushLatency = s.latencySum / float64(s.Flushes)
+	if latency > s.MaxFlushLatency {
+		s.MaxFlushLatency = latency
+	}
+	s.LastFlushAt = &started
+	w.mu.Unlock()
+}
+
+// writeResults writes a batch of check results: the logs first so the
+// attempts can point at them, then the histories, then it runs the stored
+// callbacks. historyIDs remembers the IDs of created histories across
+// batches. It returns how many logs could not be written.
+func writeResults(store *repositories.Store, batch []*result, historyIDs map[uint]uint) int {
+	logs := make([]models.NodeLog, len(batch))
+	for i, r := range batch {
+		logs[i] = r.log
+	}
+	if err := store.NodeLogs.CreateMany(logs); err != nil {
+		// one bad row should not lose the whole batch
+		log.Printf("Error writing %d node logs, retrying one by one: %v", len(logs), err)
+		for i := range logs {
+			logs[i] = batch[i].log
+			if err := store.NodeLogs.Create(&logs[i]); err != nil {
+				log.Printf("Error creating node log for node %d: %v", logs[i].NodeID, err)
+				logs[i].ID = 0
+			}
+		}
+	}
+
+	failed := 0
+	var attempts []models.CheckAttempt
+	for i, r := range batch {
+		if logs[i].ID == 0 {
+			failed++
+			continue
+		}
+		r.log = logs[i]
+		for _, a := range r.attempts {
+			a.NodeLogID = r.log.ID
+			attempts = append(attempts, a)
+		}
+	}
+	if len(attempts) > 0 {
+		if err := store.NodeLogs.CreateAttempts(attempts); err != nil {
+			log.Printf("Error saving %d check attempts: %v", len(attempts), err)
+		}
+	}
+
+	writeHistories(store, batch, historyIDs)
+
+	for i, r := range batch {
+		if logs[i].ID != 0 && r.stored != nil {
+			r.stored(&r.log, historyIDs[r.history.NodeID])
+		}
+	}
+	return failed
+}
+
+// writeHistories writes the latest history of every node in the batch,
+// creating the ones that do not exist yet
+func writeHistories(store *repositories.Store, batch []*result, historyIDs map[uint]uint) {
+	latest := make(map[uint]models.History)
+	var order []uint
+	for _, r := range batch {
+		h := r.history
+		if h.ID == 0 {
+			h.ID = historyIDs[h.NodeID]
+		}
+		if _, ok := latest[h.NodeID]; !ok {
+			order = append(order, h.NodeID)
+		}
+		latest[h.NodeID] = h
+	}
+
+	var created, saved []models.History
+	for _, nodeID := range order {
+		if h := latest[nodeID]; h.ID == 0 {
+			created = append(created, h)
+		} else {
+			saved = append(saved, h)
+		}
+	}
+
+	if len(created) > 0 {
+		if err := store.Histories.CreateMany(created); err != nil {
+			log.Printf("Error creating %d histories: %v", len(created), err)
+		} else {
+			for _, h := range created {
+				historyIDs[h.NodeID] = h.ID
+			}
+		}
+	}
+	if len(saved) > 0 {
+		if err := store.Histories.SaveMany(saved); err != nil {
+			log.Printf("Error updating %d histories: %v", len(saved), err)
+		}
+		for _, h := range saved {
+			historyIDs[h.NodeID] = h.ID
+		}
+	}
+}
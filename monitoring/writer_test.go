@@ -0,0 +1,221 @@
+package monitoring
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"uptime/config"
+	"uptime/models"
+	"uptime/repositories"
+	"uptime/repositories/memory"
+)
+
+// gatedLogs holds every batch of node logs until the gate is closed
+type gatedLogs struct {
+	repositories.NodeLogRepository
+	gate chan struct{}
+}
+
+func (g gatedLogs) CreateMany(logs []models.NodeLog) error {
+	<-g.gate
+	return g.NodeLogRepository.CreateMany(logs)
+}
+
+// startWriter starts a writer over store with the given settings
+func startWriter(t *testing.T, store *repositories.Store, batchSize, queueSize int, flushInterval time.Duration) *Writer {
+	t.Helper()
+	saved := config.AppConfig.UptimeChecker
+	config.AppConfig.UptimeChecker.WriteBatchSize = batchSize
+	config.AppConfig.UptimeChecker.WriteQueueSize = queueSize
+	config.AppConfig.UptimeChecker.WriteFlushInterval = flushInterval
+	w := StartWriter(store)
+	config.AppConfig.UptimeChecker = saved
+	return w
+}
+
+func checkResult(nodeID uint) *result {
+	return &result{
+		log:     models.NodeLog{NodeID: nodeID, Up: true, CreatedAt: time.Now()},
+		history: models.History{NodeID: nodeID, Up: true},
+	}
+}
+
+// waitFor polls cond for up to a second
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func countLogs(t *testing.T, store *repositories.Store) int {
+	t.Helper()
+	var logs []models.NodeLog
+	if err := store.NodeLogs.All(&logs); err != nil {
+		t.Fatal(err)
+	}
+	return len(logs)
+}
+
+func TestWriterFlushesFullBatches(t *testing.T) {
+	store := memory.NewStore()
+	w := startWriter(t, store, 3, 10, time.Hour)
+	defer w.Stop()
+
+	for i := 0; i < 7; i++ {
+		w.enqueue(checkResult(uint(i%2 + 1)))
+	}
+	waitFor(t, "two full batches", func() bool { return w.Stats().Flushes == 2 })
+	if s := w.Stats(); s.Written != 6 || s.LastFlushSize != 3 || s.QueueDepth != 1 {
+		t.Errorf("stats = %+v, want 6 written and 1 queued", s)
+	}
+
+	// Flush writes the partial batch right away
+	w.Flush()
+	if s := w.Stats(); s.Flushes != 3 || s.Written != 7 || s.LastFlushSize != 1 || s.QueueDepth != 0 || s.LastFlushAt == nil {
+		t.Errorf("stats after Flush() = %+v, want 7 written in 3 flushes", s)
+	}
+	if n := countLogs(t, store); n != 7 {
+		t.Errorf("%d logs stored, want 7", n)
+	}
+}
+
+func TestWriterFlushesOnInterval(t *testing.T) {
+	store := memory.NewStore()
+	w := startWriter(t, store, 100, 10, 20*time.Millisecond)
+	defer w.Stop()
+
+	w.enqueue(checkResult(1))
+	w.enqueue(checkResult(2))
+	waitFor(t, "the interval flush", func() bool { return w.Stats().Written == 2 })
+	if s := w.Stats(); s.Flushes != 1 || s.LastFlushSize != 2 {
+		t.Errorf("stats = %+v, want one flush of 2", s)
+	}
+}
+
+func TestWriterBackpressure(t *testing.T) {
+	store := memory.NewStore()
+	gate := make(chan struct{})
+	store.NodeLogs = gatedLogs{NodeLogRepository: store.NodeLogs, gate: gate}
+	w := startWriter(t, store, 1, 1, time.Hour)
+	defer w.Stop()
+
+	// the first result is being written, the second fills the queue
+	w.enqueue(checkResult(1))
+	waitFor(t, "the first flush to start", func() bool { return len(w.queue) == 0 })
+	w.enqueue(checkResult(2))
+
+	third := make(chan bool)
+	go func() { third <- w.enqueue(checkResult(3)) }()
+	waitFor(t, "the third check to block", func() bool { return w.Stats().Blocked == 1 })
+	select {
+	case <-third:
+		t.Fatal("enqueue() did not wait for room in the queue")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if s := w.Stats(); s.QueueDepth != 2 || s.QueueSize != 1 {
+		t.Errorf("stats = %+v, want 2 waiting in a queue of 1", s)
+	}
+
+	close(gate)
+	if !<-third {
+		t.Error("enqueue() = false on a running writer")
+	}
+	w.Flush()
+	if n := countLogs(t, store); n != 3 {
+		t.Errorf("%d logs stored, want 3", n)
+	}
+}
+
+func TestWriterStop(t *testing.T) {
+	store := memory.NewStore()
+	w := startWriter(t, store, 100, 10, time.Hour)
+	if writerFor(store) != w {
+		t.Fatal("writerFor() does not return the running writer")
+	}
+
+	for i := 0; i < 5; i++ {
+		w.enqueue(checkResult(1))
+	}
+	w.Stop()
+	if n := countLogs(t, store); n != 5 {
+		t.Errorf("%d logs stored after Stop(), want 5", n)
+	}
+	if writerFor(store) != nil {
+		t.Error("writerFor() returns the stopped writer")
+	}
+	if w.enqueue(checkResult(1)) {
+		t.Error("enqueue() = true after Stop()")
+	}
+	w.Flush() // returns at once
+}
+
+func TestWriteResultsHistoryIDs(t *testing.T) {
+	store := memory.NewStore()
+	historyIDs := make(map[uint]uint)
+
+	var mu sync.Mutex
+	stored := make(map[uint][]uint) // node ID to the history IDs its callbacks got
+	batch := func(nodeIDs ...uint) []*result {
+		var rs []*result
+		for _, id := range nodeIDs {
+			r := checkResult(id)
+			r.attempts = []models.CheckAttempt{{NodeID: id, Attempt: 1}, {NodeID: id, Attempt: 2}}
+			r.stored = func(l *models.NodeLog, historyID uint) {
+				mu.Lock()
+				stored[l.NodeID] = append(stored[l.NodeID], historyID)
+				mu.Unlock()
+				if l.ID == 0 {
+					t.Errorf("stored log of node %d has no ID", l.NodeID)
+				}
+			}
+			rs = append(rs, r)
+		}
+		return rs
+	}
+
+	// a node checked twice in one batch gets one history
+	if failed := writeResults(store, batch(1, 2, 1), historyIDs); failed != 0 {
+		t.Fatalf("%d failed", failed)
+	}
+	// later batches reuse the IDs instead of creating more
+	writeResults(store, batch(1, 3), historyIDs)
+
+	var histories []models.History
+	if err := store.Histories.All(&histories); err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != 3 || len(historyIDs) != 3 {
+		t.Fatalf("%d histories, %d IDs, want 3", len(histories), len(historyIDs))
+	}
+	for _, h := range histories {
+		for _, id := range stored[h.NodeID] {
+			if id != h.ID {
+				t.Errorf("node %d callbacks got history %v, want %d", h.NodeID, stored[h.NodeID], h.ID)
+			}
+		}
+		if historyIDs[h.NodeID] != h.ID {
+			t.Errorf("historyIDs[%d] = %d, want %d", h.NodeID, historyIDs[h.NodeID], h.ID)
+		}
+	}
+	if len(stored[1]) != 3 {
+		t.Errorf("node 1 stored %d times, want 3", len(stored[1]))
+	}
+
+	// every attempt points at its log
+	var logs []models.NodeLog
+	if err := store.NodeLogs.All(&logs); err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range logs {
+		var attempts []models.CheckAttempt
+		if err := store.NodeLogs.Attempts(l.ID, &attempts); err != nil || len(attempts) != 2 {
+			t.Errorf("log %d has %d attempts, %v", l.ID, len(attempts), err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"uptime/database"
+	"uptime/models"
+)
+
+func CreateAssertionRule(rule *models.AssertionRule) error {
+	return database.DB.Create(rule).Error
+}
+
+func GetAllAssertionRules(rules *[]models.AssertionRule) error {
+	return database.DB.Find(rules).Error
+}
+
+// GetAssertionRulesByNode returns the rules attached to a node,
+// or the global default set when nodeID is nil
+func GetAssertionRulesByNode(nodeID *uint, rules *[]models.AssertionRule) error {
+	if nodeID == nil {
+		return database.DB.Where("node_id IS NULL").Find(rules).Error
+	}
+	return database.DB.Where("node_id = ?", *nodeID).Find(rules).Error
+}
+
+func GetEnabledAssertionRules(rules *[]models.AssertionRule) error {
+	return database.DB.Where("enabled = ?", true).Order("id asc").Find(rules).Error
+}
+
+func GetAssertionRuleByID(id uint, rule *models.AssertionRule) error {
+	return database.DB.First(rule, id).Error
+}
+
+func UpdateAssertionRule(rule *models.AssertionRule) error {
+	return database.DB.Save(rule).Error
+}
+
+func DeleteAssertionRule(rule *models.AssertionRule) error {
+	return database.DB.Delete(rule).Error
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"uptime/database"
+	"uptime/models"
+)
+
+func CreateChatChannel(channel *models.ChatChannel) error {
+	return database.DB.Create(channel).Error
+}
+
+func GetAllChatChannels(channels *[]models.ChatChannel) error {
+	return database.DB.Find(channels).Error
+}
+
+func GetEnabledChatChannels(channels *[]models.ChatChannel) error {
+	return database.DB.Where("enabled = ?", true).Find(channels).Error
+}
+
+func GetChatChannelByID(id uint, channel *models.ChatChannel) error {
+	return database.DB.First(channel, id).Error
+}
+
+func UpdateChatChannel(channel *models.ChatChannel) error {
+	return database.DB.Save(channel).Error
+}
+
+func DeleteChatChannel(channel *models.ChatChannel) error {
+	return database.DB.Delete(channel).Error
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"uptime/database"
+	"uptime/models"
+)
+
+func CreateEmailChannel(channel *models.EmailChannel) error {
+	return database.DB.Create(channel).Error
+}
+
+func GetAllEmailChannels(channels *[]models.EmailChannel) error {
+	return database.DB.Find(channels).Error
+}
+
+func GetEnabledEmailChannels(channels *[]models.EmailChannel) error {
+	return database.DB.Where("enabled = ?", true).Find(channels).Error
+}
+
+func GetEmailChannelByID(id uint, channel *models.EmailChannel) error {
+	return database.DB.First(channel, id).Error
+}
+
+func UpdateEmailChannel(channel *models.EmailChannel) error {
+	return database.DB.Save(channel).Error
+}
+
+func DeleteEmailChannel(channel *models.EmailChannel) error {
+	return database.DB.Delete(channel).Error
+}
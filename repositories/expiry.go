@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Expiry selects the rows a retention cleanup removes: those older than
+// Before, of the nodes in NodeIDs or, with Exclude, of every node but them.
+// A nil NodeIDs without Exclude selects the rows of every node.
+type Expiry struct {
+	Before  time.Time
+	NodeIDs []uint
+	Exclude bool
+}
+
+// Scope narrows db to the rows selected by e, by their time column
+func (e Expiry) Scope(db *gorm.DB, timeColumn string) *gorm.DB {
+	db = db.Where(timeColumn+" < ?", e.Before)
+	switch {
+	case e.NodeIDs == nil:
+		return db
+	case e.Exclude && len(e.NodeIDs) == 0:
+		return db
+	case e.Exclude:
+		return db.Where("node_id NOT IN ?", e.NodeIDs)
+	default:
+		return db.Where("node_id IN ?", e.NodeIDs)
+	}
+}
+
+// DeleteBatch deletes, in one transaction, up to limit rows of table
+// selected by query, lowest ID first, and returns how many it deleted.
+// before runs first in the transaction with their IDs, e.g. to delete
+// dependent rows.
+func DeleteBatch(db, query *gorm.DB, table string, limit int, before func(tx *gorm.DB, ids []uint) error) (int64, error) {
+	var ids []uint
+	if err := query.Order("id").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if before != nil {
+			if err := before(tx, ids); err != nil {
+				return err
+			}
+		}
+		res := tx.Exec("DELETE FROM "+table+" WHERE id IN ?", ids)
+		deleted = res.RowsAffected
+		return res.Error
+	})
+	return deleted, err
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"uptime/database"
+	"uptime/models"
+
+	"gorm.io/gorm"
+)
+
+func CreateGroup(group *models.Group) error {
+	return database.DB.Create(group).Error
+}
+
+func GetAllGroups(groups *[]models.Group) error {
+	return database.DB.Order("name").Find(groups).Error
+}
+
+func GetGroupByID(id uint, group *models.Group) error {
+	return database.DB.First(group, id).Error
+}
+
+func UpdateGroup(group *models.Group) error {
+	return database.DB.Save(group).Error
+}
+
+// DeleteGroup removes the group and moves its subgroups up to its parent.
+// Its nodes are detached beforehand through the node repository.
+func DeleteGroup(group *models.Group) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Group{}).Where("parent_id = ?", group.ID).Update("parent_id", group.ParentID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(group).Error
+	})
+}
@@ -1,26 +1,66 @@
 package repositories
 
 import (
-	"uptime/database"
+	"context"
+	"time"
+	"uptime/internal/logquery"
 	"uptime/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func CreateHistory(history *models.History) error {
-	return database.DB.Create(history).Error
+type sqlHistories struct {
+	db *gorm.DB
+}
+
+func (r sqlHistories) Create(history *models.History) error {
+	return r.db.Create(history).Error
+}
+
+func (r sqlHistories) CreateMany(histories []models.History) error {
+	return r.db.CreateInBatches(&histories, insertBatchSize).Error
+}
+
+func (r sqlHistories) SaveMany(histories []models.History) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"delay", "status", "up", "suspended", "degraded", "exception", "maintenance", "failure_streak", "updated_at"}),
+	}).CreateInBatches(&histories, insertBatchSize).Error
+}
+
+func (r sqlHistories) All(histories *[]models.History) error {
+	return r.db.Find(histories).Error
+}
+
+func (r sqlHistories) ByID(id uint, history *models.History) error {
+	return r.db.First(history, id).Error
+}
+
+func (r sqlHistories) Find(ctx context.Context, filter LogFilter, histories *[]models.History) error {
+	return applyLogFilter(r.db.WithContext(ctx).Model(&models.History{}), filter).Find(histories).Error
 }
 
-func GetAllHistories(histories *[]models.History) error {
-	return database.DB.Find(histories).Error
+func (r sqlHistories) Each(ctx context.Context, filter LogFilter, fn func(history *models.History) error) error {
+	return eachLog(r.db.WithContext(ctx), filter, logquery.HistoryField, fn)
 }
 
-func GetHistoryByID(id uint, history *models.History) error {
-	return database.DB.First(history, id).Error
+func (r sqlHistories) Update(history *models.History) error {
+	return r.db.Save(history).Error
 }
 
-func UpdateHistory(history *models.History) error {
-	return database.DB.Save(history).Error
+func (r sqlHistories) Delete(history *models.History) error {
+	return r.db.Delete(history).Error
 }
 
-func DeleteHistory(history *models.History) error {
-	return database.DB.Delete(history).Error
+func (r sqlHistories) LastCheckTimes() (map[uint]time.Time, error) {
+	var rows []models.History
+	if err := r.db.Select("node_id", "updated_at").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	times := make(map[uint]time.Time, len(rows))
+	for _, row := range rows {
+		times[row.NodeID] = row.UpdatedAt
+	}
+	return times, nil
 }
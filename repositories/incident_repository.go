@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"time"
+	"uptime/models"
+
+	"gorm.io/gorm"
+)
+
+// IncidentFilter narrows an incident listing. Zero values are ignored.
+type IncidentFilter struct {
+	NodeID       uint
+	NodeIDs      []uint // only these nodes, none when empty but not nil
+	Status       string
+	Acknowledged *bool
+	From         *time.Time // incidents still open at or resolved after From
+	To           *time.Time // incidents started before To
+	Page         Page       // newest first, continuing after Page.AfterID
+}
+
+type sqlIncidents struct {
+	db *gorm.DB
+}
+
+func (r sqlIncidents) Create(incident *models.Incident) error {
+	return r.db.Create(incident).Error
+}
+
+func (r sqlIncidents) Find(filter IncidentFilter, incidents *[]models.Incident) error {
+	db := r.db.Model(&models.Incident{})
+	if filter.NodeID != 0 {
+		db = db.Where("node_id = ?", filter.NodeID)
+	}
+	if filter.NodeIDs != nil {
+		db = db.Where("node_id IN ?", filter.NodeIDs)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.Acknowledged != nil {
+		db = db.Where("acknowledged = ?", *filter.Acknowledged)
+	}
+	if filter.From != nil {
+		db = db.Where("(resolved_at IS NULL OR resolved_at >= ?)", *filter.From)
+	}
+	if filter.To != nil {
+		db = db.Where("started_at <= ?", *filter.To)
+	}
+	if filter.Page.AfterID != 0 {
+		var last models.Incident
+		if err := r.db.Select("id, started_at").First(&last, filter.Page.AfterID).Error; err != nil {
+			return err
+		}
+		db = db.Where("started_at < ? OR (started_at = ? AND id < ?)", last.StartedAt, last.StartedAt, last.ID)
+	}
+	if filter.Page.Limit > 0 {
+		db = db.Limit(filter.Page.Limit)
+	}
+	return db.Order("started_at desc, id desc").Find(incidents).Error
+}
+
+func (r sqlIncidents) Open(incidents *[]models.Incident) error {
+	return r.db.Where("status = ?", models.IncidentOpen).Find(incidents).Error
+}
+
+func (r sqlIncidents) ByID(id uint, incident *models.Incident) error {
+	return r.db.Preload("Notes").First(incident, id).Error
+}
+
+func (r sqlIncidents) Update(incident *models.Incident) error {
+	return r.db.Omit("Notes").Save(incident).Error
+}
+
+func (r sqlIncidents) CreateNote(note *models.IncidentNote) error {
+	return r.db.Create(note).Error
+}
+
+func (r sqlIncidents) StartedBetween(nodeIDs []uint, from, to time.Time, incidents *[]models.Incident) error {
+	return r.db.Where("node_id IN ? AND started_at >= ? AND started_at < ?", nodeIDs, from, to).
+		Order("started_at asc").Find(incidents).Error
+}
+
+func (r sqlIncidents) expired(e Expiry) *gorm.DB {
+	return e.Scope(r.db.Model(&models.Incident{}), "resolved_at").Where("status = ?", models.IncidentResolved)
+}
+
+func (r sqlIncidents) CountExpired(e Expiry) (int64, error) {
+	var count int64
+	err := r.expired(e).Count(&count).Error
+	return count, err
+}
+
+func (r sqlIncidents) DeleteExpired(e Expiry, limit int) (int64, error) {
+	return DeleteBatch(r.db, r.expired(e), "incidents", limit, func(tx *gorm.DB, ids []uint) error {
+		return tx.Where("incident_id IN ?", ids).Delete(&models.IncidentNote{}).Error
+	})
+}
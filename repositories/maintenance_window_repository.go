@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"uptime/database"
+	"uptime/models"
+)
+
+func CreateMaintenanceWindow(window *models.MaintenanceWindow) error {
+	return database.DB.Create(window).Error
+}
+
+func GetAllMaintenanceWindows(windows *[]models.MaintenanceWindow) error {
+	return database.DB.Find(windows).Error
+}
+
+func GetEnabledMaintenanceWindows(windows *[]models.MaintenanceWindow) error {
+	return database.DB.Where("enabled = ?", true).Find(windows).Error
+}
+
+func GetMaintenanceWindowByID(id uint, window *models.MaintenanceWindow) error {
+	return database.DB.First(window, id).Error
+}
+
+func UpdateMaintenanceWindow(window *models.MaintenanceWindow) error {
+	return database.DB.Save(window).Error
+}
+
+func DeleteMaintenanceWindow(window *models.MaintenanceWindow) error {
+	return database.DB.Delete(window).Error
+}
@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"uptime/models"
+
+	"gorm.io/gorm"
+)
+
+type certificates struct {
+	d *data
+}
+
+func (r certificates) Save(cert *models.NodeCertificate) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	// one certificate is kept per node, replacing the previous one
+	if existing, ok := r.d.certs[cert.NodeID]; ok {
+		cert.ID = existing.ID
+		cert.CreatedAt = existing.CreatedAt
+	} else if cert.ID == 0 {
+		cert.ID = r.d.nextID("node_certificates")
+	}
+	stamp(&cert.CreatedAt, &cert.UpdatedAt)
+	r.d.certs[cert.NodeID] = *cert
+	return nil
+}
+
+func (r certificates) Find(ctx context.Context, notAfter *time.Time, out *[]models.NodeCertificate) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	var found []models.NodeCertificate
+	for _, nodeID := range sortedIDs(r.d.certs) {
+		if c := r.d.certs[nodeID]; notAfter == nil || !c.NotAfter.After(*notAfter) {
+			found = append(found, c)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].NotAfter.Before(found[j].NotAfter) })
+	*out = found
+	return nil
+}
+
+func (r certificates) ByNodeID(nodeID uint, cert *models.NodeCertificate) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	c, ok := r.d.certs[nodeID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	*cert = c
+	return nil
+}
@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"uptime/models"
+	"uptime/repositories"
+
+	"gorm.io/gorm"
+)
+
+type histories struct {
+	d *data
+}
+
+func historyRow(h models.History) logRow {
+	return logRow{
+		ID:          h.ID,
+		NodeID:      h.NodeID,
+		Delay:       h.Delay,
+		Status:      h.Status,
+		Up:          h.Up,
+		Suspended:   h.Suspended,
+		Degraded:    h.Degraded,
+		Maintenance: h.Maintenance,
+		Exception:   h.Exception,
+		CreatedAt:   h.CreatedAt,
+	}
+}
+
+func (r histories) Create(history *models.History) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if history.ID == 0 {
+		history.ID = r.d.nextID("histories")
+	}
+	stamp(&history.CreatedAt, &history.UpdatedAt)
+	r.d.histories[history.ID] = *history
+	return nil
+}
+
+func (r histories) CreateMany(list []models.History) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	for i := range list {
+		h := &list[i]
+		if h.ID == 0 {
+			h.ID = r.d.nextID("histories")
+		}
+		stamp(&h.CreatedAt, &h.UpdatedAt)
+		r.d.histories[h.ID] = *h
+	}
+	return nil
+}
+
+func (r histories) SaveMany(list []models.History) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	for _, h := range list {
+		if existing, ok := r.d.histories[h.ID]; ok {
+			h.CreatedAt = existing.CreatedAt
+		}
+		stamp(&h.CreatedAt, nil)
+		r.d.histories[h.ID] = h
+	}
+	return nil
+}
+
+func (r histories) All(out *[]models.History) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	*out = r.list()
+	return nil
+}
+
+func (r histories) ByID(id uint, history *models.History) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	h, ok := r.d.histories[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	*history = h
+	return nil
+}
+
+func (r histories) Find(ctx context.Context, filter repositories.LogFilter, out *[]models.History) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	*out = filterLogs(r.list(), historyRow, filter)
+	return nil
+}
+
+func (r histories) Each(ctx context.Context, filter repositories.LogFilter, fn func(history *models.History) error) error {
+	var list []models.History
+	if err := r.Find(ctx, filter, &list); err != nil {
+		return err
+	}
+	for i := range list {
+		if err := fn(&list[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r histories) Update(history *models.History) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if history.ID == 0 {
+		history.ID = r.d.nextID("histories")
+	}
+	stamp(&history.CreatedAt, &history.UpdatedAt)
+	r.d.histories[history.ID] = *history
+	return nil
+}
+
+func (r histories) Delete(history *models.History) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	delete(r.d.histories, history.ID)
+	return nil
+}
+
+func (r histories) LastCheckTimes() (map[uint]time.Time, error) {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	times := make(map[uint]time.Time, len(r.d.histories))
+	for _, h := range r.list() {
+		times[h.NodeID] = h.UpdatedAt
+	}
+	return times, nil
+}
+
+// list returns every history in ID order. The caller holds the lock.
+func (r histories) list() []models.History {
+	var out []models.History
+	for _, id := range sortedIDs(r.d.histories) {
+		out = append(out, r.d.histories[id])
+	}
+	return out
+}
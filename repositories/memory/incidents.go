@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"uptime/models"
+	"uptime/repositories"
+
+	"gorm.io/gorm"
+)
+
+type incidents struct {
+	d *data
+}
+
+func (r incidents) Create(incident *models.Incident) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if incident.ID == 0 {
+		incident.ID = r.d.nextID("incidents")
+	}
+	if incident.Status == "" {
+		incident.Status = models.IncidentOpen
+	}
+	stamp(&incident.CreatedAt, &incident.UpdatedAt)
+	r.d.incidents[incident.ID] = stripIncident(*incident)
+	return nil
+}
+
+func (r incidents) Find(filter repositories.IncidentFilter, out *[]models.Incident) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	nodes := idSet(filter.NodeIDs)
+	found := r.list(func(i models.Incident) bool {
+		switch {
+		case filter.NodeID != 0 && i.NodeID != filter.NodeID,
+			nodes != nil && !nodes[i.NodeID],
+			filter.Status != "" && i.Status != filter.Status,
+			filter.Acknowledged != nil && i.Acknowledged != *filter.Acknowledged,
+			filter.From != nil && i.ResolvedAt != nil && i.ResolvedAt.Before(*filter.From),
+			filter.To != nil && i.StartedAt.After(*filter.To):
+			return false
+		}
+		return true
+	})
+	// newest first, the later ID first among incidents started together
+	sort.SliceStable(found, func(a, b int) bool {
+		if !found[a].StartedAt.Equal(found[b].StartedAt) {
+			return found[a].StartedAt.After(found[b].StartedAt)
+		}
+		return found[a].ID > found[b].ID
+	})
+	if filter.Page.AfterID != 0 {
+		last, ok := r.d.incidents[filter.Page.AfterID]
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		for len(found) > 0 && !(found[0].StartedAt.Before(last.StartedAt) ||
+			found[0].StartedAt.Equal(last.StartedAt) && found[0].ID < last.ID) {
+			found = found[1:]
+		}
+	}
+	*out = limit(found, filter.Page.Limit)
+	return nil
+}
+
+func (r incidents) Open(out *[]models.Incident) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	*out = r.list(func(i models.Incident) bool { return i.Status == models.IncidentOpen })
+	return nil
+}
+
+func (r incidents) ByID(id uint, incident *models.Incident) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	i, ok := r.d.incidents[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for _, noteID := range sortedIDs(r.d.notes) {
+		if n := r.d.notes[noteID]; n.IncidentID == id {
+			i.Notes = append(i.Notes, n)
+		}
+	}
+	*incident = i
+	return nil
+}
+
+func (r incidents) Update(incident *models.Incident) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if incident.ID == 0 {
+		incident.ID = r.d.nextID("incidents")
+	}
+	stamp(&incident.CreatedAt, &incident.UpdatedAt)
+	r.d.incidents[incident.ID] = stripIncident(*incident)
+	return nil
+}
+
+func (r incidents) CreateNote(note *models.IncidentNote) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if note.ID == 0 {
+		note.ID = r.d.nextID("incident_notes")
+	}
+	stamp(&note.CreatedAt, nil)
+	r.d.notes[note.ID] = *note
+	return nil
+}
+
+func (r incidents) StartedBetween(nodeIDs []uint, from, to time.Time, out *[]models.Incident) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	wanted := make(map[uint]bool, len(nodeIDs))
+	for _, id := range nodeIDs {
+		wanted[id] = true
+	}
+	found := r.list(func(i models.Incident) bool {
+		return wanted[i.NodeID] && !i.StartedAt.Before(from) && i.StartedAt.Before(to)
+	})
+	sort.SliceStable(found, func(a, b int) bool { return found[a].StartedAt.Before(found[b].StartedAt) })
+	*out = found
+	return nil
+}
+
+// list returns the incidents matching keep in ID order. The caller holds the lock.
+func (r incidents) list(keep func(models.Incident) bool) []models.Incident {
+	var out []models.Incident
+	for _, id := range sortedIDs(r.d.incidents) {
+		if i := r.d.incidents[id]; keep(i) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// stripIncident drops the notes, which are stored on their own
+func stripIncident(i models.Incident) models.Incident {
+	i.Notes = nil
+	return i
+}
+
+func (r incidents) CountExpired(e repositories.Expiry) (int64, error) {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	return int64(len(expiredIDs(r.d.incidents, r.expired(e), 0))), nil
+}
+
+func (r incidents) DeleteExpired(e repositories.Expiry, limit int) (int64, error) {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	ids := expiredIDs(r.d.incidents, r.expired(e), limit)
+	deleted := idSet(ids)
+	for id, n := range r.d.notes {
+		if deleted[n.IncidentID] {
+			delete(r.d.notes, id)
+		}
+	}
+	for _, id := range ids {
+		delete(r.d.incidents, id)
+	}
+	return int64(len(ids)), nil
+}
+
+// expired selects the resolved incidents of e, open ones are kept
+func (r incidents) expired(e repositories.Expiry) func(models.Incident) bool {
+	covered := covers(e)
+	return func(i models.Incident) bool {
+		return i.Status == models.IncidentResolved && i.ResolvedAt != nil &&
+			i.ResolvedAt.Before(e.Before) && covered(i.NodeID)
+	}
+}
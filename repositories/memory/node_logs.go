@@ -0,0 +1,239 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"uptime/models"
+	"uptime/repositories"
+
+	"gorm.io/gorm"
+)
+
+type nodeLogs struct {
+	d *data
+}
+
+func nodeLogRow(l models.NodeLog) logRow {
+	return logRow{
+		ID:          l.ID,
+		NodeID:      l.NodeID,
+		Delay:       l.Delay,
+		Status:      l.Status,
+		Up:          l.Up,
+		Suspended:   l.Suspended,
+		Degraded:    l.Degraded,
+		Maintenance: l.Maintenance,
+		Exception:   l.Exception,
+		CreatedAt:   l.CreatedAt,
+	}
+}
+
+func (r nodeLogs) Create(log *models.NodeLog) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if log.ID == 0 {
+		log.ID = r.d.nextID("node_logs")
+	}
+	stamp(&log.CreatedAt, &log.UpdatedAt)
+	r.d.logs[log.ID] = *log
+	return nil
+}
+
+func (r nodeLogs) CreateMany(logs []models.NodeLog) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	for i := range logs {
+		l := &logs[i]
+		if l.ID == 0 {
+			l.ID = r.d.nextID("node_logs")
+		}
+		stamp(&l.CreatedAt, &l.UpdatedAt)
+		r.d.logs[l.ID] = *l
+	}
+	return nil
+}
+
+func (r nodeLogs) All(out *[]models.NodeLog) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	*out = r.list(func(models.NodeLog) bool { return true })
+	return nil
+}
+
+func (r nodeLogs) ByID(id uint, log *models.NodeLog) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	l, ok := r.d.logs[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	*log = l
+	return nil
+}
+
+func (r nodeLogs) ByCycleID(cycleID string, out *[]models.NodeLog) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	*out = r.list(func(l models.NodeLog) bool { return l.CycleID == cycleID })
+	return nil
+}
+
+func (r nodeLogs) Find(ctx context.Context, filter repositories.LogFilter, out *[]models.NodeLog) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	*out = filterLogs(r.list(func(models.NodeLog) bool { return true }), nodeLogRow, filter)
+	return nil
+}
+
+func (r nodeLogs) Each(ctx context.Context, filter repositories.LogFilter, fn func(log *models.NodeLog) error) error {
+	var logs []models.NodeLog
+	if err := r.Find(ctx, filter, &logs); err != nil {
+		return err
+	}
+	for i := range logs {
+		if err := fn(&logs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r nodeLogs) InRange(nodeID uint, from, to time.Time, out *[]models.NodeLog) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	var previous *models.NodeLog
+	var inRange []models.NodeLog
+	for _, l := range r.list(func(l models.NodeLog) bool { return l.NodeID == nodeID }) {
+		switch {
+		case l.CreatedAt.Before(from):
+			if previous == nil || l.CreatedAt.After(previous.CreatedAt) {
+				p := l
+				previous = &p
+			}
+		case l.CreatedAt.Before(to):
+			inRange = append(inRange, l)
+		}
+	}
+	sort.SliceStable(inRange, func(i, j int) bool { return inRange[i].CreatedAt.Before(inRange[j].CreatedAt) })
+
+	*out = inRange
+	if previous != nil {
+		*out = append([]models.NodeLog{*previous}, inRange...)
+	}
+	return nil
+}
+
+func (r nodeLogs) OldestTime() (*time.Time, error) {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	var oldest *time.Time
+	for _, l := range r.d.logs {
+		if oldest == nil || l.CreatedAt.Before(*oldest) {
+			t := l.CreatedAt
+			oldest = &t
+		}
+	}
+	return oldest, nil
+}
+
+func (r nodeLogs) Update(log *models.NodeLog) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if log.ID == 0 {
+		log.ID = r.d.nextID("node_logs")
+	}
+	stamp(&log.CreatedAt, &log.UpdatedAt)
+	r.d.logs[log.ID] = *log
+	return nil
+}
+
+func (r nodeLogs) Delete(log *models.NodeLog) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	delete(r.d.logs, log.ID)
+	return nil
+}
+
+func (r nodeLogs) CreateAttempts(attempts []models.CheckAttempt) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	for i := range attempts {
+		a := &attempts[i]
+		if a.ID == 0 {
+			a.ID = r.d.nextID("check_attempts")
+		}
+		stamp(&a.CreatedAt, nil)
+		r.d.attempts[a.ID] = *a
+	}
+	return nil
+}
+
+func (r nodeLogs) Attempts(nodeLogID uint, out *[]models.CheckAttempt) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	var found []models.CheckAttempt
+	for _, id := range sortedIDs(r.d.attempts) {
+		if a := r.d.attempts[id]; a.NodeLogID == nodeLogID {
+			found = append(found, a)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Attempt < found[j].Attempt })
+	*out = found
+	return nil
+}
+
+// list returns the logs matching keep in ID order. The caller holds the lock.
+func (r nodeLogs) list(keep func(models.NodeLog) bool) []models.NodeLog {
+	var out []models.NodeLog
+	for _, id := range sortedIDs(r.d.logs) {
+		if l := r.d.logs[id]; keep(l) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func (r nodeLogs) CountExpired(e repositories.Expiry) (int64, error) {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	return int64(len(expiredIDs(r.d.logs, r.expired(e), 0))), nil
+}
+
+func (r nodeLogs) DeleteExpired(e repositories.Expiry, limit int) (int64, error) {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	ids := expiredIDs(r.d.logs, r.expired(e), limit)
+	deleted := idSet(ids)
+	for id, a := range r.d.attempts {
+		if deleted[a.NodeLogID] {
+			delete(r.d.attempts, id)
+		}
+	}
+	for _, id := range ids {
+		delete(r.d.logs, id)
+	}
+	return int64(len(ids)), nil
+}
+
+func (r nodeLogs) expired(e repositories.Expiry) func(models.NodeLog) bool {
+	covered := covers(e)
+	return func(l models.NodeLog) bool {
+		return l.CreatedAt.Before(e.Before) && covered(l.NodeID)
+	}
+}
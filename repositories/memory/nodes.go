@@ -0,0 +1,250 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"uptime/models"
+	"uptime/repositories"
+
+	"gorm.io/gorm"
+)
+
+type nodes struct {
+	d        *data
+	unscoped bool // include archived nodes
+}
+
+func (r nodes) Unscoped() repositories.NodeRepository {
+	return nodes{d: r.d, unscoped: true}
+}
+
+func (r nodes) Create(node *models.Node) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if err := r.checkURL(node); err != nil {
+		return err
+	}
+	if node.ID == 0 {
+		node.ID = r.d.nextID("nodes")
+	}
+	if node.Lifecycle == "" {
+		node.Lifecycle = models.NodeActive
+	}
+	stamp(&node.CreatedAt, &node.UpdatedAt)
+	r.d.nodes[node.ID] = stripNode(*node)
+	return nil
+}
+
+func (r nodes) All(out *[]models.Node) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	*out = r.list(func(models.Node) bool { return true })
+	return nil
+}
+
+func (r nodes) ByID(id uint, node *models.Node) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	n, ok := r.d.nodes[id]
+	if !ok || !r.visible(n) {
+		return gorm.ErrRecordNotFound
+	}
+	*node = n
+	return nil
+}
+
+func (r nodes) ByURL(url string, node *models.Node) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	found := r.list(func(n models.Node) bool { return n.URL == url })
+	if len(found) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	*node = found[0]
+	return nil
+}
+
+func (r nodes) ByGroupID(groupID uint, out *[]models.Node) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	*out = r.list(func(n models.Node) bool { return n.GroupID != nil && *n.GroupID == groupID })
+	return nil
+}
+
+func (r nodes) ByIDs(ids []uint, out *[]models.Node) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	wanted := idSet(ids)
+	*out = r.list(func(n models.Node) bool { return wanted[n.ID] })
+	return nil
+}
+
+func (r nodes) SetGroup(ids []uint, groupID *uint) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	for _, id := range ids {
+		if n, ok := r.d.nodes[id]; ok {
+			n.GroupID = groupID
+			stamp(nil, &n.UpdatedAt)
+			r.d.nodes[id] = n
+		}
+	}
+	return nil
+}
+
+func (r nodes) Update(node *models.Node) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if node.ID == 0 {
+		node.ID = r.d.nextID("nodes")
+	}
+	if err := r.checkURL(node); err != nil {
+		return err
+	}
+	stamp(&node.CreatedAt, &node.UpdatedAt)
+	r.d.nodes[node.ID] = stripNode(*node)
+	return nil
+}
+
+func (r nodes) Delete(node *models.Node) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	n, ok := r.d.nodes[node.ID]
+	if !ok {
+		return nil
+	}
+	if r.unscoped {
+		delete(r.d.nodes, node.ID)
+		return nil
+	}
+	if !n.DeletedAt.Valid {
+		n.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		r.d.nodes[node.ID] = n
+	}
+	node.DeletedAt = n.DeletedAt
+	return nil
+}
+
+func (r nodes) Restore(node *models.Node) error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	if n, ok := r.d.nodes[node.ID]; ok {
+		n.DeletedAt = gorm.DeletedAt{}
+		stamp(nil, &n.UpdatedAt)
+		r.d.nodes[node.ID] = n
+	}
+	node.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+func (r nodes) ResumeDue(now time.Time) (int64, error) {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+
+	var resumed int64
+	for id, n := range r.d.nodes {
+		if !r.visible(n) || n.Lifecycle != models.NodePaused || n.PausedUntil == nil || n.PausedUntil.After(now) {
+			continue
+		}
+		n.Lifecycle = models.NodeActive
+		n.PausedUntil = nil
+		stamp(nil, &n.UpdatedAt)
+		r.d.nodes[id] = n
+		resumed++
+	}
+	return resumed, nil
+}
+
+func (r nodes) WithLogs(page repositories.Page, out *[]models.Node) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	list := limit(r.list(func(n models.Node) bool { return n.ID > page.AfterID }), page.Limit)
+	byNode := make(map[uint][]models.NodeLog)
+	for _, id := range sortedIDs(r.d.logs) {
+		l := r.d.logs[id]
+		byNode[l.NodeID] = append(byNode[l.NodeID], l)
+	}
+	for i := range list {
+		list[i].NodeLogs = byNode[list[i].ID]
+	}
+	*out = list
+	return nil
+}
+
+func (r nodes) WithHistories(ctx context.Context, urls []string, ids []uint, page repositories.Page, out *[]models.Node) error {
+	r.d.mu.RLock()
+	defer r.d.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		wanted[u] = true
+	}
+	wantedIDs := idSet(ids)
+	list := r.list(func(n models.Node) bool {
+		return (urls == nil || wanted[n.URL]) && (ids == nil || wantedIDs[n.ID]) &&
+			(page.AfterID == 0 || n.ID < page.AfterID)
+	})
+
+	byNode := make(map[uint][]models.History)
+	for _, id := range sortedIDs(r.d.histories) {
+		h := r.d.histories[id]
+		byNode[h.NodeID] = append(byNode[h.NodeID], h)
+	}
+	// newest node first
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+	list = limit(list, page.Limit)
+	for i := range list {
+		list[i].Histories = byNode[list[i].ID]
+	}
+	*out = list
+	return nil
+}
+
+// list returns the nodes matching keep in ID order. The caller holds the lock.
+func (r nodes) list(keep func(models.Node) bool) []models.Node {
+	var out []models.Node
+	for _, id := range sortedIDs(r.d.nodes) {
+		if n := r.d.nodes[id]; r.visible(n) && keep(n) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// visible reports whether the repository's scope includes n
+func (r nodes) visible(n models.Node) bool {
+	return r.unscoped || !n.DeletedAt.Valid
+}
+
+// checkURL enforces the unique index on url. The caller holds the lock.
+func (r nodes) checkURL(node *models.Node) error {
+	for id, n := range r.d.nodes {
+		if id != node.ID && n.URL == node.URL {
+			return fmt.Errorf("duplicate entry %q for key url", node.URL)
+		}
+	}
+	return nil
+}
+
+// stripNode drops the associations, which are stored on their own
+func stripNode(n models.Node) models.Node {
+	n.NodeLogs = nil
+	n.Histories = nil
+	n.Tags = nil
+	return n
+}
@@ -0,0 +1,270 @@
+// Package memory keeps nodes, node logs, histories, incidents and
+// certificates in maps, for tests and the --storage=memory demo mode.
+// Nothing survives a restart.
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// data holds the rows of every repository of one store
+type data struct {
+	mu        sync.RWMutex
+	lastID    map[string]uint
+	nodes     map[uint]models.Node
+	logs      map[uint]models.NodeLog
+	attempts  map[uint]models.CheckAttempt
+	histories map[uint]models.History
+	incidents map[uint]models.Incident
+	notes     map[uint]models.IncidentNote
+	certs     map[uint]models.NodeCertificate // by node ID, one per node
+}
+
+// NewStore returns an empty in-memory store
+func NewStore() *repositories.Store {
+	d := &data{
+		lastID:    make(map[string]uint),
+		nodes:     make(map[uint]models.Node),
+		logs:      make(map[uint]models.NodeLog),
+		attempts:  make(map[uint]models.CheckAttempt),
+		histories: make(map[uint]models.History),
+		incidents: make(map[uint]models.Incident),
+		notes:     make(map[uint]models.IncidentNote),
+		certs:     make(map[uint]models.NodeCertificate),
+	}
+	return &repositories.Store{
+		Nodes:        nodes{d: d},
+		NodeLogs:     nodeLogs{d},
+		Histories:    histories{d},
+		Incidents:    incidents{d},
+		Certificates: certificates{d},
+	}
+}
+
+// nextID returns the next auto increment ID of table. The caller holds the lock.
+func (d *data) nextID(table string) uint {
+	d.lastID[table]++
+	return d.lastID[table]
+}
+
+// stamp sets the timestamps the way GORM does: created_at only when unset,
+// updated_at on every save
+func stamp(createdAt, updatedAt *time.Time) {
+	now := time.Now()
+	if createdAt != nil && createdAt.IsZero() {
+		*createdAt = now
+	}
+	if updatedAt != nil {
+		*updatedAt = now
+	}
+}
+
+// sortedIDs returns the keys of rows in ascending order
+func sortedIDs[T any](rows map[uint]T) []uint {
+	ids := make([]uint, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// idSet turns a list of IDs into a set, keeping nil as nil
+func idSet(ids []uint) map[uint]bool {
+	if ids == nil {
+		return nil
+	}
+	set := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// logRow holds the columns a LogFilter looks at
+type logRow struct {
+	ID          uint
+	NodeID      uint
+	Delay       *float64
+	Status      *uint
+	Up          bool
+	Suspended   bool
+	Degraded    bool
+	Maintenance bool
+	Exception   *string
+	CreatedAt   time.Time
+}
+
+// matches reports whether the row passes f. nodes is the set of f.NodeIDs.
+func (r logRow) matches(f repositories.LogFilter, nodes map[uint]bool) bool {
+	switch {
+	case f.NodeID != 0 && r.NodeID != f.NodeID,
+		nodes != nil && !nodes[r.NodeID],
+		f.From != nil && r.CreatedAt.Before(*f.From),
+		f.To != nil && r.CreatedAt.After(*f.To),
+		f.Up && !r.Up,
+		f.Down && r.Up,
+		f.Suspended && !r.Suspended,
+		f.Exception && r.Exception == nil,
+		f.Where != nil && !f.Where.Eval(r.field):
+		return false
+	}
+	return true
+}
+
+// field returns a column the way logquery.Expr.Eval reads it
+func (r logRow) field(name string) interface{} {
+	switch name {
+	case "id":
+		return float64(r.ID)
+	case "node_id":
+		return float64(r.NodeID)
+	case "delay":
+		if r.Delay != nil {
+			return *r.Delay
+		}
+	case "status":
+		if r.Status != nil {
+			return float64(*r.Status)
+		}
+	case "up":
+		return r.Up
+	case "suspended":
+		return r.Suspended
+	case "degraded":
+		return r.Degraded
+	case "maintenance":
+		return r.Maintenance
+	case "exception":
+		if r.Exception != nil {
+			return *r.Exception
+		}
+	case "created_at":
+		return r.CreatedAt
+	}
+	return nil
+}
+
+// compare orders two rows by column like SQL does, NULLs first
+func (r logRow) compare(o logRow, column string) int {
+	switch column {
+	case "id":
+		return compareValues(r.ID, o.ID)
+	case "node_id":
+		return compareValues(r.NodeID, o.NodeID)
+	case "delay":
+		return compareNullable(r.Delay, o.Delay)
+	case "status":
+		return compareNullable(r.Status, o.Status)
+	case "up":
+		return compareBools(r.Up, o.Up)
+	case "suspended":
+		return compareBools(r.Suspended, o.Suspended)
+	case "degraded":
+		return compareBools(r.Degraded, o.Degraded)
+	case "maintenance":
+		return compareBools(r.Maintenance, o.Maintenance)
+	case "exception":
+		return compareNullable(r.Exception, o.Exception)
+	case "created_at":
+		return r.CreatedAt.Compare(o.CreatedAt)
+	}
+	return 0
+}
+
+type ordered interface {
+	~uint | ~float64 | ~string
+}
+
+func compareValues[T ordered](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareNullable[T ordered](a, b *T) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return compareValues(*a, *b)
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	}
+	return 1
+}
+
+// filterLogs applies a LogFilter to rows, which are in ID order
+// limit keeps the first n rows, all of them when n is 0
+func limit[T any](rows []T, n int) []T {
+	if n > 0 && len(rows) > n {
+		return rows[:n]
+	}
+	return rows
+}
+
+func filterLogs[T any](rows []T, row func(T) logRow, f repositories.LogFilter) []T {
+	nodes := idSet(f.NodeIDs)
+	var out []T
+	for _, r := range rows {
+		if row(r).matches(f, nodes) {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := row(out[i]), row(out[j])
+		for _, o := range f.Order {
+			c := a.compare(b, o.Column)
+			if o.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return limit(out, f.Limit)
+}
+
+// expiredIDs returns the IDs of up to limit rows matching expired, lowest ID
+// first, or all of them when limit is 0. The caller holds the lock.
+func expiredIDs[T any](rows map[uint]T, expired func(T) bool, limit int) []uint {
+	var ids []uint
+	for _, id := range sortedIDs(rows) {
+		if limit > 0 && len(ids) == limit {
+			break
+		}
+		if expired(rows[id]) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// covers returns whether e selects the rows of a node
+func covers(e repositories.Expiry) func(nodeID uint) bool {
+	nodes := idSet(e.NodeIDs)
+	return func(nodeID uint) bool {
+		return nodes == nil || nodes[nodeID] != e.Exclude
+	}
+}
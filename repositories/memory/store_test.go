@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"uptime/config"
+	"uptime/database"
+	"uptime/internal/logquery"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// TestMain opens an in-memory SQLite database to compare the stores against
+func TestMain(m *testing.M) {
+	config.AppConfig = &config.Config{}
+	config.AppConfig.Database.Driver = database.SQLite
+	config.AppConfig.Database.DSN = ":memory:"
+
+	if err := database.Connect(); err != nil {
+		log.Fatal(err)
+	}
+	if err := database.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+var t0 = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+// sampleLogs returns logs of three nodes mixing up, down, suspended and
+// missing delays, statuses and exceptions, with the same IDs in both stores
+func sampleLogs() []models.NodeLog {
+	var logs []models.NodeLog
+	for i := 0; i < 30; i++ {
+		l := models.NodeLog{
+			ID:          uint(i + 1),
+			NodeID:      uint(i%3 + 1),
+			Up:          i%4 != 0,
+			Suspended:   i%7 == 0,
+			Maintenance: i%11 == 0,
+			CreatedAt:   t0.Add(time.Duration(i*7%30) * time.Minute),
+		}
+		if i%5 != 0 {
+			delay := float64(i%6) / 4
+			l.Delay = &delay
+		}
+		if i%3 != 2 {
+			status := uint(200)
+			if !l.Up {
+				status = 500 + uint(i%3)
+			}
+			l.Status = &status
+		}
+		if !l.Up {
+			exception := fmt.Sprintf("error %c", 'a'+i%4)
+			l.Exception = &exception
+		}
+		logs = append(logs, l)
+	}
+	return logs
+}
+
+func TestNodeLogFilterMatchesSQL(t *testing.T) {
+	sql := repositories.NewSQLStore(database.DB)
+	mem := NewStore()
+	t.Cleanup(func() { database.DB.Exec("DELETE FROM node_logs") })
+	if err := sql.NodeLogs.CreateMany(sampleLogs()); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.NodeLogs.CreateMany(sampleLogs()); err != nil {
+		t.Fatal(err)
+	}
+
+	from, to := t0.Add(5*time.Minute), t0.Add(20*time.Minute)
+	where := func(s string) *logquery.Expr {
+		e, err := logquery.Parse(s, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	tests := []struct {
+		name   string
+		filter repositories.LogFilter
+		sort   string
+	}{
+		{name: "everything", sort: "id"},
+		{name: "one node", filter: repositories.LogFilter{NodeID: 2}, sort: "-id"},
+		{name: "some nodes", filter: repositories.LogFilter{NodeIDs: []uint{1, 3}}, sort: "created_at"},
+		{name: "no nodes", filter: repositories.LogFilter{NodeIDs: []uint{}}},
+		{name: "time range", filter: repositories.LogFilter{From: &from, To: &to}, sort: "-created_at"},
+		{name: "up", filter: repositories.LogFilter{Up: true}, sort: "delay"},
+		{name: "down with exceptions", filter: repositories.LogFilter{Down: true, Exception: true}, sort: "exception,-status"},
+		{name: "suspended", filter: repositories.LogFilter{Suspended: true}, sort: "-delay"},
+		{name: "nullable sorts", sort: "status,-delay"},
+		{name: "bool sorts", sort: "-up,maintenance,-suspended"},
+		{name: "limit", filter: repositories.LogFilter{Limit: 7}, sort: "-delay,node_id"},
+		{name: "where", filter: repositories.LogFilter{Where: where("delay>=0.5 and status!=null")}, sort: "-status"},
+		{name: "where null", filter: repositories.LogFilter{Where: where("delay=null or exception~b")}, sort: "node_id,-created_at"},
+		{name: "where time", filter: repositories.LogFilter{Where: where("created_at<2026-09-01T00:10:00Z and up=false")}, sort: "created_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders, err := logquery.ParseSort(tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the id breaks ties, as the API adds it too
+			filter := tt.filter
+			for _, o := range logquery.WithID(orders) {
+				filter.OrderBy(o.Field, o.Desc)
+			}
+
+			var want, got []models.NodeLog
+			if err := sql.NodeLogs.Find(context.Background(), filter, &want); err != nil {
+				t.Fatal(err)
+			}
+			if err := mem.NodeLogs.Find(context.Background(), filter, &got); err != nil {
+				t.Fatal(err)
+			}
+			if ids(got) != ids(want) {
+				t.Errorf("Find() = %s, SQL store returns %s", ids(got), ids(want))
+			}
+
+			var each []models.NodeLog
+			err = mem.NodeLogs.Each(context.Background(), filter, func(l *models.NodeLog) error {
+				each = append(each, *l)
+				return nil
+			})
+			if err != nil || ids(each) != ids(want) {
+				t.Errorf("Each() = %s, %v, SQL store returns %s", ids(each), err, ids(want))
+			}
+		})
+	}
+}
+
+func ids(logs []models.NodeLog) string {
+	out := make([]uint, len(logs))
+	for i, l := range logs {
+		out[i] = l.ID
+	}
+	return fmt.Sprint(out)
+}
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"uptime/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type sqlCertificates struct {
+	db *gorm.DB
+}
+
+func (r sqlCertificates) Save(cert *models.NodeCertificate) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "node_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"subject", "issuer", "sans", "not_before", "not_after", "chain_valid", "chain_error", "updated_at"}),
+	}).Create(cert).Error
+}
+
+func (r sqlCertificates) Find(ctx context.Context, notAfter *time.Time, certs *[]models.NodeCertificate) error {
+	db := r.db.WithContext(ctx).Order("not_after asc, node_id asc")
+	if notAfter != nil {
+		db = db.Where("not_after <= ?", *notAfter)
+	}
+	return db.Find(certs).Error
+}
+
+func (r sqlCertificates) ByNodeID(nodeID uint, cert *models.NodeCertificate) error {
+	return r.db.Where("node_id = ?", nodeID).First(cert).Error
+}
@@ -1,26 +1,224 @@
 package repositories
 
 import (
-	"uptime/database"
+	"context"
+	"time"
+	"uptime/internal/logquery"
 	"uptime/models"
+
+	"gorm.io/gorm"
 )
 
-func CreateNodeLog(log *models.NodeLog) error {
-	return database.DB.Create(log).Error
+type sqlNodeLogs struct {
+	db *gorm.DB
+}
+
+func (r sqlNodeLogs) Create(log *models.NodeLog) error {
+	return r.db.Create(log).Error
+}
+
+func (r sqlNodeLogs) CreateMany(logs []models.NodeLog) error {
+	return r.db.CreateInBatches(&logs, insertBatchSize).Error
+}
+
+func (r sqlNodeLogs) All(logs *[]models.NodeLog) error {
+	return r.db.Find(logs).Error
+}
+
+func (r sqlNodeLogs) ByCycleID(cycleID string, logs *[]models.NodeLog) error {
+	return r.db.Where("cycle_id = ?", cycleID).Order("id asc").Find(logs).Error
 }
 
-func GetAllNodeLogs(logs *[]models.NodeLog) error {
-	return database.DB.Find(logs).Error
+func (r sqlNodeLogs) ByID(id uint, log *models.NodeLog) error {
+	return r.db.First(log, id).Error
 }
 
-func GetNodeLogByID(id uint, log *models.NodeLog) error {
-	return database.DB.First(log, id).Error
+func (r sqlNodeLogs) Find(ctx context.Context, filter LogFilter, logs *[]models.NodeLog) error {
+	return applyLogFilter(r.db.WithContext(ctx).Model(&models.NodeLog{}), filter).Find(logs).Error
 }
 
-func UpdateNodeLog(log *models.NodeLog) error {
-	return database.DB.Save(log).Error
+func (r sqlNodeLogs) Each(ctx context.Context, filter LogFilter, fn func(log *models.NodeLog) error) error {
+	return eachLog(r.db.WithContext(ctx), filter, logquery.NodeLogField, fn)
 }
 
-func DeleteNodeLog(log *models.NodeLog) error {
-	return database.DB.Delete(log).Error
+func (r sqlNodeLogs) Update(log *models.NodeLog) error {
+	return r.db.Save(log).Error
+}
+
+func (r sqlNodeLogs) Delete(log *models.NodeLog) error {
+	return r.db.Delete(log).Error
+}
+
+func (r sqlNodeLogs) CreateAttempts(attempts []models.CheckAttempt) error {
+	return r.db.CreateInBatches(&attempts, insertBatchSize).Error
+}
+
+func (r sqlNodeLogs) Attempts(nodeLogID uint, attempts *[]models.CheckAttempt) error {
+	return r.db.Where("node_log_id = ?", nodeLogID).Order("attempt asc").Find(attempts).Error
+}
+
+func (r sqlNodeLogs) CountExpired(e Expiry) (int64, error) {
+	var count int64
+	err := e.Scope(r.db.Model(&models.NodeLog{}), "created_at").Count(&count).Error
+	return count, err
+}
+
+func (r sqlNodeLogs) DeleteExpired(e Expiry, limit int) (int64, error) {
+	query := e.Scope(r.db.Model(&models.NodeLog{}), "created_at")
+	return DeleteBatch(r.db, query, "node_logs", limit, func(tx *gorm.DB, ids []uint) error {
+		return tx.Where("node_log_id IN ?", ids).Delete(&models.CheckAttempt{}).Error
+	})
+}
+
+func (r sqlNodeLogs) InRange(nodeID uint, from, to time.Time, logs *[]models.NodeLog) error {
+	var previous []models.NodeLog
+	err := r.db.Where("node_id = ? AND created_at < ?", nodeID, from).
+		Order("created_at desc").Limit(1).Find(&previous).Error
+	if err != nil {
+		return err
+	}
+
+	var inRange []models.NodeLog
+	err = r.db.Where("node_id = ? AND created_at >= ? AND created_at < ?", nodeID, from, to).
+		Order("created_at asc").Find(&inRange).Error
+	if err != nil {
+		return err
+	}
+
+	*logs = append(previous, inRange...)
+	return nil
+}
+
+func (r sqlNodeLogs) OldestTime() (*time.Time, error) {
+	var logs []models.NodeLog
+	if err := r.db.Select("created_at").Order("created_at asc").Limit(1).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return nil, nil
+	}
+	return &logs[0].CreatedAt, nil
+}
+
+// applyLogFilter adds the conditions, order and limit of a LogFilter to a node log or history query
+func applyLogFilter(db *gorm.DB, filter LogFilter) *gorm.DB {
+	if filter.NodeID != 0 {
+		db = db.Where("node_id = ?", filter.NodeID)
+	}
+	if filter.NodeIDs != nil {
+		db = db.Where("node_id IN ?", filter.NodeIDs)
+	}
+	if filter.From != nil {
+		db = db.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		db = db.Where("created_at <= ?", *filter.To)
+	}
+	if filter.Up {
+		db = db.Where("up = ?", true)
+	}
+	if filter.Down {
+		db = db.Where("up = ?", false)
+	}
+	if filter.Suspended {
+		db = db.Where("suspended = ?", true)
+	}
+	if filter.Exception {
+		db = db.Where("exception IS NOT NULL")
+	}
+	if filter.Where != nil {
+		clause, args := filter.Where.SQL()
+		db = db.Where(clause, args...)
+	}
+	// NULLs sort first ascending and last descending, which PostgreSQL
+	// does the other way around unless told
+	postgres := db.Dialector.Name() == "postgres"
+	for _, o := range filter.Order {
+		field, ok := logquery.Fields[o.Column]
+		if !ok {
+			continue
+		}
+		order := o.Column + " asc"
+		if o.Desc {
+			order = o.Column + " desc"
+		}
+		if postgres && field.Nullable {
+			if o.Desc {
+				order += " nulls last"
+			} else {
+				order += " nulls first"
+			}
+		}
+		db = db.Order(order)
+	}
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+	return db
+}
+
+// eachBatchSize is the number of rows eachLog reads at a time on SQLite
+const eachBatchSize = 1000
+
+// eachLog passes the node logs or histories matching filter to fn one at a
+// time, reading them through a database cursor. SQLite has one connection,
+// which a cursor would hold until the last row, so there the rows are read
+// in batches continuing after the last row of the previous one.
+func eachLog[T any](db *gorm.DB, filter LogFilter, field func(*T) func(string) interface{}, fn func(*T) error) error {
+	if db.Dialector.Name() == "sqlite" {
+		return eachLogBatch(db, filter, field, fn)
+	}
+
+	rows, err := applyLogFilter(db.Model(new(T)), filter).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var row T
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func eachLogBatch[T any](db *gorm.DB, filter LogFilter, field func(*T) func(string) interface{}, fn func(*T) error) error {
+	orders := make([]logquery.Order, len(filter.Order))
+	for i, o := range filter.Order {
+		orders[i] = logquery.Order{Field: o.Column, Desc: o.Desc}
+	}
+	orders = logquery.WithID(orders)
+
+	batch := filter
+	batch.Order = nil
+	for _, o := range orders {
+		batch.OrderBy(o.Field, o.Desc)
+	}
+
+	remaining := filter.Limit
+	for {
+		batch.Limit = eachBatchSize
+		if filter.Limit > 0 && remaining < eachBatchSize {
+			batch.Limit = remaining
+		}
+		var rows []T
+		if err := applyLogFilter(db.Model(new(T)), batch).Find(&rows).Error; err != nil {
+			return err
+		}
+		for i := range rows {
+			if err := fn(&rows[i]); err != nil {
+				return err
+			}
+		}
+		remaining -= len(rows)
+		if len(rows) < batch.Limit || (filter.Limit > 0 && remaining == 0) {
+			return nil
+		}
+		last := logquery.Values(orders, field(&rows[len(rows)-1]))
+		batch.Where = logquery.And(filter.Where, logquery.After(orders, last))
+	}
 }
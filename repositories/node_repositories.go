@@ -1,26 +1,98 @@
 package repositories
 
 import (
-	"uptime/database"
+	"context"
+	"time"
 	"uptime/models"
+
+	"gorm.io/gorm"
 )
 
-func CreateNode(node *models.Node) error {
-	return database.DB.Create(node).Error
+type sqlNodes struct {
+	db *gorm.DB
+}
+
+func (r sqlNodes) Unscoped() NodeRepository {
+	return sqlNodes{db: r.db.Unscoped()}
+}
+
+func (r sqlNodes) Create(node *models.Node) error {
+	return r.db.Create(node).Error
+}
+
+func (r sqlNodes) All(nodes *[]models.Node) error {
+	return r.db.Find(nodes).Error
+}
+
+func (r sqlNodes) ByID(id uint, node *models.Node) error {
+	return r.db.First(node, id).Error
+}
+
+func (r sqlNodes) Update(node *models.Node) error {
+	return r.db.Save(node).Error
+}
+
+func (r sqlNodes) Delete(node *models.Node) error {
+	return r.db.Delete(node).Error
+}
+
+func (r sqlNodes) Restore(node *models.Node) error {
+	if err := r.db.Unscoped().Model(node).Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	node.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+func (r sqlNodes) ResumeDue(now time.Time) (int64, error) {
+	result := r.db.Model(&models.Node{}).
+		Where("lifecycle = ? AND paused_until <= ?", models.NodePaused, now).
+		Updates(map[string]interface{}{"lifecycle": models.NodeActive, "paused_until": nil})
+	return result.RowsAffected, result.Error
+}
+
+func (r sqlNodes) ByURL(url string, node *models.Node) error {
+	return r.db.Where("url = ?", url).First(node).Error
+}
+
+func (r sqlNodes) ByGroupID(groupID uint, nodes *[]models.Node) error {
+	return r.db.Where("group_id = ?", groupID).Order("id").Find(nodes).Error
 }
 
-func GetAllNodes(nodes *[]models.Node) error {
-	return database.DB.Find(nodes).Error
+func (r sqlNodes) ByIDs(ids []uint, nodes *[]models.Node) error {
+	return r.db.Where("id IN ?", ids).Order("id").Find(nodes).Error
 }
 
-func GetNodeByID(id uint, node *models.Node) error {
-	return database.DB.First(node, id).Error
+func (r sqlNodes) SetGroup(ids []uint, groupID *uint) error {
+	return r.db.Model(&models.Node{}).Where("id IN ?", ids).Update("group_id", groupID).Error
 }
 
-func UpdateNode(node *models.Node) error {
-	return database.DB.Save(node).Error
+func (r sqlNodes) WithLogs(page Page, nodes *[]models.Node) error {
+	db := r.db.Preload("NodeLogs", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, node_id, delay, status, up, suspended, exception, created_at")
+	})
+	if page.AfterID != 0 {
+		db = db.Where("id > ?", page.AfterID)
+	}
+	if page.Limit > 0 {
+		db = db.Limit(page.Limit)
+	}
+	return db.Order("id").Find(nodes).Error
 }
 
-func DeleteNode(node *models.Node) error {
-	return database.DB.Delete(node).Error
+func (r sqlNodes) WithHistories(ctx context.Context, urls []string, ids []uint, page Page, nodes *[]models.Node) error {
+	db := r.db.WithContext(ctx).Preload("Histories")
+	if urls != nil {
+		db = db.Where("url IN ?", urls)
+	}
+	if ids != nil {
+		db = db.Where("id IN ?", ids)
+	}
+	if page.AfterID != 0 {
+		db = db.Where("id < ?", page.AfterID)
+	}
+	if page.Limit > 0 {
+		db = db.Limit(page.Limit)
+	}
+	return db.Order("id desc").Find(nodes).Error
 }
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"uptime/database"
+	"uptime/models"
+)
+
+// DeliveryFilter narrows a delivery listing. Zero values are ignored.
+type DeliveryFilter struct {
+	Channel  string
+	TargetID uint
+	NodeID   uint
+	Status   string
+	AfterID  uint // newest first, continuing after AfterID
+	Limit    int
+}
+
+func CreateNotificationDelivery(delivery *models.NotificationDelivery) error {
+	return database.DB.Create(delivery).Error
+}
+
+func UpdateNotificationDelivery(delivery *models.NotificationDelivery) error {
+	return database.DB.Save(delivery).Error
+}
+
+func FindNotificationDeliveries(filter DeliveryFilter, deliveries *[]models.NotificationDelivery) error {
+	db := database.DB.Model(&models.NotificationDelivery{})
+	if filter.Channel != "" {
+		db = db.Where("channel = ?", filter.Channel)
+	}
+	if filter.TargetID != 0 {
+		db = db.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.NodeID != 0 {
+		db = db.Where("node_id = ?", filter.NodeID)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.AfterID != 0 {
+		db = db.Where("id < ?", filter.AfterID)
+	}
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+	return db.Order("id desc").Find(deliveries).Error
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+	"uptime/database"
+	"uptime/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetRollupState returns the progress of a tier, or nil before its first run
+func GetRollupState(tier string) (*models.RollupState, error) {
+	state := &models.RollupState{}
+	err := database.DB.Where("tier = ?", tier).First(state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func SaveRollupState(state *models.RollupState) error {
+	return database.DB.Save(state).Error
+}
+
+// SaveRollups inserts the rollups of a tier, replacing existing buckets
+func SaveRollups(tier string, rollups []models.NodeLogRollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+	return database.DB.Table(models.RollupTable(tier)).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "node_id"}, {Name: "bucket_start"}},
+			DoUpdates: clause.AssignmentColumns(rollupColumns),
+		}).
+		CreateInBatches(rollups, 500).Error
+}
+
+// rollupColumns are overwritten when a bucket is rolled up again
+var rollupColumns = []string{
+	"bucket_end", "checks", "up_count", "down_count", "suspended_count", "maintenance_count",
+	"min_delay", "avg_delay", "max_delay", "p95_delay", "status_codes", "delay_buckets",
+	"up_time", "down_time", "suspended_time", "maintenance_time", "unknown_time", "updated_at",
+}
+
+// GetRollups loads the rollups of a node whose bucket starts within [from, to)
+func GetRollups(tier string, nodeID uint, from, to time.Time, rollups *[]models.NodeLogRollup) error {
+	return database.DB.Table(models.RollupTable(tier)).
+		Where("node_id = ? AND bucket_start >= ? AND bucket_start < ?", nodeID, from, to).
+		Order("bucket_start asc").Find(rollups).Error
+}
@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"uptime/internal/logquery"
+	"uptime/models"
+
+	"gorm.io/gorm"
+)
+
+// NodeRepository stores the monitored nodes. Archived nodes are soft deleted
+// and left out of every method unless the repository is Unscoped.
+type NodeRepository interface {
+	// Unscoped returns a repository that includes archived nodes, and whose
+	// Delete removes a node for good
+	Unscoped() NodeRepository
+	Create(node *models.Node) error
+	All(nodes *[]models.Node) error
+	ByID(id uint, node *models.Node) error
+	ByURL(url string, node *models.Node) error
+	ByGroupID(groupID uint, nodes *[]models.Node) error
+	// ByIDs loads the nodes with the given IDs in ID order
+	ByIDs(ids []uint, nodes *[]models.Node) error
+	Update(node *models.Node) error
+	// SetGroup moves the nodes to a group, or out of their group when groupID is nil
+	SetGroup(ids []uint, groupID *uint) error
+	// Delete archives the node
+	Delete(node *models.Node) error
+	// Restore brings an archived node back
+	Restore(node *models.Node) error
+	// ResumeDue sets the paused nodes whose PausedUntil has passed back to
+	// active and returns how many there were
+	ResumeDue(now time.Time) (int64, error)
+	// WithLogs loads a page of the nodes with their logs, in ID order
+	WithLogs(page Page, nodes *[]models.Node) error
+	// WithHistories loads a page of the nodes with the given URLs and IDs
+	// with their histories, newest node first. A nil urls or ids does not
+	// filter.
+	WithHistories(ctx context.Context, urls []string, ids []uint, page Page, nodes *[]models.Node) error
+}
+
+// NodeLogRepository stores the result of every check and its attempts
+type NodeLogRepository interface {
+	Create(log *models.NodeLog) error
+	// CreateMany inserts the logs in batches and sets their IDs
+	CreateMany(logs []models.NodeLog) error
+	All(logs *[]models.NodeLog) error
+	ByID(id uint, log *models.NodeLog) error
+	ByCycleID(cycleID string, logs *[]models.NodeLog) error
+	Find(ctx context.Context, filter LogFilter, logs *[]models.NodeLog) error
+	// Each passes the logs matching filter to fn one at a time, without
+	// loading them all, and stops at the first error fn returns
+	Each(ctx context.Context, filter LogFilter, fn func(log *models.NodeLog) error) error
+	// InRange loads the logs of a node created within [from, to), preceded
+	// by the last log before from, oldest first
+	InRange(nodeID uint, from, to time.Time, logs *[]models.NodeLog) error
+	// OldestTime returns when the oldest log was written, nil when there are none
+	OldestTime() (*time.Time, error)
+	Update(log *models.NodeLog) error
+	Delete(log *models.NodeLog) error
+	CreateAttempts(attempts []models.CheckAttempt) error
+	Attempts(nodeLogID uint, attempts *[]models.CheckAttempt) error
+	// CountExpired counts the logs selected by e, by their creation time
+	CountExpired(e Expiry) (int64, error)
+	// DeleteExpired deletes up to limit of the logs CountExpired counts,
+	// with their attempts, and returns how many it deleted
+	DeleteExpired(e Expiry, limit int) (int64, error)
+}
+
+// HistoryRepository stores the latest check result of each node
+type HistoryRepository interface {
+	Create(history *models.History) error
+	// CreateMany inserts the histories in batches and sets their IDs
+	CreateMany(histories []models.History) error
+	// SaveMany writes the check results of existing histories in batches
+	SaveMany(histories []models.History) error
+	All(histories *[]models.History) error
+	ByID(id uint, history *models.History) error
+	Find(ctx context.Context, filter LogFilter, histories *[]models.History) error
+	// Each passes the histories matching filter to fn one at a time, without
+	// loading them all, and stops at the first error fn returns
+	Each(ctx context.Context, filter LogFilter, fn func(history *models.History) error) error
+	Update(history *models.History) error
+	Delete(history *models.History) error
+	// LastCheckTimes returns when each node's history was last written, i.e. its last check
+	LastCheckTimes() (map[uint]time.Time, error)
+}
+
+// IncidentRepository stores incidents and their notes
+type IncidentRepository interface {
+	Create(incident *models.Incident) error
+	Find(filter IncidentFilter, incidents *[]models.Incident) error
+	Open(incidents *[]models.Incident) error
+	// ByID loads the incident with its notes
+	ByID(id uint, incident *models.Incident) error
+	Update(incident *models.Incident) error
+	CreateNote(note *models.IncidentNote) error
+	// StartedBetween loads the incidents of the given nodes that started within [from, to)
+	StartedBetween(nodeIDs []uint, from, to time.Time, incidents *[]models.Incident) error
+	// CountExpired counts the resolved incidents selected by e, by their
+	// resolve time. Open incidents are never expired.
+	CountExpired(e Expiry) (int64, error)
+	// DeleteExpired deletes up to limit of the incidents CountExpired
+	// counts, with their notes, and returns how many it deleted
+	DeleteExpired(e Expiry, limit int) (int64, error)
+}
+
+// CertificateRepository stores the latest TLS certificate seen for each node
+type CertificateRepository interface {
+	// Save inserts or replaces the certificate stored for the node
+	Save(cert *models.NodeCertificate) error
+	// Find loads the certificates expiring at or before notAfter, all of
+	// them when it is nil, soonest expiry first
+	Find(ctx context.Context, notAfter *time.Time, certs *[]models.NodeCertificate) error
+	ByNodeID(nodeID uint, cert *models.NodeCertificate) error
+}
+
+// Store groups the repositories of the check results, so they can be backed
+// by the database or kept in memory
+type Store struct {
+	Nodes        NodeRepository
+	NodeLogs     NodeLogRepository
+	Histories    HistoryRepository
+	Incidents    IncidentRepository
+	Certificates CertificateRepository
+}
+
+// LogFilter narrows a node log or history listing. Zero values are ignored.
+type LogFilter struct {
+	NodeID    uint
+	NodeIDs   []uint     // only these nodes, none when empty but not nil
+	From      *time.Time // created at or after From
+	To        *time.Time // created at or before To
+	Up        bool       // only up
+	Down      bool       // only not up
+	Suspended bool       // only suspended
+	Exception bool       // only with an exception
+	Where     *logquery.Expr
+	Order     []Order // applied in turn, the first one sorts first
+	Limit     int
+}
+
+// Order sorts a listing by one column
+type Order struct {
+	Column string // one of logquery.Fields
+	Desc   bool
+}
+
+// OrderBy appends a sort on column
+func (f *LogFilter) OrderBy(column string, desc bool) {
+	f.Order = append(f.Order, Order{Column: column, Desc: desc})
+}
+
+// Page selects one page of a listing, continuing after the last row of the
+// previous page in the listing's order
+type Page struct {
+	AfterID uint // the last ID of the previous page, 0 starts at the beginning
+	Limit   int  // 0 returns every row
+}
+
+// insertBatchSize is the number of rows per INSERT of the batch writes
+const insertBatchSize = 500
+
+// NewSQLStore returns a store backed by db
+func NewSQLStore(db *gorm.DB) *Store {
+	return &Store{
+		Nodes:        sqlNodes{db: db},
+		NodeLogs:     sqlNodeLogs{db: db},
+		Histories:    sqlHistories{db: db},
+		Incidents:    sqlIncidents{db: db},
+		Certificates: sqlCertificates{db: db},
+	}
+}
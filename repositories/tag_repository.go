@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"uptime/database"
+	"uptime/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func CreateTag(tag *models.Tag) error {
+	return database.DB.Create(tag).Error
+}
+
+func GetAllTags(tags *[]models.Tag) error {
+	return database.DB.Order("name").Find(tags).Error
+}
+
+func GetTagByID(id uint, tag *models.Tag) error {
+	return database.DB.First(tag, id).Error
+}
+
+func GetTagsByName(names []string, tags *[]models.Tag) error {
+	return database.DB.Where("name IN ?", names).Find(tags).Error
+}
+
+func UpdateTag(tag *models.Tag) error {
+	return database.DB.Save(tag).Error
+}
+
+// DeleteTag removes the tag from its nodes and deletes it
+func DeleteTag(tag *models.Tag) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", tag.ID).Delete(&models.NodeTag{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(tag).Error
+	})
+}
+
+// GetNodeTags loads the tags of the given nodes, or of every node when
+// nodeIDs is nil, keyed by node ID
+func GetNodeTags(nodeIDs []uint) (map[uint][]models.Tag, error) {
+	var rows []struct {
+		NodeID uint
+		models.Tag
+	}
+	db := database.DB.Table("node_tags").
+		Select("node_tags.node_id, tags.*").
+		Joins("JOIN tags ON tags.id = node_tags.tag_id")
+	if nodeIDs != nil {
+		db = db.Where("node_tags.node_id IN ?", nodeIDs)
+	}
+	if err := db.Order("tags.name").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	tags := make(map[uint][]models.Tag)
+	for _, r := range rows {
+		tags[r.NodeID] = append(tags[r.NodeID], r.Tag)
+	}
+	return tags, nil
+}
+
+// GetTaggedNodeIDs returns the IDs of the nodes carrying the tag
+func GetTaggedNodeIDs(tagID uint, ids *[]uint) error {
+	return database.DB.Model(&models.NodeTag{}).Where("tag_id = ?", tagID).Order("node_id").Pluck("node_id", ids).Error
+}
+
+// AddNodeTags tags every node with every tag, keeping the links that already exist
+func AddNodeTags(nodeIDs, tagIDs []uint) error {
+	links := make([]models.NodeTag, 0, len(nodeIDs)*len(tagIDs))
+	for _, nodeID := range nodeIDs {
+		for _, tagID := range tagIDs {
+			links = append(links, models.NodeTag{NodeID: nodeID, TagID: tagID})
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return database.DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(links, insertBatchSize).Error
+}
+
+// RemoveNodeTags removes the tags from the nodes
+func RemoveNodeTags(nodeIDs, tagIDs []uint) error {
+	if len(nodeIDs) == 0 || len(tagIDs) == 0 {
+		return nil
+	}
+	return database.DB.Where("node_id IN ? AND tag_id IN ?", nodeIDs, tagIDs).Delete(&models.NodeTag{}).Error
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"uptime/database"
+	"uptime/models"
+)
+
+func CreateWebhook(webhook *models.Webhook) error {
+	return database.DB.Create(webhook).Error
+}
+
+func GetAllWebhooks(webhooks *[]models.Webhook) error {
+	return database.DB.Find(webhooks).Error
+}
+
+func GetEnabledWebhooks(webhooks *[]models.Webhook) error {
+	return database.DB.Where("enabled = ?", true).Find(webhooks).Error
+}
+
+func GetWebhookByID(id uint, webhook *models.Webhook) error {
+	return database.DB.First(webhook, id).Error
+}
+
+func UpdateWebhook(webhook *models.Webhook) error {
+	return database.DB.Save(webhook).Error
+}
+
+func DeleteWebhook(webhook *models.Webhook) error {
+	return database.DB.Delete(webhook).Error
+}
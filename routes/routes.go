@@ -14,16 +14,22 @@ func SetupRoutes(app *fiber.App) {
 
 	node := api.Group("/nodes")
 	node.Get("/with-logs/all", controllers.GetAllNodesWithLogs)
+	node.Post("/bulk-assign", controllers.BulkAssignNodes)
 	node.Post("/", controllers.CreateNode)
 	node.Get("/", controllers.GetAllNodes)
 	node.Get("/:id", controllers.GetNode)
+	node.Get("/:id/tags", controllers.GetNodeTags)
 	node.Put("/:id", controllers.UpdateNode)
 	node.Delete("/:id", controllers.DeleteNode)
+	node.Post("/:id/pause", controllers.PauseNode)
+	node.Post("/:id/resume", controllers.ResumeNode)
+	node.Post("/:id/restore", controllers.RestoreNode)
 
 	nodeLogs := api.Group("/node-logs")
 	nodeLogs.Post("/", controllers.CreateNodeLog)
 	nodeLogs.Get("/", controllers.GetAllNodeLogs)
 	nodeLogs.Get("/:id", controllers.GetNodeLog)
+	nodeLogs.Get("/:id/attempts", controllers.GetNodeLogAttempts)
 	nodeLogs.Put("/:id", controllers.UpdateNodeLog)
 	nodeLogs.Delete("/:id", controllers.DeleteNodeLog)
 
@@ -34,11 +40,77 @@ func SetupRoutes(app *fiber.App) {
 	histories.Put("/:id", controllers.UpdateHistory)
 	histories.Delete("/:id", controllers.DeleteHistory)
 
+	groups := api.Group("/groups")
+	groups.Post("/", controllers.CreateGroup)
+	groups.Get("/", controllers.GetAllGroups)
+	groups.Get("/summary", controllers.GetGroupSummary)
+	groups.Get("/:id", controllers.GetGroup)
+	groups.Put("/:id", controllers.UpdateGroup)
+	groups.Delete("/:id", controllers.DeleteGroup)
+
+	tags := api.Group("/tags")
+	tags.Post("/", controllers.CreateTag)
+	tags.Get("/", controllers.GetAllTags)
+	tags.Get("/:id", controllers.GetTag)
+	tags.Put("/:id", controllers.UpdateTag)
+	tags.Delete("/:id", controllers.DeleteTag)
+
+	rules := api.Group("/rules")
+	rules.Post("/", controllers.CreateAssertionRule)
+	rules.Get("/", controllers.GetAllAssertionRules)
+	rules.Get("/:id", controllers.GetAssertionRule)
+	rules.Put("/:id", controllers.UpdateAssertionRule)
+	rules.Delete("/:id", controllers.DeleteAssertionRule)
+
+	incidents := api.Group("/incidents")
+	incidents.Get("/", controllers.GetIncidents)
+	incidents.Get("/:id", controllers.GetIncident)
+	incidents.Post("/:id/acknowledge", controllers.AcknowledgeIncident)
+	incidents.Post("/:id/notes", controllers.AddIncidentNote)
+
+	maintenanceWindows := api.Group("/maintenance-windows")
+	maintenanceWindows.Post("/", controllers.CreateMaintenanceWindow)
+	maintenanceWindows.Get("/", controllers.GetAllMaintenanceWindows)
+	maintenanceWindows.Get("/:id", controllers.GetMaintenanceWindow)
+	maintenanceWindows.Get("/:id/occurrences", controllers.GetMaintenanceOccurrences)
+	maintenanceWindows.Put("/:id", controllers.UpdateMaintenanceWindow)
+	maintenanceWindows.Delete("/:id", controllers.DeleteMaintenanceWindow)
+
+	webhooks := api.Group("/webhooks")
+	webhooks.Post("/", controllers.CreateWebhook)
+	webhooks.Get("/", controllers.GetAllWebhooks)
+	webhooks.Get("/:id", controllers.GetWebhook)
+	webhooks.Put("/:id", controllers.UpdateWebhook)
+	webhooks.Delete("/:id", controllers.DeleteWebhook)
+	webhooks.Post("/:id/test", controllers.TestWebhook)
+
+	emailChannels := api.Group("/email-channels")
+	emailChannels.Post("/", controllers.CreateEmailChannel)
+	emailChannels.Get("/", controllers.GetAllEmailChannels)
+	emailChannels.Get("/:id", controllers.GetEmailChannel)
+	emailChannels.Put("/:id", controllers.UpdateEmailChannel)
+	emailChannels.Delete("/:id", controllers.DeleteEmailChannel)
+	emailChannels.Post("/:id/test", controllers.TestEmailChannel)
+
+	chatChannels := api.Group("/chat-channels")
+	chatChannels.Post("/", controllers.CreateChatChannel)
+	chatChannels.Get("/", controllers.GetAllChatChannels)
+	chatChannels.Get("/:id", controllers.GetChatChannel)
+	chatChannels.Put("/:id", controllers.UpdateChatChannel)
+	chatChannels.Delete("/:id", controllers.DeleteChatChannel)
+	chatChannels.Post("/:id/test", controllers.TestChatChannel)
+
+	api.Get("/notifications/deliveries", controllers.GetNotificationDeliveries)
+
 	api.Get("/check-uptime", controllers.CheckUptime)
+	api.Get("/cycles/current", controllers.GetCycleProgress)
+	api.Get("/cycles/writer", controllers.GetWriterStats)
 
 	api.Get("/report/get", controllers.GetNodeReport)
 	api.Get("/report/get-smart-query", controllers.GetNodeSmartReport)
 	api.Get("/report/all-from-history", controllers.AllFormHistory)
 	api.Post("/report/bulk-url/get", controllers.GetBulkURL)
 	api.Get("/report/last", controllers.LastURLs)
+	api.Get("/report/certificates", controllers.GetCertificateReport)
+	api.Get("/report/sla", controllers.GetSLAReport)
 }
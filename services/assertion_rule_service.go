@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+var ruleTypes = map[string]bool{
+	models.RuleContains:    true,
+	models.RuleNotContains: true,
+	models.RuleRegex:       true,
+	models.RuleJSONPath:    true,
+	models.RuleHeader:      true,
+}
+
+var ruleSeverities = map[string]bool{
+	models.SeverityInfo:      true,
+	models.SeveritySuspended: true,
+	models.SeverityDown:      true,
+}
+
+// AssertionRuleInput holds the user supplied fields of an assertion rule
+type AssertionRuleInput struct {
+	NodeID     *uint  `json:"node_id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Target     string `json:"target"`
+	Value      string `json:"value"`
+	IgnoreCase bool   `json:"ignore_case"`
+	Severity   string `json:"severity"`
+	Enabled    *bool  `json:"enabled"`
+}
+
+// validateAssertionRuleInput normalizes input and copies it onto rule
+func validateAssertionRuleInput(input AssertionRuleInput, rule *models.AssertionRule) error {
+	if input.NodeID != nil {
+		if _, err := GetNode(*input.NodeID); err != nil {
+			return invalid("node %d does not exist", *input.NodeID)
+		}
+	}
+
+	ruleType := strings.ToLower(strings.TrimSpace(input.Type))
+	if !ruleTypes[ruleType] {
+		return invalid("unsupported rule type %q", input.Type)
+	}
+
+	severity := strings.ToLower(strings.TrimSpace(input.Severity))
+	if severity == "" {
+		severity = models.SeverityDown
+	}
+	if !ruleSeverities[severity] {
+		return invalid("unsupported severity %q", input.Severity)
+	}
+
+	target := strings.TrimSpace(input.Target)
+	switch ruleType {
+	case models.RuleHeader, models.RuleJSONPath:
+		if target == "" {
+			return invalid("target is required for %s rules", ruleType)
+		}
+	case models.RuleRegex:
+		pattern := input.Value
+		if input.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return invalid("invalid regex: %v", err)
+		}
+	}
+
+	if input.Value == "" && ruleType != models.RuleJSONPath && ruleType != models.RuleHeader {
+		return invalid("value cannot be empty")
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = ruleType
+	}
+
+	rule.NodeID = input.NodeID
+	rule.Name = name
+	rule.Type = ruleType
+	rule.Target = target
+	rule.Value = input.Value
+	rule.IgnoreCase = input.IgnoreCase
+	rule.Severity = severity
+	rule.Enabled = input.Enabled == nil || *input.Enabled
+	return nil
+}
+
+func CreateAssertionRule(input AssertionRuleInput) (*models.AssertionRule, error) {
+	rule := &models.AssertionRule{}
+	if err := validateAssertionRuleInput(input, rule); err != nil {
+		return nil, err
+	}
+
+	err := repositories.CreateAssertionRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func GetAllAssertionRules() ([]models.AssertionRule, error) {
+	var rules []models.AssertionRule
+	err := repositories.GetAllAssertionRules(&rules)
+	return rules, err
+}
+
+// GetAssertionRulesByNode returns a node's own rules, or the global defaults when nodeID is nil
+func GetAssertionRulesByNode(nodeID *uint) ([]models.AssertionRule, error) {
+	var rules []models.AssertionRule
+	err := repositories.GetAssertionRulesByNode(nodeID, &rules)
+	return rules, err
+}
+
+func GetAssertionRule(id uint) (*models.AssertionRule, error) {
+	if id == 0 {
+		return nil, errors.New("invalid ID")
+	}
+
+	rule := &models.AssertionRule{}
+	err := repositories.GetAssertionRuleByID(id, rule)
+	if err != nil {
+		return nil, errors.New("assertion rule not found")
+	}
+	return rule, nil
+}
+
+func UpdateAssertionRule(id uint, input AssertionRuleInput) (*models.AssertionRule, error) {
+	rule, err := GetAssertionRule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAssertionRuleInput(input, rule); err != nil {
+		return nil, err
+	}
+
+	err = repositories.UpdateAssertionRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func DeleteAssertionRuleByID(id uint) error {
+	rule, err := GetAssertionRule(id)
+	if err != nil {
+		return err
+	}
+	return repositories.DeleteAssertionRule(rule)
+}
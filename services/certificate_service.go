@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+	"time"
+	"uptime/models"
+)
+
+// GetCertificates returns the latest certificate of every node expiring at
+// or before notAfter, all of them when it is nil, soonest expiry first
+func GetCertificates(ctx context.Context, notAfter *time.Time) ([]models.NodeCertificate, error) {
+	var certs []models.NodeCertificate
+	err := store.Certificates.Find(ctx, notAfter, &certs)
+	return certs, err
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"uptime/internal/notify"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// DefaultChatAttempts is used when a chat channel does not set max_attempts
+const DefaultChatAttempts = 3
+
+// ChatChannelInput holds the user supplied fields of a chat channel
+type ChatChannelInput struct {
+	Name        string   `json:"name"`
+	Kind        string   `json:"kind"`        // telegram or slack
+	BotToken    *string  `json:"bot_token"`   // telegram, omitted on update keeps the current token
+	ChatID      string   `json:"chat_id"`     // telegram
+	WebhookURL  *string  `json:"webhook_url"` // slack, omitted on update keeps the current URL
+	APIBaseURL  string   `json:"api_base_url"`
+	NodeIDs     []uint   `json:"node_ids"`
+	GroupIDs    []uint   `json:"group_ids"`
+	Events      []string `json:"events"` // down, up, suspended; empty means all
+	MaxAttempts uint     `json:"max_attempts"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+func validHTTPURL(raw string) bool {
+	parsedURL, err := url.Parse(raw)
+	return err == nil && (parsedURL.Scheme == "http" || parsedURL.Scheme == "https") && parsedURL.Host != ""
+}
+
+// validateChatChannelInput normalizes input and copies it onto channel
+func validateChatChannelInput(input ChatChannelInput, channel *models.ChatChannel) error {
+	kind := strings.ToLower(strings.TrimSpace(input.Kind))
+
+	botToken := channel.BotToken
+	if input.BotToken != nil {
+		botToken = strings.TrimSpace(*input.BotToken)
+	}
+	webhookURL := channel.WebhookURL
+	if input.WebhookURL != nil {
+		webhookURL = strings.TrimSpace(*input.WebhookURL)
+	}
+	chatID := strings.TrimSpace(input.ChatID)
+	apiBaseURL := strings.TrimSpace(input.APIBaseURL)
+
+	switch kind {
+	case models.ChannelTelegram:
+		if botToken == "" || strings.ContainsAny(botToken, " /") {
+			return invalid("a valid bot_token is required for telegram channels")
+		}
+		if chatID == "" {
+			return invalid("chat_id is required for telegram channels")
+		}
+		if apiBaseURL != "" && !validHTTPURL(apiBaseURL) {
+			return invalid("invalid api_base_url, must be a valid HTTP/HTTPS URL")
+		}
+		webhookURL = ""
+	case models.ChannelSlack:
+		if !validHTTPURL(webhookURL) {
+			return invalid("a valid webhook_url is required for slack channels")
+		}
+		botToken = ""
+		chatID = ""
+		apiBaseURL = ""
+	default:
+		return invalid("kind must be telegram or slack")
+	}
+
+	nodeIDs, groupIDs, err := validateScope(input.NodeIDs, input.GroupIDs)
+	if err != nil {
+		return err
+	}
+
+	events, err := validateEvents(input.Events)
+	if err != nil {
+		return err
+	}
+
+	maxAttempts := input.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultChatAttempts
+	}
+	if maxAttempts > MaxDeliveryAttempts {
+		return invalid("max_attempts cannot exceed %d", MaxDeliveryAttempts)
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = kind
+	}
+
+	channel.Name = name
+	channel.Kind = kind
+	channel.BotToken = botToken
+	channel.ChatID = chatID
+	channel.WebhookURL = webhookURL
+	channel.APIBaseURL = apiBaseURL
+	channel.NodeIDs = nodeIDs
+	channel.GroupIDs = groupIDs
+	channel.Events = events
+	channel.MaxAttempts = maxAttempts
+	channel.Enabled = input.Enabled == nil || *input.Enabled
+	return nil
+}
+
+func CreateChatChannel(input ChatChannelInput) (*models.ChatChannel, error) {
+	channel := &models.ChatChannel{}
+	if err := validateChatChannelInput(input, channel); err != nil {
+		return nil, err
+	}
+
+	err := repositories.CreateChatChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func GetAllChatChannels() ([]models.ChatChannel, error) {
+	var channels []models.ChatChannel
+	err := repositories.GetAllChatChannels(&channels)
+	return channels, err
+}
+
+func GetChatChannel(id uint) (*models.ChatChannel, error) {
+	if id == 0 {
+		return nil, errors.New("invalid ID")
+	}
+
+	channel := &models.ChatChannel{}
+	err := repositories.GetChatChannelByID(id, channel)
+	if err != nil {
+		return nil, errors.New("chat channel not found")
+	}
+	return channel, nil
+}
+
+func UpdateChatChannel(id uint, input ChatChannelInput) (*models.ChatChannel, error) {
+	channel, err := GetChatChannel(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateChatChannelInput(input, channel); err != nil {
+		return nil, err
+	}
+
+	err = repositories.UpdateChatChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func DeleteChatChannelByID(id uint) error {
+	channel, err := GetChatChannel(id)
+	if err != nil {
+		return err
+	}
+	return repositories.DeleteChatChannel(channel)
+}
+
+// TestChatChannel sends a test message through the channel and returns the delivery
+func TestChatChannel(id uint) (*models.NotificationDelivery, error) {
+	channel, err := GetChatChannel(id)
+	if err != nil {
+		return nil, err
+	}
+	return notify.TestChatChannel(*channel), nil
+}
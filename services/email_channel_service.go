@@ -0,0 +1,200 @@
+package services
+
+import (
+	"errors"
+	"net"
+	"net/mail"
+	"strings"
+	"uptime/internal/notify"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// DefaultEmailAttempts is used when an email channel does not set max_attempts
+const DefaultEmailAttempts = 3
+
+var tlsModes = map[string]bool{
+	models.TLSModeNone:     true,
+	models.TLSModeStartTLS: true,
+	models.TLSModeTLS:      true,
+}
+
+// EmailChannelInput holds the user supplied fields of an email channel
+type EmailChannelInput struct {
+	Name            string   `json:"name"`
+	Host            string   `json:"host"`
+	Port            uint     `json:"port"` // defaults to 587 for starttls, 465 for tls and 25 for none
+	Username        string   `json:"username"`
+	Password        *string  `json:"password"` // omitted on update keeps the current password
+	TLSMode         string   `json:"tls_mode"` // none, starttls (default) or tls
+	SkipVerify      bool     `json:"skip_verify"`
+	From            string   `json:"from"`
+	To              []string `json:"to"`
+	NodeIDs         []uint   `json:"node_ids"`
+	GroupIDs        []uint   `json:"group_ids"`
+	Events          []string `json:"events"` // down, up, suspended; empty means all
+	SubjectTemplate string   `json:"subject_template"`
+	TextTemplate    string   `json:"text_template"`
+	HTMLTemplate    string   `json:"html_template"`
+	MaxAttempts     uint     `json:"max_attempts"`
+	Enabled         *bool    `json:"enabled"`
+}
+
+// validateEmailChannelInput normalizes input and copies it onto channel
+func validateEmailChannelInput(input EmailChannelInput, channel *models.EmailChannel) error {
+	host := strings.TrimSpace(input.Host)
+	if host == "" || (strings.ContainsAny(host, " /:") && net.ParseIP(host) == nil) {
+		return invalid("invalid SMTP host %q", input.Host)
+	}
+
+	tlsMode := strings.ToLower(strings.TrimSpace(input.TLSMode))
+	if tlsMode == "" {
+		tlsMode = models.TLSModeStartTLS
+	}
+	if !tlsModes[tlsMode] {
+		return invalid("tls_mode must be none, starttls or tls")
+	}
+
+	port := input.Port
+	if port == 0 {
+		switch tlsMode {
+		case models.TLSModeTLS:
+			port = 465
+		case models.TLSModeStartTLS:
+			port = 587
+		default:
+			port = 25
+		}
+	}
+	if port > 65535 {
+		return invalid("invalid SMTP port %d", port)
+	}
+
+	if _, err := mail.ParseAddress(input.From); err != nil {
+		return invalid("invalid from address %q", input.From)
+	}
+	if len(input.To) == 0 {
+		return invalid("at least one recipient is required")
+	}
+	var to models.StringList
+	for _, addr := range input.To {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return invalid("invalid recipient %q", addr)
+		}
+		to = append(to, strings.TrimSpace(addr))
+	}
+
+	nodeIDs, groupIDs, err := validateScope(input.NodeIDs, input.GroupIDs)
+	if err != nil {
+		return err
+	}
+
+	events, err := validateEvents(input.Events)
+	if err != nil {
+		return err
+	}
+
+	if err := notify.ValidateEmailTemplates(input.SubjectTemplate, input.TextTemplate, input.HTMLTemplate); err != nil {
+		return invalid("%v", err)
+	}
+
+	maxAttempts := input.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultEmailAttempts
+	}
+	if maxAttempts > MaxDeliveryAttempts {
+		return invalid("max_attempts cannot exceed %d", MaxDeliveryAttempts)
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = host
+	}
+
+	channel.Name = name
+	channel.Host = host
+	channel.Port = port
+	channel.Username = strings.TrimSpace(input.Username)
+	if input.Password != nil {
+		channel.Password = *input.Password
+	}
+	channel.TLSMode = tlsMode
+	channel.SkipVerify = input.SkipVerify
+	channel.From = strings.TrimSpace(input.From)
+	channel.To = to
+	channel.NodeIDs = nodeIDs
+	channel.GroupIDs = groupIDs
+	channel.Events = events
+	channel.SubjectTemplate = input.SubjectTemplate
+	channel.TextTemplate = input.TextTemplate
+	channel.HTMLTemplate = input.HTMLTemplate
+	channel.MaxAttempts = maxAttempts
+	channel.Enabled = input.Enabled == nil || *input.Enabled
+	return nil
+}
+
+func CreateEmailChannel(input EmailChannelInput) (*models.EmailChannel, error) {
+	channel := &models.EmailChannel{}
+	if err := validateEmailChannelInput(input, channel); err != nil {
+		return nil, err
+	}
+
+	err := repositories.CreateEmailChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func GetAllEmailChannels() ([]models.EmailChannel, error) {
+	var channels []models.EmailChannel
+	err := repositories.GetAllEmailChannels(&channels)
+	return channels, err
+}
+
+func GetEmailChannel(id uint) (*models.EmailChannel, error) {
+	if id == 0 {
+		return nil, errors.New("invalid ID")
+	}
+
+	channel := &models.EmailChannel{}
+	err := repositories.GetEmailChannelByID(id, channel)
+	if err != nil {
+		return nil, errors.New("email channel not found")
+	}
+	return channel, nil
+}
+
+func UpdateEmailChannel(id uint, input EmailChannelInput) (*models.EmailChannel, error) {
+	channel, err := GetEmailChannel(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateEmailChannelInput(input, channel); err != nil {
+		return nil, err
+	}
+
+	err = repositories.UpdateEmailChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func DeleteEmailChannelByID(id uint) error {
+	channel, err := GetEmailChannel(id)
+	if err != nil {
+		return err
+	}
+	return repositories.DeleteEmailChannel(channel)
+}
+
+// TestEmailChannel sends a test message through the channel and returns the delivery
+func TestEmailChannel(id uint) (*models.NotificationDelivery, error) {
+	channel, err := GetEmailChannel(id)
+	if err != nil {
+		return nil, err
+	}
+	return notify.TestEmailChannel(*channel), nil
+}
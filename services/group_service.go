@@ -0,0 +1,299 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"uptime/internal/retention"
+	"uptime/internal/scheduler"
+	"uptime/internal/sla"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// MaxRetentionDays is the longest retention a group can set
+const MaxRetentionDays = 36500
+
+// GroupInput holds the user supplied fields of a node group
+type GroupInput struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	ParentID    *uint                 `json:"parent_id"` // null for a top level group
+	Retention   models.GroupRetention `json:"retention"` // days per tier, null keeps the global setting, 0 keeps forever
+}
+
+func validateGroupInput(input GroupInput, group *models.Group) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return invalid("name cannot be empty")
+	}
+
+	for _, tier := range retention.Tiers {
+		if days := retention.Override(input.Retention, tier); days != nil && *days > MaxRetentionDays {
+			return invalid("%s retention cannot exceed %d days", tier, MaxRetentionDays)
+		}
+	}
+
+	if input.ParentID != nil {
+		if err := validateParent(group.ID, *input.ParentID); err != nil {
+			return err
+		}
+	}
+
+	group.Name = name
+	group.Description = strings.TrimSpace(input.Description)
+	group.ParentID = input.ParentID
+	group.Retention = input.Retention
+	return nil
+}
+
+// validateParent checks that parentID exists and is not groupID or one of
+// its subgroups, which would make a cycle. groupID is 0 for a new group.
+func validateParent(groupID, parentID uint) error {
+	if _, err := GetGroup(parentID); err != nil {
+		return invalid("parent group %d does not exist", parentID)
+	}
+	if groupID == 0 {
+		return nil
+	}
+	subgroups, err := GroupSubtree(groupID)
+	if err != nil {
+		return err
+	}
+	for _, id := range subgroups {
+		if id == parentID {
+			return invalid("group %d cannot be nested under itself or one of its subgroups", groupID)
+		}
+	}
+	return nil
+}
+
+func CreateGroup(input GroupInput) (*models.Group, error) {
+	group := &models.Group{}
+	if err := validateGroupInput(input, group); err != nil {
+		return nil, err
+	}
+
+	err := repositories.CreateGroup(group)
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func GetAllGroups() ([]models.Group, error) {
+	var groups []models.Group
+	err := repositories.GetAllGroups(&groups)
+	return groups, err
+}
+
+func GetGroup(id uint) (*models.Group, error) {
+	if id == 0 {
+		return nil, errors.New("invalid ID")
+	}
+
+	group := &models.Group{}
+	err := repositories.GetGroupByID(id, group)
+	if err != nil {
+		return nil, errors.New("group not found")
+	}
+	return group, nil
+}
+
+func UpdateGroup(id uint, input GroupInput) (*models.Group, error) {
+	group, err := GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateGroupInput(input, group); err != nil {
+		return nil, err
+	}
+
+	err = repositories.UpdateGroup(group)
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// DeleteGroupByID detaches the nodes of the group, archived ones included,
+// moves its subgroups up to its parent and deletes it
+func DeleteGroupByID(id uint) error {
+	group, err := GetGroup(id)
+	if err != nil {
+		return err
+	}
+
+	var nodes []models.Node
+	if err := store.Nodes.Unscoped().ByGroupID(id, &nodes); err != nil {
+		return err
+	}
+	if len(nodes) > 0 {
+		ids := make([]uint, len(nodes))
+		for i, n := range nodes {
+			ids[i] = n.ID
+		}
+		if err := store.Nodes.Unscoped().SetGroup(ids, nil); err != nil {
+			return err
+		}
+		scheduler.Invalidate()
+	}
+	return repositories.DeleteGroup(group)
+}
+
+// GroupSubtree returns the ID of the group followed by the IDs of all its
+// subgroups, at any depth
+func GroupSubtree(id uint) ([]uint, error) {
+	var groups []models.Group
+	if err := repositories.GetAllGroups(&groups); err != nil {
+		return nil, err
+	}
+	return models.NewGroupTree(groups).Subtree(id), nil
+}
+
+// GroupSummaryQuery selects the groups and the uptime range of a group
+// summary. GroupID limits it to a group and its subgroups.
+type GroupSummaryQuery struct {
+	GroupID uint
+	From    time.Time
+	To      time.Time
+}
+
+// GroupSummary counts the nodes of a group and its subgroups by their last
+// check and gives their aggregate uptime over the summary range
+type GroupSummary struct {
+	GroupID       uint     `json:"group_id"`
+	Name          string   `json:"name"`
+	ParentID      *uint    `json:"parent_id,omitempty"`
+	Nodes         int      `json:"nodes"`
+	Up            int      `json:"up"`
+	Down          int      `json:"down"`
+	Suspended     int      `json:"suspended"`
+	Unknown       int      `json:"unknown"`        // not checked yet
+	UptimePercent *float64 `json:"uptime_percent"` // nil when nothing was monitored
+}
+
+// GroupSummaryReport summarizes the groups over [From, To)
+type GroupSummaryReport struct {
+	From   time.Time      `json:"from"`
+	To     time.Time      `json:"to"`
+	Groups []GroupSummary `json:"groups"`
+}
+
+// GetGroupSummaries counts the up, down, suspended and unchecked nodes of
+// every group, subgroups included, and computes their aggregate uptime the
+// way the SLA report does
+func GetGroupSummaries(query GroupSummaryQuery) (*GroupSummaryReport, error) {
+	if !query.To.After(query.From) {
+		return nil, invalid("end of range is before its start")
+	}
+	if query.To.Sub(query.From) > MaxSLARange {
+		return nil, invalid("range cannot exceed 366 days")
+	}
+	from, to := query.From, query.To
+	if now := time.Now(); to.After(now) {
+		to = now
+	}
+	if !to.After(from) {
+		return nil, invalid("range starts in the future")
+	}
+
+	var groups []models.Group
+	if err := repositories.GetAllGroups(&groups); err != nil {
+		return nil, err
+	}
+	tree := models.NewGroupTree(groups)
+	if query.GroupID != 0 {
+		if _, err := GetGroup(query.GroupID); err != nil {
+			return nil, err
+		}
+		wanted := make(map[uint]bool)
+		for _, id := range tree.Subtree(query.GroupID) {
+			wanted[id] = true
+		}
+		var kept []models.Group
+		for _, g := range groups {
+			if wanted[g.ID] {
+				kept = append(kept, g)
+			}
+		}
+		groups = kept
+	}
+
+	var nodes, grouped []models.Node
+	if err := store.Nodes.All(&nodes); err != nil {
+		return nil, err
+	}
+	inSummary := make(map[uint]bool, len(groups))
+	for _, g := range groups {
+		inSummary[g.ID] = true
+	}
+	byGroup := make(map[uint][]int)
+	for _, n := range nodes {
+		if n.GroupID != nil && inSummary[*n.GroupID] {
+			byGroup[*n.GroupID] = append(byGroup[*n.GroupID], len(grouped))
+			grouped = append(grouped, n)
+		}
+	}
+
+	var histories []models.History
+	if err := store.Histories.All(&histories); err != nil {
+		return nil, err
+	}
+	states := make(map[uint]string, len(histories))
+	for _, h := range histories {
+		states[h.NodeID] = models.NodeState(h.Up, h.Suspended)
+	}
+
+	policies, err := retention.Load()
+	if err != nil {
+		return nil, err
+	}
+	inputs, _, err := slaInputs(grouped, policies, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GroupSummaryReport{From: from, To: to, Groups: make([]GroupSummary, 0, len(groups))}
+	for _, g := range groups {
+		summary := GroupSummary{GroupID: g.ID, Name: g.Name, ParentID: g.ParentID}
+		var groupInputs []sla.Input
+		for _, id := range tree.Subtree(g.ID) {
+			for _, i := range byGroup[id] {
+				summary.Nodes++
+				switch state, checked := states[grouped[i].ID]; {
+				case !checked:
+					summary.Unknown++
+				case state == models.StateUp:
+					summary.Up++
+				case state == models.StateSuspended:
+					summary.Suspended++
+				default:
+					summary.Down++
+				}
+				groupInputs = append(groupInputs, inputs[i])
+			}
+		}
+		if len(groupInputs) > 0 {
+			summary.UptimePercent = sla.Compute(from, to, groupInputs...).UptimePercent
+		}
+		report.Groups = append(report.Groups, summary)
+	}
+	return report, nil
+}
+
+// validateScope checks that every node and group a channel is scoped to exists
+func validateScope(nodeIDs, groupIDs []uint) (models.IDList, models.IDList, error) {
+	for _, id := range nodeIDs {
+		if _, err := GetNode(id); err != nil {
+			return nil, nil, invalid("node %d does not exist", id)
+		}
+	}
+	for _, id := range groupIDs {
+		if _, err := GetGroup(id); err != nil {
+			return nil, nil, invalid("group %d does not exist", id)
+		}
+	}
+	return models.IDList(nodeIDs), models.IDList(groupIDs), nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"uptime/models"
+)
+
+func createTestGroup(t *testing.T, name string, parentID *uint) *models.Group {
+	t.Helper()
+	g, err := CreateGroup(GroupInput{Name: name, ParentID: parentID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func createTestNode(t *testing.T, url string, groupID *uint) *models.Node {
+	t.Helper()
+	n, err := CreateNode(NodeInput{URL: url, GroupID: groupID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestGroupParentValidation(t *testing.T) {
+	openDB(t)
+	top := createTestGroup(t, "cycle top", nil)
+	middle := createTestGroup(t, "cycle middle", &top.ID)
+	bottom := createTestGroup(t, "cycle bottom", &middle.ID)
+	missing := uint(99999)
+	tooLong := uint(MaxRetentionDays + 1)
+
+	tests := []struct {
+		name  string
+		id    uint
+		input GroupInput
+		err   string
+	}{
+		{name: "under itself", id: top.ID, input: GroupInput{Name: "cycle top", ParentID: &top.ID}, err: "cannot be nested under itself"},
+		{name: "under a child", id: top.ID, input: GroupInput{Name: "cycle top", ParentID: &middle.ID}, err: "cannot be nested under itself"},
+		{name: "under a grandchild", id: top.ID, input: GroupInput{Name: "cycle top", ParentID: &bottom.ID}, err: "cannot be nested under itself"},
+		{name: "missing parent", id: bottom.ID, input: GroupInput{Name: "cycle bottom", ParentID: &missing}, err: "parent group 99999 does not exist"},
+		{name: "empty name", id: bottom.ID, input: GroupInput{Name: "  "}, err: "name cannot be empty"},
+		{name: "retention too long", id: bottom.ID, input: GroupInput{Name: "cycle bottom", Retention: models.GroupRetention{Incidents: &tooLong}}, err: "cannot exceed"},
+		{name: "to the top", id: bottom.ID, input: GroupInput{Name: "cycle bottom"}},
+		{name: "under a sibling branch", id: middle.ID, input: GroupInput{Name: "cycle middle", ParentID: &bottom.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := UpdateGroup(tt.id, tt.input)
+			if tt.err != "" {
+				if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (g.ParentID == nil) != (tt.input.ParentID == nil) || (g.ParentID != nil && *g.ParentID != *tt.input.ParentID) {
+				t.Errorf("parent = %v, want %v", g.ParentID, tt.input.ParentID)
+			}
+		})
+	}
+
+	if _, err := CreateGroup(GroupInput{Name: "orphan", ParentID: &missing}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("CreateGroup() under a missing parent = %v", err)
+	}
+}
+
+func TestDeleteGroup(t *testing.T) {
+	openDB(t)
+	parent := createTestGroup(t, "delete parent", nil)
+	group := createTestGroup(t, "delete group", &parent.ID)
+	sub := createTestGroup(t, "delete sub", &group.ID)
+	active := createTestNode(t, "https://delete-active.test", &group.ID)
+	archived := createTestNode(t, "https://delete-archived.test", &group.ID)
+	if err := store.Nodes.Delete(archived); err != nil {
+		t.Fatal(err)
+	}
+	kept := createTestNode(t, "https://delete-kept.test", &sub.ID)
+
+	if err := DeleteGroupByID(group.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetGroup(group.ID); err == nil {
+		t.Error("group still exists")
+	}
+	// its nodes, archived ones included, are taken out of it
+	for _, id := range []uint{active.ID, archived.ID} {
+		n, err := GetNodeIncludingArchived(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.GroupID != nil {
+			t.Errorf("node %d still in group %d", id, *n.GroupID)
+		}
+	}
+	// its subgroups move up to its parent with their nodes
+	g, err := GetGroup(sub.ID)
+	if err != nil || g.ParentID == nil || *g.ParentID != parent.ID {
+		t.Errorf("subgroup = %+v, %v, want it under group %d", g, err, parent.ID)
+	}
+	if n, err := GetNode(kept.ID); err != nil || n.GroupID == nil || *n.GroupID != sub.ID {
+		t.Errorf("subgroup node = %+v, %v", n, err)
+	}
+}
+
+func TestGetGroupSummaries(t *testing.T) {
+	openDB(t)
+	top := createTestGroup(t, "summary top", nil)
+	child := createTestGroup(t, "summary child", &top.ID)
+	createTestGroup(t, "summary other", nil)
+
+	up := createTestNode(t, "https://summary-up.test", &top.ID)
+	down := createTestNode(t, "https://summary-down.test", &child.ID)
+	suspended := createTestNode(t, "https://summary-suspended.test", &child.ID)
+	createTestNode(t, "https://summary-unchecked.test", &child.ID)
+	createTestNode(t, "https://summary-ungrouped.test", nil)
+	for _, h := range []models.History{
+		{NodeID: up.ID, Up: true},
+		{NodeID: down.ID},
+		{NodeID: suspended.ID, Up: true, Suspended: true},
+	} {
+		if err := store.Histories.Create(&h); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	report, err := GetGroupSummaries(GroupSummaryQuery{GroupID: top.ID, From: now.Add(-time.Hour), To: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.To.Before(now.Add(time.Minute)) {
+		t.Errorf("range ends at %s, want now", report.To)
+	}
+	// the summary of a group counts the nodes of its subgroups too
+	want := map[uint]GroupSummary{
+		top.ID:   {Nodes: 4, Up: 1, Down: 1, Suspended: 1, Unknown: 1},
+		child.ID: {Nodes: 3, Down: 1, Suspended: 1, Unknown: 1},
+	}
+	if len(report.Groups) != len(want) {
+		t.Fatalf("%d groups, want the group and its subgroup", len(report.Groups))
+	}
+	for _, g := range report.Groups {
+		w := want[g.GroupID]
+		if g.Nodes != w.Nodes || g.Up != w.Up || g.Down != w.Down || g.Suspended != w.Suspended || g.Unknown != w.Unknown {
+			t.Errorf("group %s = %+v, want %+v", g.Name, g, w)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query GroupSummaryQuery
+	}{
+		{name: "reversed range", query: GroupSummaryQuery{From: now, To: now.Add(-time.Hour)}},
+		{name: "range too long", query: GroupSummaryQuery{From: now.AddDate(-2, 0, 0), To: now}},
+		{name: "future range", query: GroupSummaryQuery{From: now.Add(time.Hour), To: now.Add(2 * time.Hour)}},
+	}
+	for _, tt := range tests {
+		if _, err := GetGroupSummaries(tt.query); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: err = %v, want invalid input", tt.name, err)
+		}
+	}
+}
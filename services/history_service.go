@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"uptime/models"
 	"uptime/repositories"
@@ -15,7 +16,7 @@ func CreateHistory(history *models.History) (*models.History, error) {
 		return nil, errors.New("NodeID is required")
 	}
 	
-	err := repositories.CreateHistory(history)
+	err := store.Histories.Create(history)
 	if err != nil {
 		return nil, err
 	}
@@ -24,7 +25,7 @@ func CreateHistory(history *models.History) (*models.History, error) {
 
 func GetAllHistories() ([]models.History, error) {
 	var histories []models.History
-	err := repositories.GetAllHistories(&histories)
+	err := store.Histories.All(&histories)
 	return histories, err
 }
 
@@ -34,7 +35,7 @@ func GetHistory(id uint) (*models.History, error) {
 	}
 	
 	history := &models.History{}
-	err := repositories.GetHistoryByID(id, history)
+	err := store.Histories.ByID(id, history)
 	if err != nil {
 		return nil, errors.New("history not found")
 	}
@@ -50,7 +51,7 @@ func UpdateHistory(history *models.History) (*models.History, error) {
 		return nil, errors.New("invalid history ID")
 	}
 	
-	err := repositories.UpdateHistory(history)
+	err := store.Histories.Update(history)
 	if err != nil {
 		return nil, err
 	}
@@ -66,5 +67,18 @@ func DeleteHistoryByID(id uint) error {
 	if err != nil {
 		return err
 	}
-	return repositories.DeleteHistory(history)
+	return store.Histories.Delete(history)
+}
+
+// FindHistories returns the histories matching filter
+func FindHistories(ctx context.Context, filter repositories.LogFilter) ([]models.History, error) {
+	var histories []models.History
+	err := store.Histories.Find(ctx, filter, &histories)
+	return histories, err
+}
+
+// EachHistory passes the histories matching filter to fn one at a time,
+// without loading them all
+func EachHistory(ctx context.Context, filter repositories.LogFilter, fn func(history *models.History) error) error {
+	return store.Histories.Each(ctx, filter, fn)
 }
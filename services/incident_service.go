@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// IncidentQuery holds the listing filters accepted by the API
+type IncidentQuery struct {
+	NodeID       uint
+	Scope        NodeScope
+	Status       string
+	Acknowledged *bool
+	From         *time.Time
+	To           *time.Time
+	Page         repositories.Page
+}
+
+func GetIncidents(query IncidentQuery) ([]models.Incident, error) {
+	if query.Status != "" && query.Status != models.IncidentOpen && query.Status != models.IncidentResolved {
+		return nil, invalid("status must be %s or %s", models.IncidentOpen, models.IncidentResolved)
+	}
+	if query.From != nil && query.To != nil && query.To.Before(*query.From) {
+		return nil, invalid("end of range is before its start")
+	}
+
+	if query.Page.AfterID != 0 {
+		if _, err := GetIncident(query.Page.AfterID); err != nil {
+			return nil, invalid("incident %d does not exist", query.Page.AfterID)
+		}
+	}
+
+	nodeIDs, err := ScopedNodeIDs(query.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	var incidents []models.Incident
+	err = store.Incidents.Find(repositories.IncidentFilter{
+		NodeID:       query.NodeID,
+		NodeIDs:      nodeIDs,
+		Status:       query.Status,
+		Acknowledged: query.Acknowledged,
+		From:         query.From,
+		To:           query.To,
+		Page:         query.Page,
+	}, &incidents)
+	return incidents, err
+}
+
+func GetIncident(id uint) (*models.Incident, error) {
+	if id == 0 {
+		return nil, errors.New("invalid ID")
+	}
+
+	incident := &models.Incident{}
+	err := store.Incidents.ByID(id, incident)
+	if err != nil {
+		return nil, errors.New("incident not found")
+	}
+	return incident, nil
+}
+
+// AcknowledgeIncident marks the incident as being handled, optionally adding a note
+func AcknowledgeIncident(id uint, by, note string) (*models.Incident, error) {
+	incident, err := GetIncident(id)
+	if err != nil {
+		return nil, err
+	}
+
+	by = strings.TrimSpace(by)
+	if by == "" {
+		return nil, invalid("acknowledged_by cannot be empty")
+	}
+
+	if !incident.Acknowledged {
+		now := time.Now()
+		incident.Acknowledged = true
+		incident.AcknowledgedAt = &now
+		incident.AcknowledgedBy = by
+		if err := store.Incidents.Update(incident); err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(note) != "" {
+		if _, err := AddIncidentNote(id, by, note); err != nil {
+			return nil, err
+		}
+	}
+	return GetIncident(id)
+}
+
+func AddIncidentNote(id uint, author, body string) (*models.IncidentNote, error) {
+	if _, err := GetIncident(id); err != nil {
+		return nil, err
+	}
+
+	author = strings.TrimSpace(author)
+	if author == "" {
+		return nil, invalid("author cannot be empty")
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, invalid("note cannot be empty")
+	}
+
+	note := &models.IncidentNote{IncidentID: id, Author: author, Body: body}
+	if err := store.Incidents.CreateNote(note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
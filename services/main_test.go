@@ -0,0 +1,35 @@
+package services
+
+import (
+	"os"
+	"testing"
+
+	"uptime/config"
+	"uptime/database"
+	"uptime/repositories"
+)
+
+func TestMain(m *testing.M) {
+	config.AppConfig = &config.Config{}
+	config.AppConfig.Database.Driver = database.SQLite
+	config.AppConfig.Database.DSN = ":memory:"
+	os.Exit(m.Run())
+}
+
+// openDB gives a test its own in-memory SQLite database, backing the store
+// and the groups, tags and settings the services read
+func openDB(t *testing.T) {
+	t.Helper()
+	if err := database.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	SetStore(repositories.NewSQLStore(database.DB))
+	t.Cleanup(func() {
+		if db, err := database.DB.DB(); err == nil {
+			db.Close()
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"uptime/internal/maintenance"
+	"uptime/internal/scheduler"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// MaxOccurrenceRange is the longest range the occurrences endpoint expands
+const MaxOccurrenceRange = 366 * 24 * time.Hour
+
+// MaintenanceWindowInput holds the user supplied fields of a maintenance window
+type MaintenanceWindowInput struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Kind        string     `json:"kind"`       // once, cron or weekly
+	StartsAt    *time.Time `json:"starts_at"`  // once
+	EndsAt      *time.Time `json:"ends_at"`    // once
+	Cron        string     `json:"cron"`       // cron, e.g. "0 2 * * *"
+	Weekdays    []string   `json:"weekdays"`   // weekly, e.g. ["fri", "sat"]
+	StartTime   string     `json:"start_time"` // weekly, HH:MM
+	Duration    uint       `json:"duration"`   // cron and weekly, minutes
+	Timezone    string     `json:"timezone"`   // IANA name, defaults to UTC
+	NodeIDs     []uint     `json:"node_ids"`
+	GroupIDs    []uint     `json:"group_ids"`
+	Enabled     *bool      `json:"enabled"`
+}
+
+// validateMaintenanceWindowInput normalizes input and copies it onto window
+func validateMaintenanceWindowInput(input MaintenanceWindowInput, window *models.MaintenanceWindow) error {
+	candidate := models.MaintenanceWindow{
+		Kind:     strings.ToLower(strings.TrimSpace(input.Kind)),
+		Timezone: strings.TrimSpace(input.Timezone),
+	}
+
+	switch candidate.Kind {
+	case models.MaintenanceOnce:
+		candidate.StartsAt = input.StartsAt
+		candidate.EndsAt = input.EndsAt
+	case models.MaintenanceCron:
+		candidate.Cron = strings.TrimSpace(input.Cron)
+		candidate.Duration = input.Duration
+	case models.MaintenanceWeekly:
+		var days []string
+		for _, day := range input.Weekdays {
+			days = append(days, strings.ToLower(strings.TrimSpace(day)))
+		}
+		if len(days) == 0 {
+			return invalid("weekdays is required for weekly windows")
+		}
+		candidate.Weekdays = strings.Join(days, ",")
+		candidate.StartTime = strings.TrimSpace(input.StartTime)
+		candidate.Duration = input.Duration
+	}
+
+	if err := maintenance.Validate(candidate); err != nil {
+		return invalid("%v", err)
+	}
+
+	nodeIDs, groupIDs, err := validateScope(input.NodeIDs, input.GroupIDs)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return invalid("name cannot be empty")
+	}
+
+	window.Name = name
+	window.Description = strings.TrimSpace(input.Description)
+	window.Kind = candidate.Kind
+	window.StartsAt = candidate.StartsAt
+	window.EndsAt = candidate.EndsAt
+	window.Cron = candidate.Cron
+	window.Weekdays = candidate.Weekdays
+	window.StartTime = candidate.StartTime
+	window.Duration = candidate.Duration
+	window.Timezone = candidate.Timezone
+	window.NodeIDs = nodeIDs
+	window.GroupIDs = groupIDs
+	window.Enabled = input.Enabled == nil || *input.Enabled
+	return nil
+}
+
+func CreateMaintenanceWindow(input MaintenanceWindowInput) (*models.MaintenanceWindow, error) {
+	window := &models.MaintenanceWindow{}
+	if err := validateMaintenanceWindowInput(input, window); err != nil {
+		return nil, err
+	}
+
+	err := repositories.CreateMaintenanceWindow(window)
+	if err != nil {
+		return nil, err
+	}
+	scheduler.Invalidate()
+	return window, nil
+}
+
+func GetAllMaintenanceWindows() ([]models.MaintenanceWindow, error) {
+	var windows []models.MaintenanceWindow
+	err := repositories.GetAllMaintenanceWindows(&windows)
+	return windows, err
+}
+
+func GetMaintenanceWindow(id uint) (*models.MaintenanceWindow, error) {
+	if id == 0 {
+		return nil, errors.New("invalid ID")
+	}
+
+	window := &models.MaintenanceWindow{}
+	err := repositories.GetMaintenanceWindowByID(id, window)
+	if err != nil {
+		return nil, errors.New("maintenance window not found")
+	}
+	return window, nil
+}
+
+func UpdateMaintenanceWindow(id uint, input MaintenanceWindowInput) (*models.MaintenanceWindow, error) {
+	window, err := GetMaintenanceWindow(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateMaintenanceWindowInput(input, window); err != nil {
+		return nil, err
+	}
+
+	err = repositories.UpdateMaintenanceWindow(window)
+	if err != nil {
+		return nil, err
+	}
+	scheduler.Invalidate()
+	return window, nil
+}
+
+func DeleteMaintenanceWindowByID(id uint) error {
+	window, err := GetMaintenanceWindow(id)
+	if err != nil {
+		return err
+	}
+	if err := repositories.DeleteMaintenanceWindow(window); err != nil {
+		return err
+	}
+	scheduler.Invalidate()
+	return nil
+}
+
+// GetMaintenanceOccurrences lists when the window is active within [from, to)
+func GetMaintenanceOccurrences(id uint, from, to time.Time) ([]maintenance.Interval, error) {
+	window, err := GetMaintenanceWindow(id)
+	if err != nil {
+		return nil, err
+	}
+	if !to.After(from) {
+		return nil, invalid("end of range is before its start")
+	}
+	if to.Sub(from) > MaxOccurrenceRange {
+		return nil, invalid("range cannot exceed 366 days")
+	}
+	return maintenance.Intervals(*window, from, to), nil
+}
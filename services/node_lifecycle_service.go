@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"uptime/internal/scheduler"
+	"uptime/models"
+)
+
+// ErrNodeArchived is returned when a node is created or updated with the URL
+// of an archived node, which should be restored instead
+var ErrNodeArchived = errors.New("node is archived")
+
+// PauseInput says how long a node is paused. Until and Duration are mutually
+// exclusive; without either the node stays paused until it is resumed.
+type PauseInput struct {
+	Until    *time.Time `json:"until"`
+	Duration string     `json:"duration"` // e.g. 30m or 2h
+}
+
+// pausedUntil returns when a node paused with input resumes, nil for never
+func pausedUntil(input PauseInput, now time.Time) (*time.Time, error) {
+	duration := strings.TrimSpace(input.Duration)
+	if input.Until != nil && duration != "" {
+		return nil, invalid("until and duration cannot be used together")
+	}
+
+	if duration != "" {
+		d, err := time.ParseDuration(duration)
+		if err != nil {
+			return nil, invalid("invalid duration %q", input.Duration)
+		}
+		if d <= 0 {
+			return nil, invalid("duration must be positive")
+		}
+		until := now.Add(d)
+		return &until, nil
+	}
+
+	if input.Until != nil {
+		if !input.Until.After(now) {
+			return nil, invalid("until must be in the future")
+		}
+		until := *input.Until
+		return &until, nil
+	}
+	return nil, nil
+}
+
+// checkArchivedURL fails when an archived node other than node monitors its URL
+func checkArchivedURL(node *models.Node) error {
+	archived := &models.Node{}
+	if err := store.Nodes.Unscoped().ByURL(node.URL, archived); err != nil {
+		return nil
+	}
+	if archived.ID == node.ID || !archived.DeletedAt.Valid {
+		return nil
+	}
+	return fmt.Errorf("%w: node %d monitors %s, restore it instead", ErrNodeArchived, archived.ID, node.URL)
+}
+
+// PauseNode stops checking a node, until input.Until or for input.Duration
+// when given. An open incident is resolved.
+func PauseNode(id uint, input PauseInput) (*models.Node, error) {
+	until, err := pausedUntil(input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := GetNode(id)
+	if err != nil {
+		return nil, err
+	}
+
+	node.Lifecycle = models.NodePaused
+	node.PausedUntil = until
+	if err := store.Nodes.Update(node); err != nil {
+		return nil, err
+	}
+	scheduler.Invalidate()
+	// an outage no longer watched would count as downtime until the node
+	// is checked again
+	if err := scheduler.CloseIncident(store, node.ID, "Closed because the node was paused"); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// ResumeNode checks a paused node again
+func ResumeNode(id uint) (*models.Node, error) {
+	node, err := GetNode(id)
+	if err != nil {
+		return nil, err
+	}
+	if node.Lifecycle != models.NodePaused {
+		return nil, invalid("node %d is not paused", id)
+	}
+
+	node.Lifecycle = models.NodeActive
+	node.PausedUntil = nil
+	if err := store.Nodes.Update(node); err != nil {
+		return nil, err
+	}
+	scheduler.Invalidate()
+	return node, nil
+}
+
+// ArchiveNode stops checking a node and hides it from the listings. Its logs,
+// history, incidents and tags are kept, so its reports still work and it can
+// be restored. An open incident is resolved.
+func ArchiveNode(id uint) error {
+	node, err := GetNode(id)
+	if err != nil {
+		return err
+	}
+
+	// soft deleted rows cannot be updated through the scoped repository,
+	// so the state is written first
+	node.Lifecycle = models.NodeArchived
+	node.PausedUntil = nil
+	if err := store.Nodes.Update(node); err != nil {
+		return err
+	}
+	if err := store.Nodes.Delete(node); err != nil {
+		return err
+	}
+	scheduler.Invalidate()
+	return scheduler.CloseIncident(store, node.ID, "Closed because the node was archived")
+}
+
+// RestoreNode brings an archived node back as active, with its history
+func RestoreNode(id uint) (*models.Node, error) {
+	node, err := GetNodeIncludingArchived(id)
+	if err != nil {
+		return nil, err
+	}
+	if !node.DeletedAt.Valid {
+		return nil, invalid("node %d is not archived", id)
+	}
+
+	existing := &models.Node{}
+	if err := store.Nodes.ByURL(node.URL, existing); err == nil {
+		return nil, invalid("node %d already monitors %s", existing.ID, node.URL)
+	}
+
+	if err := store.Nodes.Restore(node); err != nil {
+		return nil, err
+	}
+	node.Lifecycle = models.NodeActive
+	if err := store.Nodes.Update(node); err != nil {
+		return nil, err
+	}
+	scheduler.Invalidate()
+	return node, nil
+}
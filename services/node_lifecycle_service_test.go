@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"uptime/models"
+	"uptime/repositories"
+)
+
+func TestPausedUntil(t *testing.T) {
+	now := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	later, earlier := now.Add(2*time.Hour), now.Add(-time.Minute)
+	tests := []struct {
+		name  string
+		input PauseInput
+		want  *time.Time
+		err   string
+	}{
+		{name: "until resumed", input: PauseInput{}},
+		{name: "duration", input: PauseInput{Duration: " 30m "}, want: timePtr(now.Add(30 * time.Minute))},
+		{name: "until", input: PauseInput{Until: &later}, want: &later},
+		{name: "both", input: PauseInput{Until: &later, Duration: "1h"}, err: "cannot be used together"},
+		{name: "bad duration", input: PauseInput{Duration: "soon"}, err: `invalid duration "soon"`},
+		{name: "negative duration", input: PauseInput{Duration: "-1h"}, err: "duration must be positive"},
+		{name: "until in the past", input: PauseInput{Until: &earlier}, err: "until must be in the future"},
+		{name: "until now", input: PauseInput{Until: &now}, err: "until must be in the future"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pausedUntil(tt.input, now)
+			if tt.err != "" {
+				if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("pausedUntil() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// listed reports whether FindNodes lists the node under a lifecycle filter
+func listed(t *testing.T, id uint, lifecycle string) bool {
+	t.Helper()
+	nodes, err := FindNodes(NodeScope{}, lifecycle, repositories.Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range nodes {
+		if n.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNodeLifecycle(t *testing.T) {
+	openDB(t)
+	node := createTestNode(t, "https://lifecycle.test", nil)
+	incident := models.Incident{NodeID: node.ID, Status: models.IncidentOpen, State: models.StateDown, StartedAt: time.Now().Add(-time.Hour)}
+	if err := store.Incidents.Create(&incident); err != nil {
+		t.Fatal(err)
+	}
+
+	// pausing resolves the open incident
+	paused, err := PauseNode(node.ID, PauseInput{Duration: "1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paused.Lifecycle != models.NodePaused || paused.PausedUntil == nil || time.Until(*paused.PausedUntil) < 59*time.Minute {
+		t.Errorf("paused node = %+v, want paused for an hour", paused)
+	}
+	var resolved models.Incident
+	if err := store.Incidents.ByID(incident.ID, &resolved); err != nil || resolved.Status != models.IncidentResolved {
+		t.Errorf("incident = %+v, %v, want resolved", resolved, err)
+	}
+	if !listed(t, node.ID, models.NodePaused) || listed(t, node.ID, models.NodeActive) || !listed(t, node.ID, "") {
+		t.Error("paused node not listed as paused only")
+	}
+	active, err := GetActiveNodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range active {
+		if n.ID == node.ID {
+			t.Error("paused node is still checked")
+		}
+	}
+
+	resumed, err := ResumeNode(node.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Lifecycle != models.NodeActive || resumed.PausedUntil != nil {
+		t.Errorf("resumed node = %+v", resumed)
+	}
+	if _, err := ResumeNode(node.ID); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("ResumeNode() of an active node = %v", err)
+	}
+
+	// a timed pause ends on its own
+	if _, err := PauseNode(node.ID, PauseInput{Duration: "1h"}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := store.Nodes.ResumeDue(time.Now()); err != nil || n != 0 {
+		t.Errorf("ResumeDue() before the end of the pause = %d, %v", n, err)
+	}
+	if n, err := store.Nodes.ResumeDue(time.Now().Add(2 * time.Hour)); err != nil || n != 1 {
+		t.Errorf("ResumeDue() after the pause = %d, %v", n, err)
+	}
+	if n, err := GetNode(node.ID); err != nil || n.Lifecycle != models.NodeActive || n.PausedUntil != nil {
+		t.Errorf("node after the pause = %+v, %v", n, err)
+	}
+
+	// archived nodes are hidden and keep their URL
+	if err := ArchiveNode(node.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetNode(node.ID); err == nil {
+		t.Error("GetNode() returns an archived node")
+	}
+	archived, err := GetNodeIncludingArchived(node.ID)
+	if err != nil || archived.Lifecycle != models.NodeArchived || !archived.DeletedAt.Valid {
+		t.Errorf("archived node = %+v, %v", archived, err)
+	}
+	if !listed(t, node.ID, models.NodeArchived) || listed(t, node.ID, "") {
+		t.Error("archived node not listed as archived only")
+	}
+	if _, err := CreateNode(NodeInput{URL: "https://lifecycle.test"}); !errors.Is(err, ErrNodeArchived) {
+		t.Errorf("CreateNode() with an archived URL = %v", err)
+	}
+	other := createTestNode(t, "https://lifecycle-other.test", nil)
+	if _, err := UpdateNode(other.ID, NodeInput{URL: "https://lifecycle.test"}); !errors.Is(err, ErrNodeArchived) {
+		t.Errorf("UpdateNode() to an archived URL = %v", err)
+	}
+	if _, err := PauseNode(node.ID, PauseInput{}); err == nil {
+		t.Error("PauseNode() of an archived node succeeded")
+	}
+
+	restored, err := RestoreNode(node.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Lifecycle != models.NodeActive || restored.DeletedAt.Valid {
+		t.Errorf("restored node = %+v", restored)
+	}
+	if n, err := GetNode(node.ID); err != nil || n.Lifecycle != models.NodeActive {
+		t.Errorf("GetNode() after restore = %+v, %v", n, err)
+	}
+	if _, err := RestoreNode(node.ID); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("RestoreNode() of an active node = %v", err)
+	}
+
+	if _, err := FindNodes(NodeScope{}, "deleted", repositories.Page{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("FindNodes() with an unknown lifecycle = %v", err)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
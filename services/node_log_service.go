@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"uptime/models"
 	"uptime/repositories"
@@ -15,7 +16,7 @@ func CreateNodeLog(log *models.NodeLog) (*models.NodeLog, error) {
 		return nil, errors.New("NodeID is required")
 	}
 	
-	err := repositories.CreateNodeLog(log)
+	err := store.NodeLogs.Create(log)
 	if err != nil {
 		return nil, err
 	}
@@ -24,17 +25,37 @@ func CreateNodeLog(log *models.NodeLog) (*models.NodeLog, error) {
 
 func GetAllNodeLogs() ([]models.NodeLog, error) {
 	var logs []models.NodeLog
-	err := repositories.GetAllNodeLogs(&logs)
+	err := store.NodeLogs.All(&logs)
 	return logs, err
 }
 
+// GetNodeLogsByCycle returns the logs written by one check cycle
+func GetNodeLogsByCycle(cycleID string) ([]models.NodeLog, error) {
+	var logs []models.NodeLog
+	err := store.NodeLogs.ByCycleID(cycleID, &logs)
+	return logs, err
+}
+
+// FindNodeLogs returns the node logs matching filter
+func FindNodeLogs(ctx context.Context, filter repositories.LogFilter) ([]models.NodeLog, error) {
+	var logs []models.NodeLog
+	err := store.NodeLogs.Find(ctx, filter, &logs)
+	return logs, err
+}
+
+// EachNodeLog passes the node logs matching filter to fn one at a time,
+// without loading them all
+func EachNodeLog(ctx context.Context, filter repositories.LogFilter, fn func(log *models.NodeLog) error) error {
+	return store.NodeLogs.Each(ctx, filter, fn)
+}
+
 func GetNodeLog(id uint) (*models.NodeLog, error) {
 	if id == 0 {
 		return nil, errors.New("invalid ID")
 	}
 	
 	log := &models.NodeLog{}
-	err := repositories.GetNodeLogByID(id, log)
+	err := store.NodeLogs.ByID(id, log)
 	if err != nil {
 		return nil, errors.New("node log not found")
 	}
@@ -50,7 +71,7 @@ func UpdateNodeLog(log *models.NodeLog) (*models.NodeLog, error) {
 		return nil, errors.New("invalid node log ID")
 	}
 	
-	err := repositories.UpdateNodeLog(log)
+	err := store.NodeLogs.Update(log)
 	if err != nil {
 		return nil, err
 	}
@@ -66,5 +87,16 @@ func DeleteNodeLogByID(id uint) error {
 	if err != nil {
 		return err
 	}
-	return repositories.DeleteNodeLog(log)
+	return store.NodeLogs.Delete(log)
+}
+
+// GetNodeLogAttempts returns the individual attempts of a retried check
+func GetNodeLogAttempts(id uint) ([]models.CheckAttempt, error) {
+	if _, err := GetNodeLog(id); err != nil {
+		return nil, err
+	}
+
+	var attempts []models.CheckAttempt
+	err := store.NodeLogs.Attempts(id, &attempts)
+	return attempts, err
 }
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"sort"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// NodeScope narrows a listing or report to the nodes of a group, including
+// its subgroups, and to the nodes carrying a tag. Zero values are ignored.
+type NodeScope struct {
+	GroupID uint
+	Tag     string
+}
+
+// IsZero reports whether the scope selects every node
+func (s NodeScope) IsZero() bool {
+	return s.GroupID == 0 && s.Tag == ""
+}
+
+// ScopedNodeIDs returns the IDs of the nodes in scope, archived ones
+// included, in ascending order. It returns nil when the scope is zero and an empty, non-nil slice when no
+// node matches, which is what LogFilter and IncidentFilter expect.
+func ScopedNodeIDs(scope NodeScope) ([]uint, error) {
+	if scope.IsZero() {
+		return nil, nil
+	}
+
+	var inScope map[uint]bool
+	keep := func(ids []uint) {
+		next := make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			if inScope == nil || inScope[id] {
+				next[id] = true
+			}
+		}
+		inScope = next
+	}
+
+	if scope.GroupID != 0 {
+		if _, err := GetGroup(scope.GroupID); err != nil {
+			return nil, invalid("group %d does not exist", scope.GroupID)
+		}
+		groupIDs, err := GroupSubtree(scope.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		var ids []uint
+		for _, groupID := range groupIDs {
+			var nodes []models.Node
+			if err := store.Nodes.Unscoped().ByGroupID(groupID, &nodes); err != nil {
+				return nil, err
+			}
+			for _, n := range nodes {
+				ids = append(ids, n.ID)
+			}
+		}
+		keep(ids)
+	}
+
+	if scope.Tag != "" {
+		tag, err := GetTagByName(scope.Tag)
+		if err != nil {
+			return nil, invalid("tag %q does not exist", scope.Tag)
+		}
+		var ids []uint
+		if err := repositories.GetTaggedNodeIDs(tag.ID, &ids); err != nil {
+			return nil, err
+		}
+		keep(ids)
+	}
+
+	ids := make([]uint, 0, len(inScope))
+	for id := range inScope {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// NodeLifecycles are the lifecycle filters of FindNodes. The empty filter
+// lists the nodes that are not archived.
+var NodeLifecycles = map[string]bool{
+	"":                  true,
+	models.NodeActive:   true,
+	models.NodePaused:   true,
+	models.NodeArchived: true,
+}
+
+// FindNodes returns a page of the nodes in scope with their tags, narrowed
+// to a lifecycle state, in ID order
+func FindNodes(scope NodeScope, lifecycle string, page repositories.Page) ([]models.Node, error) {
+	if !NodeLifecycles[lifecycle] {
+		return nil, invalid("lifecycle must be active, paused or archived")
+	}
+
+	ids, err := ScopedNodeIDs(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := store.Nodes
+	if lifecycle == models.NodeArchived {
+		repo = repo.Unscoped()
+	}
+	var nodes []models.Node
+	if ids == nil {
+		err = repo.All(&nodes)
+	} else {
+		err = repo.ByIDs(ids, &nodes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if lifecycle != "" {
+		kept := nodes[:0]
+		for _, n := range nodes {
+			archived := n.DeletedAt.Valid
+			if archived == (lifecycle == models.NodeArchived) && (archived || n.Lifecycle == lifecycle) {
+				kept = append(kept, n)
+			}
+		}
+		nodes = kept
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	if page.AfterID != 0 {
+		nodes = nodes[sort.Search(len(nodes), func(i int) bool { return nodes[i].ID > page.AfterID }):]
+	}
+	if page.Limit > 0 && len(nodes) > page.Limit {
+		nodes = nodes[:page.Limit]
+	}
+
+	tags, err := repositories.GetNodeTags(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range nodes {
+		nodes[i].Tags = tags[nodes[i].ID]
+	}
+	return nodes, nil
+}
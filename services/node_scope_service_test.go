@@ -0,0 +1,60 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestScopedNodeIDs(t *testing.T) {
+	openDB(t)
+	top := createTestGroup(t, "scope top", nil)
+	child := createTestGroup(t, "scope child", &top.ID)
+	inTop := createTestNode(t, "https://scope-top.test", &top.ID)
+	inChild := createTestNode(t, "https://scope-child.test", &child.ID)
+	archived := createTestNode(t, "https://scope-archived.test", &child.ID)
+	outside := createTestNode(t, "https://scope-outside.test", nil)
+	if _, err := BulkAssign(BulkAssignInput{NodeIDs: []uint{inChild.ID, archived.ID, outside.ID}, AddTags: []string{"scoped"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Nodes.Delete(archived); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateTag(TagInput{Name: "unused"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		scope NodeScope
+		want  string
+	}{
+		{name: "zero", scope: NodeScope{}, want: "[]"},
+		{name: "group with subgroups", scope: NodeScope{GroupID: top.ID}, want: fmt.Sprint([]uint{inTop.ID, inChild.ID, archived.ID})},
+		{name: "subgroup", scope: NodeScope{GroupID: child.ID}, want: fmt.Sprint([]uint{inChild.ID, archived.ID})},
+		{name: "tag", scope: NodeScope{Tag: "Scoped"}, want: fmt.Sprint([]uint{inChild.ID, archived.ID, outside.ID})},
+		{name: "group and tag", scope: NodeScope{GroupID: top.ID, Tag: "scoped"}, want: fmt.Sprint([]uint{inChild.ID, archived.ID})},
+		{name: "no match", scope: NodeScope{Tag: "unused"}, want: "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := ScopedNodeIDs(tt.scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// a zero scope selects every node, an empty match none
+			if (ids == nil) != tt.scope.IsZero() {
+				t.Errorf("ScopedNodeIDs() = %#v, nil only for the zero scope", ids)
+			}
+			if got := fmt.Sprint(ids); got != tt.want {
+				t.Errorf("ScopedNodeIDs() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, scope := range []NodeScope{{GroupID: 99999}, {Tag: "nonexistent"}} {
+		if _, err := ScopedNodeIDs(scope); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("ScopedNodeIDs(%+v) err = %v, want invalid input", scope, err)
+		}
+	}
+}
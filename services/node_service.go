@@ -1,89 +1,496 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net"
+	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"uptime/internal/scheduler"
 	"uptime/models"
 	"uptime/repositories"
+	"uptime/utils"
 )
 
-func CreateNode(nodeURL string) (*models.Node, error) {
-	// Validate URL
-	if strings.TrimSpace(nodeURL) == "" {
-		return nil, errors.New("URL cannot be empty")
+// ErrInvalidInput is wrapped by every validation error returned from this package
+var ErrInvalidInput = errors.New("invalid input")
+
+// MaxNodeTimeout is the largest per-node timeout accepted, in seconds
+const MaxNodeTimeout = 300
+
+// MinNodeInterval and MaxNodeInterval bound the per-node check interval, in seconds
+const (
+	MinNodeInterval = 10
+	MaxNodeInterval = 86400
+)
+
+var allowedMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// NodeInput holds the user supplied fields of a node
+type NodeInput struct {
+	URL              string            `json:"url"`
+	Method           string            `json:"method"`
+	Headers          map[string]string `json:"headers"`
+	Body             *string           `json:"body"`
+	ExpectedStatus   string            `json:"expected_status"`
+	Timeout          uint              `json:"timeout"`
+	Interval         *uint             `json:"interval"`           // seconds, 0 uses CHECK_INTERVAL, omitted on update keeps the current interval
+	SkipDefaultRules *bool             `json:"skip_default_rules"` // omitted on update keeps the current setting
+	Type             string            `json:"type"`               // http (default), tcp or dns
+	Expect           string            `json:"expect"`             // tcp: banner substring, dns: comma separated record values
+	RecordType       string            `json:"record_type"`        // dns record type, defaults to A
+	Resolver         string            `json:"resolver"`           // dns resolver host[:port]
+	GroupID          *uint             `json:"group_id"`           // omitted on update keeps the current group, 0 removes it
+}
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidInput, fmt.Sprintf(format, args...))
+}
+
+var dnsRecordTypes = map[string]bool{
+	models.DNSRecordA:     true,
+	models.DNSRecordAAAA:  true,
+	models.DNSRecordCNAME: true,
+	models.DNSRecordMX:    true,
+	models.DNSRecordNS:    true,
+	models.DNSRecordTXT:   true,
+}
+
+// validateNodeInput normalizes input and copies it onto node
+func validateNodeInput(input NodeInput, node *models.Node) error {
+	if strings.TrimSpace(input.URL) == "" {
+		return invalid("URL cannot be empty")
+	}
+
+	if input.Timeout > MaxNodeTimeout {
+		return invalid("timeout cannot exceed %d seconds", MaxNodeTimeout)
+	}
+
+	if input.Interval != nil && *input.Interval != 0 && (*input.Interval < MinNodeInterval || *input.Interval > MaxNodeInterval) {
+		return invalid("interval must be between %d and %d seconds", MinNodeInterval, MaxNodeInterval)
+	}
+
+	nodeType := strings.ToLower(strings.TrimSpace(input.Type))
+	if nodeType == "" {
+		nodeType = models.NodeTypeHTTP
 	}
 
+	// Reset type specific fields, the matching validator fills them in
+	node.Method = ""
+	node.Headers = nil
+	node.Body = nil
+	node.ExpectedStatus = ""
+	node.Expect = ""
+	node.RecordType = ""
+	node.Resolver = ""
+
+	var err error
+	switch nodeType {
+	case models.NodeTypeHTTP:
+		err = validateHTTPNode(input, node)
+	case models.NodeTypeTCP:
+		err = validateTCPNode(input, node)
+	case models.NodeTypeDNS:
+		err = validateDNSNode(input, node)
+	default:
+		err = invalid("unsupported node type %q", input.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	if input.GroupID != nil && *input.GroupID != 0 {
+		if _, err := GetGroup(*input.GroupID); err != nil {
+			return invalid("group %d does not exist", *input.GroupID)
+		}
+	}
+
+	node.Type = nodeType
+	node.Timeout = input.Timeout
+	if input.GroupID != nil {
+		node.GroupID = input.GroupID
+		if *input.GroupID == 0 {
+			node.GroupID = nil
+		}
+	}
+	if input.Interval != nil {
+		node.Interval = *input.Interval
+	}
+	if input.SkipDefaultRules != nil {
+		node.SkipDefaultRules = *input.SkipDefaultRules
+	}
+	return nil
+}
+
+func validateHTTPNode(input NodeInput, node *models.Node) error {
 	// Parse and validate URL format
-	parsedURL, err := url.Parse(nodeURL)
+	parsedURL, err := url.Parse(input.URL)
 	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-		return nil, errors.New("invalid URL format, must be a valid HTTP/HTTPS URL")
+		return invalid("invalid URL format, must be a valid HTTP/HTTPS URL")
 	}
 
-	node := &models.Node{URL: nodeURL}
-	err = repositories.CreateNode(node)
+	method := strings.ToUpper(strings.TrimSpace(input.Method))
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !allowedMethods[method] {
+		return invalid("unsupported HTTP method %q", input.Method)
+	}
+
+	var headers models.Headers
+	for name, value := range input.Headers {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return invalid("header name cannot be empty")
+		}
+		if strings.ContainsAny(name, " :\r\n") || strings.ContainsAny(value, "\r\n") {
+			return invalid("invalid header %q", name)
+		}
+		if headers == nil {
+			headers = models.Headers{}
+		}
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+
+	if input.Body != nil && *input.Body != "" && (method == http.MethodGet || method == http.MethodHead) {
+		return invalid("request body is not allowed for %s", method)
+	}
+
+	if _, err := utils.ParseStatusSet(input.ExpectedStatus); err != nil {
+		return invalid("expected_status: %v", err)
+	}
+
+	node.URL = input.URL
+	node.Method = method
+	node.Headers = headers
+	if input.Body != nil && *input.Body != "" {
+		node.Body = input.Body
+	}
+	node.ExpectedStatus = strings.TrimSpace(input.ExpectedStatus)
+	return nil
+}
+
+// validateTCPNode accepts host:port or tcp://host:port and stores the latter
+func validateTCPNode(input NodeInput, node *models.Node) error {
+	address := strings.TrimPrefix(strings.TrimSpace(input.URL), "tcp://")
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || host == "" {
+		return invalid("invalid TCP target, must be host:port")
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return invalid("invalid TCP port %q", port)
+	}
+
+	node.URL = "tcp://" + net.JoinHostPort(host, port)
+	node.Expect = input.Expect
+	return nil
+}
+
+// validateDNSNode accepts name or dns://name and stores the latter
+func validateDNSNode(input NodeInput, node *models.Node) error {
+	name := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(input.URL), "dns://"), "/")
+	if name == "" || strings.ContainsAny(name, " /:") {
+		return invalid("invalid DNS name %q", input.URL)
+	}
+
+	recordType := strings.ToUpper(strings.TrimSpace(input.RecordType))
+	if recordType == "" {
+		recordType = models.DNSRecordA
+	}
+	if !dnsRecordTypes[recordType] {
+		return invalid("unsupported DNS record type %q", input.RecordType)
+	}
+
+	resolver := strings.TrimSpace(input.Resolver)
+	if resolver != "" {
+		host := resolver
+		if h, port, err := net.SplitHostPort(resolver); err == nil {
+			if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+				return invalid("invalid resolver port %q", port)
+			}
+			host = h
+		}
+		if host == "" || strings.ContainsAny(host, " /") {
+			return invalid("invalid resolver %q", input.Resolver)
+		}
+	}
+
+	node.URL = "dns://" + strings.ToLower(name)
+	node.RecordType = recordType
+	node.Resolver = resolver
+	node.Expect = input.Expect
+	return nil
+}
+
+func CreateNode(input NodeInput) (*models.Node, error) {
+	node := &models.Node{}
+	if err := validateNodeInput(input, node); err != nil {
+		return nil, err
+	}
+
+	if err := checkArchivedURL(node); err != nil {
+		return nil, err
+	}
+	node.Lifecycle = models.NodeActive
+
+	err := store.Nodes.Create(node)
 	if err != nil {
 		return nil, err
 	}
+	scheduler.Invalidate()
 	return node, nil
 }
 
+// GetAllNodes returns the nodes that are not archived
 func GetAllNodes() ([]models.Node, error) {
 	var nodes []models.Node
-	err := repositories.GetAllNodes(&nodes)
+	err := store.Nodes.All(&nodes)
 	return nodes, err
 }
 
+// GetActiveNodes returns the nodes that are checked, i.e. neither paused nor archived
+func GetActiveNodes() ([]models.Node, error) {
+	nodes, err := GetAllNodes()
+	if err != nil {
+		return nil, err
+	}
+	active := nodes[:0]
+	for _, n := range nodes {
+		if n.IsActive() {
+			active = append(active, n)
+		}
+	}
+	return active, nil
+}
+
 func GetNode(id uint) (*models.Node, error) {
 	if id == 0 {
 		return nil, errors.New("invalid ID")
 	}
-	
+
 	node := &models.Node{}
-	err := repositories.GetNodeByID(id, node)
+	err := store.Nodes.ByID(id, node)
 	if err != nil {
 		return nil, errors.New("node not found")
 	}
 	return node, nil
 }
 
-func UpdateNodeURL(id uint, newURL string) (*models.Node, error) {
+// GetNodeIncludingArchived returns the node even when it is archived
+func GetNodeIncludingArchived(id uint) (*models.Node, error) {
 	if id == 0 {
 		return nil, errors.New("invalid ID")
 	}
-	
-	// Validate URL
-	if strings.TrimSpace(newURL) == "" {
-		return nil, errors.New("URL cannot be empty")
+
+	node := &models.Node{}
+	if err := store.Nodes.Unscoped().ByID(id, node); err != nil {
+		return nil, errors.New("node not found")
 	}
+	return node, nil
+}
 
-	// Parse and validate URL format
-	parsedURL, err := url.Parse(newURL)
-	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-		return nil, errors.New("invalid URL format, must be a valid HTTP/HTTPS URL")
+// GetNodeByURL returns the node monitoring url, archived or not, so the
+// reports of archived nodes keep working
+func GetNodeByURL(url string) (*models.Node, error) {
+	node := &models.Node{}
+	if err := store.Nodes.Unscoped().ByURL(url, node); err != nil {
+		return nil, errors.New("node not found")
+	}
+	return node, nil
+}
+
+// GetNodesWithLogs returns a page of the nodes with their logs, in ID order
+func GetNodesWithLogs(page repositories.Page) ([]models.Node, error) {
+	var nodes []models.Node
+	err := store.Nodes.WithLogs(page, &nodes)
+	return nodes, err
+}
+
+// GetNodesWithHistories returns a page of the nodes with the given URLs and
+// IDs with their histories, newest node first. A nil urls or ids does not
+// filter. Nodes asked for by URL are returned even when archived.
+func GetNodesWithHistories(ctx context.Context, urls []string, ids []uint, page repositories.Page) ([]models.Node, error) {
+	repo := store.Nodes
+	if urls != nil {
+		repo = repo.Unscoped()
+	}
+	var nodes []models.Node
+	err := repo.WithHistories(ctx, urls, ids, page, &nodes)
+	return nodes, err
+}
+
+// CheckNodes runs a full check cycle over nodes, through the scheduler when
+// it is running
+func CheckNodes(nodes []models.Node) error {
+	return scheduler.CheckNow(store, nodes)
+}
+
+func UpdateNode(id uint, input NodeInput) (*models.Node, error) {
+	if id == 0 {
+		return nil, errors.New("invalid ID")
 	}
 
 	node, err := GetNode(id)
 	if err != nil {
 		return nil, err
 	}
-	
-	node.URL = newURL
-	err = repositories.UpdateNode(node)
+
+	if err := validateNodeInput(input, node); err != nil {
+		return nil, err
+	}
+	if err := checkArchivedURL(node); err != nil {
+		return nil, err
+	}
+
+	err = store.Nodes.Update(node)
 	if err != nil {
 		return nil, err
 	}
+	scheduler.Invalidate()
 	return node, nil
 }
 
-func DeleteNodeByID(id uint) error {
-	if id == 0 {
-		return errors.New("invalid ID")
+// MaxBulkAssignNodes is the largest number of nodes one bulk assignment changes
+const MaxBulkAssignNodes = 10000
+
+// BulkAssignInput selects nodes by ID or URL and changes their group and tags
+type BulkAssignInput struct {
+	NodeIDs    []uint   `json:"node_ids"`
+	URLs       []string `json:"urls"`
+	GroupID    *uint    `json:"group_id"`    // moves the nodes to this group
+	ClearGroup bool     `json:"clear_group"` // takes the nodes out of their group
+	AddTags    []string `json:"add_tags"`    // missing tags are created
+	RemoveTags []string `json:"remove_tags"`
+}
+
+// BulkAssignResult tells how many nodes a bulk assignment changed
+type BulkAssignResult struct {
+	Nodes int `json:"nodes"`
+}
+
+// BulkAssign moves the selected nodes to a group and adds or removes tags
+func BulkAssign(input BulkAssignInput) (*BulkAssignResult, error) {
+	if len(input.NodeIDs) == 0 && len(input.URLs) == 0 {
+		return nil, invalid("node_ids or urls is required")
 	}
-	
-	node, err := GetNode(id)
+	if input.GroupID != nil && input.ClearGroup {
+		return nil, invalid("group_id and clear_group cannot be used together")
+	}
+	if input.GroupID == nil && !input.ClearGroup && len(input.AddTags) == 0 && len(input.RemoveTags) == 0 {
+		return nil, invalid("nothing to assign, set group_id, clear_group, add_tags or remove_tags")
+	}
+	if input.GroupID != nil {
+		if _, err := GetGroup(*input.GroupID); err != nil {
+			return nil, invalid("group %d does not exist", *input.GroupID)
+		}
+	}
+
+	removed := make(map[string]bool, len(input.RemoveTags))
+	for _, name := range input.RemoveTags {
+		name, err := normalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		removed[name] = true
+	}
+	for _, name := range input.AddTags {
+		name, err := normalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		if removed[name] {
+			return nil, invalid("tag %q is both added and removed", name)
+		}
+	}
+
+	ids, err := selectNodes(input.NodeIDs, input.URLs)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if input.GroupID != nil || input.ClearGroup {
+		if err := store.Nodes.SetGroup(ids, input.GroupID); err != nil {
+			return nil, err
+		}
+		scheduler.Invalidate()
+	}
+	if len(input.AddTags) > 0 {
+		tags, err := ensureTags(input.AddTags)
+		if err != nil {
+			return nil, err
+		}
+		if err := repositories.AddNodeTags(ids, tagIDs(tags)); err != nil {
+			return nil, err
+		}
+	}
+	if len(input.RemoveTags) > 0 {
+		tags, err := findTags(input.RemoveTags)
+		if err != nil {
+			return nil, err
+		}
+		if err := repositories.RemoveNodeTags(ids, tagIDs(tags)); err != nil {
+			return nil, err
+		}
+	}
+	return &BulkAssignResult{Nodes: len(ids)}, nil
+}
+
+// selectNodes resolves node IDs and URLs to the IDs of existing nodes,
+// without duplicates
+func selectNodes(nodeIDs []uint, urls []string) ([]uint, error) {
+	var nodes []models.Node
+	if err := store.Nodes.All(&nodes); err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]bool, len(nodes))
+	byURL := make(map[string]uint, len(nodes))
+	for _, n := range nodes {
+		byID[n.ID] = true
+		byURL[n.URL] = n.ID
+	}
+
+	var ids []uint
+	seen := make(map[uint]bool)
+	add := func(id uint) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range nodeIDs {
+		if !byID[id] {
+			return nil, invalid("node %d does not exist", id)
+		}
+		add(id)
+	}
+	for _, u := range urls {
+		id, ok := byURL[strings.TrimSpace(u)]
+		if !ok {
+			return nil, invalid("no node monitors %q", u)
+		}
+		add(id)
+	}
+	if len(ids) > MaxBulkAssignNodes {
+		return nil, invalid("cannot assign more than %d nodes at once", MaxBulkAssignNodes)
+	}
+	return ids, nil
+}
+
+func tagIDs(tags []models.Tag) []uint {
+	ids := make([]uint, len(tags))
+	for i, t := range tags {
+		ids[i] = t.ID
 	}
-	return repositories.DeleteNode(node)
+	return ids
 }
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"uptime/models"
+)
+
+func TestValidateNodeInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input NodeInput
+		want  models.Node
+		err   string
+	}{
+		{
+			name:  "http defaults",
+			input: NodeInput{URL: "https://example.com/health"},
+			want:  models.Node{Type: models.NodeTypeHTTP, URL: "https://example.com/health", Method: "GET"},
+		},
+		{
+			name:  "http method and headers",
+			input: NodeInput{URL: "https://example.com", Method: "post", Headers: map[string]string{"x-token": "secret"}},
+			want:  models.Node{Type: models.NodeTypeHTTP, URL: "https://example.com", Method: "POST", Headers: models.Headers{"X-Token": "secret"}},
+		},
+		{
+			name:  "tcp has no method",
+			input: NodeInput{Type: "TCP", URL: "db.internal:5432", Method: "POST", Expect: "ready"},
+			want:  models.Node{Type: models.NodeTypeTCP, URL: "tcp://db.internal:5432", Expect: "ready"},
+		},
+		{
+			name:  "dns has no method",
+			input: NodeInput{Type: "dns", URL: "dns://Example.COM", Method: "GET"},
+			want:  models.Node{Type: models.NodeTypeDNS, URL: "dns://example.com", RecordType: models.DNSRecordA},
+		},
+		{name: "empty url", input: NodeInput{}, err: "URL cannot be empty"},
+		{name: "body with GET", input: NodeInput{URL: "http://example.com", Body: strPtr("x")}, err: "request body is not allowed for GET"},
+		{name: "unknown method", input: NodeInput{URL: "http://example.com", Method: "FETCH"}, err: `unsupported HTTP method "FETCH"`},
+		{name: "short interval", input: NodeInput{URL: "http://example.com", Interval: uintPtr(5)}, err: "interval must be between"},
+		{name: "tcp without port", input: NodeInput{Type: "tcp", URL: "db.internal"}, err: "must be host:port"},
+		{name: "unknown type", input: NodeInput{Type: "icmp", URL: "example.com"}, err: `unsupported node type "icmp"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a stale method from an earlier HTTP configuration must not survive
+			node := models.Node{Method: "PUT"}
+			err := validateNodeInput(tt.input, &node)
+			if tt.err != "" {
+				if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if node.Type != tt.want.Type || node.URL != tt.want.URL || node.Method != tt.want.Method ||
+				node.Expect != tt.want.Expect || node.RecordType != tt.want.RecordType ||
+				len(node.Headers) != len(tt.want.Headers) || node.Headers["X-Token"] != tt.want.Headers["X-Token"] {
+				t.Errorf("node = %+v, want %+v", node, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateNodeKeepsOmittedFields(t *testing.T) {
+	openDB(t)
+	group := createTestGroup(t, "update group", nil)
+	created, err := CreateNode(NodeInput{URL: "https://update.test", GroupID: &group.ID, Interval: uintPtr(60), SkipDefaultRules: boolPtr(true)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a PUT without them keeps the group, interval and rule setting
+	node, err := UpdateNode(created.ID, NodeInput{URL: "https://update.test/health", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.URL != "https://update.test/health" || node.Timeout != 5 {
+		t.Errorf("node = %+v, want the new URL and timeout", node)
+	}
+	if node.GroupID == nil || *node.GroupID != group.ID || node.Interval != 60 || !node.SkipDefaultRules {
+		t.Errorf("group %v, interval %d, skip %v, want the stored values", node.GroupID, node.Interval, node.SkipDefaultRules)
+	}
+
+	// given ones replace them, a 0 group takes the node out of its group
+	node, err = UpdateNode(created.ID, NodeInput{URL: "https://update.test/health", GroupID: uintPtr(0), Interval: uintPtr(0), SkipDefaultRules: boolPtr(false)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := GetNode(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []*models.Node{node, stored} {
+		if n.GroupID != nil || n.Interval != 0 || n.SkipDefaultRules {
+			t.Errorf("group %v, interval %d, skip %v, want them cleared", n.GroupID, n.Interval, n.SkipDefaultRules)
+		}
+	}
+
+	if _, err := UpdateNode(created.ID, NodeInput{URL: "https://update.test", GroupID: uintPtr(99999)}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("UpdateNode() into a missing group = %v", err)
+	}
+}
+
+func TestBulkAssign(t *testing.T) {
+	openDB(t)
+	group := createTestGroup(t, "bulk group", nil)
+	a := createTestNode(t, "https://bulk-a.test", nil)
+	b := createTestNode(t, "https://bulk-b.test", nil)
+	missing := uint(99999)
+
+	tests := []struct {
+		name  string
+		input BulkAssignInput
+		err   string
+	}{
+		{name: "no nodes", input: BulkAssignInput{GroupID: &group.ID}, err: "node_ids or urls is required"},
+		{name: "nothing to do", input: BulkAssignInput{NodeIDs: []uint{a.ID}}, err: "nothing to assign"},
+		{name: "group and clear", input: BulkAssignInput{NodeIDs: []uint{a.ID}, GroupID: &group.ID, ClearGroup: true}, err: "cannot be used together"},
+		{name: "missing group", input: BulkAssignInput{NodeIDs: []uint{a.ID}, GroupID: &missing}, err: "group 99999 does not exist"},
+		{name: "missing node", input: BulkAssignInput{NodeIDs: []uint{missing}, ClearGroup: true}, err: "node 99999 does not exist"},
+		{name: "unknown url", input: BulkAssignInput{URLs: []string{"https://bulk-c.test"}, ClearGroup: true}, err: "no node monitors"},
+		{name: "added and removed", input: BulkAssignInput{NodeIDs: []uint{a.ID}, AddTags: []string{"Gold"}, RemoveTags: []string{"gold "}}, err: "both added and removed"},
+		{name: "empty tag", input: BulkAssignInput{NodeIDs: []uint{a.ID}, AddTags: []string{" "}}, err: "tag name cannot be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := BulkAssign(tt.input); !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+
+	groupOf := func(id uint) *uint {
+		t.Helper()
+		n, err := GetNode(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n.GroupID
+	}
+
+	// nodes picked by ID and URL are counted once
+	result, err := BulkAssign(BulkAssignInput{NodeIDs: []uint{a.ID, b.ID}, URLs: []string{" https://bulk-a.test"}, GroupID: &group.ID, AddTags: []string{"Bulk-Tag"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Nodes != 2 {
+		t.Errorf("%d nodes assigned, want 2", result.Nodes)
+	}
+	for _, id := range []uint{a.ID, b.ID} {
+		if g := groupOf(id); g == nil || *g != group.ID {
+			t.Errorf("node %d in group %v, want %d", id, g, group.ID)
+		}
+		if tags, err := GetNodeTags(id); err != nil || len(tags) != 1 || tags[0].Name != "bulk-tag" {
+			t.Errorf("node %d tags = %+v, %v, want bulk-tag", id, tags, err)
+		}
+	}
+
+	if _, err := BulkAssign(BulkAssignInput{NodeIDs: []uint{a.ID}, ClearGroup: true, RemoveTags: []string{"BULK-TAG"}}); err != nil {
+		t.Fatal(err)
+	}
+	if g := groupOf(a.ID); g != nil {
+		t.Errorf("node %d still in group %d", a.ID, *g)
+	}
+	if g := groupOf(b.ID); g == nil || *g != group.ID {
+		t.Errorf("node %d left its group", b.ID)
+	}
+	if tags, _ := GetNodeTags(a.ID); len(tags) != 0 {
+		t.Errorf("node %d tags = %+v, want none", a.ID, tags)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func uintPtr(n uint) *uint {
+	return &n
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"errors"
+	"time"
+	"uptime/internal/maintenance"
+	"uptime/internal/retention"
+	"uptime/internal/rollup"
+	"uptime/internal/sla"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// MaxSLARange is the longest range an SLA report covers
+const MaxSLARange = 366 * 24 * time.Hour
+
+// SLAQuery selects the nodes and the range of an SLA report. Exactly one of
+// NodeID, URL and GroupID is set; a group includes its subgroups.
+type SLAQuery struct {
+	NodeID  uint
+	URL     string
+	GroupID uint
+	From    time.Time
+	To      time.Time
+}
+
+// NodeSLA is the SLA report of one node of a group
+type NodeSLA struct {
+	NodeID uint   `json:"node_id"`
+	URL    string `json:"url"`
+	sla.Report
+}
+
+// SLAReport is the SLA report of a node, or of a group together with its
+// nodes and the nodes of its subgroups
+type SLAReport struct {
+	sla.Report
+	RollupTier string    `json:"rollup_tier,omitempty"` // set when part of the range was read from rollups
+	Nodes      []NodeSLA `json:"nodes,omitempty"`
+}
+
+// GetSLAReport computes uptime, downtime, incident and latency figures from
+// the time between checks. Maintenance time is left out of the uptime
+// percentage. The part of the range older than the raw log retention is read
+// from rollups.
+func GetSLAReport(query SLAQuery) (*SLAReport, error) {
+	selectors := 0
+	for _, set := range []bool{query.NodeID != 0, query.URL != "", query.GroupID != 0} {
+		if set {
+			selectors++
+		}
+	}
+	if selectors != 1 {
+		return nil, invalid("exactly one of node_id, url and group_id is required")
+	}
+	if !query.To.After(query.From) {
+		return nil, invalid("end of range is before its start")
+	}
+	if query.To.Sub(query.From) > MaxSLARange {
+		return nil, invalid("range cannot exceed 366 days")
+	}
+
+	from, to := query.From, query.To
+	if now := time.Now(); to.After(now) {
+		to = now
+	}
+	if !to.After(from) {
+		return nil, invalid("range starts in the future")
+	}
+
+	var nodes []models.Node
+	switch {
+	case query.NodeID != 0:
+		node, err := GetNodeIncludingArchived(query.NodeID)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, *node)
+	case query.URL != "":
+		var node models.Node
+		if err := store.Nodes.Unscoped().ByURL(query.URL, &node); err != nil {
+			return nil, errors.New("node not found")
+		}
+		nodes = append(nodes, node)
+	default:
+		if _, err := GetGroup(query.GroupID); err != nil {
+			return nil, err
+		}
+		ids, err := ScopedNodeIDs(NodeScope{GroupID: query.GroupID})
+		if err != nil {
+			return nil, err
+		}
+		// archived nodes no longer count towards their group
+		if err := store.Nodes.ByIDs(ids, &nodes); err != nil {
+			return nil, err
+		}
+	}
+
+	policies, err := retention.Load()
+	if err != nil {
+		return nil, err
+	}
+	inputs, tier, err := slaInputs(nodes, policies, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SLAReport{Report: sla.Compute(from, to, inputs...), RollupTier: tier}
+	if query.GroupID != 0 {
+		report.Nodes = make([]NodeSLA, len(nodes))
+		for i, n := range nodes {
+			report.Nodes[i] = NodeSLA{NodeID: n.ID, URL: n.URL, Report: sla.Compute(from, to, inputs[i])}
+		}
+	}
+	return report, nil
+}
+
+// slaInputs loads the check results, maintenance windows and incidents of
+// nodes. Each node's range is planned under the retention of its own group;
+// the coarsest rollup tier read is returned.
+func slaInputs(nodes []models.Node, policies *retention.Set, from, to time.Time) ([]sla.Input, string, error) {
+	if len(nodes) == 0 {
+		return nil, "", nil
+	}
+
+	ids := make([]uint, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	var incidents []models.Incident
+	if err := store.Incidents.StartedBetween(ids, from, to, &incidents); err != nil {
+		return nil, "", err
+	}
+	byNode := make(map[uint][]models.Incident)
+	for _, inc := range incidents {
+		byNode[inc.NodeID] = append(byNode[inc.NodeID], inc)
+	}
+
+	windows := maintenance.Load()
+	now := time.Now()
+	tier := ""
+	inputs := make([]sla.Input, len(nodes))
+	for i, n := range nodes {
+		plan := rollup.PlanRange(from, to, now, policies.For(n.GroupID))
+		if plan.Tier == models.RollupDaily || tier == "" {
+			tier = plan.Tier
+		}
+
+		var rollups []models.NodeLogRollup
+		if plan.Tier != "" {
+			if err := repositories.GetRollups(plan.Tier, n.ID, plan.RollupFrom, plan.RollupTo(to), &rollups); err != nil {
+				return nil, "", err
+			}
+		}
+
+		var logs []models.NodeLog
+		if plan.RawFrom.Before(to) {
+			if err := store.NodeLogs.InRange(n.ID, plan.RawFrom, to, &logs); err != nil {
+				return nil, "", err
+			}
+		}
+
+		samples := make([]sla.Sample, len(logs))
+		for j, l := range logs {
+			samples[j] = sla.Sample{
+				Time:        l.CreatedAt,
+				State:       models.NodeState(l.Up, l.Suspended),
+				Maintenance: l.Maintenance,
+				Delay:       l.Delay,
+			}
+		}
+
+		inputs[i] = sla.Input{
+			Samples:     samples,
+			RawFrom:     plan.RawFrom,
+			Rollups:     rollups,
+			MaxGap:      sla.MaxGap(n),
+			Maintenance: windows.For(n.ID, n.GroupID, from, to),
+			Incidents:   byNode[n.ID],
+		}
+	}
+	return inputs, tier, nil
+}
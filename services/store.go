@@ -0,0 +1,12 @@
+package services
+
+import "uptime/repositories"
+
+// store holds the nodes, logs, histories and incidents the services work on
+var store *repositories.Store
+
+// SetStore sets the store used by the services. It must be called before
+// any service is used.
+func SetStore(s *repositories.Store) {
+	store = s
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// MaxTagLength is the longest tag name accepted
+const MaxTagLength = 64
+
+// TagInput holds the user supplied fields of a tag
+type TagInput struct {
+	Name string `json:"name"`
+}
+
+// normalizeTagName trims and lowercases a tag name, so tags match regardless of case
+func normalizeTagName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", invalid("tag name cannot be empty")
+	}
+	if utf8.RuneCountInString(name) > MaxTagLength {
+		return "", invalid("tag name cannot exceed %d characters", MaxTagLength)
+	}
+	if strings.ContainsAny(name, ",\r\n") {
+		return "", invalid("invalid tag name %q", name)
+	}
+	return name, nil
+}
+
+// validateTagInput normalizes input and copies it onto tag. Names are
+// checked for duplicates here since SQLite words the unique index error
+// differently from the other drivers.
+func validateTagInput(input TagInput, tag *models.Tag) error {
+	name, err := normalizeTagName(input.Name)
+	if err != nil {
+		return err
+	}
+	if existing, err := GetTagByName(name); err == nil && existing.ID != tag.ID {
+		return fmt.Errorf("duplicate tag name %q", name)
+	}
+	tag.Name = name
+	return nil
+}
+
+func CreateTag(input TagInput) (*models.Tag, error) {
+	tag := &models.Tag{}
+	if err := validateTagInput(input, tag); err != nil {
+		return nil, err
+	}
+
+	err := repositories.CreateTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func GetAllTags() ([]models.Tag, error) {
+	var tags []models.Tag
+	err := repositories.GetAllTags(&tags)
+	return tags, err
+}
+
+func GetTag(id uint) (*models.Tag, error) {
+	if id == 0 {
+		return nil, errors.New("invalid ID")
+	}
+
+	tag := &models.Tag{}
+	err := repositories.GetTagByID(id, tag)
+	if err != nil {
+		return nil, errors.New("tag not found")
+	}
+	return tag, nil
+}
+
+// GetTagByName returns the tag called name, ignoring case
+func GetTagByName(name string) (*models.Tag, error) {
+	tags, err := findTags([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, errors.New("tag not found")
+	}
+	return &tags[0], nil
+}
+
+func UpdateTag(id uint, input TagInput) (*models.Tag, error) {
+	tag, err := GetTag(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateTagInput(input, tag); err != nil {
+		return nil, err
+	}
+
+	err = repositories.UpdateTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func DeleteTagByID(id uint) error {
+	tag, err := GetTag(id)
+	if err != nil {
+		return err
+	}
+	return repositories.DeleteTag(tag)
+}
+
+// GetNodeTags returns the tags of a node
+func GetNodeTags(nodeID uint) ([]models.Tag, error) {
+	if _, err := GetNodeIncludingArchived(nodeID); err != nil {
+		return nil, err
+	}
+	tags, err := repositories.GetNodeTags([]uint{nodeID})
+	if err != nil {
+		return nil, err
+	}
+	if tags[nodeID] == nil {
+		return []models.Tag{}, nil
+	}
+	return tags[nodeID], nil
+}
+
+// findTags loads the tags with the given names
+func findTags(names []string) ([]models.Tag, error) {
+	normalized := make([]string, len(names))
+	for i, name := range names {
+		n, err := normalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		normalized[i] = n
+	}
+	var tags []models.Tag
+	err := repositories.GetTagsByName(normalized, &tags)
+	return tags, err
+}
+
+// ensureTags loads the tags with the given names, creating the missing ones
+func ensureTags(names []string) ([]models.Tag, error) {
+	tags, err := findTags(names)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		existing[t.Name] = true
+	}
+	for _, name := range names {
+		name, _ = normalizeTagName(name)
+		if existing[name] {
+			continue
+		}
+		tag := models.Tag{Name: name}
+		if err := repositories.CreateTag(&tag); err != nil {
+			return nil, err
+		}
+		existing[name] = true
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
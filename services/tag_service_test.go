@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTagName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		err  string
+	}{
+		{name: "gold", want: "gold"},
+		{name: "  Gold Plan ", want: "gold plan"},
+		{name: strings.Repeat("ж", MaxTagLength), want: strings.Repeat("ж", MaxTagLength)},
+		{name: " ", err: "tag name cannot be empty"},
+		{name: strings.Repeat("a", MaxTagLength+1), err: "cannot exceed"},
+		{name: "a,b", err: "invalid tag name"},
+		{name: "a\nb", err: "invalid tag name"},
+	}
+	for _, tt := range tests {
+		got, err := normalizeTagName(tt.name)
+		if tt.err != "" {
+			if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("normalizeTagName(%q) err = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("normalizeTagName(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	openDB(t)
+	tag, err := CreateTag(TagInput{Name: " Premium "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.Name != "premium" {
+		t.Errorf("name = %q, want premium", tag.Name)
+	}
+	// names are unique regardless of case
+	if _, err := CreateTag(TagInput{Name: "PREMIUM"}); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("CreateTag() of a duplicate = %v", err)
+	}
+	if found, err := GetTagByName("PreMium"); err != nil || found.ID != tag.ID {
+		t.Errorf("GetTagByName() = %+v, %v", found, err)
+	}
+
+	other, err := CreateTag(TagInput{Name: "basic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UpdateTag(other.ID, TagInput{Name: "Premium"}); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("UpdateTag() to a taken name = %v", err)
+	}
+	if updated, err := UpdateTag(tag.ID, TagInput{Name: "PREMIUM"}); err != nil || updated.Name != "premium" {
+		t.Errorf("UpdateTag() to its own name = %+v, %v", updated, err)
+	}
+
+	// missing tags are created once
+	tags, err := ensureTags([]string{"basic", "Trial", "trial"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 {
+		t.Errorf("ensureTags() = %+v, want basic and trial", tags)
+	}
+	if _, err := GetTagByName("trial"); err != nil {
+		t.Errorf("trial was not created: %v", err)
+	}
+
+	if err := DeleteTagByID(other.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetTag(other.ID); err == nil {
+		t.Error("deleted tag still exists")
+	}
+}
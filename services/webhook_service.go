@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"uptime/internal/notify"
+	"uptime/models"
+	"uptime/repositories"
+)
+
+// DefaultWebhookAttempts is used when a webhook does not set max_attempts
+const DefaultWebhookAttempts = 5
+
+// MaxDeliveryAttempts is the largest max_attempts accepted by any notification channel
+const MaxDeliveryAttempts = 10
+
+// notificationEvents are the events a notification channel can subscribe to
+var notificationEvents = map[string]bool{
+	notify.EventDown:      true,
+	notify.EventUp:        true,
+	notify.EventSuspended: true,
+}
+
+// validateEvents checks a list of event names and joins it for storage
+func validateEvents(input []string) (string, error) {
+	var events []string
+	for _, event := range input {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if !notificationEvents[event] {
+			return "", invalid("unsupported event %q", event)
+		}
+		events = append(events, event)
+	}
+	return strings.Join(events, ","), nil
+}
+
+// WebhookInput holds the user supplied fields of a webhook
+type WebhookInput struct {
+	Name        string   `json:"name"`
+	URL         string   `json:"url"`
+	Secret      *string  `json:"secret"` // omitted on update keeps the current secret, "" removes it
+	Events      []string `json:"events"` // down, up, suspended; empty means all
+	MaxAttempts uint     `json:"max_attempts"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+// validateWebhookInput normalizes input and copies it onto webhook
+func validateWebhookInput(input WebhookInput, webhook *models.Webhook) error {
+	parsedURL, err := url.Parse(strings.TrimSpace(input.URL))
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return invalid("invalid URL format, must be a valid HTTP/HTTPS URL")
+	}
+
+	events, err := validateEvents(input.Events)
+	if err != nil {
+		return err
+	}
+
+	maxAttempts := input.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultWebhookAttempts
+	}
+	if maxAttempts > MaxDeliveryAttempts {
+		return invalid("max_attempts cannot exceed %d", MaxDeliveryAttempts)
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = parsedURL.Host
+	}
+
+	webhook.Name = name
+	webhook.URL = parsedURL.String()
+	if input.Secret != nil {
+		webhook.Secret = *input.Secret
+	}
+	webhook.Events = events
+	webhook.MaxAttempts = maxAttempts
+	webhook.Enabled = input.Enabled == nil || *input.Enabled
+	return nil
+}
+
+func CreateWebhook(input WebhookInput) (*models.Webhook, error) {
+	webhook := &models.Webhook{}
+	if err := validateWebhookInput(input, webhook); err != nil {
+		return nil, err
+	}
+
+	err := repositories.CreateWebhook(webhook)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func GetAllWebhooks() ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := repositories.GetAllWebhooks(&webhooks)
+	return webhooks, err
+}
+
+func GetWebhook(id uint) (*models.Webhook, error) {
+	if id == 0 {
+		return nil, errors.New("invalid ID")
+	}
+
+	webhook := &models.Webhook{}
+	err := repositories.GetWebhookByID(id, webhook)
+	if err != nil {
+		return nil, errors.New("webhook not found")
+	}
+	return webhook, nil
+}
+
+func UpdateWebhook(id uint, input WebhookInput) (*models.Webhook, error) {
+	webhook, err := GetWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateWebhookInput(input, webhook); err != nil {
+		return nil, err
+	}
+
+	err = repositories.UpdateWebhook(webhook)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func DeleteWebhookByID(id uint) error {
+	webhook, err := GetWebhook(id)
+	if err != nil {
+		return err
+	}
+	return repositories.DeleteWebhook(webhook)
+}
+
+// TestWebhook sends a test event to the webhook and returns the delivery
+func TestWebhook(id uint) (*models.NotificationDelivery, error) {
+	webhook, err := GetWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+	return notify.TestWebhook(*webhook)
+}
+
+// DeliveryQuery holds the delivery log filters accepted by the API
+type DeliveryQuery struct {
+	Channel  string
+	TargetID uint
+	NodeID   uint
+	Status   string
+	AfterID  uint
+	Limit    int
+}
+
+func GetNotificationDeliveries(query DeliveryQuery) ([]models.NotificationDelivery, error) {
+	switch query.Status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryFailed:
+	default:
+		return nil, invalid("status must be %s, %s or %s", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryFailed)
+	}
+
+	var deliveries []models.NotificationDelivery
+	err := repositories.FindNotificationDeliveries(repositories.DeliveryFilter{
+		Channel:  query.Channel,
+		TargetID: query.TargetID,
+		NodeID:   query.NodeID,
+		Status:   query.Status,
+		AfterID:  query.AfterID,
+		Limit:    query.Limit,
+	}, &deliveries)
+	return deliveries, err
+}
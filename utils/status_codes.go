@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusRange is an inclusive range of HTTP status codes
+type StatusRange struct {
+	From int
+	To   int
+}
+
+// StatusSet is a list of accepted HTTP status codes and ranges
+type StatusSet []StatusRange
+
+// DefaultStatusSet accepts every 2xx response
+var DefaultStatusSet = StatusSet{{From: 200, To: 299}}
+
+// ParseStatusSet parses a spec such as "200-299,301,401".
+// An empty spec yields DefaultStatusSet.
+func ParseStatusSet(spec string) (StatusSet, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return DefaultStatusSet, nil
+	}
+
+	var set StatusSet
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		from, to := part, part
+		if i := strings.Index(part, "-"); i >= 0 {
+			from, to = strings.TrimSpace(part[:i]), strings.TrimSpace(part[i+1:])
+		}
+
+		f, err := parseStatusCode(from)
+		if err != nil {
+			return nil, err
+		}
+		t, err := parseStatusCode(to)
+		if err != nil {
+			return nil, err
+		}
+		if f > t {
+			return nil, fmt.Errorf("invalid status range %q", part)
+		}
+		set = append(set, StatusRange{From: f, To: t})
+	}
+
+	if len(set) == 0 {
+		return DefaultStatusSet, nil
+	}
+	return set, nil
+}
+
+// Contains reports whether code is accepted by the set
+func (s StatusSet) Contains(code int) bool {
+	for _, r := range s {
+		if code >= r.From && code <= r.To {
+			return true
+		}
+	}
+	return false
+}
+
+func parseStatusCode(s string) (int, error) {
+	code, err := strconv.Atoi(s)
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q", s)
+	}
+	return code, nil
+}
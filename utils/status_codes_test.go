@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseStatusSet(t *testing.T) {
+	tests := []struct {
+		spec string
+		want StatusSet
+		err  string
+	}{
+		{spec: "", want: DefaultStatusSet},
+		{spec: " , ", want: DefaultStatusSet},
+		{spec: "200", want: StatusSet{{200, 200}}},
+		{spec: "200-299,301,401", want: StatusSet{{200, 299}, {301, 301}, {401, 401}}},
+		{spec: " 200 - 204 , 404 ", want: StatusSet{{200, 204}, {404, 404}}},
+		{spec: "100-599", want: StatusSet{{100, 599}}},
+		{spec: "abc", err: `invalid status code "abc"`},
+		{spec: "99", err: `invalid status code "99"`},
+		{spec: "600", err: `invalid status code "600"`},
+		{spec: "200-", err: `invalid status code ""`},
+		{spec: "299-200", err: `invalid status range "299-200"`},
+		{spec: "200,2xx", err: `invalid status code "2xx"`},
+	}
+	for _, tt := range tests {
+		got, err := ParseStatusSet(tt.spec)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseStatusSet(%q) err = %v, want %q", tt.spec, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseStatusSet(%q) = %v, %v, want %v", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+func TestStatusSetContains(t *testing.T) {
+	set := StatusSet{{200, 299}, {301, 301}}
+	tests := []struct {
+		code int
+		want bool
+	}{
+		{199, false},
+		{200, true},
+		{299, true},
+		{300, false},
+		{301, true},
+		{404, false},
+	}
+	for _, tt := range tests {
+		if got := set.Contains(tt.code); got != tt.want {
+			t.Errorf("Contains(%d) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}